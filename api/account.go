@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"errors"
 	"net/http"
+	"time"
 
 	db "simplebank/db/sqlc"
 	"simplebank/token"
@@ -14,6 +15,10 @@ import (
 
 type createAccountRequest struct {
 	Currency string `json:"currency" binding:"required,currency"`
+	// Type defaults to a checking account
+	Type        string `json:"type" binding:"omitempty,oneof=checking savings"`
+	Name        string `json:"name" binding:"max=50"`
+	Description string `json:"description" binding:"max=500"`
 }
 
 func (server *Server) createAccount(ctx *gin.Context) {
@@ -28,16 +33,30 @@ func (server *Server) createAccount(ctx *gin.Context) {
 
 	// Getting the user which made the request
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	accountType := req.Type
+	if accountType == "" {
+		accountType = db.AccountTypeChecking
+	}
 	// Getting args provided on the request body
-	arg := db.CreateAccountParams{
-		Owner:    authPayload.Username,
-		Currency: req.Currency,
-		Balance:  0,
+	arg := db.CreateAccountTxParams{
+		CreateAccountParams: db.CreateAccountParams{
+			Owner:       authPayload.Username,
+			Currency:    req.Currency,
+			Balance:     0,
+			Type:        accountType,
+			Name:        req.Name,
+			Description: req.Description,
+		},
+		MaxPerCurrency: server.config.MaxAccountsPerCurrency,
 	}
 
 	// Creating the account
-	account, err := server.store.CreateAccount(ctx, arg)
+	account, err := server.store.CreateAccountTx(ctx, arg)
 	if err != nil {
+		if errors.Is(err, db.ErrAccountLimitReached) {
+			ctx.JSON(http.StatusForbidden, errorCodeResponse(errCodeAccountLimitReached, err))
+			return
+		}
 		if pqErr, ok := err.(*pq.Error); ok {
 			switch pqErr.Code.Name() {
 			case "foreign_key_violation", "unique_violation":
@@ -76,12 +95,8 @@ func (server *Server) getAccount(ctx *gin.Context) {
 		return
 	}
 
-	// Getting the user which made the request
-	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
-	// Checking if user owns account
-	if account.Owner != authPayload.Username {
-		err := errors.New("account doesn't belong to the authenticated user")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+	// Checking if the account is shared with the user
+	if _, ok := server.authorizeAccount(ctx, account, db.AccountRoleViewer); !ok {
 		return
 	}
 
@@ -89,8 +104,11 @@ func (server *Server) getAccount(ctx *gin.Context) {
 }
 
 type listAccountRequest struct {
-	PageID   int32 `form:"page_id" binding:"required,min=1"`
-	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+	Currency string `form:"currency" binding:"omitempty,currency"`
+	// Name matches accounts with the text anywhere in their name
+	Name     string `form:"name" binding:"max=50"`
+	PageID   int32  `form:"page_id" binding:"required,min=1"`
+	PageSize int32  `form:"page_size" binding:"required,min=5,max=10"`
 }
 
 func (server *Server) listAccounts(ctx *gin.Context) {
@@ -105,11 +123,17 @@ func (server *Server) listAccounts(ctx *gin.Context) {
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
 	// Here, we'll get the query params
 	arg := db.ListAccountsParams{
-		Owner: authPayload.Username,
-		Limit: req.PageSize,
+		Username: authPayload.Username,
+		Limit:    req.PageSize,
 		// Calculating the offset from page number and size
 		Offset: (req.PageID - 1) * req.PageSize,
 	}
+	if req.Currency != "" {
+		arg.Currency = sql.NullString{String: req.Currency, Valid: true}
+	}
+	if req.Name != "" {
+		arg.Name = sql.NullString{String: req.Name, Valid: true}
+	}
 
 	accounts, err := server.store.ListAccounts(ctx, arg)
 	if err != nil {
@@ -119,3 +143,258 @@ func (server *Server) listAccounts(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, accounts)
 }
+
+type updateAccountURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type updateAccountRequest struct {
+	// Fields which are left out keep their value
+	Name        *string `json:"name" binding:"omitempty,max=50"`
+	Description *string `json:"description" binding:"omitempty,max=500"`
+}
+
+func (server *Server) updateAccount(ctx *gin.Context) {
+	var uri updateAccountURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req updateAccountRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if req.Name == nil && req.Description == nil {
+		err := errors.New("name or description must be given")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, valid := server.validAccount(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	if _, ok := server.authorizeAccount(ctx, account, db.AccountRoleOwner); !ok {
+		return
+	}
+
+	arg := db.UpdateAccountDetailsParams{
+		ID: account.ID,
+	}
+	if req.Name != nil {
+		arg.Name = sql.NullString{String: *req.Name, Valid: true}
+	}
+	if req.Description != nil {
+		arg.Description = sql.NullString{String: *req.Description, Valid: true}
+	}
+
+	account, err := server.store.UpdateAccountDetails(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, account)
+}
+
+type getAccountBalanceURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type getAccountBalanceQuery struct {
+	At time.Time `form:"at" binding:"required" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+type accountBalanceResponse struct {
+	AccountID int64     `json:"account_id"`
+	Currency  string    `json:"currency"`
+	At        time.Time `json:"at"`
+	Balance   int64     `json:"balance"`
+}
+
+func (server *Server) getAccountBalance(ctx *gin.Context) {
+	var uri getAccountBalanceURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req getAccountBalanceQuery
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if req.At.After(time.Now()) {
+		err := errors.New("balance can't be asked for a time in the future")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, err := server.store.GetAccount(ctx, uri.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// Checking if the account is shared with the user
+	if _, ok := server.authorizeAccount(ctx, account, db.AccountRoleViewer); !ok {
+		return
+	}
+
+	// The balance starts from the last snapshot before the time, instead of summing every entry
+	balance, err := server.store.GetAccountBalanceAt(ctx, db.GetAccountBalanceAtParams{
+		At:        req.At,
+		AccountID: account.ID,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, accountBalanceResponse{
+		AccountID: account.ID,
+		Currency:  account.Currency,
+		At:        req.At,
+		Balance:   balance,
+	})
+}
+
+type accountStatusURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type accountStatusRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+type closeAccountRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+	// PayoutAccountID is another account of the owner which receives the balance, it's required unless the balance is zero
+	PayoutAccountID int64 `json:"payout_account_id" binding:"omitempty,min=1"`
+}
+
+func (server *Server) freezeAccount(ctx *gin.Context) {
+	var req accountStatusRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	server.changeAccountStatus(ctx, db.ChangeAccountStatusTxParams{
+		Status: db.AccountStatusFrozen,
+		Reason: req.Reason,
+	})
+}
+
+func (server *Server) unfreezeAccount(ctx *gin.Context) {
+	var req accountStatusRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	server.changeAccountStatus(ctx, db.ChangeAccountStatusTxParams{
+		Status: db.AccountStatusActive,
+		Reason: req.Reason,
+	})
+}
+
+func (server *Server) closeAccount(ctx *gin.Context) {
+	var req closeAccountRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	server.changeAccountStatus(ctx, db.ChangeAccountStatusTxParams{
+		Status:          db.AccountStatusClosed,
+		Reason:          req.Reason,
+		PayoutAccountID: req.PayoutAccountID,
+	})
+}
+
+// changeAccountStatus changes the status of the account in the URL, which can be done by its owners.
+// An account frozen by the staff of the bank can only be unfrozen by them
+func (server *Server) changeAccountStatus(ctx *gin.Context, arg db.ChangeAccountStatusTxParams) {
+	var uri accountStatusURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, valid := server.validAccount(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	if _, ok := server.authorizeAccount(ctx, account, db.AccountRoleOwner); !ok {
+		return
+	}
+
+	// Getting the user which made the request
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	if arg.Status == db.AccountStatusActive {
+		frozenByStaff, err := server.frozenByStaff(ctx, account)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		if frozenByStaff {
+			err := errors.New("account was frozen by the bank")
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+	}
+
+	arg.AccountID = account.ID
+	arg.ChangedBy = authPayload.Username
+	server.applyAccountStatusChange(ctx, arg)
+}
+
+// applyAccountStatusChange changes the status of an account the user is allowed to act on
+func (server *Server) applyAccountStatusChange(ctx *gin.Context, arg db.ChangeAccountStatusTxParams) {
+	result, err := server.store.ChangeAccountStatusTx(ctx, arg)
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrInvalidStatusChange):
+			ctx.JSON(http.StatusConflict, errorCodeResponse(errCodeInvalidStatusChange, err))
+		case errors.Is(err, db.ErrAccountNotEmpty):
+			ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(errCodeAccountNotEmpty, err))
+		case errors.Is(err, db.ErrAccountHasHolds):
+			ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(errCodeAccountHasHolds, err))
+		case errors.Is(err, db.ErrInvalidPayout):
+			ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(errCodeInvalidPayout, err))
+		case accountStatusErrorCode(err) != "":
+			ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(accountStatusErrorCode(err), err))
+		default:
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// frozenByStaff tells if the account was last frozen by a banker or an admin
+func (server *Server) frozenByStaff(ctx *gin.Context, account db.Account) (bool, error) {
+	change, err := server.store.GetLastAccountStatusChange(ctx, account.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, err
+	}
+
+	// The change records whether it was made by the staff, since the role of whoever made it may have changed since
+	return change.ToStatus == db.AccountStatusFrozen && change.ByStaff, nil
+}
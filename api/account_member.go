@@ -0,0 +1,229 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	db "simplebank/db/sqlc"
+	"simplebank/token"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+// accountRoleRanks orders the roles of account members, each role can do everything the lower ones can
+var accountRoleRanks = map[string]int{
+	db.AccountRoleViewer:  1,
+	db.AccountRoleSpender: 2,
+	db.AccountRoleOwner:   3,
+}
+
+// accountMember gets the membership of the user in the account, the account owner being an owner member
+// of it. It returns sql.ErrNoRows when the account isn't shared with the user
+func (server *Server) accountMember(ctx *gin.Context, account db.Account, username string) (db.AccountMember, error) {
+	if account.Owner == username {
+		return db.AccountMember{
+			AccountID: account.ID,
+			Username:  username,
+			Role:      db.AccountRoleOwner,
+			InvitedBy: username,
+			CreatedAt: account.CreatedAt,
+		}, nil
+	}
+
+	return server.store.GetAccountMember(ctx, db.GetAccountMemberParams{
+		AccountID: account.ID,
+		Username:  username,
+	})
+}
+
+// authorizeAccount checks that the authenticated user is a member of the account with at least the role.
+// The error response is already written when it returns false
+func (server *Server) authorizeAccount(ctx *gin.Context, account db.Account, role string) (db.AccountMember, bool) {
+	// Getting the user which made the request
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	member, err := server.accountMember(ctx, account, authPayload.Username)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err := errors.New("account doesn't belong to the authenticated user")
+			ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+			return member, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return member, false
+	}
+
+	if accountRoleRanks[member.Role] < accountRoleRanks[role] {
+		err := fmt.Errorf("a %s of the account can't do this", member.Role)
+		ctx.JSON(http.StatusForbidden, errorCodeResponse(errCodeAccountRoleNotAllowed, err))
+		return member, false
+	}
+
+	return member, true
+}
+
+// authorizeSpending checks that the authenticated user can take the amount out of the account,
+// which spenders can only do up to their spend limit. The error response is already written when it returns false
+func (server *Server) authorizeSpending(ctx *gin.Context, account db.Account, amount int64) bool {
+	member, ok := server.authorizeAccount(ctx, account, db.AccountRoleSpender)
+	if !ok {
+		return false
+	}
+
+	if member.Role == db.AccountRoleSpender && amount > member.SpendLimit {
+		err := fmt.Errorf("amount %d is over the spend limit of %d", amount, member.SpendLimit)
+		ctx.JSON(http.StatusForbidden, errorCodeResponse(errCodeSpendLimitExceeded, err))
+		return false
+	}
+
+	return true
+}
+
+type accountMembersURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type addAccountMemberRequest struct {
+	Username string `json:"username" binding:"required,alphanum"`
+	Role     string `json:"role" binding:"required,oneof=owner spender viewer"`
+	// SpendLimit is the largest amount a spender can take out of the account at once
+	SpendLimit int64 `json:"spend_limit" binding:"omitempty,gt=0"`
+}
+
+func (server *Server) addAccountMember(ctx *gin.Context) {
+	var uri accountMembersURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req addAccountMemberRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// Only spenders have a limit, and they can't spend anything without one
+	if (req.Role == db.AccountRoleSpender) != (req.SpendLimit > 0) {
+		err := errors.New("spend_limit must be set for spenders, and only for them")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, valid := server.validAccount(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	if _, ok := server.authorizeAccount(ctx, account, db.AccountRoleOwner); !ok {
+		return
+	}
+
+	if req.Username == account.Owner {
+		err := errors.New("user already owns the account")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	arg := db.CreateAccountMemberParams{
+		AccountID:  account.ID,
+		Username:   req.Username,
+		Role:       req.Role,
+		SpendLimit: req.SpendLimit,
+		InvitedBy:  authPayload.Username,
+	}
+
+	member, err := server.store.CreateAccountMember(ctx, arg)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok {
+			switch pqErr.Code.Name() {
+			case "foreign_key_violation", "unique_violation":
+				ctx.JSON(http.StatusForbidden, errorResponse(err))
+				return
+			}
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, member)
+}
+
+func (server *Server) listAccountMembers(ctx *gin.Context) {
+	var uri accountMembersURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, valid := server.validAccount(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	if _, ok := server.authorizeAccount(ctx, account, db.AccountRoleViewer); !ok {
+		return
+	}
+
+	members, err := server.store.ListAccountMembers(ctx, account.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, members)
+}
+
+type removeAccountMemberURI struct {
+	ID       int64  `uri:"id" binding:"required,min=1"`
+	Username string `uri:"username" binding:"required,alphanum"`
+}
+
+func (server *Server) removeAccountMember(ctx *gin.Context) {
+	var uri removeAccountMemberURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, valid := server.validAccount(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	if uri.Username == account.Owner {
+		err := errors.New("the owner can't be removed from the account")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// Members can leave the account, but only owners can remove someone else
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	role := db.AccountRoleOwner
+	if uri.Username == authPayload.Username {
+		role = db.AccountRoleViewer
+	}
+	if _, ok := server.authorizeAccount(ctx, account, role); !ok {
+		return
+	}
+
+	rows, err := server.store.DeleteAccountMember(ctx, db.DeleteAccountMemberParams{
+		AccountID: account.ID,
+		Username:  uri.Username,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if rows == 0 {
+		err := errors.New("user isn't a member of the account")
+		ctx.JSON(http.StatusNotFound, errorResponse(err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,173 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	db "simplebank/db/sqlc"
+	"simplebank/token"
+
+	"github.com/gin-gonic/gin"
+)
+
+// The handlers of the /admin routes are only reached by bankers and admins, so they don't check
+// who the accounts belong to
+
+func (server *Server) adminGetAccount(ctx *gin.Context) {
+	var req getAccountRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, valid := server.validAccount(ctx, req.ID)
+	if !valid {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, account)
+}
+
+type adminListAccountsRequest struct {
+	// Username lists the accounts the user owns or which are shared with them
+	Username string `form:"username" binding:"required,alphanum"`
+	PageID   int32  `form:"page_id" binding:"required,min=1"`
+	PageSize int32  `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+func (server *Server) adminListAccounts(ctx *gin.Context) {
+	var req adminListAccountsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	accounts, err := server.store.ListAccounts(ctx, db.ListAccountsParams{
+		Username: req.Username,
+		Limit:    req.PageSize,
+		Offset:   (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, accounts)
+}
+
+func (server *Server) adminFreezeAccount(ctx *gin.Context) {
+	server.adminChangeAccountStatus(ctx, db.AccountStatusFrozen)
+}
+
+func (server *Server) adminUnfreezeAccount(ctx *gin.Context) {
+	server.adminChangeAccountStatus(ctx, db.AccountStatusActive)
+}
+
+// adminChangeAccountStatus freezes or unfreezes any account, an account frozen by the staff
+// staying frozen until the staff unfreezes it
+func (server *Server) adminChangeAccountStatus(ctx *gin.Context, status string) {
+	var uri accountStatusURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req accountStatusRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, valid := server.validAccount(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	server.applyAccountStatusChange(ctx, db.ChangeAccountStatusTxParams{
+		AccountID: account.ID,
+		Status:    status,
+		ChangedBy: authPayload.Username,
+		ByStaff:   true,
+		Reason:    req.Reason,
+	})
+}
+
+func (server *Server) adminReverseTransfer(ctx *gin.Context) {
+	transfer, req, ok := server.bindReversal(ctx)
+	if !ok {
+		return
+	}
+
+	server.applyReversal(ctx, db.ReverseTransferTxParams{
+		TransferID: transfer.ID,
+		Amount:     req.Amount,
+	})
+}
+
+// adminCreateDeposit deposits money on behalf of the owner of the account, the deposit being theirs
+func (server *Server) adminCreateDeposit(ctx *gin.Context) {
+	var req depositRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, valid := server.validAccount(ctx, req.AccountID)
+	if !valid {
+		return
+	}
+
+	server.applyDeposit(ctx, db.DepositTxParams{
+		AccountID: account.ID,
+		Amount:    req.Amount,
+		User:      account.Owner,
+	})
+}
+
+type updateUserRoleURI struct {
+	Username string `uri:"username" binding:"required,alphanum"`
+}
+
+type updateUserRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=customer banker admin"`
+}
+
+// updateUserRole gives a user another role, signing them out of every device so that their tokens
+// carry the new role. Admins can't change their own role, so there is always one left
+func (server *Server) updateUserRole(ctx *gin.Context) {
+	var uri updateUserRoleURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req updateUserRoleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if uri.Username == authPayload.Username {
+		err := errors.New("admins can't change their own role")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	user, err := server.store.UpdateUserRoleTx(ctx, db.UpdateUserRoleParams{
+		Username: uri.Username,
+		Role:     req.Role,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newUserResponse(user))
+}
@@ -0,0 +1,715 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "simplebank/db/mock"
+	db "simplebank/db/sqlc"
+	"simplebank/token"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+// staffAuthorization authorizes the request as a user with the role
+func staffAuthorization(username string, role string) func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+	return func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+		addRoleAuthorization(t, request, tokenMaker, authorizationTypeBearer, username, role, time.Minute)
+	}
+}
+
+func TestAdminGetAccountAPI(t *testing.T) {
+	customer, _ := randomUser(t)
+	account := randomAccount(customer.Username)
+
+	testCases := []struct {
+		name          string
+		accountID     int64
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recoder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "Banker",
+			accountID: account.ID,
+			setupAuth: staffAuthorization("banker", db.UserRoleBanker),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				// The account isn't shared with the staff
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchAccount(t, recorder.Body, account)
+			},
+		},
+		{
+			name:      "Admin",
+			accountID: account.ID,
+			setupAuth: staffAuthorization("admin", db.UserRoleAdmin),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:      "Customer",
+			accountID: account.ID,
+			setupAuth: staffAuthorization(customer.Username, db.UserRoleCustomer),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireBodyErrorCode(t, recorder.Body, errCodeRoleNotAllowed)
+			},
+		},
+		{
+			name:      "NoAuthorization",
+			accountID: account.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:      "NotFound",
+			accountID: account.ID,
+			setupAuth: staffAuthorization("banker", db.UserRoleBanker),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:      "InvalidID",
+			accountID: 0,
+			setupAuth: staffAuthorization("banker", db.UserRoleBanker),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/admin/accounts/%d", tc.accountID)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestAdminListAccountsAPI(t *testing.T) {
+	customer, _ := randomUser(t)
+
+	n := 5
+	accounts := make([]db.Account, n)
+	for i := range accounts {
+		accounts[i] = randomAccount(customer.Username)
+	}
+
+	testCases := []struct {
+		name          string
+		query         string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recoder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "OK",
+			query:     fmt.Sprintf("username=%s&page_id=1&page_size=%d", customer.Username, n),
+			setupAuth: staffAuthorization("banker", db.UserRoleBanker),
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListAccountsParams{
+					Username: customer.Username,
+					Limit:    int32(n),
+					Offset:   0,
+				}
+				store.EXPECT().ListAccounts(gomock.Any(), gomock.Eq(arg)).Times(1).Return(accounts, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchAccounts(t, recorder.Body, accounts)
+			},
+		},
+		{
+			name:      "MissingUsername",
+			query:     fmt.Sprintf("page_id=1&page_size=%d", n),
+			setupAuth: staffAuthorization("banker", db.UserRoleBanker),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccounts(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "Customer",
+			query:     fmt.Sprintf("username=%s&page_id=1&page_size=%d", customer.Username, n),
+			setupAuth: staffAuthorization(customer.Username, db.UserRoleCustomer),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccounts(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/admin/accounts?"+tc.query, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestAdminChangeAccountStatusAPI(t *testing.T) {
+	customer, _ := randomUser(t)
+	account := randomAccount(customer.Username)
+
+	testCases := []struct {
+		name          string
+		action        string
+		body          gin.H
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recoder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "Freeze",
+			action:    "freeze",
+			body:      gin.H{"reason": "suspected fraud"},
+			setupAuth: staffAuthorization("banker", db.UserRoleBanker),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(0)
+
+				arg := db.ChangeAccountStatusTxParams{
+					AccountID: account.ID,
+					Status:    db.AccountStatusFrozen,
+					ChangedBy: "banker",
+					ByStaff:   true,
+					Reason:    "suspected fraud",
+				}
+				store.EXPECT().
+					ChangeAccountStatusTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.ChangeAccountStatusTxResult{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:      "Unfreeze",
+			action:    "unfreeze",
+			body:      gin.H{"reason": "investigation closed"},
+			setupAuth: staffAuthorization("admin", db.UserRoleAdmin),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				// The staff can unfreeze accounts whoever froze them
+				store.EXPECT().GetLastAccountStatusChange(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().
+					ChangeAccountStatusTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.ChangeAccountStatusTxParams) (db.ChangeAccountStatusTxResult, error) {
+						require.Equal(t, db.AccountStatusActive, arg.Status)
+						require.Equal(t, "admin", arg.ChangedBy)
+						require.True(t, arg.ByStaff)
+						return db.ChangeAccountStatusTxResult{}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:      "Customer",
+			action:    "freeze",
+			body:      gin.H{"reason": "suspected fraud"},
+			setupAuth: staffAuthorization(customer.Username, db.UserRoleCustomer),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ChangeAccountStatusTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:      "MissingReason",
+			action:    "freeze",
+			body:      gin.H{},
+			setupAuth: staffAuthorization("banker", db.UserRoleBanker),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ChangeAccountStatusTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "InvalidStatusChange",
+			action:    "unfreeze",
+			body:      gin.H{"reason": "investigation closed"},
+			setupAuth: staffAuthorization("banker", db.UserRoleBanker),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					ChangeAccountStatusTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ChangeAccountStatusTxResult{}, db.ErrInvalidStatusChange)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				requireBodyErrorCode(t, recorder.Body, errCodeInvalidStatusChange)
+			},
+		},
+		{
+			name:      "NotFound",
+			action:    "freeze",
+			body:      gin.H{"reason": "suspected fraud"},
+			setupAuth: staffAuthorization("banker", db.UserRoleBanker),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().ChangeAccountStatusTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/admin/accounts/%d/%s", account.ID, tc.action)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestAdminReverseTransferAPI(t *testing.T) {
+	account1 := randomAccount("sender")
+	account2 := randomAccount("recipient")
+	transfer := randomTransfer(account1.ID, account2.ID)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recoder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "OK",
+			body:      gin.H{"amount": 1},
+			setupAuth: staffAuthorization("banker", db.UserRoleBanker),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(transfer, nil)
+				// The recipient doesn't have to agree
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+
+				arg := db.ReverseTransferTxParams{
+					TransferID: transfer.ID,
+					Amount:     1,
+				}
+				store.EXPECT().
+					ReverseTransferTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.ReverseTransferTxResult{OriginalTransfer: transfer}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:      "Customer",
+			setupAuth: staffAuthorization("recipient", db.UserRoleCustomer),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ReverseTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:      "TransferNotFound",
+			setupAuth: staffAuthorization("banker", db.UserRoleBanker),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(db.Transfer{}, sql.ErrNoRows)
+				store.EXPECT().ReverseTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:      "AlreadyReversed",
+			setupAuth: staffAuthorization("admin", db.UserRoleAdmin),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(transfer, nil)
+				store.EXPECT().
+					ReverseTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReverseTransferTxResult{}, db.ErrTransferAlreadyReversed)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				requireBodyErrorCode(t, recorder.Body, errCodeTransferAlreadyReversed)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			// The user doesn't have two-factor authentication
+			store.EXPECT().GetTotpSecret(gomock.Any(), gomock.Any()).AnyTimes().Return(db.TotpSecret{}, sql.ErrNoRows)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			var body []byte
+			if tc.body != nil {
+				data, err := json.Marshal(tc.body)
+				require.NoError(t, err)
+				body = data
+			}
+
+			url := fmt.Sprintf("/admin/transfers/%d/reverse", transfer.ID)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestAdminCreateDepositAPI(t *testing.T) {
+	customer, _ := randomUser(t)
+	account := randomAccount(customer.Username)
+	amount := int64(100)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recoder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"account_id": account.ID,
+				"amount":     amount,
+			},
+			setupAuth: staffAuthorization("banker", db.UserRoleBanker),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+
+				// The deposit is made on behalf of the customer, so it's theirs
+				arg := db.DepositTxParams{
+					AccountID: account.ID,
+					Amount:    amount,
+					User:      customer.Username,
+				}
+				store.EXPECT().
+					DepositTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.DepositTxResult{Deposit: randomDeposit(account.ID, customer.Username)}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "Customer",
+			body: gin.H{
+				"account_id": account.ID,
+				"amount":     amount,
+			},
+			setupAuth: staffAuthorization(customer.Username, db.UserRoleCustomer),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().DepositTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "AccountNotFound",
+			body: gin.H{
+				"account_id": account.ID,
+				"amount":     amount,
+			},
+			setupAuth: staffAuthorization("banker", db.UserRoleBanker),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().DepositTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "AccountFrozen",
+			body: gin.H{
+				"account_id": account.ID,
+				"amount":     amount,
+			},
+			setupAuth: staffAuthorization("banker", db.UserRoleBanker),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					DepositTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.DepositTxResult{}, db.ErrAccountFrozen)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireBodyErrorCode(t, recorder.Body, errCodeAccountFrozen)
+			},
+		},
+		{
+			name: "InvalidAmount",
+			body: gin.H{
+				"account_id": account.ID,
+				"amount":     -amount,
+			},
+			setupAuth: staffAuthorization("banker", db.UserRoleBanker),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().DepositTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			// The user doesn't have two-factor authentication
+			store.EXPECT().GetTotpSecret(gomock.Any(), gomock.Any()).AnyTimes().Return(db.TotpSecret{}, sql.ErrNoRows)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/admin/deposits", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestUpdateUserRoleAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	banker := user
+	banker.Role = db.UserRoleBanker
+
+	testCases := []struct {
+		name          string
+		username      string
+		body          gin.H
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recoder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "OK",
+			username:  user.Username,
+			body:      gin.H{"role": db.UserRoleBanker},
+			setupAuth: staffAuthorization("admin", db.UserRoleAdmin),
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.UpdateUserRoleParams{
+					Username: user.Username,
+					Role:     db.UserRoleBanker,
+				}
+				store.EXPECT().UpdateUserRoleTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(banker, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got userResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, user.Username, got.Username)
+				require.Equal(t, db.UserRoleBanker, got.Role)
+			},
+		},
+		{
+			name:      "Banker",
+			username:  user.Username,
+			body:      gin.H{"role": db.UserRoleAdmin},
+			setupAuth: staffAuthorization("banker", db.UserRoleBanker),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateUserRoleTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireBodyErrorCode(t, recorder.Body, errCodeRoleNotAllowed)
+			},
+		},
+		{
+			name:      "OwnRole",
+			username:  "admin",
+			body:      gin.H{"role": db.UserRoleCustomer},
+			setupAuth: staffAuthorization("admin", db.UserRoleAdmin),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateUserRoleTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "InvalidRole",
+			username:  user.Username,
+			body:      gin.H{"role": "superuser"},
+			setupAuth: staffAuthorization("admin", db.UserRoleAdmin),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateUserRoleTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "UserNotFound",
+			username:  user.Username,
+			body:      gin.H{"role": db.UserRoleBanker},
+			setupAuth: staffAuthorization("admin", db.UserRoleAdmin),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateUserRoleTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			// The user doesn't have two-factor authentication
+			store.EXPECT().GetTotpSecret(gomock.Any(), gomock.Any()).AnyTimes().Return(db.TotpSecret{}, sql.ErrNoRows)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/admin/users/%s/role", tc.username)
+			request, err := http.NewRequest(http.MethodPatch, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -44,9 +44,18 @@ func (server *Server) createDeposit(ctx *gin.Context) {
 		User:      authPayload.Username,
 	}
 
+	server.applyDeposit(ctx, arg)
+}
+
+// applyDeposit puts the money on the account, and returns the deposit
+func (server *Server) applyDeposit(ctx *gin.Context, arg db.DepositTxParams) {
 	// Calling the deposit transaction function
 	result, err := server.store.DepositTx(ctx, arg)
 	if err != nil {
+		if code := accountStatusErrorCode(err); code != "" {
+			ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(code, err))
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
@@ -105,14 +114,13 @@ func (server *Server) listDeposits(ctx *gin.Context) {
 		return
 	}
 
-	// Getting the user which made the request
-	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	account, valid := server.validAccount(ctx, req.AccountId)
+	if !valid {
+		return
+	}
 
-	// Checking if account belongs to the user
-	account, err := server.store.GetAccount(ctx, req.AccountId)
-	if account.Owner != authPayload.Username {
-		err := errors.New("account doesn't belong to the authenticated user")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+	// Checking if the account is shared with the user
+	if _, ok := server.authorizeAccount(ctx, account, db.AccountRoleViewer); !ok {
 		return
 	}
 
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+
+	db "simplebank/db/sqlc"
+
+	"github.com/gin-gonic/gin"
+)
+
+type listAccountEntriesURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type listAccountEntriesQuery struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+func (server *Server) listAccountEntries(ctx *gin.Context) {
+	var uri listAccountEntriesURI
+	// Here, we'll use the URL params
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req listAccountEntriesQuery
+	// And here, the query params
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, err := server.store.GetAccount(ctx, uri.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// Checking if the account is shared with the user
+	if _, ok := server.authorizeAccount(ctx, account, db.AccountRoleViewer); !ok {
+		return
+	}
+
+	arg := db.ListLedgerEntriesParams{
+		AccountID: account.ID,
+		Limit:     req.PageSize,
+		// Calculating the offset from page number and size
+		Offset: (req.PageID - 1) * req.PageSize,
+	}
+
+	// Each entry comes with the account balance right after it was posted
+	entries, err := server.store.ListLedgerEntries(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, entries)
+}
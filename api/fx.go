@@ -0,0 +1,71 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	db "simplebank/db/sqlc"
+	"simplebank/fx"
+	"simplebank/token"
+
+	"github.com/gin-gonic/gin"
+)
+
+type createFxQuoteRequest struct {
+	FromCurrency string `json:"from_currency" binding:"required,currency"`
+	ToCurrency   string `json:"to_currency" binding:"required,currency,nefield=FromCurrency"`
+	Amount       int64  `json:"amount" binding:"required,gt=0"`
+}
+
+func (server *Server) createFxQuote(ctx *gin.Context) {
+	// Reading the request body
+	var req createFxQuoteRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	marketRate, err := server.rateProvider.Rate(ctx, req.FromCurrency, req.ToCurrency)
+	if err != nil {
+		if errors.Is(err, fx.ErrRateUnavailable) {
+			ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(errCodeRateUnavailable, err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// The rate offered to the user has the spread taken out of it
+	rate := fx.ApplySpread(marketRate, server.config.FXSpreadBps)
+	toAmount := fx.Convert(req.Amount, rate)
+	if toAmount <= 0 {
+		err := errors.New("amount is too small to be converted")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// Getting the user which made the request
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	// The rate is locked until the quote expires
+	arg := db.CreateFxQuoteParams{
+		Username:     authPayload.Username,
+		FromCurrency: req.FromCurrency,
+		ToCurrency:   req.ToCurrency,
+		FromAmount:   req.Amount,
+		ToAmount:     toAmount,
+		MarketRate:   marketRate,
+		SpreadBps:    server.config.FXSpreadBps,
+		Rate:         rate,
+		ExpiresAt:    time.Now().Add(server.config.FXQuoteDuration),
+	}
+
+	quote, err := server.store.CreateFxQuote(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, quote)
+}
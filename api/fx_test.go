@@ -0,0 +1,246 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "simplebank/db/mock"
+	db "simplebank/db/sqlc"
+	"simplebank/token"
+	"simplebank/util"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateFxQuoteAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	amount := int64(1000)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recoder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"from_currency": util.USD,
+				"to_currency":   util.EUR,
+				"amount":        amount,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateFxQuote(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateFxQuoteParams) (db.FxQuote, error) {
+						// The test server rate is 0.8, with a 50 bps spread
+						require.Equal(t, user.Username, arg.Username)
+						require.Equal(t, util.USD, arg.FromCurrency)
+						require.Equal(t, util.EUR, arg.ToCurrency)
+						require.Equal(t, amount, arg.FromAmount)
+						require.Equal(t, int64(796), arg.ToAmount)
+						require.Equal(t, int64(80_000_000), arg.MarketRate)
+						require.Equal(t, int32(50), arg.SpreadBps)
+						require.Equal(t, int64(79_600_000), arg.Rate)
+						require.WithinDuration(t, time.Now().Add(time.Minute), arg.ExpiresAt, time.Second)
+
+						return db.FxQuote{
+							ID:           1,
+							Username:     arg.Username,
+							FromCurrency: arg.FromCurrency,
+							ToCurrency:   arg.ToCurrency,
+							FromAmount:   arg.FromAmount,
+							ToAmount:     arg.ToAmount,
+							MarketRate:   arg.MarketRate,
+							SpreadBps:    arg.SpreadBps,
+							Rate:         arg.Rate,
+						}, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				quote := requireBodyFxQuote(t, recorder.Body)
+				require.Equal(t, int64(796), quote.ToAmount)
+			},
+		},
+		{
+			name: "InverseRate",
+			body: gin.H{
+				"from_currency": util.EUR,
+				"to_currency":   util.USD,
+				"amount":        amount,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateFxQuote(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateFxQuoteParams) (db.FxQuote, error) {
+						require.Equal(t, int64(125_000_000), arg.MarketRate)
+						require.Equal(t, int64(1243), arg.ToAmount)
+						return db.FxQuote{}, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "RateUnavailable",
+			body: gin.H{
+				"from_currency": util.USD,
+				"to_currency":   util.CAD,
+				"amount":        amount,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateFxQuote(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireBodyErrorCode(t, recorder.Body, errCodeRateUnavailable)
+			},
+		},
+		{
+			name: "SameCurrency",
+			body: gin.H{
+				"from_currency": util.USD,
+				"to_currency":   util.USD,
+				"amount":        amount,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateFxQuote(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "AmountTooSmall",
+			body: gin.H{
+				"from_currency": util.USD,
+				"to_currency":   util.EUR,
+				"amount":        1,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateFxQuote(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidCurrency",
+			body: gin.H{
+				"from_currency": "XYZ",
+				"to_currency":   util.EUR,
+				"amount":        amount,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateFxQuote(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NoAuthorization",
+			body: gin.H{
+				"from_currency": util.USD,
+				"to_currency":   util.EUR,
+				"amount":        amount,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateFxQuote(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			body: gin.H{
+				"from_currency": util.USD,
+				"to_currency":   util.EUR,
+				"amount":        amount,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateFxQuote(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.FxQuote{}, sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			// Marshal body data to JSON
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := "/fx/quotes"
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func requireBodyFxQuote(t *testing.T, body *bytes.Buffer) db.FxQuote {
+	data, err := ioutil.ReadAll(body)
+	require.NoError(t, err)
+
+	var gotQuote db.FxQuote
+	err = json.Unmarshal(data, &gotQuote)
+	require.NoError(t, err)
+	return gotQuote
+}
@@ -0,0 +1,193 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	db "simplebank/db/sqlc"
+	"simplebank/token"
+
+	"github.com/gin-gonic/gin"
+)
+
+type createHoldRequest struct {
+	AccountID int64 `json:"account_id" binding:"required,min=1"`
+	Amount    int64 `json:"amount" binding:"required,gt=0"`
+}
+
+func (server *Server) createHold(ctx *gin.Context) {
+	// Reading the request body
+	var req createHoldRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// Getting the account by the provided ID
+	account, err := server.store.GetAccount(ctx, req.AccountID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// Only owners, and spenders within their limit, can reserve money on it
+	if !server.authorizeSpending(ctx, account, req.Amount) {
+		return
+	}
+
+	// Getting the user which made the request
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	arg := db.AuthorizeHoldTxParams{
+		AccountID: req.AccountID,
+		Amount:    req.Amount,
+		User:      authPayload.Username,
+		ExpiresAt: time.Now().Add(server.config.HoldDuration),
+	}
+
+	result, err := server.store.AuthorizeHoldTx(ctx, arg)
+	if err != nil {
+		if errors.Is(err, db.ErrInsufficientFunds) {
+			ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(errCodeInsufficientFunds, err))
+			return
+		}
+		if code := accountStatusErrorCode(err); code != "" {
+			ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(code, err))
+			return
+		}
+		var limitErr *db.LimitExceededError
+		if errors.As(err, &limitErr) {
+			ctx.JSON(http.StatusUnprocessableEntity, limitExceededResponse(limitErr))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+type holdURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) getHold(ctx *gin.Context) {
+	hold, ok := server.authorizedHold(ctx)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, hold)
+}
+
+type captureHoldRequest struct {
+	// Amount is optional, all the money held is captured by default
+	Amount int64 `json:"amount" binding:"omitempty,gt=0"`
+}
+
+func (server *Server) captureHold(ctx *gin.Context) {
+	// The request body may be empty, to capture the whole hold
+	var req captureHoldRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	hold, ok := server.authorizedHold(ctx)
+	if !ok {
+		return
+	}
+
+	// The user may have lost access to the account, or had their spend limit lowered, since the hold was made
+	account, valid := server.validAccount(ctx, hold.AccountID)
+	if !valid {
+		return
+	}
+
+	amount := req.Amount
+	if amount == 0 {
+		amount = hold.Amount
+	}
+	if !server.authorizeSpending(ctx, account, amount) {
+		return
+	}
+
+	arg := db.CaptureHoldTxParams{
+		HoldID: hold.ID,
+		Amount: req.Amount,
+	}
+
+	result, err := server.store.CaptureHoldTx(ctx, arg)
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrHoldNotActive), errors.Is(err, db.ErrHoldExpired):
+			ctx.JSON(http.StatusConflict, errorCodeResponse(errCodeHoldNotActive, err))
+		case errors.Is(err, db.ErrCaptureExceedsHold):
+			ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(errCodeCaptureExceedsHold, err))
+		case accountStatusErrorCode(err) != "":
+			ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(accountStatusErrorCode(err), err))
+		default:
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+func (server *Server) voidHold(ctx *gin.Context) {
+	hold, ok := server.authorizedHold(ctx)
+	if !ok {
+		return
+	}
+
+	result, err := server.store.VoidHoldTx(ctx, hold.ID)
+	if err != nil {
+		if errors.Is(err, db.ErrHoldNotActive) {
+			ctx.JSON(http.StatusConflict, errorCodeResponse(errCodeHoldNotActive, err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// authorizedHold gets the hold from the URL params, checking that it was made by the authenticated user.
+// The error response is already written when it returns false
+func (server *Server) authorizedHold(ctx *gin.Context) (db.Hold, bool) {
+	var uri holdURI
+	// Here, we'll use the URL params
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return db.Hold{}, false
+	}
+
+	hold, err := server.store.GetHold(ctx, uri.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return db.Hold{}, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return db.Hold{}, false
+	}
+
+	// Getting the user which made the request
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if hold.User != authPayload.Username {
+		err := errors.New("hold wasn't made by the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return db.Hold{}, false
+	}
+
+	return hold, true
+}
@@ -0,0 +1,177 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	db "simplebank/db/sqlc"
+	"simplebank/token"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	idempotencyKeyHeader      = "Idempotency-Key"
+	idempotentReplayedHeader  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+	idempotencyInProgressCode = 0
+	// idempotencySaveAttempts is how many times saving a response is tried before giving up
+	idempotencySaveAttempts   = 3
+	idempotencySaveRetryDelay = 100 * time.Millisecond
+)
+
+// responseBodyRecorder keeps a copy of everything written to the response body
+type responseBodyRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseBodyRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseBodyRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// hashIdempotentRequest identifies a request, so a key can't be reused for a different one
+func hashIdempotentRequest(method string, path string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method + " " + path + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// idempotencyMiddleware creates a gin middleware which makes requests with an
+// Idempotency-Key header safe to retry. The first response for a key is stored
+// and replayed for the same request, while reusing the key for another request
+// is rejected. It must run after the authMiddleware, since keys belong to users,
+// and before the middlewares which may refuse the request, like the twoFactorMiddleware
+func idempotencyMiddleware(store db.Store, duration time.Duration) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		key := ctx.GetHeader(idempotencyKeyHeader)
+		// Requests without a key are processed as usual
+		if len(key) == 0 {
+			ctx.Next()
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			err := errors.New("idempotency key is too long")
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+
+		// Reading the body and putting it back, so the handler can still bind it
+		body, err := io.ReadAll(ctx.Request.Body)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+		requestHash := hashIdempotentRequest(ctx.Request.Method, ctx.Request.URL.Path, body)
+
+		// Claiming the key, which fails if it's already in use
+		_, err = store.CreateIdempotencyKey(ctx, db.CreateIdempotencyKeyParams{
+			Username:    authPayload.Username,
+			Key:         key,
+			RequestHash: requestHash,
+			ExpiresAt:   time.Now().Add(duration),
+		})
+		if err == sql.ErrNoRows {
+			replayIdempotentRequest(ctx, store, authPayload.Username, key, requestHash)
+			return
+		}
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		// A handler which panics is treated like a server error, releasing the key before gin recovers
+		defer func() {
+			if r := recover(); r != nil {
+				releaseIdempotencyKey(ctx, store, authPayload.Username, key)
+				panic(r)
+			}
+		}()
+
+		recorder := &responseBodyRecorder{ResponseWriter: ctx.Writer}
+		ctx.Writer = recorder
+		ctx.Next()
+
+		// Requests refused by a later middleware never reached the handler, and server errors aren't stored
+		// either, so the client can try again with the same key
+		status := recorder.Status()
+		if ctx.IsAborted() || status >= http.StatusInternalServerError {
+			releaseIdempotencyKey(ctx, store, authPayload.Username, key)
+			return
+		}
+
+		arg := db.UpdateIdempotencyKeyResponseParams{
+			Username:       authPayload.Username,
+			Key:            key,
+			ResponseStatus: int32(status),
+			ResponseBody:   recorder.body.Bytes(),
+		}
+		for attempt := 1; attempt <= idempotencySaveAttempts; attempt++ {
+			_, err = store.UpdateIdempotencyKeyResponse(ctx, arg)
+			if err == nil {
+				return
+			}
+			if attempt < idempotencySaveAttempts {
+				time.Sleep(idempotencySaveRetryDelay)
+			}
+		}
+
+		// The request went through, so the key is left in progress until it expires rather than
+		// released, since a retry would make the request again
+		log.Printf("cannot save the response of idempotency key %q of %s: %v", key, authPayload.Username, err)
+	}
+}
+
+// releaseIdempotencyKey frees a key whose request failed, so it can be used again
+func releaseIdempotencyKey(ctx *gin.Context, store db.Store, username string, key string) {
+	store.DeleteIdempotencyKey(ctx, db.DeleteIdempotencyKeyParams{
+		Username: username,
+		Key:      key,
+	})
+}
+
+// replayIdempotentRequest sends back the stored response of a request which was already processed
+func replayIdempotentRequest(ctx *gin.Context, store db.Store, username string, key string, requestHash string) {
+	idempotencyKey, err := store.GetIdempotencyKey(ctx, db.GetIdempotencyKeyParams{
+		Username: username,
+		Key:      key,
+	})
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if idempotencyKey.RequestHash != requestHash {
+		err := errors.New("idempotency key was already used for a different request")
+		ctx.AbortWithStatusJSON(http.StatusConflict, errorCodeResponse(errCodeIdempotencyKeyReused, err))
+		return
+	}
+
+	if idempotencyKey.ResponseStatus == idempotencyInProgressCode {
+		err := errors.New("a request with this idempotency key is still being processed")
+		ctx.AbortWithStatusJSON(http.StatusConflict, errorCodeResponse(errCodeIdempotencyKeyInProgress, err))
+		return
+	}
+
+	ctx.Header(idempotentReplayedHeader, "true")
+	ctx.Data(int(idempotencyKey.ResponseStatus), gin.MIMEJSON, idempotencyKey.ResponseBody)
+	ctx.Abort()
+}
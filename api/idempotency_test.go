@@ -0,0 +1,366 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "simplebank/db/mock"
+	db "simplebank/db/sqlc"
+	"simplebank/token"
+	"simplebank/totp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	amount := int64(10)
+	key := "c6ad3b1e-0a3f-4d8e-9d4f-0e2b2c5f1a77"
+
+	body := gin.H{
+		"account_id": account.ID,
+		"amount":     amount,
+	}
+	data, err := json.Marshal(body)
+	require.NoError(t, err)
+
+	requestHash := hashIdempotentRequest(http.MethodPost, "/deposits", data)
+	storedResponse := []byte(`{"deposit":{"id":1}}`)
+
+	testCases := []struct {
+		name          string
+		key           string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recoder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "NoKey",
+			key:  "",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateIdempotencyKey(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().DepositTx(gomock.Any(), gomock.Any()).Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "FirstRequest",
+			key:  key,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateIdempotencyKeyParams) (db.IdempotencyKey, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.Equal(t, key, arg.Key)
+						require.Equal(t, requestHash, arg.RequestHash)
+						return db.IdempotencyKey{Username: arg.Username, Key: arg.Key, RequestHash: arg.RequestHash}, nil
+					})
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().DepositTx(gomock.Any(), gomock.Any()).Times(1)
+				store.EXPECT().
+					UpdateIdempotencyKeyResponse(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.UpdateIdempotencyKeyResponseParams) (db.IdempotencyKey, error) {
+						require.Equal(t, int32(http.StatusOK), arg.ResponseStatus)
+						require.NotEmpty(t, arg.ResponseBody)
+						return db.IdempotencyKey{}, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Empty(t, recorder.Header().Get(idempotentReplayedHeader))
+			},
+		},
+		{
+			name: "Replay",
+			key:  key,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateIdempotencyKey(gomock.Any(), gomock.Any()).Times(1).Return(db.IdempotencyKey{}, sql.ErrNoRows)
+				store.EXPECT().
+					GetIdempotencyKey(gomock.Any(), gomock.Eq(db.GetIdempotencyKeyParams{Username: user.Username, Key: key})).
+					Times(1).
+					Return(db.IdempotencyKey{
+						Username:       user.Username,
+						Key:            key,
+						RequestHash:    requestHash,
+						ResponseStatus: http.StatusOK,
+						ResponseBody:   storedResponse,
+					}, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().DepositTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "true", recorder.Header().Get(idempotentReplayedHeader))
+				require.Equal(t, storedResponse, recorder.Body.Bytes())
+			},
+		},
+		{
+			name: "DifferentRequest",
+			key:  key,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateIdempotencyKey(gomock.Any(), gomock.Any()).Times(1).Return(db.IdempotencyKey{}, sql.ErrNoRows)
+				store.EXPECT().
+					GetIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.IdempotencyKey{
+						Username:       user.Username,
+						Key:            key,
+						RequestHash:    "another-hash",
+						ResponseStatus: http.StatusOK,
+						ResponseBody:   storedResponse,
+					}, nil)
+				store.EXPECT().DepositTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				requireBodyErrorCode(t, recorder.Body, errCodeIdempotencyKeyReused)
+			},
+		},
+		{
+			name: "InProgress",
+			key:  key,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateIdempotencyKey(gomock.Any(), gomock.Any()).Times(1).Return(db.IdempotencyKey{}, sql.ErrNoRows)
+				store.EXPECT().
+					GetIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.IdempotencyKey{
+						Username:    user.Username,
+						Key:         key,
+						RequestHash: requestHash,
+					}, nil)
+				store.EXPECT().DepositTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				requireBodyErrorCode(t, recorder.Body, errCodeIdempotencyKeyInProgress)
+			},
+		},
+		{
+			name: "ServerErrorReleasesKey",
+			key:  key,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateIdempotencyKey(gomock.Any(), gomock.Any()).Times(1).Return(db.IdempotencyKey{}, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().DepositTx(gomock.Any(), gomock.Any()).Times(1).Return(db.DepositTxResult{}, sql.ErrConnDone)
+				store.EXPECT().UpdateIdempotencyKeyResponse(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().
+					DeleteIdempotencyKey(gomock.Any(), gomock.Eq(db.DeleteIdempotencyKeyParams{Username: user.Username, Key: key})).
+					Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name: "SaveFailureKeepsKey",
+			key:  key,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateIdempotencyKey(gomock.Any(), gomock.Any()).Times(1).Return(db.IdempotencyKey{}, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().DepositTx(gomock.Any(), gomock.Any()).Times(1).Return(db.DepositTxResult{}, nil)
+				store.EXPECT().
+					UpdateIdempotencyKeyResponse(gomock.Any(), gomock.Any()).
+					Times(idempotencySaveAttempts).
+					Return(db.IdempotencyKey{}, sql.ErrConnDone)
+				// The deposit was made, so a retry must not make it again
+				store.EXPECT().DeleteIdempotencyKey(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "PanicReleasesKey",
+			key:  key,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateIdempotencyKey(gomock.Any(), gomock.Any()).Times(1).Return(db.IdempotencyKey{}, nil)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					DoAndReturn(func(_ interface{}, _ int64) (db.Account, error) {
+						panic("unexpected failure")
+					})
+				store.EXPECT().UpdateIdempotencyKeyResponse(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().
+					DeleteIdempotencyKey(gomock.Any(), gomock.Eq(db.DeleteIdempotencyKeyParams{Username: user.Username, Key: key})).
+					Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name: "NoAuthorization",
+			key:  key,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateIdempotencyKey(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().DepositTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := "/deposits"
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			if len(tc.key) > 0 {
+				request.Header.Set(idempotencyKeyHeader, tc.key)
+			}
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestIdempotencyTwoFactor(t *testing.T) {
+	user, _ := randomUser(t)
+	key := "5f0f3c9e-8a53-4c2e-bb1a-9f6de7f4a0c2"
+
+	data, err := json.Marshal(gin.H{"account_id": 1, "amount": 10})
+	require.NoError(t, err)
+
+	requestHash := hashIdempotentRequest(http.MethodPost, "/withdraws", data)
+	storedResponse := []byte(`{"withdraw":{"id":1}}`)
+
+	testCases := []struct {
+		name          string
+		code          string
+		buildStubs    func(store *mockdb.MockStore, secret db.TotpSecret)
+		checkResponse func(recoder *httptest.ResponseRecorder)
+	}{
+		{
+			// The code sent with the first request was used up, a retry must not need another one
+			name: "ReplayWithoutCode",
+			code: "",
+			buildStubs: func(store *mockdb.MockStore, secret db.TotpSecret) {
+				store.EXPECT().CreateIdempotencyKey(gomock.Any(), gomock.Any()).Times(1).Return(db.IdempotencyKey{}, sql.ErrNoRows)
+				store.EXPECT().
+					GetIdempotencyKey(gomock.Any(), gomock.Eq(db.GetIdempotencyKeyParams{Username: user.Username, Key: key})).
+					Times(1).
+					Return(db.IdempotencyKey{
+						Username:       user.Username,
+						Key:            key,
+						RequestHash:    requestHash,
+						ResponseStatus: http.StatusOK,
+						ResponseBody:   storedResponse,
+					}, nil)
+				store.EXPECT().GetTotpSecret(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().UseTotpStep(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().RecordTotpFailure(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "true", recorder.Header().Get(idempotentReplayedHeader))
+				require.Equal(t, storedResponse, recorder.Body.Bytes())
+			},
+		},
+		{
+			name: "RefusedCodeReleasesKey",
+			code: "000000",
+			buildStubs: func(store *mockdb.MockStore, secret db.TotpSecret) {
+				store.EXPECT().CreateIdempotencyKey(gomock.Any(), gomock.Any()).Times(1)
+				store.EXPECT().GetTotpSecret(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(secret, nil)
+				store.EXPECT().RecordTotpFailure(gomock.Any(), gomock.Any()).Times(1)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				// The refusal isn't stored, so the request can be sent again with the same key and a valid code
+				store.EXPECT().
+					DeleteIdempotencyKey(gomock.Any(), gomock.Eq(db.DeleteIdempotencyKeyParams{Username: user.Username, Key: key})).
+					Times(1)
+				store.EXPECT().UpdateIdempotencyKeyResponse(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				requireBodyErrorCode(t, recorder.Body, errCodeInvalidTwoFactorCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			server := newTestServer(t, store)
+			secret, plainSecret := randomTotpSecret(t, server.totpCipher, user.Username)
+			// The code is drawn again until it can't be mistaken for the one the refused case sends
+			for totp.Code(plainSecret, totp.Step(time.Now())) == "000000" {
+				secret, plainSecret = randomTotpSecret(t, server.totpCipher, user.Username)
+			}
+			tc.buildStubs(store, secret)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodPost, "/withdraws", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			request.Header.Set(idempotencyKeyHeader, key)
+			if len(tc.code) > 0 {
+				request.Header.Set(twoFactorCodeHeader, tc.code)
+			}
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
@@ -1,23 +1,45 @@
 package api
 
 import (
+	"context"
 	"os"
 	db "simplebank/db/sqlc"
+	"simplebank/fx"
 	"simplebank/util"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 )
 
+// revokedSessions is a RevocationChecker which only knows the sessions revoked by the test
+type revokedSessions map[uuid.UUID]bool
+
+func (sessions revokedSessions) IsRevoked(ctx context.Context, sessionID uuid.UUID) (bool, error) {
+	return sessions[sessionID], nil
+}
+
 func newTestServer(t *testing.T, store db.Store) *Server {
 	config := util.Config{
-		TokenSymmetricKey:   util.RandomString(32),
-		AccessTokenDuration: time.Minute,
+		TokenSymmetricKey:      util.RandomString(32),
+		AccessTokenDuration:    time.Minute,
+		RefreshTokenDuration:   time.Hour,
+		ChallengeTokenDuration: time.Minute,
+		TOTPEncryptionKey:      util.RandomString(32),
+		IdempotencyKeyDuration: time.Minute,
+		FXQuoteDuration:        time.Minute,
+		FXSpreadBps:            50,
+		HoldDuration:           time.Hour,
+		MaxAccountsPerCurrency: 5,
 	}
 
-	server, err := NewServer(config, store)
+	rateProvider := fx.NewStaticRateProvider(map[string]map[string]float64{
+		util.USD: {util.EUR: 0.8},
+	})
+
+	server, err := NewServer(config, store, rateProvider, revokedSessions{})
 	require.NoError(t, err)
 
 	return server
@@ -17,8 +17,8 @@ const (
 	authorizationPayloadKey = "authorization_payload"
 )
 
-// AuthMiddleware creates a gin middleware for authorization
-func authMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
+// AuthMiddleware creates a gin middleware for authorization, which only accepts the access tokens of sessions which weren't revoked
+func authMiddleware(tokenMaker token.Maker, revocationChecker RevocationChecker) gin.HandlerFunc {
 	abort := func(ctx *gin.Context, err error) {
 		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
 		ctx.Abort()
@@ -51,7 +51,43 @@ func authMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
 			return
 		}
 
+		// Refresh and challenge tokens are signed with the same key, but they aren't meant to be sent with requests
+		if payload.Type != token.TypeAccess {
+			abort(ctx, errors.New("not an access token"))
+			return
+		}
+
+		revoked, err := revocationChecker.IsRevoked(ctx, payload.SessionID)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			ctx.Abort()
+			return
+		}
+		if revoked {
+			abort(ctx, errors.New("session has been revoked"))
+			return
+		}
+
 		ctx.Set(authorizationPayloadKey, payload)
 		ctx.Next()
 	}
 }
+
+// requireRole creates a gin middleware which only lets users with one of the roles through,
+// it must come after authMiddleware
+func requireRole(roles ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		for _, role := range roles {
+			if authPayload.Role == role {
+				ctx.Next()
+				return
+			}
+		}
+
+		err := fmt.Errorf("the %q role isn't allowed to do this", authPayload.Role)
+		ctx.JSON(http.StatusForbidden, errorCodeResponse(errCodeRoleNotAllowed, err))
+		ctx.Abort()
+	}
+}
@@ -7,9 +7,11 @@ import (
 	"testing"
 	"time"
 
+	db "simplebank/db/sqlc"
 	"simplebank/token"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 )
 
@@ -21,7 +23,19 @@ func addAuthorization(
 	username string,
 	duration time.Duration,
 ) {
-	token, payload, err := tokenMaker.CreateToken(username, duration)
+	addRoleAuthorization(t, request, tokenMaker, authorizationType, username, db.UserRoleCustomer, duration)
+}
+
+func addRoleAuthorization(
+	t *testing.T,
+	request *http.Request,
+	tokenMaker token.Maker,
+	authorizationType string,
+	username string,
+	role string,
+	duration time.Duration,
+) {
+	token, payload, err := tokenMaker.CreateToken(username, role, uuid.New(), token.TypeAccess, duration)
 	require.NoError(t, err)
 	require.NotEmpty(t, payload)
 
@@ -30,6 +44,8 @@ func addAuthorization(
 }
 
 func TestAuthMiddleware(t *testing.T) {
+	revokedSessionID := uuid.New()
+
 	testCases := []struct {
 		name          string
 		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
@@ -79,6 +95,45 @@ func TestAuthMiddleware(t *testing.T) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
 			},
 		},
+		{
+			name: "RefreshToken",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				refreshToken, _, err := tokenMaker.CreateToken("user", db.UserRoleCustomer, uuid.New(), token.TypeRefresh, time.Minute)
+				require.NoError(t, err)
+
+				authorizationHeader := fmt.Sprintf("%s %s", authorizationTypeBearer, refreshToken)
+				request.Header.Set(authorizationHeaderKey, authorizationHeader)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "ChallengeToken",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				challengeToken, _, err := tokenMaker.CreateToken("user", "", challengeSessionID, token.TypeChallenge, time.Minute)
+				require.NoError(t, err)
+
+				authorizationHeader := fmt.Sprintf("%s %s", authorizationTypeBearer, challengeToken)
+				request.Header.Set(authorizationHeaderKey, authorizationHeader)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "RevokedSession",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				accessToken, _, err := tokenMaker.CreateToken("user", db.UserRoleCustomer, revokedSessionID, token.TypeAccess, time.Minute)
+				require.NoError(t, err)
+
+				authorizationHeader := fmt.Sprintf("%s %s", authorizationTypeBearer, accessToken)
+				request.Header.Set(authorizationHeaderKey, authorizationHeader)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
 	}
 
 	for i := range testCases {
@@ -89,7 +144,7 @@ func TestAuthMiddleware(t *testing.T) {
 			authPath := "/auth"
 			server.router.GET(
 				authPath,
-				authMiddleware(server.tokenMaker),
+				authMiddleware(server.tokenMaker, revokedSessions{revokedSessionID: true}),
 				func(ctx *gin.Context) {
 					ctx.JSON(http.StatusOK, gin.H{})
 				},
@@ -105,3 +160,66 @@ func TestAuthMiddleware(t *testing.T) {
 		})
 	}
 }
+
+func TestRequireRole(t *testing.T) {
+	testCases := []struct {
+		name          string
+		role          string
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Banker",
+			role: db.UserRoleBanker,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "Admin",
+			role: db.UserRoleAdmin,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "Customer",
+			role: db.UserRoleCustomer,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireBodyErrorCode(t, recorder.Body, errCodeRoleNotAllowed)
+			},
+		},
+		{
+			name: "NoRole",
+			role: "",
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			server := newTestServer(t, nil)
+			authPath := "/staff"
+			server.router.GET(
+				authPath,
+				authMiddleware(server.tokenMaker, revokedSessions{}),
+				requireRole(db.UserRoleBanker, db.UserRoleAdmin),
+				func(ctx *gin.Context) {
+					ctx.JSON(http.StatusOK, gin.H{})
+				},
+			)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, authPath, nil)
+			require.NoError(t, err)
+
+			addRoleAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "user", tc.role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
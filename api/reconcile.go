@@ -0,0 +1,20 @@
+package api
+
+import (
+	"net/http"
+
+	"simplebank/reconcile"
+
+	"github.com/gin-gonic/gin"
+)
+
+// reconcileLedger checks the invariants of the whole ledger, its route is only allowed for admins
+func (server *Server) reconcileLedger(ctx *gin.Context) {
+	report, err := reconcile.Run(ctx, server.store)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
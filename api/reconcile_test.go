@@ -0,0 +1,141 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "simplebank/db/mock"
+	db "simplebank/db/sqlc"
+	"simplebank/reconcile"
+	"simplebank/token"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconcileLedgerAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	admin, _ := randomUser(t)
+
+	buildReconcileStubs := func(store *mockdb.MockStore) {
+		store.EXPECT().
+			ListBalanceMismatches(gomock.Any(), gomock.Any()).
+			Times(1).
+			Return([]db.ListBalanceMismatchesRow{{AccountID: 1, Balance: 100, EntriesTotal: 90}}, nil)
+		store.EXPECT().ListTransferEntryMismatches(gomock.Any(), gomock.Any()).Times(1)
+		store.EXPECT().ListDepositEntryMismatches(gomock.Any(), gomock.Any()).Times(1)
+		store.EXPECT().ListWithdrawEntryMismatches(gomock.Any(), gomock.Any()).Times(1)
+		store.EXPECT().ListUnbalancedJournals(gomock.Any(), gomock.Any()).Times(1)
+	}
+
+	testCases := []struct {
+		name          string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addRoleAuthorization(t, request, tokenMaker, authorizationTypeBearer, admin.Username, db.UserRoleAdmin, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				buildReconcileStubs(store)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				data, err := ioutil.ReadAll(recorder.Body)
+				require.NoError(t, err)
+
+				var report reconcile.Report
+				err = json.Unmarshal(data, &report)
+				require.NoError(t, err)
+				require.False(t, report.Passed)
+				require.Len(t, report.Checks, 5)
+				require.Len(t, report.Checks[0].Violations, 1)
+				require.Equal(t, int64(1), report.Checks[0].Violations[0].ID)
+			},
+		},
+		{
+			name: "Customer",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListBalanceMismatches(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireBodyErrorCode(t, recorder.Body, errCodeRoleNotAllowed)
+			},
+		},
+		{
+			// Bankers can act on accounts, but not check the whole ledger
+			name: "Banker",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addRoleAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, db.UserRoleBanker, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListBalanceMismatches(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireBodyErrorCode(t, recorder.Body, errCodeRoleNotAllowed)
+			},
+		},
+		{
+			name: "NoAuthorization",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListBalanceMismatches(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addRoleAuthorization(t, request, tokenMaker, authorizationTypeBearer, admin.Username, db.UserRoleAdmin, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListBalanceMismatches(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/admin/reconciliation", nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
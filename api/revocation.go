@@ -0,0 +1,145 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"sync"
+	"time"
+
+	db "simplebank/db/sqlc"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// sessionBlockedChannel is the Postgres channel on which the ID of every blocked session is sent
+const sessionBlockedChannel = "session_blocked"
+
+// RevocationChecker tells if the session an access token was issued for has been revoked
+type RevocationChecker interface {
+	IsRevoked(ctx context.Context, sessionID uuid.UUID) (bool, error)
+}
+
+type revocationEntry struct {
+	revoked   bool
+	expiresAt time.Time
+}
+
+// RevocationCache checks sessions on the database, and remembers the answer for a short duration so that
+// most requests don't hit it. Sessions blocked on any replica are revoked right away through Postgres
+// notifications, the duration bounding how long a missed notification goes unnoticed
+type RevocationCache struct {
+	store    db.Store
+	duration time.Duration
+
+	mu       sync.Mutex
+	entries  map[uuid.UUID]revocationEntry
+	prunedAt time.Time
+}
+
+// NewRevocationCache creates a new RevocationCache
+func NewRevocationCache(store db.Store, duration time.Duration) *RevocationCache {
+	return &RevocationCache{
+		store:    store,
+		duration: duration,
+		entries:  make(map[uuid.UUID]revocationEntry),
+		prunedAt: time.Now(),
+	}
+}
+
+// IsRevoked checks if the session is blocked, or doesn't exist at all
+func (cache *RevocationCache) IsRevoked(ctx context.Context, sessionID uuid.UUID) (bool, error) {
+	cache.mu.Lock()
+	entry, ok := cache.entries[sessionID]
+	cache.mu.Unlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.revoked, nil
+	}
+
+	session, err := cache.store.GetSession(ctx, sessionID)
+	if err != nil && err != sql.ErrNoRows {
+		return false, err
+	}
+
+	return cache.set(sessionID, err == sql.ErrNoRows || session.IsBlocked), nil
+}
+
+// Revoke remembers that the session is blocked
+func (cache *RevocationCache) Revoke(sessionID uuid.UUID) {
+	cache.set(sessionID, true)
+}
+
+// Purge forgets every session, which are checked on the database again
+func (cache *RevocationCache) Purge() {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	cache.entries = make(map[uuid.UUID]revocationEntry)
+}
+
+// set remembers if the session is revoked, and returns it. Blocked sessions are never unblocked,
+// so a revocation isn't overwritten by a lookup which started before it
+func (cache *RevocationCache) set(sessionID uuid.UUID, revoked bool) bool {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	if entry, ok := cache.entries[sessionID]; ok && entry.revoked {
+		revoked = true
+	}
+
+	now := time.Now()
+	if now.Sub(cache.prunedAt) > cache.duration {
+		for id, entry := range cache.entries {
+			if now.After(entry.expiresAt) {
+				delete(cache.entries, id)
+			}
+		}
+		cache.prunedAt = now
+	}
+
+	cache.entries[sessionID] = revocationEntry{
+		revoked:   revoked,
+		expiresAt: now.Add(cache.duration),
+	}
+	return revoked
+}
+
+// Listen revokes the sessions blocked by any replica as they are announced by Postgres,
+// until the context is cancelled
+func (cache *RevocationCache) Listen(ctx context.Context, dataSource string) error {
+	listener := pq.NewListener(dataSource, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Println("session listener:", err)
+		}
+	})
+	defer listener.Close()
+
+	err := listener.Listen(sessionBlockedChannel)
+	if err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case notification := <-listener.Notify:
+			// The listener reconnected, and the sessions blocked meanwhile are unknown
+			if notification == nil {
+				cache.Purge()
+				continue
+			}
+
+			sessionID, err := uuid.Parse(notification.Extra)
+			if err != nil {
+				log.Printf("invalid blocked session %q: %v", notification.Extra, err)
+				continue
+			}
+			cache.Revoke(sessionID)
+		case <-time.After(90 * time.Second):
+			// Making sure the connection is still alive when nothing was announced for a while
+			go listener.Ping()
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	mockdb "simplebank/db/mock"
+	db "simplebank/db/sqlc"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRevocationCache(t *testing.T) {
+	session := randomSession("user")
+	blockedSession := randomSession("user")
+	blockedSession.IsBlocked = true
+	missingSession := randomSession("user")
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Each session is only looked up once while it's cached
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(session, nil)
+	store.EXPECT().GetSession(gomock.Any(), gomock.Eq(blockedSession.ID)).Times(1).Return(blockedSession, nil)
+	store.EXPECT().GetSession(gomock.Any(), gomock.Eq(missingSession.ID)).Times(1).Return(db.Session{}, sql.ErrNoRows)
+
+	cache := NewRevocationCache(store, time.Minute)
+	for i := 0; i < 2; i++ {
+		revoked, err := cache.IsRevoked(context.Background(), session.ID)
+		require.NoError(t, err)
+		require.False(t, revoked)
+
+		revoked, err = cache.IsRevoked(context.Background(), blockedSession.ID)
+		require.NoError(t, err)
+		require.True(t, revoked)
+
+		revoked, err = cache.IsRevoked(context.Background(), missingSession.ID)
+		require.NoError(t, err)
+		require.True(t, revoked)
+	}
+
+	// A session blocked on another replica is revoked without waiting for the cache to expire
+	cache.Revoke(session.ID)
+	revoked, err := cache.IsRevoked(context.Background(), session.ID)
+	require.NoError(t, err)
+	require.True(t, revoked)
+}
+
+func TestRevocationCacheExpiry(t *testing.T) {
+	session := randomSession("user")
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(3).Return(session, nil)
+
+	// Nothing is cached for long with no duration
+	cache := NewRevocationCache(store, 0)
+	revoked, err := cache.IsRevoked(context.Background(), session.ID)
+	require.NoError(t, err)
+	require.False(t, revoked)
+
+	revoked, err = cache.IsRevoked(context.Background(), session.ID)
+	require.NoError(t, err)
+	require.False(t, revoked)
+
+	// A lookup which read the session before it was blocked doesn't undo the revocation
+	cache.Revoke(session.ID)
+	revoked, err = cache.IsRevoked(context.Background(), session.ID)
+	require.NoError(t, err)
+	require.True(t, revoked)
+}
+
+func TestRevocationCachePurge(t *testing.T) {
+	session := randomSession("user")
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	gomock.InOrder(
+		store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(db.Session{}, sql.ErrConnDone),
+		store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(2).Return(session, nil),
+	)
+
+	cache := NewRevocationCache(store, time.Minute)
+
+	// Errors aren't cached
+	_, err := cache.IsRevoked(context.Background(), session.ID)
+	require.ErrorIs(t, err, sql.ErrConnDone)
+
+	revoked, err := cache.IsRevoked(context.Background(), session.ID)
+	require.NoError(t, err)
+	require.False(t, revoked)
+
+	// Every session is checked again once the cache is purged
+	cache.Purge()
+	revoked, err = cache.IsRevoked(context.Background(), session.ID)
+	require.NoError(t, err)
+	require.False(t, revoked)
+}
@@ -0,0 +1,239 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	db "simplebank/db/sqlc"
+	"simplebank/token"
+
+	"github.com/gin-gonic/gin"
+)
+
+type createScheduledTransferRequest struct {
+	FromAccountID int64      `json:"from_account_id" binding:"required,min=1"`
+	ToAccountID   int64      `json:"to_account_id" binding:"required,min=1,nefield=FromAccountID"`
+	Amount        int64      `json:"amount" binding:"required,gt=0"`
+	Currency      string     `json:"currency" binding:"required,currency"`
+	Frequency     string     `json:"frequency" binding:"required,oneof=once daily weekly monthly"`
+	StartAt       *time.Time `json:"start_at"`
+}
+
+func (server *Server) createScheduledTransfer(ctx *gin.Context) {
+	// Reading the request body
+	var req createScheduledTransferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// Orders without a start time are executed right away
+	startAt := time.Now()
+	if req.StartAt != nil {
+		if req.StartAt.Before(startAt) {
+			err := errors.New("start_at must not be in the past")
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		startAt = *req.StartAt
+	}
+
+	// Checking if accounts have the required currency
+	fromAccount, valid := server.validAccountCurrency(ctx, req.FromAccountID, req.Currency)
+	if !valid {
+		return
+	}
+
+	// Every run is checked against the spend limit when the order is made
+	if !server.authorizeSpending(ctx, fromAccount, req.Amount) {
+		return
+	}
+
+	// Getting the user which made the request
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	_, valid = server.validAccountCurrency(ctx, req.ToAccountID, req.Currency)
+	if !valid {
+		return
+	}
+
+	arg := db.CreateScheduledTransferParams{
+		Owner:         authPayload.Username,
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+		Frequency:     req.Frequency,
+		StartAt:       startAt,
+	}
+
+	scheduledTransfer, err := server.store.CreateScheduledTransfer(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, scheduledTransfer)
+}
+
+type listScheduledTransferRequest struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+func (server *Server) listScheduledTransfers(ctx *gin.Context) {
+	var req listScheduledTransferRequest
+	// Here, we'll use the query params
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// Getting the user which made the request
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	arg := db.ListScheduledTransfersParams{
+		Owner: authPayload.Username,
+		Limit: req.PageSize,
+		// Calculating the offset from page number and size
+		Offset: (req.PageID - 1) * req.PageSize,
+	}
+
+	scheduledTransfers, err := server.store.ListScheduledTransfers(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, scheduledTransfers)
+}
+
+type scheduledTransferURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type listScheduledTransferRunsQuery struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+func (server *Server) listScheduledTransferRuns(ctx *gin.Context) {
+	var req listScheduledTransferRunsQuery
+	// Here, we'll use the query params
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	scheduledTransfer, ok := server.authorizedScheduledTransfer(ctx)
+	if !ok {
+		return
+	}
+
+	arg := db.ListScheduledTransferRunsParams{
+		ScheduledTransferID: scheduledTransfer.ID,
+		Limit:               req.PageSize,
+		// Calculating the offset from page number and size
+		Offset: (req.PageID - 1) * req.PageSize,
+	}
+
+	runs, err := server.store.ListScheduledTransferRuns(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, runs)
+}
+
+// pauseScheduledTransfer stops the runs of an order until it's resumed
+func (server *Server) pauseScheduledTransfer(ctx *gin.Context) {
+	server.changeScheduledTransferStatus(ctx, server.store.PauseScheduledTransfer, db.ScheduledTransferActive)
+}
+
+// resumeScheduledTransfer starts a paused order again, forgetting about the runs which failed before
+func (server *Server) resumeScheduledTransfer(ctx *gin.Context) {
+	server.changeScheduledTransferStatus(ctx, server.store.ResumeScheduledTransfer, db.ScheduledTransferPaused)
+}
+
+// cancelScheduledTransfer stops an order for good. It's kept as cancelled, so its runs can still be listed
+func (server *Server) cancelScheduledTransfer(ctx *gin.Context) {
+	server.changeScheduledTransferStatus(
+		ctx,
+		server.store.CancelScheduledTransfer,
+		db.ScheduledTransferActive,
+		db.ScheduledTransferPaused,
+	)
+}
+
+// changeScheduledTransferStatus changes the status of an order of the authenticated user,
+// which must have one of the given statuses
+func (server *Server) changeScheduledTransferStatus(
+	ctx *gin.Context,
+	change func(ctx context.Context, id int64) (db.ScheduledTransfer, error),
+	fromStatuses ...string,
+) {
+	scheduledTransfer, ok := server.authorizedScheduledTransfer(ctx)
+	if !ok {
+		return
+	}
+
+	allowed := false
+	for _, status := range fromStatuses {
+		if scheduledTransfer.Status == status {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		err := fmt.Errorf("scheduled transfer is %s", scheduledTransfer.Status)
+		ctx.JSON(http.StatusConflict, errorCodeResponse(errCodeInvalidStatusChange, err))
+		return
+	}
+
+	scheduledTransfer, err := change(ctx, scheduledTransfer.ID)
+	if err != nil {
+		// The order is being executed by a worker, or was changed by another request in the meantime
+		if err == sql.ErrNoRows {
+			err := errors.New("scheduled transfer is being executed, try again later")
+			ctx.JSON(http.StatusConflict, errorCodeResponse(errCodeScheduledTransferRunning, err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, scheduledTransfer)
+}
+
+// authorizedScheduledTransfer gets the order in the URL, which must belong to the authenticated user
+func (server *Server) authorizedScheduledTransfer(ctx *gin.Context) (db.ScheduledTransfer, bool) {
+	var uri scheduledTransferURI
+	// Here, we'll use the URL params
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return db.ScheduledTransfer{}, false
+	}
+
+	scheduledTransfer, err := server.store.GetScheduledTransfer(ctx, uri.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return db.ScheduledTransfer{}, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return db.ScheduledTransfer{}, false
+	}
+
+	// Getting the user which made the request
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if scheduledTransfer.Owner != authPayload.Username {
+		err := errors.New("scheduled transfer doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return db.ScheduledTransfer{}, false
+	}
+
+	return scheduledTransfer, true
+}
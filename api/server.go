@@ -1,9 +1,12 @@
 package api
 
 import (
+	"errors"
 	"fmt"
 	db "simplebank/db/sqlc"
+	"simplebank/fx"
 	"simplebank/token"
+	"simplebank/totp"
 	"simplebank/util"
 
 	"github.com/gin-gonic/gin"
@@ -13,24 +16,35 @@ import (
 
 // Server serves HTTP requests for our simple banking service
 type Server struct {
-	config     util.Config
-	store      db.Store
-	tokenMaker token.Maker
-	router     *gin.Engine
+	config            util.Config
+	store             db.Store
+	tokenMaker        token.Maker
+	revocationChecker RevocationChecker
+	totpCipher        *totp.Cipher
+	rateProvider      fx.RateProvider
+	router            *gin.Engine
 }
 
 // NewSErver creates a new HTTP server and setup routing
-func NewServer(config util.Config, store db.Store) (*Server, error) {
+func NewServer(config util.Config, store db.Store, rateProvider fx.RateProvider, revocationChecker RevocationChecker) (*Server, error) {
 	//tokenMaker, err := token.NewJWTMaker(config.TokenSymmetricKey) // If we want to use JWT tokens
 	tokenMaker, err := token.NewPasetoMaker(config.TokenSymmetricKey) // If we want to use Paseto tokens
 	if err != nil {
 		return nil, fmt.Errorf("cannot create token maker: %w", err)
 	}
 
+	totpCipher, err := totp.NewCipher(config.TOTPEncryptionKey)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create totp cipher: %w", err)
+	}
+
 	server := &Server{
-		config:     config,
-		store:      store,
-		tokenMaker: tokenMaker,
+		config:            config,
+		store:             store,
+		tokenMaker:        tokenMaker,
+		revocationChecker: revocationChecker,
+		totpCipher:        totpCipher,
+		rateProvider:      rateProvider,
 	}
 
 	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
@@ -47,21 +61,85 @@ func (server *Server) setupRouter() {
 	// Adding routes to the router
 	router.POST("/users", server.createUser)
 	router.POST("/users/login", server.loginUser)
+	router.POST("/users/login/2fa", server.loginUserTwoFactor)
 	router.POST("/tokens/renew_access", server.renewAccessToken)
 
 	// Defining group of routes which require authentication
-	authRoutes := router.Group("/").Use(authMiddleware(server.tokenMaker))
+	authRoutes := router.Group("/").Use(authMiddleware(server.tokenMaker, server.revocationChecker))
+	// Money moving requests can be safely retried with an idempotency key
+	idempotency := idempotencyMiddleware(server.store, server.config.IdempotencyKeyDuration)
+	// Sensitive requests, such as the ones moving money out of accounts, need a TOTP code from the users who enabled two-factor authentication.
+	// It comes after the idempotency middleware, so retries of a request which went through are replayed without a new code
+	twoFactor := twoFactorMiddleware(server.store, server.totpCipher)
+
+	authRoutes.POST("/users/logout", server.logoutUser)
+	authRoutes.POST("/users/2fa/setup", server.setupTwoFactor)
+	authRoutes.POST("/users/2fa/verify", server.verifyTwoFactor)
+	authRoutes.POST("/users/2fa/disable", server.disableTwoFactor)
+	authRoutes.POST("/users/2fa/recovery_codes", server.regenerateRecoveryCodes)
+	authRoutes.GET("/sessions", server.listSessions)
+	authRoutes.DELETE("/sessions/:id", server.revokeSession)
+	authRoutes.POST("/sessions/revoke_all", server.revokeAllSessions)
 
 	authRoutes.POST("/accounts", server.createAccount)
 	authRoutes.GET("/accounts/:id", server.getAccount)
 	authRoutes.GET("/accounts", server.listAccounts)
-
-	authRoutes.POST("/transfers", server.createTransfer)
-
-	authRoutes.POST("/deposits", server.createDeposit)
+	authRoutes.PATCH("/accounts/:id", server.updateAccount)
+	authRoutes.GET("/accounts/:id/balance", server.getAccountBalance)
+	authRoutes.GET("/accounts/:id/entries", server.listAccountEntries)
+	authRoutes.GET("/accounts/:id/statements", server.getStatement)
+	authRoutes.POST("/accounts/:id/freeze", server.freezeAccount)
+	authRoutes.POST("/accounts/:id/unfreeze", server.unfreezeAccount)
+	authRoutes.POST("/accounts/:id/close", idempotency, twoFactor, server.closeAccount)
+	authRoutes.POST("/accounts/:id/members", twoFactor, server.addAccountMember)
+	authRoutes.GET("/accounts/:id/members", server.listAccountMembers)
+	authRoutes.DELETE("/accounts/:id/members/:username", server.removeAccountMember)
+
+	authRoutes.POST("/transfers", idempotency, twoFactor, server.createTransfer)
+	authRoutes.POST("/transfers/batch", idempotency, twoFactor, server.createBatchTransfer)
+	authRoutes.POST("/transfers/preview", server.previewTransfer)
+	authRoutes.GET("/transfers/:id", server.getTransfer)
+	authRoutes.GET("/transfers", server.listTransfers)
+	authRoutes.POST("/transfers/:id/reverse", idempotency, twoFactor, server.reverseTransfer)
+
+	authRoutes.POST("/deposits", idempotency, server.createDeposit)
 	authRoutes.GET("/deposits/:id", server.getDeposit)
 	authRoutes.GET("/deposits", server.listDeposits)
 
+	authRoutes.POST("/withdraws", idempotency, twoFactor, server.createWithdraw)
+	authRoutes.GET("/withdraws/:id", server.getWithdraw)
+	authRoutes.GET("/withdraws", server.listWithdraws)
+
+	authRoutes.POST("/holds", idempotency, twoFactor, server.createHold)
+	authRoutes.GET("/holds/:id", server.getHold)
+	authRoutes.POST("/holds/:id/capture", idempotency, twoFactor, server.captureHold)
+	authRoutes.POST("/holds/:id/void", idempotency, server.voidHold)
+
+	authRoutes.POST("/fx/quotes", server.createFxQuote)
+
+	authRoutes.POST("/scheduled_transfers", idempotency, twoFactor, server.createScheduledTransfer)
+	authRoutes.GET("/scheduled_transfers", server.listScheduledTransfers)
+	authRoutes.GET("/scheduled_transfers/:id/runs", server.listScheduledTransferRuns)
+	authRoutes.POST("/scheduled_transfers/:id/pause", server.pauseScheduledTransfer)
+	authRoutes.POST("/scheduled_transfers/:id/resume", twoFactor, server.resumeScheduledTransfer)
+	authRoutes.DELETE("/scheduled_transfers/:id", server.cancelScheduledTransfer)
+
+	// Defining group of routes for the staff of the bank, which act on the accounts of any customer
+	adminRoutes := router.Group("/admin").Use(
+		authMiddleware(server.tokenMaker, server.revocationChecker),
+		requireRole(db.UserRoleBanker, db.UserRoleAdmin),
+	)
+
+	adminRoutes.GET("/accounts/:id", server.adminGetAccount)
+	adminRoutes.GET("/accounts", server.adminListAccounts)
+	adminRoutes.POST("/accounts/:id/freeze", server.adminFreezeAccount)
+	adminRoutes.POST("/accounts/:id/unfreeze", server.adminUnfreezeAccount)
+	adminRoutes.POST("/transfers/:id/reverse", idempotency, twoFactor, server.adminReverseTransfer)
+	adminRoutes.POST("/deposits", idempotency, twoFactor, server.adminCreateDeposit)
+
+	adminRoutes.GET("/reconciliation", requireRole(db.UserRoleAdmin), server.reconcileLedger)
+	adminRoutes.PATCH("/users/:username/role", requireRole(db.UserRoleAdmin), twoFactor, server.updateUserRole)
+
 	server.router = router
 }
 
@@ -73,3 +151,65 @@ func (server *Server) Start(address string) error {
 func errorResponse(err error) gin.H {
 	return gin.H{"error": err.Error()}
 }
+
+// Error codes sent to the clients, so they don't need to parse error messages
+const (
+	errCodeInsufficientFunds        = "insufficient_funds"
+	errCodeIdempotencyKeyReused     = "idempotency_key_reused"
+	errCodeIdempotencyKeyInProgress = "idempotency_key_in_progress"
+	errCodeRateUnavailable          = "rate_unavailable"
+	errCodeCurrencyMismatch         = "currency_mismatch"
+	errCodeQuoteUnavailable         = "quote_unavailable"
+	errCodeQuoteMismatch            = "quote_mismatch"
+	errCodeTransferAlreadyReversed  = "transfer_already_reversed"
+	errCodeReversalExceedsTransfer  = "reversal_exceeds_transfer"
+	errCodeReversalNotAllowed       = "reversal_not_allowed"
+	errCodeHoldNotActive            = "hold_not_active"
+	errCodeCaptureExceedsHold       = "capture_exceeds_hold"
+	errCodeLimitExceeded            = "limit_exceeded"
+	errCodeAccountFrozen            = "account_frozen"
+	errCodeAccountClosed            = "account_closed"
+	errCodeInvalidStatusChange      = "invalid_status_change"
+	errCodeAccountNotEmpty          = "account_not_empty"
+	errCodeAccountHasHolds          = "account_has_holds"
+	errCodeInvalidPayout            = "invalid_payout"
+	errCodeAccountRoleNotAllowed    = "account_role_not_allowed"
+	errCodeSpendLimitExceeded       = "spend_limit_exceeded"
+	errCodeAccountLimitReached      = "account_limit_reached"
+	errCodeRefreshTokenReused       = "refresh_token_reused"
+	errCodeRoleNotAllowed           = "role_not_allowed"
+	errCodeTwoFactorRequired        = "two_factor_required"
+	errCodeInvalidTwoFactorCode     = "invalid_two_factor_code"
+	errCodeTooManyTwoFactorAttempts = "too_many_two_factor_attempts"
+	errCodeTwoFactorAlreadyEnabled  = "two_factor_already_enabled"
+	errCodeTwoFactorNotEnabled      = "two_factor_not_enabled"
+	errCodeScheduledTransferRunning = "scheduled_transfer_running"
+)
+
+func errorCodeResponse(code string, err error) gin.H {
+	return gin.H{"error": err.Error(), "code": code}
+}
+
+// accountStatusErrorCode gives the error code for an account which can't move money,
+// or an empty string if the error isn't about the status of an account
+func accountStatusErrorCode(err error) string {
+	switch {
+	case errors.Is(err, db.ErrAccountFrozen):
+		return errCodeAccountFrozen
+	case errors.Is(err, db.ErrAccountClosed):
+		return errCodeAccountClosed
+	default:
+		return ""
+	}
+}
+
+// limitExceededResponse tells the client which limit was hit and how much of it is left
+func limitExceededResponse(err *db.LimitExceededError) gin.H {
+	return gin.H{
+		"error":     err.Error(),
+		"code":      errCodeLimitExceeded,
+		"limit":     err.Limit,
+		"max":       err.Max,
+		"remaining": err.Remaining,
+	}
+}
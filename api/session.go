@@ -0,0 +1,149 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	db "simplebank/db/sqlc"
+	"simplebank/token"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// sessionResponse leaves the refresh token out of the session
+type sessionResponse struct {
+	ID        uuid.UUID `json:"id"`
+	UserAgent string    `json:"user_agent"`
+	ClientIp  string    `json:"client_ip"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newSessionResponse(session db.Session) sessionResponse {
+	return sessionResponse{
+		ID:        session.ID,
+		UserAgent: session.UserAgent,
+		ClientIp:  session.ClientIp,
+		ExpiresAt: session.ExpiresAt,
+		CreatedAt: session.CreatedAt,
+	}
+}
+
+type logoutUserRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// logoutUser blocks the session of the refresh token, so it can't renew access tokens anymore
+func (server *Server) logoutUser(ctx *gin.Context) {
+	var req logoutUserRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	refreshPayload, err := server.tokenMaker.VerifyToken(req.RefreshToken)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	if refreshPayload.Type != token.TypeRefresh {
+		err := errors.New("not a refresh token")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	server.blockSession(ctx, refreshPayload.SessionID)
+}
+
+type listSessionsRequest struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+// listSessions lists the devices the user is signed in on
+func (server *Server) listSessions(ctx *gin.Context) {
+	var req listSessionsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// Getting the user which made the request
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	arg := db.ListActiveSessionsParams{
+		Username: authPayload.Username,
+		Limit:    req.PageSize,
+		// Calculating the offset from page number and size
+		Offset: (req.PageID - 1) * req.PageSize,
+	}
+
+	sessions, err := server.store.ListActiveSessions(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := make([]sessionResponse, len(sessions))
+	for i, session := range sessions {
+		rsp[i] = newSessionResponse(session)
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type revokeSessionRequest struct {
+	ID string `uri:"id" binding:"required,uuid"`
+}
+
+func (server *Server) revokeSession(ctx *gin.Context) {
+	var req revokeSessionRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	server.blockSession(ctx, uuid.MustParse(req.ID))
+}
+
+// blockSession blocks a session of the authenticated user, sessions of other users being left alone as if
+// they didn't exist
+func (server *Server) blockSession(ctx *gin.Context, sessionID uuid.UUID) {
+	// Getting the user which made the request
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	rows, err := server.store.BlockSession(ctx, db.BlockSessionParams{
+		ID:       sessionID,
+		Username: authPayload.Username,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if rows == 0 {
+		err := errors.New("session doesn't exist")
+		ctx.JSON(http.StatusNotFound, errorResponse(err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+type revokeAllSessionsResponse struct {
+	Revoked int64 `json:"revoked"`
+}
+
+// revokeAllSessions signs the user out of every device
+func (server *Server) revokeAllSessions(ctx *gin.Context) {
+	// Getting the user which made the request
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	revoked, err := server.store.BlockUserSessions(ctx, authPayload.Username)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, revokeAllSessionsResponse{Revoked: revoked})
+}
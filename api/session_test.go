@@ -0,0 +1,303 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "simplebank/db/mock"
+	db "simplebank/db/sqlc"
+	"simplebank/token"
+	"simplebank/util"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogoutUserAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	// The refresh token is made by the server of each case, so its ID is only known once the request is sent
+	var sessionID uuid.UUID
+
+	testCases := []struct {
+		name          string
+		body          func(t *testing.T, tokenMaker token.Maker) gin.H
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recoder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: func(t *testing.T, tokenMaker token.Maker) gin.H {
+				refreshToken, payload, err := tokenMaker.CreateToken(user.Username, db.UserRoleCustomer, uuid.New(), token.TypeRefresh, time.Minute)
+				require.NoError(t, err)
+				sessionID = payload.SessionID
+				return gin.H{"refresh_token": refreshToken}
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					BlockSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.BlockSessionParams) (int64, error) {
+						require.Equal(t, sessionID, arg.ID)
+						require.Equal(t, user.Username, arg.Username)
+						return 1, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+		{
+			name: "SessionOfAnotherUser",
+			body: func(t *testing.T, tokenMaker token.Maker) gin.H {
+				refreshToken, _, err := tokenMaker.CreateToken("another_user", db.UserRoleCustomer, uuid.New(), token.TypeRefresh, time.Minute)
+				require.NoError(t, err)
+				return gin.H{"refresh_token": refreshToken}
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					BlockSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(0), nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidRefreshToken",
+			body: func(t *testing.T, tokenMaker token.Maker) gin.H {
+				return gin.H{"refresh_token": "invalid"}
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().BlockSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "NoAuthorization",
+			body: func(t *testing.T, tokenMaker token.Maker) gin.H {
+				refreshToken, _, err := tokenMaker.CreateToken(user.Username, db.UserRoleCustomer, uuid.New(), token.TypeRefresh, time.Minute)
+				require.NoError(t, err)
+				return gin.H{"refresh_token": refreshToken}
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().BlockSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body(t, server.tokenMaker))
+			require.NoError(t, err)
+
+			url := "/users/logout"
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestListSessionsAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	n := 5
+	sessions := make([]db.Session, n)
+	for i := range sessions {
+		sessions[i] = randomSession(user.Username)
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	arg := db.ListActiveSessionsParams{
+		Username: user.Username,
+		Limit:    int32(n),
+		Offset:   0,
+	}
+	store.EXPECT().
+		ListActiveSessions(gomock.Any(), gomock.Eq(arg)).
+		Times(1).
+		Return(sessions, nil)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	url := fmt.Sprintf("/sessions?page_id=1&page_size=%d", n)
+	request, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	// Refresh tokens are never sent back
+	require.NotContains(t, recorder.Body.String(), "refresh_token")
+
+	var got []sessionResponse
+	err = json.Unmarshal(recorder.Body.Bytes(), &got)
+	require.NoError(t, err)
+	require.Len(t, got, n)
+	for i, session := range sessions {
+		require.Equal(t, session.ID, got[i].ID)
+		require.Equal(t, session.UserAgent, got[i].UserAgent)
+	}
+}
+
+func TestRevokeSessionAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	session := randomSession(user.Username)
+
+	testCases := []struct {
+		name          string
+		sessionID     string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recoder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "OK",
+			sessionID: session.ID.String(),
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.BlockSessionParams{
+					ID:       session.ID,
+					Username: user.Username,
+				}
+				store.EXPECT().
+					BlockSession(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(int64(1), nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+		{
+			name:      "NotFound",
+			sessionID: session.ID.String(),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					BlockSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(0), nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:      "InvalidID",
+			sessionID: "invalid",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().BlockSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/sessions/%s", tc.sessionID)
+			request, err := http.NewRequest(http.MethodDelete, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestRevokeAllSessionsAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		BlockUserSessions(gomock.Any(), gomock.Eq(user.Username)).
+		Times(1).
+		Return(int64(3), nil)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodPost, "/sessions/revoke_all", nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var got revokeAllSessionsResponse
+	err = json.Unmarshal(recorder.Body.Bytes(), &got)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), got.Revoked)
+}
+
+func randomSession(username string) db.Session {
+	id := uuid.New()
+	return db.Session{
+		ID:           id,
+		Username:     username,
+		RefreshToken: util.RandomString(32),
+		UserAgent:    util.RandomString(10),
+		ClientIp:     "127.0.0.1",
+		ExpiresAt:    time.Now().Add(time.Hour),
+		CreatedAt:    time.Now(),
+		FamilyID:     id,
+	}
+}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	db "simplebank/db/sqlc"
+	"simplebank/statement"
+
+	"github.com/gin-gonic/gin"
+)
+
+type getStatementURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type getStatementQuery struct {
+	Month string `form:"month" binding:"required"`
+	// Format defaults to json
+	Format string `form:"format" binding:"omitempty,oneof=pdf csv json"`
+}
+
+// statementContentTypes are the content types of the downloadable formats
+var statementContentTypes = map[string]string{
+	statement.FormatPDF: "application/pdf",
+	statement.FormatCSV: "text/csv",
+}
+
+func (server *Server) getStatement(ctx *gin.Context) {
+	var uri getStatementURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req getStatementQuery
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	period, err := statement.ParseMonth(req.Month)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	format := req.Format
+	if format == "" {
+		format = statement.FormatJSON
+	}
+
+	account, err := server.store.GetAccount(ctx, uri.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// Checking if the account is shared with the user
+	if _, ok := server.authorizeAccount(ctx, account, db.AccountRoleViewer); !ok {
+		return
+	}
+
+	// Statements of past months are usually generated by the worker already
+	if format != statement.FormatJSON && period.AddDate(0, 1, 0).Before(time.Now()) {
+		stored, err := server.store.GetStatement(ctx, db.GetStatementParams{
+			AccountID: account.ID,
+			Period:    period,
+		})
+		if err == nil {
+			content := stored.Csv
+			if format == statement.FormatPDF {
+				content = stored.Pdf
+			}
+			sendStatement(ctx, account, req.Month, format, content)
+			return
+		}
+		if err != sql.ErrNoRows {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+	}
+
+	accountStatement, err := statement.Generate(ctx, server.store, account, period)
+	if err != nil {
+		if errors.Is(err, statement.ErrMonthNotStarted) {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	var content []byte
+	switch format {
+	case statement.FormatJSON:
+		ctx.JSON(http.StatusOK, accountStatement)
+		return
+	case statement.FormatPDF:
+		content, err = accountStatement.PDF()
+	case statement.FormatCSV:
+		content, err = accountStatement.CSV()
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	sendStatement(ctx, account, req.Month, format, content)
+}
+
+// sendStatement sends a rendered statement as a file to be downloaded
+func sendStatement(ctx *gin.Context, account db.Account, month string, format string, content []byte) {
+	filename := fmt.Sprintf("statement-%d-%s.%s", account.ID, month, format)
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	ctx.Data(http.StatusOK, statementContentTypes[format], content)
+}
@@ -0,0 +1,287 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "simplebank/db/mock"
+	db "simplebank/db/sqlc"
+	"simplebank/statement"
+	"simplebank/token"
+	"simplebank/util"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetStatementAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+
+	month := "2026-09"
+	period := time.Date(2026, time.September, 1, 0, 0, 0, 0, time.UTC)
+	entry := db.ListStatementEntriesRow{
+		ID:         util.RandomInt(1, 1000),
+		AccountID:  account.ID,
+		Amount:     util.RandomMoney(),
+		CreatedAt:  period.Add(time.Hour),
+		SourceType: db.EntrySourceDeposit,
+	}
+	stored := db.Statement{
+		AccountID: account.ID,
+		Period:    period,
+		Pdf:       []byte("%PDF-1.4\n"),
+		Csv:       []byte("date\n"),
+	}
+
+	// The statement of the current month can't be stored yet
+	now := time.Now().UTC()
+	currentMonth := now.Format(statement.MonthLayout)
+	nextMonth := time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, time.UTC).Format(statement.MonthLayout)
+
+	buildStatementStubs := func(store *mockdb.MockStore) {
+		store.EXPECT().
+			GetAccountBalanceAt(gomock.Any(), gomock.Any()).
+			Times(1).
+			Return(account.Balance-entry.Amount, nil)
+		store.EXPECT().
+			ListStatementEntries(gomock.Any(), gomock.Any()).
+			Times(1).
+			Return([]db.ListStatementEntriesRow{entry}, nil)
+	}
+
+	testCases := []struct {
+		name          string
+		accountID     int64
+		month         string
+		format        string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "OK",
+			accountID: account.ID,
+			month:     month,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetStatement(gomock.Any(), gomock.Any()).Times(0)
+				buildStatementStubs(store)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				rsp := requireBodyStatement(t, recorder.Body)
+				require.Equal(t, month, rsp.Month)
+				require.Equal(t, account.Balance-entry.Amount, rsp.OpeningBalance)
+				require.Equal(t, account.Balance, rsp.ClosingBalance)
+				require.Len(t, rsp.Lines, 1)
+				require.Equal(t, "Deposit", rsp.Lines[0].Description)
+			},
+		},
+		{
+			name:      "OKStoredPDF",
+			accountID: account.ID,
+			month:     month,
+			format:    statement.FormatPDF,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					GetStatement(gomock.Any(), gomock.Eq(db.GetStatementParams{AccountID: account.ID, Period: period})).
+					Times(1).
+					Return(stored, nil)
+				store.EXPECT().ListStatementEntries(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "application/pdf", recorder.Header().Get("Content-Type"))
+				require.Contains(t, recorder.Header().Get("Content-Disposition"), fmt.Sprintf("statement-%d-%s.pdf", account.ID, month))
+				require.Equal(t, stored.Pdf, recorder.Body.Bytes())
+			},
+		},
+		{
+			name:      "OKNotStoredCSV",
+			accountID: account.ID,
+			month:     month,
+			format:    statement.FormatCSV,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					GetStatement(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Statement{}, sql.ErrNoRows)
+				buildStatementStubs(store)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "text/csv", recorder.Header().Get("Content-Type"))
+				require.Contains(t, recorder.Body.String(), "Opening balance")
+			},
+		},
+		{
+			name:      "OKCurrentMonthPDF",
+			accountID: account.ID,
+			month:     currentMonth,
+			format:    statement.FormatPDF,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetStatement(gomock.Any(), gomock.Any()).Times(0)
+				buildStatementStubs(store)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.True(t, bytes.HasPrefix(recorder.Body.Bytes(), []byte("%PDF-")))
+			},
+		},
+		{
+			name:      "MonthNotStarted",
+			accountID: account.ID,
+			month:     nextMonth,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().ListStatementEntries(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "InvalidMonth",
+			accountID: account.ID,
+			month:     "09-2026",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "InvalidFormat",
+			accountID: account.ID,
+			month:     month,
+			format:    "xlsx",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "UnauthorizedUser",
+			accountID: account.ID,
+			month:     month,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "unauthorized_user", time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccountMember(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.AccountMember{}, sql.ErrNoRows)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().ListStatementEntries(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:      "NotFound",
+			accountID: account.ID,
+			month:     month,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:      "NoAuthorization",
+			accountID: account.ID,
+			month:     month,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/accounts/%d/statements", tc.accountID)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			// Add query parameters to request URL
+			q := request.URL.Query()
+			q.Add("month", tc.month)
+			if tc.format != "" {
+				q.Add("format", tc.format)
+			}
+			request.URL.RawQuery = q.Encode()
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func requireBodyStatement(t *testing.T, body *bytes.Buffer) statement.Statement {
+	data, err := ioutil.ReadAll(body)
+	require.NoError(t, err)
+
+	var rsp statement.Statement
+	err = json.Unmarshal(data, &rsp)
+	require.NoError(t, err)
+	return rsp
+}
@@ -2,11 +2,16 @@ package api
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
 
+	db "simplebank/db/sqlc"
+	"simplebank/token"
+
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 type renewAccessTokenRequest struct {
@@ -14,10 +19,15 @@ type renewAccessTokenRequest struct {
 }
 
 type renewAccessTokenResponse struct {
-	AccessToken          string    `json:"access_token"`
-	AccessTokenExpiresAt time.Time `json:"access_token_expires_at"`
+	SessionID             uuid.UUID `json:"session_id"`
+	AccessToken           string    `json:"access_token"`
+	AccessTokenExpiresAt  time.Time `json:"access_token_expires_at"`
+	RefreshToken          string    `json:"refresh_token"`
+	RefreshTokenExpiresAt time.Time `json:"refresh_token_expires_at"`
 }
 
+// renewAccessToken exchanges a refresh token for a new access token and a new refresh token of the same
+// session family, after which the old refresh token can't be used anymore
 func (server *Server) renewAccessToken(ctx *gin.Context) {
 	var req renewAccessTokenRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
@@ -31,7 +41,13 @@ func (server *Server) renewAccessToken(ctx *gin.Context) {
 		return
 	}
 
-	session, err := server.store.GetSession(ctx, refreshPayload.ID)
+	if refreshPayload.Type != token.TypeRefresh {
+		err := errors.New("not a refresh token")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	session, err := server.store.GetSession(ctx, refreshPayload.SessionID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			ctx.JSON(http.StatusNotFound, errorResponse(err))
@@ -65,8 +81,24 @@ func (server *Server) renewAccessToken(ctx *gin.Context) {
 		return
 	}
 
+	// The role is read again, since it may have changed since the refresh token was issued
+	user, err := server.store.GetUser(ctx, session.Username)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	sessionID, err := uuid.NewRandom()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
 	accessToken, accessPayload, err := server.tokenMaker.CreateToken(
-		refreshPayload.Username,
+		user.Username,
+		user.Role,
+		sessionID,
+		token.TypeAccess,
 		server.config.AccessTokenDuration,
 	)
 	if err != nil {
@@ -74,9 +106,52 @@ func (server *Server) renewAccessToken(ctx *gin.Context) {
 		return
 	}
 
+	refreshToken, newRefreshPayload, err := server.tokenMaker.CreateToken(
+		user.Username,
+		user.Role,
+		sessionID,
+		token.TypeRefresh,
+		server.config.RefreshTokenDuration,
+	)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	result, err := server.store.RotateSessionTx(ctx, db.RotateSessionTxParams{
+		ID: session.ID,
+		Session: db.CreateSessionParams{
+			ID:           sessionID,
+			Username:     session.Username,
+			RefreshToken: refreshToken,
+			UserAgent:    ctx.Request.UserAgent(),
+			ClientIp:     ctx.ClientIP(),
+			IsBlocked:    false,
+			ExpiresAt:    newRefreshPayload.ExpiredAt,
+		},
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrSessionBlocked) {
+			ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// The refresh token was exchanged before, so whoever else holds it was signed out along with the user
+	if result.ReuseEvent != nil {
+		err := errors.New("refresh token was already used, the session has been revoked")
+		ctx.JSON(http.StatusUnauthorized, errorCodeResponse(errCodeRefreshTokenReused, err))
+		return
+	}
+
 	rsp := renewAccessTokenResponse{
-		AccessToken:          accessToken,
-		AccessTokenExpiresAt: accessPayload.ExpiredAt,
+		SessionID:             result.Session.ID,
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  accessPayload.ExpiredAt,
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: newRefreshPayload.ExpiredAt,
 	}
 	ctx.JSON(http.StatusOK, rsp)
 }
@@ -0,0 +1,231 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "simplebank/db/mock"
+	db "simplebank/db/sqlc"
+	"simplebank/token"
+	"simplebank/util"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenewAccessTokenAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	// The user was made a banker since the refresh token was issued
+	promoted := user
+	promoted.Role = db.UserRoleBanker
+
+	testCases := []struct {
+		name          string
+		body          func(refreshToken string) gin.H
+		buildStubs    func(store *mockdb.MockStore, session db.Session)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker, refreshToken string)
+	}{
+		{
+			name: "OK",
+			body: func(refreshToken string) gin.H {
+				return gin.H{"refresh_token": refreshToken}
+			},
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(session, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(promoted, nil)
+				store.EXPECT().
+					RotateSessionTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.RotateSessionTxParams) (db.RotateSessionTxResult, error) {
+						require.Equal(t, session.ID, arg.ID)
+						require.Equal(t, user.Username, arg.Session.Username)
+						require.NotEqual(t, session.RefreshToken, arg.Session.RefreshToken)
+
+						newSession := randomSession(user.Username)
+						newSession.ID = arg.Session.ID
+						newSession.FamilyID = session.FamilyID
+						newSession.ParentID = uuid.NullUUID{UUID: session.ID, Valid: true}
+						return db.RotateSessionTxResult{Session: newSession}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker, refreshToken string) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp renewAccessTokenResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &rsp)
+				require.NoError(t, err)
+				require.NotEqual(t, refreshToken, rsp.RefreshToken)
+
+				// Both new tokens belong to the new session
+				accessPayload, err := tokenMaker.VerifyToken(rsp.AccessToken)
+				require.NoError(t, err)
+				require.Equal(t, rsp.SessionID, accessPayload.SessionID)
+				require.Equal(t, db.UserRoleBanker, accessPayload.Role)
+				require.Equal(t, token.TypeAccess, accessPayload.Type)
+
+				refreshPayload, err := tokenMaker.VerifyToken(rsp.RefreshToken)
+				require.NoError(t, err)
+				require.Equal(t, rsp.SessionID, refreshPayload.SessionID)
+				require.Equal(t, token.TypeRefresh, refreshPayload.Type)
+			},
+		},
+		{
+			name: "RefreshTokenReused",
+			body: func(refreshToken string) gin.H {
+				return gin.H{"refresh_token": refreshToken}
+			},
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(session, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().
+					RotateSessionTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.RotateSessionTxResult{
+						ReuseEvent: &db.SecurityEvent{
+							ID:              1,
+							Username:        user.Username,
+							Kind:            db.SecurityEventRefreshTokenReused,
+							SessionFamilyID: session.FamilyID,
+						},
+					}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker, refreshToken string) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				requireBodyErrorCode(t, recorder.Body, errCodeRefreshTokenReused)
+				require.NotContains(t, recorder.Body.String(), "access_token")
+			},
+		},
+		{
+			name: "BlockedSession",
+			body: func(refreshToken string) gin.H {
+				return gin.H{"refresh_token": refreshToken}
+			},
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				session.IsBlocked = true
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(session, nil)
+				store.EXPECT().RotateSessionTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker, refreshToken string) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "BlockedWhileRenewing",
+			body: func(refreshToken string) gin.H {
+				return gin.H{"refresh_token": refreshToken}
+			},
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(session, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().
+					RotateSessionTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.RotateSessionTxResult{}, db.ErrSessionBlocked)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker, refreshToken string) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "MismatchedToken",
+			body: func(refreshToken string) gin.H {
+				return gin.H{"refresh_token": refreshToken}
+			},
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				session.RefreshToken = "another_token"
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(session, nil)
+				store.EXPECT().RotateSessionTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker, refreshToken string) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "SessionNotFound",
+			body: func(refreshToken string) gin.H {
+				return gin.H{"refresh_token": refreshToken}
+			},
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Any()).Times(1).Return(db.Session{}, sql.ErrNoRows)
+				store.EXPECT().RotateSessionTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker, refreshToken string) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidToken",
+			body: func(refreshToken string) gin.H {
+				return gin.H{"refresh_token": "invalid"}
+			},
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().RotateSessionTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker, refreshToken string) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			// The session of the refresh token is made once the token exists
+			session := randomSession(user.Username)
+			refreshToken, _, err := server.tokenMaker.CreateToken(user.Username, user.Role, session.ID, token.TypeRefresh, time.Minute)
+			require.NoError(t, err)
+			session.RefreshToken = refreshToken
+			tc.buildStubs(store, session)
+
+			data, err := json.Marshal(tc.body(refreshToken))
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/tokens/renew_access", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder, server.tokenMaker, refreshToken)
+		})
+	}
+}
+
+func TestRenewAccessTokenWithAccessTokenAPI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetSession(gomock.Any(), gomock.Any()).Times(0)
+	store.EXPECT().RotateSessionTx(gomock.Any(), gomock.Any()).Times(0)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	// Access tokens belong to the same session as refresh tokens, but can't be exchanged for new ones
+	accessToken, _, err := server.tokenMaker.CreateToken(util.RandomOwner(), db.UserRoleCustomer, uuid.New(), token.TypeAccess, time.Minute)
+	require.NoError(t, err)
+
+	data, err := json.Marshal(gin.H{"refresh_token": accessToken})
+	require.NoError(t, err)
+
+	request, err := http.NewRequest(http.MethodPost, "/tokens/renew_access", bytes.NewReader(data))
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
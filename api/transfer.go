@@ -4,7 +4,10 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"io"
+	"math"
 	"net/http"
+	"time"
 
 	db "simplebank/db/sqlc"
 	"simplebank/token"
@@ -17,9 +20,10 @@ type transferRequest struct {
 	ToAccountID   int64  `json:"to_account_id" binding:"required,min=1"`
 	Amount        int64  `json:"amount" binding:"required,gt=0"`
 	Currency      string `json:"currency" binding:"required,currency"`
+	QuoteID       int64  `json:"quote_id" binding:"omitempty,min=1"`
 }
 
-func (server *Server) validAccountCurrency(ctx *gin.Context, accountID int64, currency string) (db.Account, bool) {
+func (server *Server) validAccount(ctx *gin.Context, accountID int64) (db.Account, bool) {
 	// Getting the account by the provided ID
 	account, err := server.store.GetAccount(ctx, accountID)
 	if err != nil {
@@ -32,6 +36,15 @@ func (server *Server) validAccountCurrency(ctx *gin.Context, accountID int64, cu
 		return account, false
 	}
 
+	return account, true
+}
+
+func (server *Server) validAccountCurrency(ctx *gin.Context, accountID int64, currency string) (db.Account, bool) {
+	account, valid := server.validAccount(ctx, accountID)
+	if !valid {
+		return account, false
+	}
+
 	// Checking if currency matches
 	if account.Currency != currency {
 		err := fmt.Errorf("account [%d] currency mismatch: %s vs %s", account.ID, account.Currency, currency)
@@ -43,47 +56,508 @@ func (server *Server) validAccountCurrency(ctx *gin.Context, accountID int64, cu
 	return account, true
 }
 
-func (server *Server) createTransfer(ctx *gin.Context) {
+// bindTransfer reads a transfer request, checking the accounts and that the authenticated user
+// can spend the amount from the origin account. The error response is already written when it returns false
+func (server *Server) bindTransfer(ctx *gin.Context) (db.TransferTxParams, db.Account, bool) {
 	// Reading the request body
 	var req transferRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
-		return
+		return db.TransferTxParams{}, db.Account{}, false
 	}
 
 	// Checking if accounts have the required currency
 	fromAccount, valid := server.validAccountCurrency(ctx, req.FromAccountID, req.Currency)
 	if !valid {
-		return
+		return db.TransferTxParams{}, db.Account{}, false
 	}
 
-	// Getting the user which made the request
-	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
-	if fromAccount.Owner != authPayload.Username {
-		err := errors.New("origin account doesn't belong to the authenticated user")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
-		return
+	if !server.authorizeSpending(ctx, fromAccount, req.Amount) {
+		return db.TransferTxParams{}, db.Account{}, false
 	}
 
-	_, valid = server.validAccountCurrency(ctx, req.ToAccountID, req.Currency)
+	var toAccount db.Account
+	if req.QuoteID == 0 {
+		toAccount, valid = server.validAccountCurrency(ctx, req.ToAccountID, req.Currency)
+	} else {
+		// The quote takes care of converting to the destination account currency
+		toAccount, valid = server.validAccount(ctx, req.ToAccountID)
+	}
 	if !valid {
-		return
+		return db.TransferTxParams{}, db.Account{}, false
 	}
 
+	// Getting the user which made the request, the quote must be theirs
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
 	// Creating data to be set for the transfer
 	arg := db.TransferTxParams{
 		FromAccountID: req.FromAccountID,
 		ToAccountID:   req.ToAccountID,
 		Amount:        req.Amount,
+		QuoteID:       req.QuoteID,
+		User:          authPayload.Username,
+	}
+
+	return arg, toAccount, true
+}
+
+func (server *Server) createTransfer(ctx *gin.Context) {
+	arg, _, valid := server.bindTransfer(ctx)
+	if !valid {
+		return
 	}
 
 	// Calling the transfer transaction function
 	result, err := server.store.TransferTx(ctx, arg)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		transferErrorResponse(ctx, err)
 		return
 	}
 
 	// Returning data to the request if no error occurs
 	ctx.JSON(http.StatusOK, result)
 }
+
+type transferPreviewResponse struct {
+	Amount       int64  `json:"amount"`
+	Fee          int64  `json:"fee"`
+	FromCurrency string `json:"from_currency"`
+	ToAmount     int64  `json:"to_amount"`
+	ToCurrency   string `json:"to_currency"`
+	Rate         int64  `json:"rate"`
+	// Balances of the accounts once the transfer and its fee are made
+	FromAccountBalance int64 `json:"from_account_balance"`
+	// ToAccountBalance is only shown when the destination account is shared with the authenticated user
+	ToAccountBalance *int64 `json:"to_account_balance,omitempty"`
+}
+
+func (server *Server) previewTransfer(ctx *gin.Context) {
+	arg, toAccount, valid := server.bindTransfer(ctx)
+	if !valid {
+		return
+	}
+
+	// Nothing is committed, and a quote used by the preview can still be used by the transfer
+	result, err := server.store.PreviewTransferTx(ctx, arg)
+	if err != nil {
+		transferErrorResponse(ctx, err)
+		return
+	}
+
+	rsp := transferPreviewResponse{
+		Amount:             result.Transfer.Amount,
+		Fee:                result.Transfer.Fee,
+		FromCurrency:       result.Transfer.FromCurrency,
+		ToAmount:           result.Transfer.ToAmount,
+		ToCurrency:         result.Transfer.ToCurrency,
+		Rate:               result.Transfer.Rate,
+		FromAccountBalance: result.FromAccount.Balance,
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if _, err := server.accountMember(ctx, toAccount, authPayload.Username); err == nil {
+		rsp.ToAccountBalance = &result.ToAccount.Balance
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+// transferErrorResponse sends the response for an error of a transfer transaction
+func transferErrorResponse(ctx *gin.Context, err error) {
+	var limitErr *db.LimitExceededError
+	if errors.As(err, &limitErr) {
+		ctx.JSON(http.StatusUnprocessableEntity, limitExceededResponse(limitErr))
+		return
+	}
+
+	status, code := transferErrorStatus(err)
+	if code == "" {
+		ctx.JSON(status, errorResponse(err))
+		return
+	}
+	ctx.JSON(status, errorCodeResponse(code, err))
+}
+
+type batchTransferLegRequest struct {
+	ToAccountID int64 `json:"to_account_id" binding:"required,min=1"`
+	Amount      int64 `json:"amount" binding:"required,gt=0"`
+}
+
+type batchTransferRequest struct {
+	FromAccountID int64                     `json:"from_account_id" binding:"required,min=1"`
+	Currency      string                    `json:"currency" binding:"required,currency"`
+	Legs          []batchTransferLegRequest `json:"legs" binding:"required,min=1,max=50,dive"`
+	// BestEffort commits the legs which succeed, instead of failing the whole batch
+	BestEffort bool `json:"best_effort"`
+}
+
+// Statuses of the legs of a batch transfer
+const (
+	batchLegSucceeded    = "succeeded"
+	batchLegFailed       = "failed"
+	batchLegRolledBack   = "rolled_back"
+	batchLegNotAttempted = "not_attempted"
+)
+
+type batchTransferLegResponse struct {
+	ToAccountID int64                `json:"to_account_id"`
+	Amount      int64                `json:"amount"`
+	Status      string               `json:"status"`
+	Result      *db.TransferTxResult `json:"result,omitempty"`
+	Error       string               `json:"error,omitempty"`
+	Code        string               `json:"code,omitempty"`
+}
+
+type batchTransferResponse struct {
+	Legs  []batchTransferLegResponse `json:"legs"`
+	Error string                     `json:"error,omitempty"`
+	Code  string                     `json:"code,omitempty"`
+}
+
+func (server *Server) createBatchTransfer(ctx *gin.Context) {
+	// Reading the request body
+	var req batchTransferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// The whole batch is checked against the spend limit, so its total must fit in an int64
+	var total int64
+	for i, leg := range req.Legs {
+		if leg.Amount > math.MaxInt64-total {
+			err := fmt.Errorf("leg %d makes the total of the batch too large", i)
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		total += leg.Amount
+	}
+
+	// Checking every account up front, so a batch is never rejected half way
+	fromAccount, valid := server.validAccountCurrency(ctx, req.FromAccountID, req.Currency)
+	if !valid {
+		return
+	}
+
+	if !server.authorizeSpending(ctx, fromAccount, total) {
+		return
+	}
+
+	arg := db.BatchTransferTxParams{
+		FromAccountID: req.FromAccountID,
+		Legs:          make([]db.BatchTransferLeg, len(req.Legs)),
+		BestEffort:    req.BestEffort,
+	}
+
+	checked := make(map[int64]bool)
+	for i, leg := range req.Legs {
+		if leg.ToAccountID == req.FromAccountID {
+			err := fmt.Errorf("leg %d sends money to the origin account", i)
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+
+		if !checked[leg.ToAccountID] {
+			if _, valid := server.validAccountCurrency(ctx, leg.ToAccountID, req.Currency); !valid {
+				return
+			}
+			checked[leg.ToAccountID] = true
+		}
+
+		arg.Legs[i] = db.BatchTransferLeg{
+			ToAccountID: leg.ToAccountID,
+			Amount:      leg.Amount,
+		}
+	}
+
+	result, err := server.store.BatchTransferTx(ctx, arg)
+
+	var legErr *db.BatchLegError
+	if err != nil && !errors.As(err, &legErr) {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := batchTransferResponse{
+		Legs: make([]batchTransferLegResponse, len(arg.Legs)),
+	}
+	for i, leg := range arg.Legs {
+		legRsp := batchTransferLegResponse{
+			ToAccountID: leg.ToAccountID,
+			Amount:      leg.Amount,
+			Status:      batchLegNotAttempted,
+		}
+
+		if i < len(result.Legs) {
+			legResult := result.Legs[i]
+			switch {
+			case legResult.Err != nil:
+				legRsp.Status = batchLegFailed
+				legRsp.Error = legResult.Err.Error()
+				_, legRsp.Code = transferErrorStatus(legResult.Err)
+			case legErr != nil:
+				// The transfer was made, but the whole batch was undone
+				legRsp.Status = batchLegRolledBack
+			default:
+				legRsp.Status = batchLegSucceeded
+				legRsp.Result = &result.Legs[i].TransferTxResult
+			}
+		}
+
+		rsp.Legs[i] = legRsp
+	}
+
+	if legErr != nil {
+		var status int
+		status, rsp.Code = transferErrorStatus(legErr.Err)
+		rsp.Error = legErr.Error()
+		ctx.JSON(status, rsp)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+// transferErrorStatus gives the response status and error code for an error of a transfer
+func transferErrorStatus(err error) (int, string) {
+	switch {
+	case errors.Is(err, db.ErrInsufficientFunds):
+		return http.StatusUnprocessableEntity, errCodeInsufficientFunds
+	case errors.As(err, new(*db.LimitExceededError)):
+		return http.StatusUnprocessableEntity, errCodeLimitExceeded
+	case errors.Is(err, db.ErrCurrencyMismatch):
+		return http.StatusBadRequest, errCodeCurrencyMismatch
+	case errors.Is(err, db.ErrQuoteUnavailable):
+		return http.StatusUnprocessableEntity, errCodeQuoteUnavailable
+	case errors.Is(err, db.ErrQuoteMismatch):
+		return http.StatusUnprocessableEntity, errCodeQuoteMismatch
+	case errors.Is(err, db.ErrAccountFrozen):
+		return http.StatusUnprocessableEntity, errCodeAccountFrozen
+	case errors.Is(err, db.ErrAccountClosed):
+		return http.StatusUnprocessableEntity, errCodeAccountClosed
+	case errors.Is(err, sql.ErrNoRows):
+		return http.StatusNotFound, ""
+	default:
+		return http.StatusInternalServerError, ""
+	}
+}
+
+type getTransferRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) getTransfer(ctx *gin.Context) {
+	var req getTransferRequest
+	// Here, we'll use the URL params
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	transfer, err := server.store.GetTransfer(ctx, req.ID)
+	if err != nil {
+		// If no item was found
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// Getting the user which made the request
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	// The transfer can be seen by the members of both sides
+	for _, accountID := range []int64{transfer.FromAccountID, transfer.ToAccountID} {
+		account, err := server.store.GetAccount(ctx, accountID)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		_, err = server.accountMember(ctx, account, authPayload.Username)
+		if err == nil {
+			ctx.JSON(http.StatusOK, transfer)
+			return
+		}
+		if err != sql.ErrNoRows {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+	}
+
+	err = errors.New("transfer doesn't involve any account of the authenticated user")
+	ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+}
+
+// transferDirectionAll lists both incoming and outgoing transfers of an account
+const transferDirectionAll = "all"
+
+type reverseTransferURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type reverseTransferRequest struct {
+	// Amount is optional, all that is left of the transfer is reversed by default
+	Amount int64 `json:"amount" binding:"omitempty,gt=0"`
+}
+
+func (server *Server) reverseTransfer(ctx *gin.Context) {
+	transfer, req, ok := server.bindReversal(ctx)
+	if !ok {
+		return
+	}
+
+	// Getting the user which made the request
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	// Only the owners of the recipient account can send the money back
+	toAccount, err := server.store.GetAccount(ctx, transfer.ToAccountID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	member, err := server.accountMember(ctx, toAccount, authPayload.Username)
+	if err != nil && err != sql.ErrNoRows {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if err != nil || member.Role != db.AccountRoleOwner {
+		err := errors.New("transfer wasn't received by the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	server.applyReversal(ctx, db.ReverseTransferTxParams{
+		TransferID: transfer.ID,
+		Amount:     req.Amount,
+	})
+}
+
+// bindReversal reads the transfer to reverse and the request. The error response is already written
+// when it returns false
+func (server *Server) bindReversal(ctx *gin.Context) (db.Transfer, reverseTransferRequest, bool) {
+	var uri reverseTransferURI
+	var req reverseTransferRequest
+	// Here, we'll use the URL params
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return db.Transfer{}, req, false
+	}
+
+	// The request body may be empty, for a full reversal
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return db.Transfer{}, req, false
+	}
+
+	transfer, err := server.store.GetTransfer(ctx, uri.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return transfer, req, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return transfer, req, false
+	}
+
+	return transfer, req, true
+}
+
+// applyReversal sends the money of a transfer back, once the user is known to be allowed to
+func (server *Server) applyReversal(ctx *gin.Context, arg db.ReverseTransferTxParams) {
+	result, err := server.store.ReverseTransferTx(ctx, arg)
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrTransferAlreadyReversed):
+			ctx.JSON(http.StatusConflict, errorCodeResponse(errCodeTransferAlreadyReversed, err))
+		case errors.Is(err, db.ErrReversalExceedsTransfer):
+			ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(errCodeReversalExceedsTransfer, err))
+		case errors.Is(err, db.ErrReversalOfReversal), errors.Is(err, db.ErrReversalTooSmall):
+			ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(errCodeReversalNotAllowed, err))
+		case errors.Is(err, db.ErrInsufficientFunds):
+			ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(errCodeInsufficientFunds, err))
+		case accountStatusErrorCode(err) != "":
+			ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(accountStatusErrorCode(err), err))
+		default:
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+type listTransferRequest struct {
+	AccountID int64      `form:"account_id" binding:"required,min=1"`
+	Direction string     `form:"direction" binding:"omitempty,oneof=in out all"`
+	FromDate  *time.Time `form:"from_date" time_format:"2006-01-02T15:04:05Z07:00"`
+	ToDate    *time.Time `form:"to_date" time_format:"2006-01-02T15:04:05Z07:00"`
+	MinAmount *int64     `form:"min_amount" binding:"omitempty,gt=0"`
+	MaxAmount *int64     `form:"max_amount" binding:"omitempty,gt=0"`
+	PageID    int32      `form:"page_id" binding:"required,min=1"`
+	PageSize  int32      `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+func (server *Server) listTransfers(ctx *gin.Context) {
+	var req listTransferRequest
+	// Here, we'll use the query params
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if req.FromDate != nil && req.ToDate != nil && !req.FromDate.Before(*req.ToDate) {
+		err := errors.New("from_date must be before to_date")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if req.MinAmount != nil && req.MaxAmount != nil && *req.MinAmount > *req.MaxAmount {
+		err := errors.New("min_amount must not be greater than max_amount")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, valid := server.validAccount(ctx, req.AccountID)
+	if !valid {
+		return
+	}
+
+	// Checking if the account is shared with the user
+	if _, ok := server.authorizeAccount(ctx, account, db.AccountRoleViewer); !ok {
+		return
+	}
+
+	// Here, we'll get the query params
+	arg := db.ListTransfersParams{
+		AccountID: req.AccountID,
+		Direction: req.Direction,
+		Limit:     req.PageSize,
+		// Calculating the offset from page number and size
+		Offset: (req.PageID - 1) * req.PageSize,
+	}
+	if len(arg.Direction) == 0 {
+		arg.Direction = transferDirectionAll
+	}
+	if req.FromDate != nil {
+		arg.FromDate = sql.NullTime{Time: *req.FromDate, Valid: true}
+	}
+	if req.ToDate != nil {
+		arg.ToDate = sql.NullTime{Time: *req.ToDate, Valid: true}
+	}
+	if req.MinAmount != nil {
+		arg.MinAmount = sql.NullInt64{Int64: *req.MinAmount, Valid: true}
+	}
+	if req.MaxAmount != nil {
+		arg.MaxAmount = sql.NullInt64{Int64: *req.MaxAmount, Valid: true}
+	}
+
+	transfers, err := server.store.ListTransfers(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, transfers)
+}
@@ -0,0 +1,428 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	db "simplebank/db/sqlc"
+	"simplebank/token"
+	"simplebank/totp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	// totpIssuer is the name authenticator apps show next to the codes
+	totpIssuer = "Simple Bank"
+	// twoFactorCodeHeader carries the TOTP code of sensitive requests
+	twoFactorCodeHeader = "X-TOTP-Code"
+	// maxTwoFactorFailures is how many codes can be refused in a row before checking codes is locked
+	// for twoFactorLockout, so they can't be guessed
+	maxTwoFactorFailures = 5
+	twoFactorLockout     = 15 * time.Minute
+)
+
+// challengeSessionID is the session of challenge tokens, which don't belong to any
+var challengeSessionID = uuid.Nil
+
+type loginChallengeResponse struct {
+	TwoFactorRequired       bool      `json:"two_factor_required"`
+	ChallengeToken          string    `json:"challenge_token"`
+	ChallengeTokenExpiresAt time.Time `json:"challenge_token_expires_at"`
+}
+
+// sendLoginChallenge gives a user whose password was checked a short-lived challenge token,
+// which is exchanged for the tokens of a new session along with a TOTP code
+func (server *Server) sendLoginChallenge(ctx *gin.Context, user db.User) {
+	challengeToken, challengePayload, err := server.tokenMaker.CreateToken(
+		user.Username,
+		"",
+		challengeSessionID,
+		token.TypeChallenge,
+		server.config.ChallengeTokenDuration,
+	)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := loginChallengeResponse{
+		TwoFactorRequired:       true,
+		ChallengeToken:          challengeToken,
+		ChallengeTokenExpiresAt: challengePayload.ExpiredAt,
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type loginUserTwoFactorRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	// Code is a TOTP code, or RecoveryCode one of the recovery codes for users who lost their authenticator
+	Code         string `json:"code" binding:"required_without=RecoveryCode,omitempty,len=6,numeric"`
+	RecoveryCode string `json:"recovery_code" binding:"required_without=Code"`
+}
+
+// loginUserTwoFactor is the second step of the login of users with two-factor authentication
+func (server *Server) loginUserTwoFactor(ctx *gin.Context) {
+	var req loginUserTwoFactorRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	challengePayload, err := server.tokenMaker.VerifyToken(req.ChallengeToken)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	if challengePayload.Type != token.TypeChallenge {
+		err := errors.New("not a challenge token")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	user, err := server.store.GetUser(ctx, challengePayload.Username)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	secret, err := server.store.GetTotpSecret(ctx, user.Username)
+	if err != nil && err != sql.ErrNoRows {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// Two-factor authentication was disabled since the challenge was sent, so the password must be checked again
+	if err == sql.ErrNoRows || !secret.EnabledAt.Valid {
+		err := errors.New("two-factor authentication isn't enabled")
+		ctx.JSON(http.StatusUnauthorized, errorCodeResponse(errCodeTwoFactorNotEnabled, err))
+		return
+	}
+
+	if !checkSecondFactor(ctx, server.store, server.totpCipher, secret, req.Code, req.RecoveryCode) {
+		return
+	}
+
+	server.startSession(ctx, user)
+}
+
+type setupTwoFactorResponse struct {
+	// Secret is typed in authenticator apps, or URL read from a QR code
+	Secret string `json:"secret"`
+	URL    string `json:"url"`
+}
+
+// setupTwoFactor starts the enrolment of an authenticator, which must be verified with a first code
+// before two-factor authentication is enabled. Starting again replaces the secret
+func (server *Server) setupTwoFactor(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	encryptedSecret, err := server.totpCipher.Encrypt(secret, authPayload.Username)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	_, err = server.store.UpsertTotpSecret(ctx, db.UpsertTotpSecretParams{
+		Username:        authPayload.Username,
+		EncryptedSecret: encryptedSecret,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err := errors.New("two-factor authentication is already enabled")
+			ctx.JSON(http.StatusConflict, errorCodeResponse(errCodeTwoFactorAlreadyEnabled, err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := setupTwoFactorResponse{
+		Secret: totp.EncodeSecret(secret),
+		URL:    totp.URL(totpIssuer, authPayload.Username, secret),
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type verifyTwoFactorRequest struct {
+	Code string `json:"code" binding:"required,len=6,numeric"`
+}
+
+type recoveryCodesResponse struct {
+	// RecoveryCodes are only shown once, each of them can be used once instead of a TOTP code
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// verifyTwoFactor enables two-factor authentication once the user shows their authenticator
+// gives the right codes, and sends their first recovery codes
+func (server *Server) verifyTwoFactor(ctx *gin.Context) {
+	var req verifyTwoFactorRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	secret, err := server.store.GetTotpSecret(ctx, authPayload.Username)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if secret.EnabledAt.Valid {
+		err := errors.New("two-factor authentication is already enabled")
+		ctx.JSON(http.StatusConflict, errorCodeResponse(errCodeTwoFactorAlreadyEnabled, err))
+		return
+	}
+
+	if !checkSecondFactor(ctx, server.store, server.totpCipher, secret, req.Code, "") {
+		return
+	}
+
+	codes, hashedCodes, err := generateRecoveryCodes()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	_, err = server.store.EnableTwoFactorTx(ctx, db.RecoveryCodesTxParams{
+		Username:    authPayload.Username,
+		HashedCodes: hashedCodes,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err := errors.New("two-factor authentication is already enabled")
+			ctx.JSON(http.StatusConflict, errorCodeResponse(errCodeTwoFactorAlreadyEnabled, err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, recoveryCodesResponse{RecoveryCodes: codes})
+}
+
+type twoFactorCodeRequest struct {
+	Code         string `json:"code" binding:"required_without=RecoveryCode,omitempty,len=6,numeric"`
+	RecoveryCode string `json:"recovery_code" binding:"required_without=Code"`
+}
+
+// disableTwoFactor forgets the authenticator and the recovery codes of the user
+func (server *Server) disableTwoFactor(ctx *gin.Context) {
+	var req twoFactorCodeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	secret, enabled := server.enabledTotpSecret(ctx)
+	if !enabled {
+		return
+	}
+
+	if !checkSecondFactor(ctx, server.store, server.totpCipher, secret, req.Code, req.RecoveryCode) {
+		return
+	}
+
+	err := server.store.DisableTwoFactorTx(ctx, secret.Username)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// regenerateRecoveryCodes gives the user new recovery codes, the previous ones can't be used anymore
+func (server *Server) regenerateRecoveryCodes(ctx *gin.Context) {
+	var req twoFactorCodeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	secret, enabled := server.enabledTotpSecret(ctx)
+	if !enabled {
+		return
+	}
+
+	if !checkSecondFactor(ctx, server.store, server.totpCipher, secret, req.Code, req.RecoveryCode) {
+		return
+	}
+
+	codes, hashedCodes, err := generateRecoveryCodes()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	err = server.store.ReplaceRecoveryCodesTx(ctx, db.RecoveryCodesTxParams{
+		Username:    secret.Username,
+		HashedCodes: hashedCodes,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, recoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// enabledTotpSecret gets the secret of the authorized user, writing the error response
+// if two-factor authentication isn't enabled
+func (server *Server) enabledTotpSecret(ctx *gin.Context) (db.TotpSecret, bool) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	secret, err := server.store.GetTotpSecret(ctx, authPayload.Username)
+	if err != nil && err != sql.ErrNoRows {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return secret, false
+	}
+
+	if err == sql.ErrNoRows || !secret.EnabledAt.Valid {
+		err := errors.New("two-factor authentication isn't enabled")
+		ctx.JSON(http.StatusConflict, errorCodeResponse(errCodeTwoFactorNotEnabled, err))
+		return secret, false
+	}
+
+	return secret, true
+}
+
+// generateRecoveryCodes generates new recovery codes along with their hashes, which are stored
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes, err := totp.GenerateRecoveryCodes()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hashedCodes := make([]string, len(codes))
+	for i, code := range codes {
+		hashedCodes[i] = totp.HashRecoveryCode(code)
+	}
+	return codes, hashedCodes, nil
+}
+
+// checkSecondFactor checks a TOTP code of the user, or one of their recovery codes when it's given
+// instead, writing the error response if it's refused. Each code is only accepted once, and nothing
+// is checked for a while once too many codes were refused in a row
+func checkSecondFactor(
+	ctx *gin.Context,
+	store db.Store,
+	totpCipher *totp.Cipher,
+	secret db.TotpSecret,
+	code string,
+	recoveryCode string,
+) bool {
+	if secret.FailedAttempts >= maxTwoFactorFailures &&
+		secret.LastFailedAt.Valid && time.Since(secret.LastFailedAt.Time) < twoFactorLockout {
+		err := errors.New("too many invalid codes, try again later")
+		ctx.JSON(http.StatusTooManyRequests, errorCodeResponse(errCodeTooManyTwoFactorAttempts, err))
+		return false
+	}
+
+	var accepted bool
+	if len(recoveryCode) > 0 {
+		rows, err := store.UseRecoveryCode(ctx, db.UseRecoveryCodeParams{
+			Username:   secret.Username,
+			HashedCode: totp.HashRecoveryCode(recoveryCode),
+		})
+		if err == nil && rows > 0 {
+			accepted = true
+			err = store.ResetTotpFailures(ctx, secret.Username)
+		}
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return false
+		}
+	} else {
+		plainSecret, err := totpCipher.Decrypt(secret.EncryptedSecret, secret.Username)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return false
+		}
+
+		// A code of a step which was already used is refused, so codes seen by someone else can't be replayed
+		step, ok := totp.Validate(plainSecret, code, time.Now())
+		if ok {
+			rows, err := store.UseTotpStep(ctx, db.UseTotpStepParams{
+				Username: secret.Username,
+				Step:     step,
+			})
+			if err != nil {
+				ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+				return false
+			}
+			accepted = rows > 0
+		}
+	}
+
+	if !accepted {
+		_, err := store.RecordTotpFailure(ctx, db.RecordTotpFailureParams{
+			Username:    secret.Username,
+			WindowStart: time.Now().Add(-twoFactorLockout),
+		})
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return false
+		}
+
+		err = errors.New("invalid two-factor code")
+		ctx.JSON(http.StatusUnauthorized, errorCodeResponse(errCodeInvalidTwoFactorCode, err))
+		return false
+	}
+
+	return true
+}
+
+// twoFactorMiddleware creates a gin middleware which asks users with two-factor authentication for
+// a TOTP code in the X-TOTP-Code header. It must run after the authMiddleware and the idempotencyMiddleware,
+// since a code can only be used once and retries of a request which went through must still be replayed
+func twoFactorMiddleware(store db.Store, totpCipher *totp.Cipher) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		secret, err := store.GetTotpSecret(ctx, authPayload.Username)
+		if err != nil && err != sql.ErrNoRows {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		// Users without two-factor authentication are let through
+		if err == sql.ErrNoRows || !secret.EnabledAt.Valid {
+			ctx.Next()
+			return
+		}
+
+		code := ctx.GetHeader(twoFactorCodeHeader)
+		if len(code) == 0 {
+			err := errors.New("a TOTP code is required in the " + twoFactorCodeHeader + " header")
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorCodeResponse(errCodeTwoFactorRequired, err))
+			return
+		}
+
+		if !checkSecondFactor(ctx, store, totpCipher, secret, code, "") {
+			ctx.Abort()
+			return
+		}
+
+		ctx.Next()
+	}
+}
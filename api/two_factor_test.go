@@ -0,0 +1,866 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	mockdb "simplebank/db/mock"
+	db "simplebank/db/sqlc"
+	"simplebank/token"
+	"simplebank/totp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoginUserChallengeAPI(t *testing.T) {
+	user, password := randomUser(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := newTestServer(t, store)
+	secret, _ := randomTotpSecret(t, server.totpCipher, user.Username)
+
+	store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+	store.EXPECT().GetTotpSecret(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(secret, nil)
+	// No session is created until a TOTP code is sent
+	store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(0)
+
+	data, err := json.Marshal(gin.H{
+		"username": user.Username,
+		"password": password,
+	})
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, "/users/login", bytes.NewReader(data))
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.NotContains(t, recorder.Body.String(), "access_token")
+
+	var got loginChallengeResponse
+	err = json.Unmarshal(recorder.Body.Bytes(), &got)
+	require.NoError(t, err)
+	require.True(t, got.TwoFactorRequired)
+
+	// Challenge tokens don't belong to any session, nor give any role
+	payload, err := server.tokenMaker.VerifyToken(got.ChallengeToken)
+	require.NoError(t, err)
+	require.Equal(t, user.Username, payload.Username)
+	require.Equal(t, challengeSessionID, payload.SessionID)
+	require.Empty(t, payload.Role)
+}
+
+func TestLoginUserTwoFactorAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		body          func(t *testing.T, tokenMaker token.Maker, plainSecret []byte) gin.H
+		buildStubs    func(store *mockdb.MockStore, secret db.TotpSecret)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: func(t *testing.T, tokenMaker token.Maker, plainSecret []byte) gin.H {
+				return gin.H{
+					"challenge_token": challengeToken(t, tokenMaker, user.Username),
+					"code":            totp.Code(plainSecret, totp.Step(time.Now())),
+				}
+			},
+			buildStubs: func(store *mockdb.MockStore, secret db.TotpSecret) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().GetTotpSecret(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(secret, nil)
+
+				store.EXPECT().
+					UseTotpStep(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.UseTotpStepParams) (int64, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.InDelta(t, totp.Step(time.Now()), arg.Step, 1)
+						return 1, nil
+					})
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateSessionParams) (db.Session, error) {
+						return db.Session{ID: arg.ID, Username: arg.Username}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got loginUserResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.NotEmpty(t, got.AccessToken)
+				require.NotEmpty(t, got.RefreshToken)
+				require.Equal(t, user.Username, got.User.Username)
+			},
+		},
+		{
+			name: "RecoveryCode",
+			body: func(t *testing.T, tokenMaker token.Maker, plainSecret []byte) gin.H {
+				return gin.H{
+					"challenge_token": challengeToken(t, tokenMaker, user.Username),
+					"recovery_code":   "ABCD-EFGH",
+				}
+			},
+			buildStubs: func(store *mockdb.MockStore, secret db.TotpSecret) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().GetTotpSecret(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(secret, nil)
+
+				arg := db.UseRecoveryCodeParams{
+					Username:   user.Username,
+					HashedCode: totp.HashRecoveryCode("abcdefgh"),
+				}
+				store.EXPECT().UseRecoveryCode(gomock.Any(), gomock.Eq(arg)).Times(1).Return(int64(1), nil)
+				store.EXPECT().ResetTotpFailures(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(nil)
+				store.EXPECT().UseTotpStep(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(1)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "UsedRecoveryCode",
+			body: func(t *testing.T, tokenMaker token.Maker, plainSecret []byte) gin.H {
+				return gin.H{
+					"challenge_token": challengeToken(t, tokenMaker, user.Username),
+					"recovery_code":   "abcd-efgh",
+				}
+			},
+			buildStubs: func(store *mockdb.MockStore, secret db.TotpSecret) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().GetTotpSecret(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(secret, nil)
+				store.EXPECT().UseRecoveryCode(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+				store.EXPECT().RecordTotpFailure(gomock.Any(), gomock.Any()).Times(1)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				requireBodyErrorCode(t, recorder.Body, errCodeInvalidTwoFactorCode)
+			},
+		},
+		{
+			name: "InvalidCode",
+			body: func(t *testing.T, tokenMaker token.Maker, plainSecret []byte) gin.H {
+				return gin.H{
+					"challenge_token": challengeToken(t, tokenMaker, user.Username),
+					"code":            totp.Code(plainSecret, totp.Step(time.Now())-10),
+				}
+			},
+			buildStubs: func(store *mockdb.MockStore, secret db.TotpSecret) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().GetTotpSecret(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(secret, nil)
+				store.EXPECT().UseTotpStep(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().
+					RecordTotpFailure(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.RecordTotpFailureParams) (db.TotpSecret, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.WithinDuration(t, time.Now().Add(-twoFactorLockout), arg.WindowStart, time.Second)
+						return secret, nil
+					})
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				requireBodyErrorCode(t, recorder.Body, errCodeInvalidTwoFactorCode)
+			},
+		},
+		{
+			name: "ReplayedCode",
+			body: func(t *testing.T, tokenMaker token.Maker, plainSecret []byte) gin.H {
+				return gin.H{
+					"challenge_token": challengeToken(t, tokenMaker, user.Username),
+					"code":            totp.Code(plainSecret, totp.Step(time.Now())),
+				}
+			},
+			buildStubs: func(store *mockdb.MockStore, secret db.TotpSecret) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().GetTotpSecret(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(secret, nil)
+				// The step was already used by an earlier code
+				store.EXPECT().UseTotpStep(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+				store.EXPECT().RecordTotpFailure(gomock.Any(), gomock.Any()).Times(1)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				requireBodyErrorCode(t, recorder.Body, errCodeInvalidTwoFactorCode)
+			},
+		},
+		{
+			name: "TooManyAttempts",
+			body: func(t *testing.T, tokenMaker token.Maker, plainSecret []byte) gin.H {
+				return gin.H{
+					"challenge_token": challengeToken(t, tokenMaker, user.Username),
+					"code":            totp.Code(plainSecret, totp.Step(time.Now())),
+				}
+			},
+			buildStubs: func(store *mockdb.MockStore, secret db.TotpSecret) {
+				secret.FailedAttempts = maxTwoFactorFailures
+				secret.LastFailedAt = sql.NullTime{Time: time.Now().Add(-time.Minute), Valid: true}
+
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().GetTotpSecret(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(secret, nil)
+				// Even the right code is refused until the lockout is over
+				store.EXPECT().UseTotpStep(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+				requireBodyErrorCode(t, recorder.Body, errCodeTooManyTwoFactorAttempts)
+			},
+		},
+		{
+			name: "LockoutOver",
+			body: func(t *testing.T, tokenMaker token.Maker, plainSecret []byte) gin.H {
+				return gin.H{
+					"challenge_token": challengeToken(t, tokenMaker, user.Username),
+					"code":            totp.Code(plainSecret, totp.Step(time.Now())),
+				}
+			},
+			buildStubs: func(store *mockdb.MockStore, secret db.TotpSecret) {
+				secret.FailedAttempts = maxTwoFactorFailures
+				secret.LastFailedAt = sql.NullTime{Time: time.Now().Add(-twoFactorLockout - time.Minute), Valid: true}
+
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().GetTotpSecret(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(secret, nil)
+				store.EXPECT().UseTotpStep(gomock.Any(), gomock.Any()).Times(1).Return(int64(1), nil)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(1)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "NotChallengeToken",
+			body: func(t *testing.T, tokenMaker token.Maker, plainSecret []byte) gin.H {
+				// Only challenge tokens can be exchanged for a session along with a code
+				accessToken, _, err := tokenMaker.CreateToken(user.Username, user.Role, uuid.New(), token.TypeAccess, time.Minute)
+				require.NoError(t, err)
+				return gin.H{
+					"challenge_token": accessToken,
+					"code":            totp.Code(plainSecret, totp.Step(time.Now())),
+				}
+			},
+			buildStubs: func(store *mockdb.MockStore, secret db.TotpSecret) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "ExpiredChallengeToken",
+			body: func(t *testing.T, tokenMaker token.Maker, plainSecret []byte) gin.H {
+				expiredToken, _, err := tokenMaker.CreateToken(user.Username, "", challengeSessionID, token.TypeChallenge, -time.Minute)
+				require.NoError(t, err)
+				return gin.H{
+					"challenge_token": expiredToken,
+					"code":            totp.Code(plainSecret, totp.Step(time.Now())),
+				}
+			},
+			buildStubs: func(store *mockdb.MockStore, secret db.TotpSecret) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "TwoFactorDisabled",
+			body: func(t *testing.T, tokenMaker token.Maker, plainSecret []byte) gin.H {
+				return gin.H{
+					"challenge_token": challengeToken(t, tokenMaker, user.Username),
+					"code":            totp.Code(plainSecret, totp.Step(time.Now())),
+				}
+			},
+			buildStubs: func(store *mockdb.MockStore, secret db.TotpSecret) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().GetTotpSecret(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(db.TotpSecret{}, sql.ErrNoRows)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				requireBodyErrorCode(t, recorder.Body, errCodeTwoFactorNotEnabled)
+			},
+		},
+		{
+			name: "MissingCode",
+			body: func(t *testing.T, tokenMaker token.Maker, plainSecret []byte) gin.H {
+				return gin.H{
+					"challenge_token": challengeToken(t, tokenMaker, user.Username),
+				}
+			},
+			buildStubs: func(store *mockdb.MockStore, secret db.TotpSecret) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			server := newTestServer(t, store)
+			secret, plainSecret := randomTotpSecret(t, server.totpCipher, user.Username)
+			tc.buildStubs(store, secret)
+
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body(t, server.tokenMaker, plainSecret))
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/users/login/2fa", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestSetupTwoFactorAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore, totpCipher *totp.Cipher)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore, totpCipher *totp.Cipher) {
+				store.EXPECT().
+					UpsertTotpSecret(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.UpsertTotpSecretParams) (db.TotpSecret, error) {
+						require.Equal(t, user.Username, arg.Username)
+
+						// The secret is only stored encrypted
+						plainSecret, err := totpCipher.Decrypt(arg.EncryptedSecret, user.Username)
+						require.NoError(t, err)
+						require.Len(t, plainSecret, totp.SecretSize)
+						return db.TotpSecret{Username: arg.Username, EncryptedSecret: arg.EncryptedSecret}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got setupTwoFactorResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.NotEmpty(t, got.Secret)
+				require.True(t, strings.HasPrefix(got.URL, "otpauth://totp/"))
+				require.Contains(t, got.URL, "secret="+got.Secret)
+			},
+		},
+		{
+			name: "AlreadyEnabled",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore, totpCipher *totp.Cipher) {
+				store.EXPECT().
+					UpsertTotpSecret(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TotpSecret{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				requireBodyErrorCode(t, recorder.Body, errCodeTwoFactorAlreadyEnabled)
+			},
+		},
+		{
+			name: "NoAuthorization",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore, totpCipher *totp.Cipher) {
+				store.EXPECT().UpsertTotpSecret(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			server := newTestServer(t, store)
+			tc.buildStubs(store, server.totpCipher)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodPost, "/users/2fa/setup", nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestVerifyTwoFactorAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		code          func(plainSecret []byte) string
+		buildStubs    func(store *mockdb.MockStore, secret db.TotpSecret)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			code: func(plainSecret []byte) string {
+				return totp.Code(plainSecret, totp.Step(time.Now()))
+			},
+			buildStubs: func(store *mockdb.MockStore, secret db.TotpSecret) {
+				secret.EnabledAt = sql.NullTime{}
+				store.EXPECT().GetTotpSecret(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(secret, nil)
+				store.EXPECT().UseTotpStep(gomock.Any(), gomock.Any()).Times(1).Return(int64(1), nil)
+				store.EXPECT().
+					EnableTwoFactorTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.RecoveryCodesTxParams) (db.TotpSecret, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.Len(t, arg.HashedCodes, totp.RecoveryCodeCount)
+						return secret, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got recoveryCodesResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Len(t, got.RecoveryCodes, totp.RecoveryCodeCount)
+			},
+		},
+		{
+			name: "InvalidCode",
+			code: func(plainSecret []byte) string {
+				return totp.Code(plainSecret, totp.Step(time.Now())+10)
+			},
+			buildStubs: func(store *mockdb.MockStore, secret db.TotpSecret) {
+				secret.EnabledAt = sql.NullTime{}
+				store.EXPECT().GetTotpSecret(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(secret, nil)
+				store.EXPECT().RecordTotpFailure(gomock.Any(), gomock.Any()).Times(1)
+				store.EXPECT().EnableTwoFactorTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				requireBodyErrorCode(t, recorder.Body, errCodeInvalidTwoFactorCode)
+			},
+		},
+		{
+			name: "AlreadyEnabled",
+			code: func(plainSecret []byte) string {
+				return totp.Code(plainSecret, totp.Step(time.Now()))
+			},
+			buildStubs: func(store *mockdb.MockStore, secret db.TotpSecret) {
+				store.EXPECT().GetTotpSecret(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(secret, nil)
+				store.EXPECT().UseTotpStep(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().EnableTwoFactorTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				requireBodyErrorCode(t, recorder.Body, errCodeTwoFactorAlreadyEnabled)
+			},
+		},
+		{
+			name: "NotSetUp",
+			code: func(plainSecret []byte) string {
+				return totp.Code(plainSecret, totp.Step(time.Now()))
+			},
+			buildStubs: func(store *mockdb.MockStore, secret db.TotpSecret) {
+				store.EXPECT().GetTotpSecret(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(db.TotpSecret{}, sql.ErrNoRows)
+				store.EXPECT().EnableTwoFactorTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidCodeFormat",
+			code: func(plainSecret []byte) string {
+				return "abcdef"
+			},
+			buildStubs: func(store *mockdb.MockStore, secret db.TotpSecret) {
+				store.EXPECT().GetTotpSecret(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			server := newTestServer(t, store)
+			secret, plainSecret := randomTotpSecret(t, server.totpCipher, user.Username)
+			tc.buildStubs(store, secret)
+
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{"code": tc.code(plainSecret)})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/users/2fa/verify", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestDisableTwoFactorAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		body          func(plainSecret []byte) gin.H
+		buildStubs    func(store *mockdb.MockStore, secret db.TotpSecret)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: func(plainSecret []byte) gin.H {
+				return gin.H{"code": totp.Code(plainSecret, totp.Step(time.Now()))}
+			},
+			buildStubs: func(store *mockdb.MockStore, secret db.TotpSecret) {
+				store.EXPECT().GetTotpSecret(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(secret, nil)
+				store.EXPECT().UseTotpStep(gomock.Any(), gomock.Any()).Times(1).Return(int64(1), nil)
+				store.EXPECT().DisableTwoFactorTx(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+		{
+			name: "RecoveryCode",
+			body: func(plainSecret []byte) gin.H {
+				return gin.H{"recovery_code": "abcd-efgh"}
+			},
+			buildStubs: func(store *mockdb.MockStore, secret db.TotpSecret) {
+				store.EXPECT().GetTotpSecret(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(secret, nil)
+				store.EXPECT().UseRecoveryCode(gomock.Any(), gomock.Any()).Times(1).Return(int64(1), nil)
+				store.EXPECT().ResetTotpFailures(gomock.Any(), gomock.Any()).Times(1).Return(nil)
+				store.EXPECT().DisableTwoFactorTx(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidCode",
+			body: func(plainSecret []byte) gin.H {
+				return gin.H{"code": totp.Code(plainSecret, totp.Step(time.Now())-10)}
+			},
+			buildStubs: func(store *mockdb.MockStore, secret db.TotpSecret) {
+				store.EXPECT().GetTotpSecret(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(secret, nil)
+				store.EXPECT().RecordTotpFailure(gomock.Any(), gomock.Any()).Times(1)
+				store.EXPECT().DisableTwoFactorTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "NotEnabled",
+			body: func(plainSecret []byte) gin.H {
+				return gin.H{"code": totp.Code(plainSecret, totp.Step(time.Now()))}
+			},
+			buildStubs: func(store *mockdb.MockStore, secret db.TotpSecret) {
+				// The enrolment was started but never verified
+				secret.EnabledAt = sql.NullTime{}
+				store.EXPECT().GetTotpSecret(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(secret, nil)
+				store.EXPECT().DisableTwoFactorTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				requireBodyErrorCode(t, recorder.Body, errCodeTwoFactorNotEnabled)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			server := newTestServer(t, store)
+			secret, plainSecret := randomTotpSecret(t, server.totpCipher, user.Username)
+			tc.buildStubs(store, secret)
+
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body(plainSecret))
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/users/2fa/disable", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestRegenerateRecoveryCodesAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := newTestServer(t, store)
+	secret, plainSecret := randomTotpSecret(t, server.totpCipher, user.Username)
+
+	var hashedCodes []string
+	store.EXPECT().GetTotpSecret(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(secret, nil)
+	store.EXPECT().UseTotpStep(gomock.Any(), gomock.Any()).Times(1).Return(int64(1), nil)
+	store.EXPECT().
+		ReplaceRecoveryCodesTx(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ interface{}, arg db.RecoveryCodesTxParams) error {
+			require.Equal(t, user.Username, arg.Username)
+			hashedCodes = arg.HashedCodes
+			return nil
+		})
+
+	data, err := json.Marshal(gin.H{"code": totp.Code(plainSecret, totp.Step(time.Now()))})
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, "/users/2fa/recovery_codes", bytes.NewReader(data))
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	// Only the hashes of the codes sent to the user are stored
+	var got recoveryCodesResponse
+	err = json.Unmarshal(recorder.Body.Bytes(), &got)
+	require.NoError(t, err)
+	require.Len(t, got.RecoveryCodes, totp.RecoveryCodeCount)
+	for i, code := range got.RecoveryCodes {
+		require.Equal(t, totp.HashRecoveryCode(code), hashedCodes[i])
+	}
+}
+
+func TestTwoFactorMiddleware(t *testing.T) {
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		setupCode     func(request *http.Request, plainSecret []byte)
+		buildStubs    func(store *mockdb.MockStore, secret db.TotpSecret)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			setupCode: func(request *http.Request, plainSecret []byte) {
+				request.Header.Set(twoFactorCodeHeader, totp.Code(plainSecret, totp.Step(time.Now())))
+			},
+			buildStubs: func(store *mockdb.MockStore, secret db.TotpSecret) {
+				store.EXPECT().GetTotpSecret(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(secret, nil)
+				store.EXPECT().UseTotpStep(gomock.Any(), gomock.Any()).Times(1).Return(int64(1), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "TwoFactorNotEnabled",
+			setupCode: func(request *http.Request, plainSecret []byte) {
+			},
+			buildStubs: func(store *mockdb.MockStore, secret db.TotpSecret) {
+				store.EXPECT().GetTotpSecret(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(db.TotpSecret{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "MissingCode",
+			setupCode: func(request *http.Request, plainSecret []byte) {
+			},
+			buildStubs: func(store *mockdb.MockStore, secret db.TotpSecret) {
+				store.EXPECT().GetTotpSecret(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(secret, nil)
+				store.EXPECT().RecordTotpFailure(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				requireBodyErrorCode(t, recorder.Body, errCodeTwoFactorRequired)
+			},
+		},
+		{
+			name: "InvalidCode",
+			setupCode: func(request *http.Request, plainSecret []byte) {
+				request.Header.Set(twoFactorCodeHeader, "000000")
+			},
+			buildStubs: func(store *mockdb.MockStore, secret db.TotpSecret) {
+				store.EXPECT().GetTotpSecret(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(secret, nil)
+				store.EXPECT().RecordTotpFailure(gomock.Any(), gomock.Any()).Times(1)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				requireBodyErrorCode(t, recorder.Body, errCodeInvalidTwoFactorCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			server := newTestServer(t, store)
+			secret, plainSecret := randomTotpSecret(t, server.totpCipher, user.Username)
+			// The code is drawn again until it can't be mistaken for the one the invalid case sends
+			for totp.Code(plainSecret, totp.Step(time.Now())) == "000000" {
+				secret, plainSecret = randomTotpSecret(t, server.totpCipher, user.Username)
+			}
+			tc.buildStubs(store, secret)
+
+			sensitivePath := "/sensitive"
+			server.router.POST(
+				sensitivePath,
+				authMiddleware(server.tokenMaker, revokedSessions{}),
+				twoFactorMiddleware(store, server.totpCipher),
+				func(ctx *gin.Context) {
+					ctx.JSON(http.StatusOK, gin.H{})
+				},
+			)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodPost, sensitivePath, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			tc.setupCode(request, plainSecret)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestTwoFactorRoutes(t *testing.T) {
+	user, _ := randomUser(t)
+
+	// Every request which moves money out of an account, or changes who controls it, needs a code
+	routes := []struct {
+		path string
+		role string
+	}{
+		{path: "/transfers", role: db.UserRoleCustomer},
+		{path: "/transfers/batch", role: db.UserRoleCustomer},
+		{path: "/transfers/1/reverse", role: db.UserRoleCustomer},
+		{path: "/withdraws", role: db.UserRoleCustomer},
+		{path: "/holds", role: db.UserRoleCustomer},
+		{path: "/holds/1/capture", role: db.UserRoleCustomer},
+		{path: "/scheduled_transfers", role: db.UserRoleCustomer},
+		{path: "/accounts/1/close", role: db.UserRoleCustomer},
+		{path: "/accounts/1/members", role: db.UserRoleCustomer},
+		{path: "/admin/transfers/1/reverse", role: db.UserRoleBanker},
+		{path: "/admin/deposits", role: db.UserRoleBanker},
+	}
+
+	for i := range routes {
+		route := routes[i]
+
+		t.Run(route.path, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			server := newTestServer(t, store)
+			secret, _ := randomTotpSecret(t, server.totpCipher, user.Username)
+
+			// The request is refused before reaching the handler
+			store.EXPECT().GetTotpSecret(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(secret, nil)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodPost, route.path, nil)
+			require.NoError(t, err)
+
+			addRoleAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, route.role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			requireBodyErrorCode(t, recorder.Body, errCodeTwoFactorRequired)
+		})
+	}
+}
+
+// randomTotpSecret returns the enabled secret of the user as it's stored, along with the plain secret
+func randomTotpSecret(t *testing.T, totpCipher *totp.Cipher, username string) (db.TotpSecret, []byte) {
+	plainSecret, err := totp.GenerateSecret()
+	require.NoError(t, err)
+
+	encryptedSecret, err := totpCipher.Encrypt(plainSecret, username)
+	require.NoError(t, err)
+
+	secret := db.TotpSecret{
+		Username:        username,
+		EncryptedSecret: encryptedSecret,
+		EnabledAt:       sql.NullTime{Time: time.Now(), Valid: true},
+		CreatedAt:       time.Now(),
+	}
+	return secret, plainSecret
+}
+
+func challengeToken(t *testing.T, tokenMaker token.Maker, username string) string {
+	challengeToken, _, err := tokenMaker.CreateToken(username, "", challengeSessionID, token.TypeChallenge, time.Minute)
+	require.NoError(t, err)
+	return challengeToken
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	db "simplebank/db/sqlc"
+	"simplebank/token"
 	"simplebank/util"
 
 	"github.com/gin-gonic/gin"
@@ -24,6 +25,8 @@ type userResponse struct {
 	Username          string    `json:"username"`
 	FullName          string    `json:"full_name"`
 	Email             string    `json:"email"`
+	Tier              string    `json:"tier"`
+	Role              string    `json:"role"`
 	PasswordChangedAt time.Time `json:"password_changed_at"`
 	CreatedAt         time.Time `json:"created_at"`
 }
@@ -33,6 +36,8 @@ func newUserResponse(user db.User) userResponse {
 		Username:          user.Username,
 		FullName:          user.FullName,
 		Email:             user.Email,
+		Tier:              user.Tier,
+		Role:              user.Role,
 		PasswordChangedAt: user.PasswordChangedAt,
 		CreatedAt:         user.CreatedAt,
 	}
@@ -113,8 +118,35 @@ func (server *Server) loginUser(ctx *gin.Context) {
 		return
 	}
 
+	secret, err := server.store.GetTotpSecret(ctx, user.Username)
+	if err != nil && err != sql.ErrNoRows {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// Users with two-factor authentication only get tokens once they send a TOTP code along with the challenge
+	if err == nil && secret.EnabledAt.Valid {
+		server.sendLoginChallenge(ctx, user)
+		return
+	}
+
+	server.startSession(ctx, user)
+}
+
+// startSession signs the user in on a new device, creating the session its tokens belong to
+func (server *Server) startSession(ctx *gin.Context, user db.User) {
+	// Both tokens belong to the session, so that access tokens stop working once it's blocked
+	sessionID, err := uuid.NewRandom()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
 	accessToken, accessPayload, err := server.tokenMaker.CreateToken(
 		user.Username,
+		user.Role,
+		sessionID,
+		token.TypeAccess,
 		server.config.AccessTokenDuration,
 	)
 	if err != nil {
@@ -124,6 +156,9 @@ func (server *Server) loginUser(ctx *gin.Context) {
 
 	refreshToken, refreshPayload, err := server.tokenMaker.CreateToken(
 		user.Username,
+		user.Role,
+		sessionID,
+		token.TypeRefresh,
 		server.config.RefreshTokenDuration,
 	)
 	if err != nil {
@@ -132,13 +167,15 @@ func (server *Server) loginUser(ctx *gin.Context) {
 	}
 
 	session, err := server.store.CreateSession(ctx, db.CreateSessionParams{
-		ID:           refreshPayload.ID,
+		ID:           sessionID,
 		Username:     user.Username,
 		RefreshToken: refreshToken,
 		UserAgent:    ctx.Request.UserAgent(),
 		ClientIp:     ctx.ClientIP(),
 		IsBlocked:    false,
 		ExpiresAt:    refreshPayload.ExpiredAt,
+		// Each login starts a new family, which the refresh token is rotated within
+		FamilyID: sessionID,
 	})
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
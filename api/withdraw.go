@@ -0,0 +1,152 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	db "simplebank/db/sqlc"
+	"simplebank/token"
+
+	"github.com/gin-gonic/gin"
+)
+
+type withdrawRequest struct {
+	AccountID int64 `json:"account_id" binding:"required,min=1"`
+	Amount    int64 `json:"amount" binding:"required,gt=0"`
+}
+
+func (server *Server) createWithdraw(ctx *gin.Context) {
+	// Reading the request body
+	var req withdrawRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// Getting the account by the provided ID
+	account, err := server.store.GetAccount(ctx, req.AccountID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// Only owners, and spenders within their limit, can take money out of it
+	if !server.authorizeSpending(ctx, account, req.Amount) {
+		return
+	}
+
+	// Getting the user which made the request
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	// Creating data to be set for the withdraw
+	arg := db.WithdrawTxParams{
+		AccountID: req.AccountID,
+		Amount:    req.Amount,
+		User:      authPayload.Username,
+	}
+
+	// Calling the withdraw transaction function
+	result, err := server.store.WithdrawTx(ctx, arg)
+	if err != nil {
+		if errors.Is(err, db.ErrInsufficientFunds) {
+			ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(errCodeInsufficientFunds, err))
+			return
+		}
+		if code := accountStatusErrorCode(err); code != "" {
+			ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(code, err))
+			return
+		}
+		var limitErr *db.LimitExceededError
+		if errors.As(err, &limitErr) {
+			ctx.JSON(http.StatusUnprocessableEntity, limitExceededResponse(limitErr))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// Returning data to the request if no error occurs
+	ctx.JSON(http.StatusOK, result)
+}
+
+type getWithdrawRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) getWithdraw(ctx *gin.Context) {
+	var req getWithdrawRequest
+	// Here, we'll use the URL params
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	withdraw, err := server.store.GetWithdraw(ctx, req.ID)
+	if err != nil {
+		// If no item was found
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// Getting the user which made the request
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	// Checking if user made the withdraw
+	if withdraw.User != authPayload.Username {
+		err := errors.New("withdraw wasn't made by the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, withdraw)
+}
+
+type listWithdrawRequest struct {
+	AccountID int64 `form:"account_id" binding:"required,min=1"`
+	PageID    int32 `form:"page_id" binding:"required,min=1"`
+	PageSize  int32 `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+func (server *Server) listWithdraws(ctx *gin.Context) {
+	var req listWithdrawRequest
+	// Here, we'll use the query params
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, valid := server.validAccount(ctx, req.AccountID)
+	if !valid {
+		return
+	}
+
+	// Checking if the account is shared with the user
+	if _, ok := server.authorizeAccount(ctx, account, db.AccountRoleViewer); !ok {
+		return
+	}
+
+	// Here, we'll get the query params
+	arg := db.ListWithdrawsParams{
+		AccountID: req.AccountID,
+		Limit:     req.PageSize,
+		// Calculating the offset from page number and size
+		Offset: (req.PageID - 1) * req.PageSize,
+	}
+
+	withdraws, err := server.store.ListWithdraws(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, withdraws)
+}
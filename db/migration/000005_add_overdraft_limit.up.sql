@@ -0,0 +1,7 @@
+ALTER TABLE "accounts" ADD COLUMN "overdraft_limit" bigint NOT NULL DEFAULT 0;
+
+ALTER TABLE "accounts" ADD CONSTRAINT "accounts_overdraft_limit_check" CHECK ("overdraft_limit" >= 0);
+
+ALTER TABLE "accounts" ADD CONSTRAINT "accounts_balance_check" CHECK ("balance" >= -"overdraft_limit");
+
+COMMENT ON COLUMN "accounts"."overdraft_limit" IS 'how far below zero the balance is allowed to go';
@@ -0,0 +1,18 @@
+CREATE TABLE "idempotency_keys" (
+  "username" varchar NOT NULL,
+  "key" varchar NOT NULL,
+  "request_hash" varchar NOT NULL,
+  "response_status" int NOT NULL DEFAULT 0,
+  "response_body" bytea NOT NULL DEFAULT '',
+  "expires_at" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("username", "key")
+);
+
+CREATE INDEX ON "idempotency_keys" ("expires_at");
+
+COMMENT ON COLUMN "idempotency_keys"."request_hash" IS 'hash of the method, path and body of the original request';
+
+COMMENT ON COLUMN "idempotency_keys"."response_status" IS 'zero while the original request is still being processed';
+
+ALTER TABLE "idempotency_keys" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
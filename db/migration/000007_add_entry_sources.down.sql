@@ -0,0 +1,3 @@
+ALTER TABLE IF EXISTS "entries" DROP COLUMN IF EXISTS "source_id";
+
+ALTER TABLE IF EXISTS "entries" DROP COLUMN IF EXISTS "source_type";
@@ -0,0 +1,14 @@
+-- Entries created before this migration can't be traced back to their transaction
+ALTER TABLE "entries" ADD COLUMN "source_type" varchar NOT NULL DEFAULT 'unknown';
+
+ALTER TABLE "entries" ADD COLUMN "source_id" bigint NOT NULL DEFAULT 0;
+
+ALTER TABLE "entries" ALTER COLUMN "source_type" DROP DEFAULT;
+
+ALTER TABLE "entries" ALTER COLUMN "source_id" DROP DEFAULT;
+
+CREATE INDEX ON "entries" ("source_type", "source_id");
+
+COMMENT ON COLUMN "entries"."source_type" IS 'kind of transaction which created the entry';
+
+COMMENT ON COLUMN "entries"."source_id" IS 'id of the transaction which created the entry';
@@ -0,0 +1,13 @@
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "quote_id";
+
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "spread_bps";
+
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "rate";
+
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "to_amount";
+
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "to_currency";
+
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "from_currency";
+
+DROP TABLE IF EXISTS "fx_quotes";
@@ -0,0 +1,64 @@
+CREATE TABLE "fx_quotes" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "from_currency" varchar NOT NULL,
+  "to_currency" varchar NOT NULL,
+  "from_amount" bigint NOT NULL,
+  "to_amount" bigint NOT NULL,
+  "market_rate" bigint NOT NULL,
+  "spread_bps" int NOT NULL,
+  "rate" bigint NOT NULL,
+  "expires_at" timestamptz NOT NULL,
+  "used_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "fx_quotes" ("username");
+
+COMMENT ON COLUMN "fx_quotes"."market_rate" IS 'rate from the provider, scaled by 10^8';
+
+COMMENT ON COLUMN "fx_quotes"."spread_bps" IS 'spread taken out of the market rate, in basis points';
+
+COMMENT ON COLUMN "fx_quotes"."rate" IS 'rate applied to the transfer, scaled by 10^8';
+
+COMMENT ON COLUMN "fx_quotes"."used_at" IS 'a quote can be used by a single transfer';
+
+ALTER TABLE "fx_quotes" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+-- Existing transfers were all made between accounts with the same currency
+ALTER TABLE "transfers" ADD COLUMN "from_currency" varchar;
+
+ALTER TABLE "transfers" ADD COLUMN "to_currency" varchar;
+
+ALTER TABLE "transfers" ADD COLUMN "to_amount" bigint;
+
+ALTER TABLE "transfers" ADD COLUMN "rate" bigint NOT NULL DEFAULT 100000000;
+
+ALTER TABLE "transfers" ADD COLUMN "spread_bps" int NOT NULL DEFAULT 0;
+
+ALTER TABLE "transfers" ADD COLUMN "quote_id" bigint UNIQUE;
+
+UPDATE "transfers" AS t SET
+  "from_currency" = a."currency",
+  "to_currency" = a."currency",
+  "to_amount" = t."amount"
+FROM "accounts" AS a
+WHERE a."id" = t."from_account_id";
+
+ALTER TABLE "transfers" ALTER COLUMN "from_currency" SET NOT NULL;
+
+ALTER TABLE "transfers" ALTER COLUMN "to_currency" SET NOT NULL;
+
+ALTER TABLE "transfers" ALTER COLUMN "to_amount" SET NOT NULL;
+
+ALTER TABLE "transfers" ALTER COLUMN "rate" DROP DEFAULT;
+
+ALTER TABLE "transfers" ALTER COLUMN "spread_bps" DROP DEFAULT;
+
+COMMENT ON COLUMN "transfers"."amount" IS 'must be positive, in the currency of the origin account';
+
+COMMENT ON COLUMN "transfers"."to_amount" IS 'amount credited, in the currency of the destination account';
+
+COMMENT ON COLUMN "transfers"."rate" IS 'rate applied to the transfer, scaled by 10^8';
+
+ALTER TABLE "transfers" ADD FOREIGN KEY ("quote_id") REFERENCES "fx_quotes" ("id");
@@ -0,0 +1,54 @@
+CREATE TABLE "scheduled_transfers" (
+  "id" bigserial PRIMARY KEY,
+  "owner" varchar NOT NULL,
+  "from_account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "frequency" varchar NOT NULL,
+  "start_at" timestamptz NOT NULL,
+  "next_run_at" timestamptz NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'active',
+  "failure_count" int NOT NULL DEFAULT 0,
+  "locked_until" timestamptz,
+  "last_run_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "scheduled_transfer_runs" (
+  "id" bigserial PRIMARY KEY,
+  "scheduled_transfer_id" bigint NOT NULL,
+  "scheduled_for" timestamptz NOT NULL,
+  "status" varchar NOT NULL,
+  "transfer_id" bigint,
+  "error" varchar NOT NULL DEFAULT '',
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "scheduled_transfers" ("owner");
+
+CREATE INDEX ON "scheduled_transfers" ("status", "next_run_at");
+
+CREATE INDEX ON "scheduled_transfer_runs" ("scheduled_transfer_id");
+
+-- An occurrence can't be executed again while it's pending or after it succeeded
+CREATE UNIQUE INDEX ON "scheduled_transfer_runs" ("scheduled_transfer_id", "scheduled_for") WHERE "status" IN ('pending', 'succeeded');
+
+COMMENT ON COLUMN "scheduled_transfers"."frequency" IS 'once, daily, weekly or monthly';
+
+COMMENT ON COLUMN "scheduled_transfers"."status" IS 'active, paused or completed';
+
+COMMENT ON COLUMN "scheduled_transfers"."failure_count" IS 'consecutive failed runs, the order is paused after too many of them';
+
+COMMENT ON COLUMN "scheduled_transfers"."locked_until" IS 'set while a worker is executing the order';
+
+COMMENT ON COLUMN "scheduled_transfer_runs"."status" IS 'pending, succeeded or failed';
+
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "scheduled_transfer_runs" ADD FOREIGN KEY ("scheduled_transfer_id") REFERENCES "scheduled_transfers" ("id");
+
+ALTER TABLE "scheduled_transfer_runs" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
@@ -0,0 +1,16 @@
+ALTER TABLE "users" ADD COLUMN "is_admin" boolean NOT NULL DEFAULT false;
+
+ALTER TABLE "transfers" ADD COLUMN "reversed_amount" bigint NOT NULL DEFAULT 0;
+
+ALTER TABLE "transfers" ADD COLUMN "reversal_of_id" bigint;
+
+CREATE INDEX ON "transfers" ("reversal_of_id");
+
+-- A transfer can't be refunded for more than what the recipient got
+ALTER TABLE "transfers" ADD CONSTRAINT "transfers_reversed_amount_check" CHECK ("reversed_amount" >= 0 AND "reversed_amount" <= "to_amount");
+
+COMMENT ON COLUMN "transfers"."reversed_amount" IS 'how much of to_amount was refunded, up to all of it';
+
+COMMENT ON COLUMN "transfers"."reversal_of_id" IS 'the transfer which is refunded by this one';
+
+ALTER TABLE "transfers" ADD FOREIGN KEY ("reversal_of_id") REFERENCES "transfers" ("id");
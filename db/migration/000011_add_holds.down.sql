@@ -0,0 +1,13 @@
+ALTER TABLE IF EXISTS "accounts" DROP CONSTRAINT IF EXISTS "accounts_held_amount_check";
+
+ALTER TABLE IF EXISTS "accounts" DROP CONSTRAINT IF EXISTS "accounts_balance_check";
+
+ALTER TABLE IF EXISTS "accounts" ADD CONSTRAINT "accounts_balance_check" CHECK ("balance" >= -"overdraft_limit");
+
+COMMENT ON COLUMN "accounts"."balance" IS 'must not go below -overdraft_limit';
+
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "available_balance";
+
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "held_amount";
+
+DROP TABLE IF EXISTS "holds";
@@ -0,0 +1,44 @@
+CREATE TABLE "holds" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "captured_amount" bigint NOT NULL DEFAULT 0,
+  "status" varchar NOT NULL DEFAULT 'active',
+  "user" varchar NOT NULL,
+  "expires_at" timestamptz NOT NULL,
+  "finished_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "holds" ("account_id");
+
+CREATE INDEX ON "holds" ("user");
+
+CREATE INDEX ON "holds" ("expires_at") WHERE "status" = 'active';
+
+COMMENT ON COLUMN "holds"."amount" IS 'must be positive, reserved until the hold is captured, voided or expired';
+
+COMMENT ON COLUMN "holds"."captured_amount" IS 'how much was taken from the account, up to the amount held';
+
+COMMENT ON COLUMN "holds"."status" IS 'active, captured, voided or expired';
+
+ALTER TABLE "holds" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "holds" ADD FOREIGN KEY ("user") REFERENCES "users" ("username");
+
+ALTER TABLE "accounts" ADD COLUMN "held_amount" bigint NOT NULL DEFAULT 0;
+
+ALTER TABLE "accounts" ADD COLUMN "available_balance" bigint NOT NULL GENERATED ALWAYS AS ("balance" - "held_amount") STORED;
+
+COMMENT ON COLUMN "accounts"."balance" IS 'balance - held_amount must not go below -overdraft_limit';
+
+COMMENT ON COLUMN "accounts"."held_amount" IS 'sum of the active holds on the account';
+
+COMMENT ON COLUMN "accounts"."available_balance" IS 'generated as balance - held_amount';
+
+-- Money which is held can't be spent, so it counts against the overdraft limit as well
+ALTER TABLE "accounts" DROP CONSTRAINT "accounts_balance_check";
+
+ALTER TABLE "accounts" ADD CONSTRAINT "accounts_balance_check" CHECK ("balance" - "held_amount" >= -"overdraft_limit");
+
+ALTER TABLE "accounts" ADD CONSTRAINT "accounts_held_amount_check" CHECK ("held_amount" >= 0);
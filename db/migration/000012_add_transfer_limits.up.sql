@@ -0,0 +1,28 @@
+ALTER TABLE "users" ADD COLUMN "tier" varchar NOT NULL DEFAULT 'standard';
+
+CREATE TABLE "transfer_limits" (
+  "tier" varchar NOT NULL,
+  "currency" varchar NOT NULL,
+  "max_per_transaction" bigint NOT NULL,
+  "daily_amount" bigint NOT NULL,
+  "daily_count" int NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("tier", "currency")
+);
+
+COMMENT ON COLUMN "users"."tier" IS 'picks the transfer limits which apply to the user';
+
+COMMENT ON COLUMN "transfer_limits"."max_per_transaction" IS 'largest amount a single transfer or withdrawal can take out';
+
+COMMENT ON COLUMN "transfer_limits"."daily_amount" IS 'total which can be taken out of the accounts of the user in a day';
+
+COMMENT ON COLUMN "transfer_limits"."daily_count" IS 'how many transfers and withdrawals the user can make in a day';
+
+-- Users of a tier without limits for a currency aren't limited
+INSERT INTO "transfer_limits" ("tier", "currency", "max_per_transaction", "daily_amount", "daily_count") VALUES
+  ('standard', 'USD', 1000000, 5000000, 100),
+  ('standard', 'EUR', 1000000, 5000000, 100),
+  ('standard', 'CAD', 1000000, 5000000, 100),
+  ('premium', 'USD', 10000000, 50000000, 1000),
+  ('premium', 'EUR', 10000000, 50000000, 1000),
+  ('premium', 'CAD', 10000000, 50000000, 1000);
@@ -0,0 +1,5 @@
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "fee";
+
+DROP TABLE IF EXISTS "fee_rules";
+
+DROP TABLE IF EXISTS "house_accounts";
@@ -0,0 +1,48 @@
+CREATE TABLE "fee_rules" (
+  "id" bigserial PRIMARY KEY,
+  "currency" varchar NOT NULL,
+  "transfer_type" varchar NOT NULL,
+  "min_amount" bigint NOT NULL DEFAULT 0,
+  "flat_fee" bigint NOT NULL DEFAULT 0,
+  "percentage_bps" int NOT NULL DEFAULT 0,
+  "min_fee" bigint NOT NULL DEFAULT 0,
+  "max_fee" bigint NOT NULL DEFAULT 0,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE UNIQUE INDEX ON "fee_rules" ("currency", "transfer_type", "min_amount");
+
+CREATE TABLE "house_accounts" (
+  "currency" varchar PRIMARY KEY,
+  "account_id" bigint UNIQUE NOT NULL
+);
+
+ALTER TABLE "transfers" ADD COLUMN "fee" bigint NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN "fee_rules"."transfer_type" IS 'same_currency or cross_currency';
+
+COMMENT ON COLUMN "fee_rules"."min_amount" IS 'the rule applies to transfers of at least this amount, until the rule with the next tier';
+
+COMMENT ON COLUMN "fee_rules"."percentage_bps" IS 'charged on the amount, in basis points';
+
+COMMENT ON COLUMN "fee_rules"."max_fee" IS 'zero when the fee has no cap';
+
+COMMENT ON COLUMN "house_accounts"."account_id" IS 'account which collects the fees charged in the currency';
+
+COMMENT ON COLUMN "transfers"."fee" IS 'charged to the origin account on top of the amount, in its currency';
+
+ALTER TABLE "house_accounts" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+-- The bank keeps its revenue in accounts of its own, whose owner can't log in
+-- since it has no password and isn't a valid username for new users.
+-- They are kept when the migration is rolled back, since entries may point to them
+INSERT INTO "users" ("username", "hashed_password", "full_name", "email")
+VALUES ('simple_bank', '', 'Simple Bank', 'revenue@simplebank.local')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO "accounts" ("owner", "balance", "currency")
+VALUES ('simple_bank', 0, 'USD'), ('simple_bank', 0, 'EUR'), ('simple_bank', 0, 'CAD')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO "house_accounts" ("currency", "account_id")
+SELECT "currency", "id" FROM "accounts" WHERE "owner" = 'simple_bank';
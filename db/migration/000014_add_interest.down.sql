@@ -0,0 +1,25 @@
+-- The interest which was already paid stays in the accounts, but its entries point to postings which are dropped
+DELETE FROM "entries" WHERE "source_type" = 'interest';
+
+DELETE FROM "house_accounts" WHERE "purpose" = 'interest_expense';
+
+DELETE FROM "accounts" WHERE "owner" = 'simple_bank' AND "id" NOT IN (SELECT "account_id" FROM "house_accounts");
+
+DROP TABLE IF EXISTS "interest_accruals";
+
+DROP TABLE IF EXISTS "interest_postings";
+
+DROP TABLE IF EXISTS "interest_rates";
+
+ALTER TABLE IF EXISTS "house_accounts" DROP CONSTRAINT IF EXISTS "house_accounts_pkey";
+
+ALTER TABLE IF EXISTS "house_accounts" DROP COLUMN IF EXISTS "purpose";
+
+ALTER TABLE IF EXISTS "house_accounts" ADD PRIMARY KEY ("currency");
+
+DROP INDEX IF EXISTS "owner_currency_type_key";
+
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "type";
+
+-- This fails if a user has more than one account in a currency
+ALTER TABLE "accounts" ADD CONSTRAINT "owner_currency_key" UNIQUE ("owner", "currency");
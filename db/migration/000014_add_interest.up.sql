@@ -0,0 +1,90 @@
+ALTER TABLE "accounts" ADD COLUMN "type" varchar NOT NULL DEFAULT 'checking';
+
+-- Users can have a checking and a savings account in each currency,
+-- while the bank can have as many house accounts as it needs
+ALTER TABLE "accounts" DROP CONSTRAINT "owner_currency_key";
+
+CREATE UNIQUE INDEX "owner_currency_type_key" ON "accounts" ("owner", "currency", "type") WHERE "type" <> 'house';
+
+UPDATE "accounts" SET "type" = 'house' WHERE "id" IN (SELECT "account_id" FROM "house_accounts");
+
+ALTER TABLE "house_accounts" ADD COLUMN "purpose" varchar NOT NULL DEFAULT 'fee_revenue';
+
+ALTER TABLE "house_accounts" DROP CONSTRAINT "house_accounts_pkey";
+
+ALTER TABLE "house_accounts" ADD PRIMARY KEY ("purpose", "currency");
+
+CREATE TABLE "interest_rates" (
+  "account_type" varchar NOT NULL,
+  "currency" varchar NOT NULL,
+  "annual_rate_bps" int NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("account_type", "currency")
+);
+
+CREATE TABLE "interest_accruals" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "accrual_date" date NOT NULL,
+  "balance" bigint NOT NULL,
+  "annual_rate_bps" int NOT NULL,
+  "amount" bigint NOT NULL,
+  "posting_id" bigint,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "interest_postings" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "period" date NOT NULL,
+  "amount" bigint NOT NULL,
+  "remainder" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE UNIQUE INDEX ON "interest_accruals" ("account_id", "accrual_date");
+
+CREATE INDEX ON "interest_accruals" ("posting_id");
+
+CREATE UNIQUE INDEX ON "interest_postings" ("account_id", "period");
+
+COMMENT ON COLUMN "accounts"."type" IS 'checking or savings, and house for the accounts of the bank';
+
+COMMENT ON COLUMN "house_accounts"."purpose" IS 'fee_revenue or interest_expense';
+
+COMMENT ON COLUMN "interest_rates"."annual_rate_bps" IS 'yearly interest rate, in basis points';
+
+COMMENT ON COLUMN "interest_accruals"."balance" IS 'balance of the account at the end of the day';
+
+COMMENT ON COLUMN "interest_accruals"."amount" IS 'balance * annual_rate_bps, which is the interest of the day in 1/3650000 of the smallest unit';
+
+COMMENT ON COLUMN "interest_accruals"."posting_id" IS 'set once the interest is paid';
+
+COMMENT ON COLUMN "interest_postings"."period" IS 'first day of the month the interest was accrued in';
+
+COMMENT ON COLUMN "interest_postings"."amount" IS 'interest credited to the account';
+
+COMMENT ON COLUMN "interest_postings"."remainder" IS 'fraction of the smallest unit left over, carried to the next posting';
+
+ALTER TABLE "interest_accruals" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "interest_accruals" ADD FOREIGN KEY ("posting_id") REFERENCES "interest_postings" ("id");
+
+ALTER TABLE "interest_postings" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+-- Interest is paid out of expense accounts, which are allowed to go as far below zero as needed
+INSERT INTO "accounts" ("owner", "balance", "currency", "type", "overdraft_limit")
+VALUES
+  ('simple_bank', 0, 'USD', 'house', 9223372036854775807),
+  ('simple_bank', 0, 'EUR', 'house', 9223372036854775807),
+  ('simple_bank', 0, 'CAD', 'house', 9223372036854775807);
+
+INSERT INTO "house_accounts" ("purpose", "currency", "account_id")
+SELECT 'interest_expense', "currency", "id" FROM "accounts"
+WHERE "owner" = 'simple_bank' AND "id" NOT IN (SELECT "account_id" FROM "house_accounts");
+
+-- Accounts of a type without a rate for their currency don't earn interest
+INSERT INTO "interest_rates" ("account_type", "currency", "annual_rate_bps") VALUES
+  ('savings', 'USD', 200),
+  ('savings', 'EUR', 150),
+  ('savings', 'CAD', 200);
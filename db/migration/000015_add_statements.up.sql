@@ -0,0 +1,14 @@
+CREATE TABLE "statements" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "period" date NOT NULL,
+  "pdf" bytea NOT NULL,
+  "csv" bytea NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE UNIQUE INDEX ON "statements" ("account_id", "period");
+
+COMMENT ON COLUMN "statements"."period" IS 'first day of the month of the statement';
+
+ALTER TABLE "statements" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
@@ -0,0 +1,14 @@
+CREATE TABLE "balance_snapshots" (
+  "account_id" bigint NOT NULL,
+  "snapshot_date" date NOT NULL,
+  "balance" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("account_id", "snapshot_date")
+);
+
+-- Balances at a point in time only sum the entries of an account between two instants
+CREATE INDEX ON "entries" ("account_id", "created_at");
+
+COMMENT ON COLUMN "balance_snapshots"."balance" IS 'balance of the account at the end of the day, in UTC';
+
+ALTER TABLE "balance_snapshots" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
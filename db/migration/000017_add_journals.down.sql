@@ -0,0 +1,16 @@
+DROP TRIGGER IF EXISTS "journal_balanced" ON "entries";
+
+DROP FUNCTION IF EXISTS "check_journal_balanced";
+
+-- The customer entries are kept, but the lines of the cash and conversion accounts go with them
+DELETE FROM "entries" WHERE "account_id" IN (
+  SELECT "account_id" FROM "house_accounts" WHERE "purpose" IN ('cash', 'fx_conversion')
+);
+
+DELETE FROM "house_accounts" WHERE "purpose" IN ('cash', 'fx_conversion');
+
+DELETE FROM "accounts" WHERE "owner" = 'simple_bank' AND "id" NOT IN (SELECT "account_id" FROM "house_accounts");
+
+ALTER TABLE IF EXISTS "entries" DROP COLUMN IF EXISTS "journal_id";
+
+DROP TABLE IF EXISTS "journals";
@@ -0,0 +1,107 @@
+CREATE TABLE "journals" (
+  "id" bigserial PRIMARY KEY,
+  "kind" varchar NOT NULL,
+  "source_id" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "journals" ("kind", "source_id");
+
+ALTER TABLE "entries" ADD COLUMN "journal_id" bigint;
+
+CREATE INDEX ON "entries" ("journal_id");
+
+COMMENT ON COLUMN "journals"."kind" IS 'kind of transaction which made the posting';
+
+COMMENT ON COLUMN "journals"."source_id" IS 'id of the transaction which made the posting';
+
+COMMENT ON COLUMN "entries"."journal_id" IS 'posting the entry is a line of, whose entries add up to zero in every currency';
+
+ALTER TABLE "entries" ADD FOREIGN KEY ("journal_id") REFERENCES "journals" ("id");
+
+-- Money comes into and goes out of the bank through cash accounts, and changes currency through
+-- conversion accounts. Both can go as far below zero as needed
+INSERT INTO "accounts" ("owner", "balance", "currency", "type", "overdraft_limit")
+VALUES
+  ('simple_bank', 0, 'USD', 'house', 9223372036854775807),
+  ('simple_bank', 0, 'EUR', 'house', 9223372036854775807),
+  ('simple_bank', 0, 'CAD', 'house', 9223372036854775807);
+
+INSERT INTO "house_accounts" ("purpose", "currency", "account_id")
+SELECT 'cash', "currency", "id" FROM "accounts"
+WHERE "owner" = 'simple_bank' AND "id" NOT IN (SELECT "account_id" FROM "house_accounts");
+
+INSERT INTO "accounts" ("owner", "balance", "currency", "type", "overdraft_limit")
+VALUES
+  ('simple_bank', 0, 'USD', 'house', 9223372036854775807),
+  ('simple_bank', 0, 'EUR', 'house', 9223372036854775807),
+  ('simple_bank', 0, 'CAD', 'house', 9223372036854775807);
+
+INSERT INTO "house_accounts" ("purpose", "currency", "account_id")
+SELECT 'fx_conversion', "currency", "id" FROM "accounts"
+WHERE "owner" = 'simple_bank' AND "id" NOT IN (SELECT "account_id" FROM "house_accounts");
+
+-- The entries made so far become journals of the transaction which made them
+INSERT INTO "journals" ("kind", "source_id", "created_at")
+SELECT "source_type", "source_id", MIN("created_at") FROM "entries"
+GROUP BY "source_type", "source_id";
+
+UPDATE "entries" SET "journal_id" = "journals"."id"
+FROM "journals"
+WHERE "journals"."kind" = "entries"."source_type" AND "journals"."source_id" = "entries"."source_id";
+
+-- Deposits, withdrawals and captured holds only had the entry of the customer, and transfers between
+-- currencies didn't balance in either of them, so the missing lines are booked against the house accounts
+INSERT INTO "entries" ("account_id", "amount", "source_type", "source_id", "journal_id", "created_at")
+SELECT
+  "house_accounts"."account_id",
+  -"lines"."total",
+  "journals"."kind",
+  "journals"."source_id",
+  "journals"."id",
+  "journals"."created_at"
+FROM (
+  SELECT "entries"."journal_id", "accounts"."currency", SUM("entries"."amount") AS "total"
+  FROM "entries"
+  JOIN "accounts" ON "accounts"."id" = "entries"."account_id"
+  GROUP BY "entries"."journal_id", "accounts"."currency"
+  HAVING SUM("entries"."amount") <> 0
+) AS "lines"
+JOIN "journals" ON "journals"."id" = "lines"."journal_id"
+JOIN "house_accounts" ON "house_accounts"."currency" = "lines"."currency"
+  AND "house_accounts"."purpose" = CASE WHEN "journals"."kind" = 'transfer' THEN 'fx_conversion' ELSE 'cash' END;
+
+UPDATE "accounts" SET "balance" = (
+  SELECT COALESCE(SUM("amount"), 0) FROM "entries" WHERE "entries"."account_id" = "accounts"."id"
+)
+WHERE "id" IN (SELECT "account_id" FROM "house_accounts" WHERE "purpose" IN ('cash', 'fx_conversion'));
+
+ALTER TABLE "entries" ALTER COLUMN "journal_id" SET NOT NULL;
+
+-- Every journal must add up to zero in each currency once its transaction commits,
+-- so the entries of every currency always add up to zero as well
+CREATE FUNCTION "check_journal_balanced"() RETURNS trigger AS $$
+DECLARE
+  unbalanced varchar;
+BEGIN
+  SELECT "accounts"."currency" INTO unbalanced
+  FROM "entries"
+  JOIN "accounts" ON "accounts"."id" = "entries"."account_id"
+  WHERE "entries"."journal_id" = NEW."journal_id"
+  GROUP BY "accounts"."currency"
+  HAVING SUM("entries"."amount") <> 0
+  LIMIT 1;
+
+  IF unbalanced IS NOT NULL THEN
+    RAISE EXCEPTION 'journal % does not balance in %', NEW."journal_id", unbalanced
+      USING ERRCODE = 'check_violation', CONSTRAINT = 'journal_balanced';
+  END IF;
+
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE CONSTRAINT TRIGGER "journal_balanced"
+AFTER INSERT OR UPDATE ON "entries"
+DEFERRABLE INITIALLY DEFERRED
+FOR EACH ROW EXECUTE FUNCTION "check_journal_balanced"();
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS "account_status_changes";
+
+DROP INDEX IF EXISTS "owner_currency_type_key";
+
+-- This fails if a user reopened an account they had closed
+CREATE UNIQUE INDEX "owner_currency_type_key" ON "accounts" ("owner", "currency", "type") WHERE "type" <> 'house';
+
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "status";
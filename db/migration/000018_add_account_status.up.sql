@@ -0,0 +1,32 @@
+ALTER TABLE "accounts" ADD COLUMN "status" varchar NOT NULL DEFAULT 'active';
+
+CREATE TABLE "account_status_changes" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "from_status" varchar NOT NULL,
+  "to_status" varchar NOT NULL,
+  "changed_by" varchar NOT NULL,
+  "reason" varchar NOT NULL,
+  "payout_transfer_id" bigint,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "account_status_changes" ("account_id");
+
+-- A closed account doesn't stop its owner from opening a new one of the same kind
+DROP INDEX "owner_currency_type_key";
+
+CREATE UNIQUE INDEX "owner_currency_type_key" ON "accounts" ("owner", "currency", "type")
+WHERE "type" <> 'house' AND "status" <> 'closed';
+
+COMMENT ON COLUMN "accounts"."status" IS 'active, frozen or closed, only active accounts can move money';
+
+COMMENT ON COLUMN "account_status_changes"."changed_by" IS 'owner of the account or the admin who changed its status';
+
+COMMENT ON COLUMN "account_status_changes"."payout_transfer_id" IS 'transfer which paid out the balance of a closed account';
+
+ALTER TABLE "account_status_changes" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "account_status_changes" ADD FOREIGN KEY ("changed_by") REFERENCES "users" ("username");
+
+ALTER TABLE "account_status_changes" ADD FOREIGN KEY ("payout_transfer_id") REFERENCES "transfers" ("id");
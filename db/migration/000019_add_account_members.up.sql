@@ -0,0 +1,24 @@
+CREATE TABLE "account_members" (
+  "account_id" bigint NOT NULL,
+  "username" varchar NOT NULL,
+  "role" varchar NOT NULL,
+  "spend_limit" bigint NOT NULL DEFAULT 0,
+  "invited_by" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("account_id", "username")
+);
+
+CREATE INDEX ON "account_members" ("username");
+
+COMMENT ON COLUMN "account_members"."username" IS 'user the account is shared with, besides its owner';
+
+COMMENT ON COLUMN "account_members"."role" IS 'owner, spender or viewer';
+
+COMMENT ON COLUMN "account_members"."spend_limit" IS 'largest amount a spender can take out of the account at once';
+
+ALTER TABLE "account_members" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "account_members" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "account_members" ADD FOREIGN KEY ("invited_by") REFERENCES "users" ("username");
+
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS "accounts_owner_currency_idx";
+
+-- This fails if a user opened more than one account of a type in a currency
+CREATE UNIQUE INDEX "owner_currency_type_key" ON "accounts" ("owner", "currency", "type")
+WHERE "type" <> 'house' AND "status" <> 'closed';
+
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "description";
+
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "name";
@@ -0,0 +1,10 @@
+ALTER TABLE "accounts" ADD COLUMN "name" varchar NOT NULL DEFAULT '';
+
+ALTER TABLE "accounts" ADD COLUMN "description" varchar NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN "accounts"."name" IS 'nickname given by the owner, such as vacation fund';
+
+-- Users can open several accounts in a currency, up to a configured limit
+DROP INDEX "owner_currency_type_key";
+
+CREATE INDEX ON "accounts" ("owner", "currency");
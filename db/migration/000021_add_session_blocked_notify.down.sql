@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS "session_blocked" ON "sessions";
+
+DROP FUNCTION IF EXISTS "notify_session_blocked";
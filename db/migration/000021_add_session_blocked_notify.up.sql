@@ -0,0 +1,14 @@
+-- Every replica listens on "session_blocked" to drop the cached state of blocked sessions,
+-- whichever query blocked them. Notifications are only sent once the transaction commits
+CREATE FUNCTION "notify_session_blocked"() RETURNS trigger AS $$
+BEGIN
+  PERFORM pg_notify('session_blocked', NEW."id"::text);
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "session_blocked"
+AFTER UPDATE OF "is_blocked" ON "sessions"
+FOR EACH ROW
+WHEN (NEW."is_blocked" AND NOT OLD."is_blocked")
+EXECUTE FUNCTION "notify_session_blocked"();
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS "security_events";
+
+ALTER TABLE IF EXISTS "sessions" DROP COLUMN IF EXISTS "rotated_at";
+
+ALTER TABLE IF EXISTS "sessions" DROP COLUMN IF EXISTS "parent_id";
+
+ALTER TABLE IF EXISTS "sessions" DROP COLUMN IF EXISTS "family_id";
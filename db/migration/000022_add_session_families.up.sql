@@ -0,0 +1,36 @@
+ALTER TABLE "sessions" ADD COLUMN "family_id" uuid;
+
+ALTER TABLE "sessions" ADD COLUMN "parent_id" uuid;
+
+ALTER TABLE "sessions" ADD COLUMN "rotated_at" timestamptz;
+
+-- Every existing session starts its own family
+UPDATE "sessions" SET "family_id" = "id";
+
+ALTER TABLE "sessions" ALTER COLUMN "family_id" SET NOT NULL;
+
+CREATE TABLE "security_events" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "kind" varchar NOT NULL,
+  "session_family_id" uuid NOT NULL,
+  "user_agent" varchar NOT NULL,
+  "client_ip" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "sessions" ("family_id");
+
+CREATE INDEX ON "security_events" ("username");
+
+COMMENT ON COLUMN "sessions"."family_id" IS 'session the refresh token was first issued for at login, shared by every rotation of it';
+
+COMMENT ON COLUMN "sessions"."parent_id" IS 'session of the refresh token this one replaced';
+
+COMMENT ON COLUMN "sessions"."rotated_at" IS 'when the refresh token was exchanged for a new one, after which it must not be used again';
+
+COMMENT ON COLUMN "security_events"."kind" IS 'refresh_token_reused';
+
+ALTER TABLE "sessions" ADD FOREIGN KEY ("parent_id") REFERENCES "sessions" ("id");
+
+ALTER TABLE "security_events" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
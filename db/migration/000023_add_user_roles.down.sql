@@ -0,0 +1,6 @@
+ALTER TABLE IF EXISTS "users" ADD COLUMN IF NOT EXISTS "is_admin" boolean NOT NULL DEFAULT false;
+
+-- Bankers go back to being customers
+UPDATE "users" SET "is_admin" = true WHERE "role" = 'admin';
+
+ALTER TABLE IF EXISTS "users" DROP COLUMN IF EXISTS "role";
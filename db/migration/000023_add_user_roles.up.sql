@@ -0,0 +1,7 @@
+ALTER TABLE "users" ADD COLUMN "role" varchar NOT NULL DEFAULT 'customer';
+
+UPDATE "users" SET "role" = 'admin' WHERE "is_admin";
+
+ALTER TABLE "users" DROP COLUMN "is_admin";
+
+COMMENT ON COLUMN "users"."role" IS 'customer, banker or admin';
@@ -0,0 +1,33 @@
+CREATE TABLE "totp_secrets" (
+  "username" varchar PRIMARY KEY,
+  "encrypted_secret" bytea NOT NULL,
+  "last_used_step" bigint NOT NULL DEFAULT 0,
+  "failed_attempts" integer NOT NULL DEFAULT 0,
+  "last_failed_at" timestamptz,
+  "enabled_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "recovery_codes" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "hashed_code" varchar NOT NULL,
+  "used_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE UNIQUE INDEX ON "recovery_codes" ("username", "hashed_code");
+
+COMMENT ON COLUMN "totp_secrets"."encrypted_secret" IS 'encrypted with the TOTP encryption key of the config';
+
+COMMENT ON COLUMN "totp_secrets"."last_used_step" IS 'time step of the last code accepted, earlier codes being refused so they can''t be replayed';
+
+COMMENT ON COLUMN "totp_secrets"."failed_attempts" IS 'codes refused in a row, checking codes being locked for a while when there are too many';
+
+COMMENT ON COLUMN "totp_secrets"."enabled_at" IS 'when the first code was verified, from which codes are asked at login and for sensitive operations';
+
+COMMENT ON COLUMN "recovery_codes"."hashed_code" IS 'SHA-256 of the code, which can be used once instead of a TOTP code';
+
+ALTER TABLE "totp_secrets" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "recovery_codes" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
@@ -0,0 +1,2 @@
+-- Cancelled orders stay cancelled, they're never picked by the workers
+COMMENT ON COLUMN "scheduled_transfers"."status" IS 'active, paused or completed';
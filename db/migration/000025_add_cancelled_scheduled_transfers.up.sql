@@ -0,0 +1,2 @@
+-- Orders deleted by their owners are kept as cancelled, along with the history of their runs
+COMMENT ON COLUMN "scheduled_transfers"."status" IS 'active, paused, completed or cancelled';
@@ -0,0 +1 @@
+ALTER TABLE IF EXISTS "account_status_changes" DROP COLUMN IF EXISTS "by_staff";
@@ -0,0 +1,16 @@
+ALTER TABLE "account_status_changes" ADD COLUMN "by_staff" boolean NOT NULL DEFAULT false;
+
+-- Only the staff of the bank change the status of accounts they aren't an owner of
+UPDATE "account_status_changes" c
+SET "by_staff" = true
+FROM "accounts" a
+WHERE a."id" = c."account_id"
+  AND c."changed_by" <> a."owner"
+  AND NOT EXISTS (
+    SELECT 1 FROM "account_members" m
+    WHERE m."account_id" = c."account_id"
+      AND m."username" = c."changed_by"
+      AND m."role" = 'owner'
+  );
+
+COMMENT ON COLUMN "account_status_changes"."by_staff" IS 'made by a banker or an admin, whatever their role is now';
@@ -8,6 +8,7 @@ import (
 	context "context"
 	reflect "reflect"
 	db "simplebank/db/sqlc"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	uuid "github.com/google/uuid"
@@ -36,6 +37,21 @@ func (m *MockStore) EXPECT() *MockStoreMockRecorder {
 	return m.recorder
 }
 
+// AccrueInterest mocks base method.
+func (m *MockStore) AccrueInterest(arg0 context.Context, arg1 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AccrueInterest", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AccrueInterest indicates an expected call of AccrueInterest.
+func (mr *MockStoreMockRecorder) AccrueInterest(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AccrueInterest", reflect.TypeOf((*MockStore)(nil).AccrueInterest), arg0, arg1)
+}
+
 // AddAccountBalance mocks base method.
 func (m *MockStore) AddAccountBalance(arg0 context.Context, arg1 db.AddAccountBalanceParams) (db.Account, error) {
 	m.ctrl.T.Helper()
@@ -51,361 +67,1989 @@ func (mr *MockStoreMockRecorder) AddAccountBalance(arg0, arg1 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAccountBalance", reflect.TypeOf((*MockStore)(nil).AddAccountBalance), arg0, arg1)
 }
 
-// CreateAccount mocks base method.
-func (m *MockStore) CreateAccount(arg0 context.Context, arg1 db.CreateAccountParams) (db.Account, error) {
+// AddAccountHeldAmount mocks base method.
+func (m *MockStore) AddAccountHeldAmount(arg0 context.Context, arg1 db.AddAccountHeldAmountParams) (db.Account, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateAccount", arg0, arg1)
+	ret := m.ctrl.Call(m, "AddAccountHeldAmount", arg0, arg1)
 	ret0, _ := ret[0].(db.Account)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateAccount indicates an expected call of CreateAccount.
-func (mr *MockStoreMockRecorder) CreateAccount(arg0, arg1 interface{}) *gomock.Call {
+// AddAccountHeldAmount indicates an expected call of AddAccountHeldAmount.
+func (mr *MockStoreMockRecorder) AddAccountHeldAmount(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccount", reflect.TypeOf((*MockStore)(nil).CreateAccount), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAccountHeldAmount", reflect.TypeOf((*MockStore)(nil).AddAccountHeldAmount), arg0, arg1)
 }
 
-// CreateDeposit mocks base method.
-func (m *MockStore) CreateDeposit(arg0 context.Context, arg1 db.CreateDepositParams) (db.Deposit, error) {
+// AddTransferReversedAmount mocks base method.
+func (m *MockStore) AddTransferReversedAmount(arg0 context.Context, arg1 db.AddTransferReversedAmountParams) (db.Transfer, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateDeposit", arg0, arg1)
-	ret0, _ := ret[0].(db.Deposit)
+	ret := m.ctrl.Call(m, "AddTransferReversedAmount", arg0, arg1)
+	ret0, _ := ret[0].(db.Transfer)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateDeposit indicates an expected call of CreateDeposit.
-func (mr *MockStoreMockRecorder) CreateDeposit(arg0, arg1 interface{}) *gomock.Call {
+// AddTransferReversedAmount indicates an expected call of AddTransferReversedAmount.
+func (mr *MockStoreMockRecorder) AddTransferReversedAmount(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDeposit", reflect.TypeOf((*MockStore)(nil).CreateDeposit), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddTransferReversedAmount", reflect.TypeOf((*MockStore)(nil).AddTransferReversedAmount), arg0, arg1)
 }
 
-// CreateEntry mocks base method.
-func (m *MockStore) CreateEntry(arg0 context.Context, arg1 db.CreateEntryParams) (db.Entry, error) {
+// AuthorizeHoldTx mocks base method.
+func (m *MockStore) AuthorizeHoldTx(arg0 context.Context, arg1 db.AuthorizeHoldTxParams) (db.HoldTxResult, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateEntry", arg0, arg1)
-	ret0, _ := ret[0].(db.Entry)
+	ret := m.ctrl.Call(m, "AuthorizeHoldTx", arg0, arg1)
+	ret0, _ := ret[0].(db.HoldTxResult)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateEntry indicates an expected call of CreateEntry.
-func (mr *MockStoreMockRecorder) CreateEntry(arg0, arg1 interface{}) *gomock.Call {
+// AuthorizeHoldTx indicates an expected call of AuthorizeHoldTx.
+func (mr *MockStoreMockRecorder) AuthorizeHoldTx(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEntry", reflect.TypeOf((*MockStore)(nil).CreateEntry), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthorizeHoldTx", reflect.TypeOf((*MockStore)(nil).AuthorizeHoldTx), arg0, arg1)
 }
 
-// CreateSession mocks base method.
-func (m *MockStore) CreateSession(arg0 context.Context, arg1 db.CreateSessionParams) (db.Session, error) {
+// BatchTransferTx mocks base method.
+func (m *MockStore) BatchTransferTx(arg0 context.Context, arg1 db.BatchTransferTxParams) (db.BatchTransferTxResult, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateSession", arg0, arg1)
-	ret0, _ := ret[0].(db.Session)
+	ret := m.ctrl.Call(m, "BatchTransferTx", arg0, arg1)
+	ret0, _ := ret[0].(db.BatchTransferTxResult)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateSession indicates an expected call of CreateSession.
-func (mr *MockStoreMockRecorder) CreateSession(arg0, arg1 interface{}) *gomock.Call {
+// BatchTransferTx indicates an expected call of BatchTransferTx.
+func (mr *MockStoreMockRecorder) BatchTransferTx(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSession", reflect.TypeOf((*MockStore)(nil).CreateSession), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchTransferTx", reflect.TypeOf((*MockStore)(nil).BatchTransferTx), arg0, arg1)
 }
 
-// CreateTransfer mocks base method.
-func (m *MockStore) CreateTransfer(arg0 context.Context, arg1 db.CreateTransferParams) (db.Transfer, error) {
+// BlockSession mocks base method.
+func (m *MockStore) BlockSession(arg0 context.Context, arg1 db.BlockSessionParams) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateTransfer", arg0, arg1)
-	ret0, _ := ret[0].(db.Transfer)
+	ret := m.ctrl.Call(m, "BlockSession", arg0, arg1)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateTransfer indicates an expected call of CreateTransfer.
-func (mr *MockStoreMockRecorder) CreateTransfer(arg0, arg1 interface{}) *gomock.Call {
+// BlockSession indicates an expected call of BlockSession.
+func (mr *MockStoreMockRecorder) BlockSession(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransfer", reflect.TypeOf((*MockStore)(nil).CreateTransfer), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockSession", reflect.TypeOf((*MockStore)(nil).BlockSession), arg0, arg1)
 }
 
-// CreateUser mocks base method.
-func (m *MockStore) CreateUser(arg0 context.Context, arg1 db.CreateUserParams) (db.User, error) {
+// BlockSessionFamily mocks base method.
+func (m *MockStore) BlockSessionFamily(arg0 context.Context, arg1 uuid.UUID) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateUser", arg0, arg1)
-	ret0, _ := ret[0].(db.User)
+	ret := m.ctrl.Call(m, "BlockSessionFamily", arg0, arg1)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateUser indicates an expected call of CreateUser.
-func (mr *MockStoreMockRecorder) CreateUser(arg0, arg1 interface{}) *gomock.Call {
+// BlockSessionFamily indicates an expected call of BlockSessionFamily.
+func (mr *MockStoreMockRecorder) BlockSessionFamily(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockStore)(nil).CreateUser), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockSessionFamily", reflect.TypeOf((*MockStore)(nil).BlockSessionFamily), arg0, arg1)
 }
 
-// CreateWithdraw mocks base method.
-func (m *MockStore) CreateWithdraw(arg0 context.Context, arg1 db.CreateWithdrawParams) (db.Withdraw, error) {
+// BlockUserSessions mocks base method.
+func (m *MockStore) BlockUserSessions(arg0 context.Context, arg1 string) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateWithdraw", arg0, arg1)
-	ret0, _ := ret[0].(db.Withdraw)
+	ret := m.ctrl.Call(m, "BlockUserSessions", arg0, arg1)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateWithdraw indicates an expected call of CreateWithdraw.
-func (mr *MockStoreMockRecorder) CreateWithdraw(arg0, arg1 interface{}) *gomock.Call {
+// BlockUserSessions indicates an expected call of BlockUserSessions.
+func (mr *MockStoreMockRecorder) BlockUserSessions(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWithdraw", reflect.TypeOf((*MockStore)(nil).CreateWithdraw), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockUserSessions", reflect.TypeOf((*MockStore)(nil).BlockUserSessions), arg0, arg1)
 }
 
-// DeleteAccount mocks base method.
-func (m *MockStore) DeleteAccount(arg0 context.Context, arg1 int64) error {
+// CancelScheduledTransfer mocks base method.
+func (m *MockStore) CancelScheduledTransfer(arg0 context.Context, arg1 int64) (db.ScheduledTransfer, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteAccount", arg0, arg1)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "CancelScheduledTransfer", arg0, arg1)
+	ret0, _ := ret[0].(db.ScheduledTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// DeleteAccount indicates an expected call of DeleteAccount.
-func (mr *MockStoreMockRecorder) DeleteAccount(arg0, arg1 interface{}) *gomock.Call {
+// CancelScheduledTransfer indicates an expected call of CancelScheduledTransfer.
+func (mr *MockStoreMockRecorder) CancelScheduledTransfer(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccount", reflect.TypeOf((*MockStore)(nil).DeleteAccount), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelScheduledTransfer", reflect.TypeOf((*MockStore)(nil).CancelScheduledTransfer), arg0, arg1)
 }
 
-// DepositTx mocks base method.
-func (m *MockStore) DepositTx(arg0 context.Context, arg1 db.DepositTxParams) (db.DepositTxResult, error) {
+// CaptureHoldTx mocks base method.
+func (m *MockStore) CaptureHoldTx(arg0 context.Context, arg1 db.CaptureHoldTxParams) (db.CaptureHoldTxResult, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DepositTx", arg0, arg1)
-	ret0, _ := ret[0].(db.DepositTxResult)
+	ret := m.ctrl.Call(m, "CaptureHoldTx", arg0, arg1)
+	ret0, _ := ret[0].(db.CaptureHoldTxResult)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// DepositTx indicates an expected call of DepositTx.
-func (mr *MockStoreMockRecorder) DepositTx(arg0, arg1 interface{}) *gomock.Call {
+// CaptureHoldTx indicates an expected call of CaptureHoldTx.
+func (mr *MockStoreMockRecorder) CaptureHoldTx(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DepositTx", reflect.TypeOf((*MockStore)(nil).DepositTx), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CaptureHoldTx", reflect.TypeOf((*MockStore)(nil).CaptureHoldTx), arg0, arg1)
 }
 
-// GetAccount mocks base method.
-func (m *MockStore) GetAccount(arg0 context.Context, arg1 int64) (db.Account, error) {
+// ChangeAccountStatusTx mocks base method.
+func (m *MockStore) ChangeAccountStatusTx(arg0 context.Context, arg1 db.ChangeAccountStatusTxParams) (db.ChangeAccountStatusTxResult, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAccount", arg0, arg1)
+	ret := m.ctrl.Call(m, "ChangeAccountStatusTx", arg0, arg1)
+	ret0, _ := ret[0].(db.ChangeAccountStatusTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChangeAccountStatusTx indicates an expected call of ChangeAccountStatusTx.
+func (mr *MockStoreMockRecorder) ChangeAccountStatusTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeAccountStatusTx", reflect.TypeOf((*MockStore)(nil).ChangeAccountStatusTx), arg0, arg1)
+}
+
+// ClaimDueScheduledTransfers mocks base method.
+func (m *MockStore) ClaimDueScheduledTransfers(arg0 context.Context, arg1 db.ClaimDueScheduledTransfersParams) ([]db.ScheduledTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimDueScheduledTransfers", arg0, arg1)
+	ret0, _ := ret[0].([]db.ScheduledTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimDueScheduledTransfers indicates an expected call of ClaimDueScheduledTransfers.
+func (mr *MockStoreMockRecorder) ClaimDueScheduledTransfers(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimDueScheduledTransfers", reflect.TypeOf((*MockStore)(nil).ClaimDueScheduledTransfers), arg0, arg1)
+}
+
+// CountOpenAccounts mocks base method.
+func (m *MockStore) CountOpenAccounts(arg0 context.Context, arg1 db.CountOpenAccountsParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountOpenAccounts", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountOpenAccounts indicates an expected call of CountOpenAccounts.
+func (mr *MockStoreMockRecorder) CountOpenAccounts(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountOpenAccounts", reflect.TypeOf((*MockStore)(nil).CountOpenAccounts), arg0, arg1)
+}
+
+// CountRecoveryCodes mocks base method.
+func (m *MockStore) CountRecoveryCodes(arg0 context.Context, arg1 string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountRecoveryCodes", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountRecoveryCodes indicates an expected call of CountRecoveryCodes.
+func (mr *MockStoreMockRecorder) CountRecoveryCodes(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountRecoveryCodes", reflect.TypeOf((*MockStore)(nil).CountRecoveryCodes), arg0, arg1)
+}
+
+// CreateAccount mocks base method.
+func (m *MockStore) CreateAccount(arg0 context.Context, arg1 db.CreateAccountParams) (db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAccount", arg0, arg1)
 	ret0, _ := ret[0].(db.Account)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetAccount indicates an expected call of GetAccount.
-func (mr *MockStoreMockRecorder) GetAccount(arg0, arg1 interface{}) *gomock.Call {
+// CreateAccount indicates an expected call of CreateAccount.
+func (mr *MockStoreMockRecorder) CreateAccount(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccount", reflect.TypeOf((*MockStore)(nil).GetAccount), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccount", reflect.TypeOf((*MockStore)(nil).CreateAccount), arg0, arg1)
 }
 
-// GetAccountForUpdate mocks base method.
-func (m *MockStore) GetAccountForUpdate(arg0 context.Context, arg1 int64) (db.Account, error) {
+// CreateAccountMember mocks base method.
+func (m *MockStore) CreateAccountMember(arg0 context.Context, arg1 db.CreateAccountMemberParams) (db.AccountMember, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAccountForUpdate", arg0, arg1)
+	ret := m.ctrl.Call(m, "CreateAccountMember", arg0, arg1)
+	ret0, _ := ret[0].(db.AccountMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAccountMember indicates an expected call of CreateAccountMember.
+func (mr *MockStoreMockRecorder) CreateAccountMember(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccountMember", reflect.TypeOf((*MockStore)(nil).CreateAccountMember), arg0, arg1)
+}
+
+// CreateAccountStatusChange mocks base method.
+func (m *MockStore) CreateAccountStatusChange(arg0 context.Context, arg1 db.CreateAccountStatusChangeParams) (db.AccountStatusChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAccountStatusChange", arg0, arg1)
+	ret0, _ := ret[0].(db.AccountStatusChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAccountStatusChange indicates an expected call of CreateAccountStatusChange.
+func (mr *MockStoreMockRecorder) CreateAccountStatusChange(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccountStatusChange", reflect.TypeOf((*MockStore)(nil).CreateAccountStatusChange), arg0, arg1)
+}
+
+// CreateAccountTx mocks base method.
+func (m *MockStore) CreateAccountTx(arg0 context.Context, arg1 db.CreateAccountTxParams) (db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAccountTx", arg0, arg1)
 	ret0, _ := ret[0].(db.Account)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetAccountForUpdate indicates an expected call of GetAccountForUpdate.
-func (mr *MockStoreMockRecorder) GetAccountForUpdate(arg0, arg1 interface{}) *gomock.Call {
+// CreateAccountTx indicates an expected call of CreateAccountTx.
+func (mr *MockStoreMockRecorder) CreateAccountTx(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountForUpdate", reflect.TypeOf((*MockStore)(nil).GetAccountForUpdate), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccountTx", reflect.TypeOf((*MockStore)(nil).CreateAccountTx), arg0, arg1)
 }
 
-// GetDeposit mocks base method.
-func (m *MockStore) GetDeposit(arg0 context.Context, arg1 int64) (db.Deposit, error) {
+// CreateBalanceSnapshots mocks base method.
+func (m *MockStore) CreateBalanceSnapshots(arg0 context.Context, arg1 time.Time) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetDeposit", arg0, arg1)
+	ret := m.ctrl.Call(m, "CreateBalanceSnapshots", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateBalanceSnapshots indicates an expected call of CreateBalanceSnapshots.
+func (mr *MockStoreMockRecorder) CreateBalanceSnapshots(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBalanceSnapshots", reflect.TypeOf((*MockStore)(nil).CreateBalanceSnapshots), arg0, arg1)
+}
+
+// CreateDeposit mocks base method.
+func (m *MockStore) CreateDeposit(arg0 context.Context, arg1 db.CreateDepositParams) (db.Deposit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDeposit", arg0, arg1)
 	ret0, _ := ret[0].(db.Deposit)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetDeposit indicates an expected call of GetDeposit.
-func (mr *MockStoreMockRecorder) GetDeposit(arg0, arg1 interface{}) *gomock.Call {
+// CreateDeposit indicates an expected call of CreateDeposit.
+func (mr *MockStoreMockRecorder) CreateDeposit(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeposit", reflect.TypeOf((*MockStore)(nil).GetDeposit), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDeposit", reflect.TypeOf((*MockStore)(nil).CreateDeposit), arg0, arg1)
 }
 
-// GetEntry mocks base method.
-func (m *MockStore) GetEntry(arg0 context.Context, arg1 int64) (db.Entry, error) {
+// CreateEntry mocks base method.
+func (m *MockStore) CreateEntry(arg0 context.Context, arg1 db.CreateEntryParams) (db.Entry, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetEntry", arg0, arg1)
+	ret := m.ctrl.Call(m, "CreateEntry", arg0, arg1)
 	ret0, _ := ret[0].(db.Entry)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetEntry indicates an expected call of GetEntry.
-func (mr *MockStoreMockRecorder) GetEntry(arg0, arg1 interface{}) *gomock.Call {
+// CreateEntry indicates an expected call of CreateEntry.
+func (mr *MockStoreMockRecorder) CreateEntry(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEntry", reflect.TypeOf((*MockStore)(nil).GetEntry), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEntry", reflect.TypeOf((*MockStore)(nil).CreateEntry), arg0, arg1)
 }
 
-// GetSession mocks base method.
-func (m *MockStore) GetSession(arg0 context.Context, arg1 uuid.UUID) (db.Session, error) {
+// CreateFeeRule mocks base method.
+func (m *MockStore) CreateFeeRule(arg0 context.Context, arg1 db.CreateFeeRuleParams) (db.FeeRule, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetSession", arg0, arg1)
-	ret0, _ := ret[0].(db.Session)
+	ret := m.ctrl.Call(m, "CreateFeeRule", arg0, arg1)
+	ret0, _ := ret[0].(db.FeeRule)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetSession indicates an expected call of GetSession.
-func (mr *MockStoreMockRecorder) GetSession(arg0, arg1 interface{}) *gomock.Call {
+// CreateFeeRule indicates an expected call of CreateFeeRule.
+func (mr *MockStoreMockRecorder) CreateFeeRule(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSession", reflect.TypeOf((*MockStore)(nil).GetSession), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFeeRule", reflect.TypeOf((*MockStore)(nil).CreateFeeRule), arg0, arg1)
 }
 
-// GetTransfer mocks base method.
-func (m *MockStore) GetTransfer(arg0 context.Context, arg1 int64) (db.Transfer, error) {
+// CreateFxQuote mocks base method.
+func (m *MockStore) CreateFxQuote(arg0 context.Context, arg1 db.CreateFxQuoteParams) (db.FxQuote, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetTransfer", arg0, arg1)
-	ret0, _ := ret[0].(db.Transfer)
+	ret := m.ctrl.Call(m, "CreateFxQuote", arg0, arg1)
+	ret0, _ := ret[0].(db.FxQuote)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetTransfer indicates an expected call of GetTransfer.
-func (mr *MockStoreMockRecorder) GetTransfer(arg0, arg1 interface{}) *gomock.Call {
+// CreateFxQuote indicates an expected call of CreateFxQuote.
+func (mr *MockStoreMockRecorder) CreateFxQuote(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransfer", reflect.TypeOf((*MockStore)(nil).GetTransfer), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFxQuote", reflect.TypeOf((*MockStore)(nil).CreateFxQuote), arg0, arg1)
 }
 
-// GetUser mocks base method.
-func (m *MockStore) GetUser(arg0 context.Context, arg1 string) (db.User, error) {
+// CreateHold mocks base method.
+func (m *MockStore) CreateHold(arg0 context.Context, arg1 db.CreateHoldParams) (db.Hold, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUser", arg0, arg1)
-	ret0, _ := ret[0].(db.User)
+	ret := m.ctrl.Call(m, "CreateHold", arg0, arg1)
+	ret0, _ := ret[0].(db.Hold)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetUser indicates an expected call of GetUser.
-func (mr *MockStoreMockRecorder) GetUser(arg0, arg1 interface{}) *gomock.Call {
+// CreateHold indicates an expected call of CreateHold.
+func (mr *MockStoreMockRecorder) CreateHold(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockStore)(nil).GetUser), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateHold", reflect.TypeOf((*MockStore)(nil).CreateHold), arg0, arg1)
 }
 
-// Getwithdraw mocks base method.
-func (m *MockStore) Getwithdraw(arg0 context.Context, arg1 int64) (db.Withdraw, error) {
+// CreateIdempotencyKey mocks base method.
+func (m *MockStore) CreateIdempotencyKey(arg0 context.Context, arg1 db.CreateIdempotencyKeyParams) (db.IdempotencyKey, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Getwithdraw", arg0, arg1)
-	ret0, _ := ret[0].(db.Withdraw)
+	ret := m.ctrl.Call(m, "CreateIdempotencyKey", arg0, arg1)
+	ret0, _ := ret[0].(db.IdempotencyKey)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// Getwithdraw indicates an expected call of Getwithdraw.
-func (mr *MockStoreMockRecorder) Getwithdraw(arg0, arg1 interface{}) *gomock.Call {
+// CreateIdempotencyKey indicates an expected call of CreateIdempotencyKey.
+func (mr *MockStoreMockRecorder) CreateIdempotencyKey(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Getwithdraw", reflect.TypeOf((*MockStore)(nil).Getwithdraw), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateIdempotencyKey", reflect.TypeOf((*MockStore)(nil).CreateIdempotencyKey), arg0, arg1)
 }
 
-// ListAccounts mocks base method.
-func (m *MockStore) ListAccounts(arg0 context.Context, arg1 db.ListAccountsParams) ([]db.Account, error) {
+// CreateInterestPosting mocks base method.
+func (m *MockStore) CreateInterestPosting(arg0 context.Context, arg1 db.CreateInterestPostingParams) (db.InterestPosting, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListAccounts", arg0, arg1)
-	ret0, _ := ret[0].([]db.Account)
+	ret := m.ctrl.Call(m, "CreateInterestPosting", arg0, arg1)
+	ret0, _ := ret[0].(db.InterestPosting)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListAccounts indicates an expected call of ListAccounts.
-func (mr *MockStoreMockRecorder) ListAccounts(arg0, arg1 interface{}) *gomock.Call {
+// CreateInterestPosting indicates an expected call of CreateInterestPosting.
+func (mr *MockStoreMockRecorder) CreateInterestPosting(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccounts", reflect.TypeOf((*MockStore)(nil).ListAccounts), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateInterestPosting", reflect.TypeOf((*MockStore)(nil).CreateInterestPosting), arg0, arg1)
 }
 
-// ListDeposits mocks base method.
-func (m *MockStore) ListDeposits(arg0 context.Context, arg1 db.ListDepositsParams) ([]db.Deposit, error) {
+// CreateInterestRate mocks base method.
+func (m *MockStore) CreateInterestRate(arg0 context.Context, arg1 db.CreateInterestRateParams) (db.InterestRate, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListDeposits", arg0, arg1)
-	ret0, _ := ret[0].([]db.Deposit)
+	ret := m.ctrl.Call(m, "CreateInterestRate", arg0, arg1)
+	ret0, _ := ret[0].(db.InterestRate)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListDeposits indicates an expected call of ListDeposits.
-func (mr *MockStoreMockRecorder) ListDeposits(arg0, arg1 interface{}) *gomock.Call {
+// CreateInterestRate indicates an expected call of CreateInterestRate.
+func (mr *MockStoreMockRecorder) CreateInterestRate(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeposits", reflect.TypeOf((*MockStore)(nil).ListDeposits), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateInterestRate", reflect.TypeOf((*MockStore)(nil).CreateInterestRate), arg0, arg1)
 }
 
-// ListEntries mocks base method.
-func (m *MockStore) ListEntries(arg0 context.Context, arg1 db.ListEntriesParams) ([]db.Entry, error) {
+// CreateJournal mocks base method.
+func (m *MockStore) CreateJournal(arg0 context.Context, arg1 db.CreateJournalParams) (db.Journal, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListEntries", arg0, arg1)
-	ret0, _ := ret[0].([]db.Entry)
+	ret := m.ctrl.Call(m, "CreateJournal", arg0, arg1)
+	ret0, _ := ret[0].(db.Journal)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListEntries indicates an expected call of ListEntries.
-func (mr *MockStoreMockRecorder) ListEntries(arg0, arg1 interface{}) *gomock.Call {
+// CreateJournal indicates an expected call of CreateJournal.
+func (mr *MockStoreMockRecorder) CreateJournal(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEntries", reflect.TypeOf((*MockStore)(nil).ListEntries), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateJournal", reflect.TypeOf((*MockStore)(nil).CreateJournal), arg0, arg1)
 }
 
-// ListTransfers mocks base method.
-func (m *MockStore) ListTransfers(arg0 context.Context, arg1 db.ListTransfersParams) ([]db.Transfer, error) {
+// CreateRecoveryCode mocks base method.
+func (m *MockStore) CreateRecoveryCode(arg0 context.Context, arg1 db.CreateRecoveryCodeParams) (db.RecoveryCode, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListTransfers", arg0, arg1)
-	ret0, _ := ret[0].([]db.Transfer)
+	ret := m.ctrl.Call(m, "CreateRecoveryCode", arg0, arg1)
+	ret0, _ := ret[0].(db.RecoveryCode)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListTransfers indicates an expected call of ListTransfers.
-func (mr *MockStoreMockRecorder) ListTransfers(arg0, arg1 interface{}) *gomock.Call {
+// CreateRecoveryCode indicates an expected call of CreateRecoveryCode.
+func (mr *MockStoreMockRecorder) CreateRecoveryCode(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTransfers", reflect.TypeOf((*MockStore)(nil).ListTransfers), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRecoveryCode", reflect.TypeOf((*MockStore)(nil).CreateRecoveryCode), arg0, arg1)
 }
 
-// ListWithdraws mocks base method.
-func (m *MockStore) ListWithdraws(arg0 context.Context, arg1 db.ListWithdrawsParams) ([]db.Withdraw, error) {
+// CreateScheduledTransfer mocks base method.
+func (m *MockStore) CreateScheduledTransfer(arg0 context.Context, arg1 db.CreateScheduledTransferParams) (db.ScheduledTransfer, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListWithdraws", arg0, arg1)
-	ret0, _ := ret[0].([]db.Withdraw)
+	ret := m.ctrl.Call(m, "CreateScheduledTransfer", arg0, arg1)
+	ret0, _ := ret[0].(db.ScheduledTransfer)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListWithdraws indicates an expected call of ListWithdraws.
-func (mr *MockStoreMockRecorder) ListWithdraws(arg0, arg1 interface{}) *gomock.Call {
+// CreateScheduledTransfer indicates an expected call of CreateScheduledTransfer.
+func (mr *MockStoreMockRecorder) CreateScheduledTransfer(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWithdraws", reflect.TypeOf((*MockStore)(nil).ListWithdraws), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateScheduledTransfer", reflect.TypeOf((*MockStore)(nil).CreateScheduledTransfer), arg0, arg1)
 }
 
-// TransferTx mocks base method.
-func (m *MockStore) TransferTx(arg0 context.Context, arg1 db.TransferTxParams) (db.TransferTxResult, error) {
+// CreateScheduledTransferRun mocks base method.
+func (m *MockStore) CreateScheduledTransferRun(arg0 context.Context, arg1 db.CreateScheduledTransferRunParams) (db.ScheduledTransferRun, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "TransferTx", arg0, arg1)
-	ret0, _ := ret[0].(db.TransferTxResult)
+	ret := m.ctrl.Call(m, "CreateScheduledTransferRun", arg0, arg1)
+	ret0, _ := ret[0].(db.ScheduledTransferRun)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// TransferTx indicates an expected call of TransferTx.
-func (mr *MockStoreMockRecorder) TransferTx(arg0, arg1 interface{}) *gomock.Call {
+// CreateScheduledTransferRun indicates an expected call of CreateScheduledTransferRun.
+func (mr *MockStoreMockRecorder) CreateScheduledTransferRun(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransferTx", reflect.TypeOf((*MockStore)(nil).TransferTx), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateScheduledTransferRun", reflect.TypeOf((*MockStore)(nil).CreateScheduledTransferRun), arg0, arg1)
 }
 
-// UpdateAccount mocks base method.
-func (m *MockStore) UpdateAccount(arg0 context.Context, arg1 db.UpdateAccountParams) (db.Account, error) {
+// CreateSecurityEvent mocks base method.
+func (m *MockStore) CreateSecurityEvent(arg0 context.Context, arg1 db.CreateSecurityEventParams) (db.SecurityEvent, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateAccount", arg0, arg1)
-	ret0, _ := ret[0].(db.Account)
+	ret := m.ctrl.Call(m, "CreateSecurityEvent", arg0, arg1)
+	ret0, _ := ret[0].(db.SecurityEvent)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// UpdateAccount indicates an expected call of UpdateAccount.
-func (mr *MockStoreMockRecorder) UpdateAccount(arg0, arg1 interface{}) *gomock.Call {
+// CreateSecurityEvent indicates an expected call of CreateSecurityEvent.
+func (mr *MockStoreMockRecorder) CreateSecurityEvent(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAccount", reflect.TypeOf((*MockStore)(nil).UpdateAccount), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSecurityEvent", reflect.TypeOf((*MockStore)(nil).CreateSecurityEvent), arg0, arg1)
+}
+
+// CreateSession mocks base method.
+func (m *MockStore) CreateSession(arg0 context.Context, arg1 db.CreateSessionParams) (db.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSession", arg0, arg1)
+	ret0, _ := ret[0].(db.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSession indicates an expected call of CreateSession.
+func (mr *MockStoreMockRecorder) CreateSession(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSession", reflect.TypeOf((*MockStore)(nil).CreateSession), arg0, arg1)
+}
+
+// CreateStatement mocks base method.
+func (m *MockStore) CreateStatement(arg0 context.Context, arg1 db.CreateStatementParams) (db.Statement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateStatement", arg0, arg1)
+	ret0, _ := ret[0].(db.Statement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateStatement indicates an expected call of CreateStatement.
+func (mr *MockStoreMockRecorder) CreateStatement(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateStatement", reflect.TypeOf((*MockStore)(nil).CreateStatement), arg0, arg1)
+}
+
+// CreateTransfer mocks base method.
+func (m *MockStore) CreateTransfer(arg0 context.Context, arg1 db.CreateTransferParams) (db.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTransfer", arg0, arg1)
+	ret0, _ := ret[0].(db.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTransfer indicates an expected call of CreateTransfer.
+func (mr *MockStoreMockRecorder) CreateTransfer(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransfer", reflect.TypeOf((*MockStore)(nil).CreateTransfer), arg0, arg1)
+}
+
+// CreateTransferLimit mocks base method.
+func (m *MockStore) CreateTransferLimit(arg0 context.Context, arg1 db.CreateTransferLimitParams) (db.TransferLimit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTransferLimit", arg0, arg1)
+	ret0, _ := ret[0].(db.TransferLimit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTransferLimit indicates an expected call of CreateTransferLimit.
+func (mr *MockStoreMockRecorder) CreateTransferLimit(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransferLimit", reflect.TypeOf((*MockStore)(nil).CreateTransferLimit), arg0, arg1)
+}
+
+// CreateUser mocks base method.
+func (m *MockStore) CreateUser(arg0 context.Context, arg1 db.CreateUserParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUser", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUser indicates an expected call of CreateUser.
+func (mr *MockStoreMockRecorder) CreateUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockStore)(nil).CreateUser), arg0, arg1)
+}
+
+// CreateWithdraw mocks base method.
+func (m *MockStore) CreateWithdraw(arg0 context.Context, arg1 db.CreateWithdrawParams) (db.Withdraw, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWithdraw", arg0, arg1)
+	ret0, _ := ret[0].(db.Withdraw)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWithdraw indicates an expected call of CreateWithdraw.
+func (mr *MockStoreMockRecorder) CreateWithdraw(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWithdraw", reflect.TypeOf((*MockStore)(nil).CreateWithdraw), arg0, arg1)
+}
+
+// DeleteAccountMember mocks base method.
+func (m *MockStore) DeleteAccountMember(arg0 context.Context, arg1 db.DeleteAccountMemberParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAccountMember", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteAccountMember indicates an expected call of DeleteAccountMember.
+func (mr *MockStoreMockRecorder) DeleteAccountMember(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccountMember", reflect.TypeOf((*MockStore)(nil).DeleteAccountMember), arg0, arg1)
+}
+
+// DeleteExpiredIdempotencyKeys mocks base method.
+func (m *MockStore) DeleteExpiredIdempotencyKeys(arg0 context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredIdempotencyKeys", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpiredIdempotencyKeys indicates an expected call of DeleteExpiredIdempotencyKeys.
+func (mr *MockStoreMockRecorder) DeleteExpiredIdempotencyKeys(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredIdempotencyKeys", reflect.TypeOf((*MockStore)(nil).DeleteExpiredIdempotencyKeys), arg0)
+}
+
+// DeleteFeeRule mocks base method.
+func (m *MockStore) DeleteFeeRule(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFeeRule", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteFeeRule indicates an expected call of DeleteFeeRule.
+func (mr *MockStoreMockRecorder) DeleteFeeRule(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFeeRule", reflect.TypeOf((*MockStore)(nil).DeleteFeeRule), arg0, arg1)
+}
+
+// DeleteIdempotencyKey mocks base method.
+func (m *MockStore) DeleteIdempotencyKey(arg0 context.Context, arg1 db.DeleteIdempotencyKeyParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteIdempotencyKey", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteIdempotencyKey indicates an expected call of DeleteIdempotencyKey.
+func (mr *MockStoreMockRecorder) DeleteIdempotencyKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteIdempotencyKey", reflect.TypeOf((*MockStore)(nil).DeleteIdempotencyKey), arg0, arg1)
+}
+
+// DeleteInterestRate mocks base method.
+func (m *MockStore) DeleteInterestRate(arg0 context.Context, arg1 db.DeleteInterestRateParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteInterestRate", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteInterestRate indicates an expected call of DeleteInterestRate.
+func (mr *MockStoreMockRecorder) DeleteInterestRate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteInterestRate", reflect.TypeOf((*MockStore)(nil).DeleteInterestRate), arg0, arg1)
+}
+
+// DeleteRecoveryCodes mocks base method.
+func (m *MockStore) DeleteRecoveryCodes(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRecoveryCodes", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRecoveryCodes indicates an expected call of DeleteRecoveryCodes.
+func (mr *MockStoreMockRecorder) DeleteRecoveryCodes(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRecoveryCodes", reflect.TypeOf((*MockStore)(nil).DeleteRecoveryCodes), arg0, arg1)
+}
+
+// DeleteTotpSecret mocks base method.
+func (m *MockStore) DeleteTotpSecret(arg0 context.Context, arg1 string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTotpSecret", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteTotpSecret indicates an expected call of DeleteTotpSecret.
+func (mr *MockStoreMockRecorder) DeleteTotpSecret(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTotpSecret", reflect.TypeOf((*MockStore)(nil).DeleteTotpSecret), arg0, arg1)
+}
+
+// DepositTx mocks base method.
+func (m *MockStore) DepositTx(arg0 context.Context, arg1 db.DepositTxParams) (db.DepositTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DepositTx", arg0, arg1)
+	ret0, _ := ret[0].(db.DepositTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DepositTx indicates an expected call of DepositTx.
+func (mr *MockStoreMockRecorder) DepositTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DepositTx", reflect.TypeOf((*MockStore)(nil).DepositTx), arg0, arg1)
+}
+
+// DisableTwoFactorTx mocks base method.
+func (m *MockStore) DisableTwoFactorTx(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableTwoFactorTx", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableTwoFactorTx indicates an expected call of DisableTwoFactorTx.
+func (mr *MockStoreMockRecorder) DisableTwoFactorTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableTwoFactorTx", reflect.TypeOf((*MockStore)(nil).DisableTwoFactorTx), arg0, arg1)
+}
+
+// EnableTotpSecret mocks base method.
+func (m *MockStore) EnableTotpSecret(arg0 context.Context, arg1 string) (db.TotpSecret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableTotpSecret", arg0, arg1)
+	ret0, _ := ret[0].(db.TotpSecret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnableTotpSecret indicates an expected call of EnableTotpSecret.
+func (mr *MockStoreMockRecorder) EnableTotpSecret(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableTotpSecret", reflect.TypeOf((*MockStore)(nil).EnableTotpSecret), arg0, arg1)
+}
+
+// EnableTwoFactorTx mocks base method.
+func (m *MockStore) EnableTwoFactorTx(arg0 context.Context, arg1 db.RecoveryCodesTxParams) (db.TotpSecret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableTwoFactorTx", arg0, arg1)
+	ret0, _ := ret[0].(db.TotpSecret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnableTwoFactorTx indicates an expected call of EnableTwoFactorTx.
+func (mr *MockStoreMockRecorder) EnableTwoFactorTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableTwoFactorTx", reflect.TypeOf((*MockStore)(nil).EnableTwoFactorTx), arg0, arg1)
+}
+
+// ExpireHoldTx mocks base method.
+func (m *MockStore) ExpireHoldTx(arg0 context.Context, arg1 int64) (db.HoldTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpireHoldTx", arg0, arg1)
+	ret0, _ := ret[0].(db.HoldTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExpireHoldTx indicates an expected call of ExpireHoldTx.
+func (mr *MockStoreMockRecorder) ExpireHoldTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpireHoldTx", reflect.TypeOf((*MockStore)(nil).ExpireHoldTx), arg0, arg1)
+}
+
+// FinishHold mocks base method.
+func (m *MockStore) FinishHold(arg0 context.Context, arg1 db.FinishHoldParams) (db.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishHold", arg0, arg1)
+	ret0, _ := ret[0].(db.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FinishHold indicates an expected call of FinishHold.
+func (mr *MockStoreMockRecorder) FinishHold(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishHold", reflect.TypeOf((*MockStore)(nil).FinishHold), arg0, arg1)
+}
+
+// FinishScheduledTransferRun mocks base method.
+func (m *MockStore) FinishScheduledTransferRun(arg0 context.Context, arg1 db.FinishScheduledTransferRunParams) (db.ScheduledTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishScheduledTransferRun", arg0, arg1)
+	ret0, _ := ret[0].(db.ScheduledTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FinishScheduledTransferRun indicates an expected call of FinishScheduledTransferRun.
+func (mr *MockStoreMockRecorder) FinishScheduledTransferRun(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishScheduledTransferRun", reflect.TypeOf((*MockStore)(nil).FinishScheduledTransferRun), arg0, arg1)
+}
+
+// GetAccount mocks base method.
+func (m *MockStore) GetAccount(arg0 context.Context, arg1 int64) (db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccount", arg0, arg1)
+	ret0, _ := ret[0].(db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccount indicates an expected call of GetAccount.
+func (mr *MockStoreMockRecorder) GetAccount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccount", reflect.TypeOf((*MockStore)(nil).GetAccount), arg0, arg1)
+}
+
+// GetAccountBalanceAt mocks base method.
+func (m *MockStore) GetAccountBalanceAt(arg0 context.Context, arg1 db.GetAccountBalanceAtParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountBalanceAt", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountBalanceAt indicates an expected call of GetAccountBalanceAt.
+func (mr *MockStoreMockRecorder) GetAccountBalanceAt(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountBalanceAt", reflect.TypeOf((*MockStore)(nil).GetAccountBalanceAt), arg0, arg1)
+}
+
+// GetAccountForUpdate mocks base method.
+func (m *MockStore) GetAccountForUpdate(arg0 context.Context, arg1 int64) (db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountForUpdate indicates an expected call of GetAccountForUpdate.
+func (mr *MockStoreMockRecorder) GetAccountForUpdate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountForUpdate", reflect.TypeOf((*MockStore)(nil).GetAccountForUpdate), arg0, arg1)
+}
+
+// GetAccountMember mocks base method.
+func (m *MockStore) GetAccountMember(arg0 context.Context, arg1 db.GetAccountMemberParams) (db.AccountMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountMember", arg0, arg1)
+	ret0, _ := ret[0].(db.AccountMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountMember indicates an expected call of GetAccountMember.
+func (mr *MockStoreMockRecorder) GetAccountMember(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountMember", reflect.TypeOf((*MockStore)(nil).GetAccountMember), arg0, arg1)
+}
+
+// GetBalanceSnapshot mocks base method.
+func (m *MockStore) GetBalanceSnapshot(arg0 context.Context, arg1 db.GetBalanceSnapshotParams) (db.BalanceSnapshot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBalanceSnapshot", arg0, arg1)
+	ret0, _ := ret[0].(db.BalanceSnapshot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBalanceSnapshot indicates an expected call of GetBalanceSnapshot.
+func (mr *MockStoreMockRecorder) GetBalanceSnapshot(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalanceSnapshot", reflect.TypeOf((*MockStore)(nil).GetBalanceSnapshot), arg0, arg1)
+}
+
+// GetDailyOutgoing mocks base method.
+func (m *MockStore) GetDailyOutgoing(arg0 context.Context, arg1 db.GetDailyOutgoingParams) (db.GetDailyOutgoingRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDailyOutgoing", arg0, arg1)
+	ret0, _ := ret[0].(db.GetDailyOutgoingRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDailyOutgoing indicates an expected call of GetDailyOutgoing.
+func (mr *MockStoreMockRecorder) GetDailyOutgoing(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDailyOutgoing", reflect.TypeOf((*MockStore)(nil).GetDailyOutgoing), arg0, arg1)
+}
+
+// GetDeposit mocks base method.
+func (m *MockStore) GetDeposit(arg0 context.Context, arg1 int64) (db.Deposit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeposit", arg0, arg1)
+	ret0, _ := ret[0].(db.Deposit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeposit indicates an expected call of GetDeposit.
+func (mr *MockStoreMockRecorder) GetDeposit(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeposit", reflect.TypeOf((*MockStore)(nil).GetDeposit), arg0, arg1)
+}
+
+// GetEntry mocks base method.
+func (m *MockStore) GetEntry(arg0 context.Context, arg1 int64) (db.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEntry", arg0, arg1)
+	ret0, _ := ret[0].(db.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEntry indicates an expected call of GetEntry.
+func (mr *MockStoreMockRecorder) GetEntry(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEntry", reflect.TypeOf((*MockStore)(nil).GetEntry), arg0, arg1)
+}
+
+// GetFeeRule mocks base method.
+func (m *MockStore) GetFeeRule(arg0 context.Context, arg1 db.GetFeeRuleParams) (db.FeeRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFeeRule", arg0, arg1)
+	ret0, _ := ret[0].(db.FeeRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFeeRule indicates an expected call of GetFeeRule.
+func (mr *MockStoreMockRecorder) GetFeeRule(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFeeRule", reflect.TypeOf((*MockStore)(nil).GetFeeRule), arg0, arg1)
+}
+
+// GetFxQuote mocks base method.
+func (m *MockStore) GetFxQuote(arg0 context.Context, arg1 int64) (db.FxQuote, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFxQuote", arg0, arg1)
+	ret0, _ := ret[0].(db.FxQuote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFxQuote indicates an expected call of GetFxQuote.
+func (mr *MockStoreMockRecorder) GetFxQuote(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFxQuote", reflect.TypeOf((*MockStore)(nil).GetFxQuote), arg0, arg1)
+}
+
+// GetHold mocks base method.
+func (m *MockStore) GetHold(arg0 context.Context, arg1 int64) (db.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHold", arg0, arg1)
+	ret0, _ := ret[0].(db.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHold indicates an expected call of GetHold.
+func (mr *MockStoreMockRecorder) GetHold(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHold", reflect.TypeOf((*MockStore)(nil).GetHold), arg0, arg1)
+}
+
+// GetHoldForUpdate mocks base method.
+func (m *MockStore) GetHoldForUpdate(arg0 context.Context, arg1 int64) (db.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHoldForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(db.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHoldForUpdate indicates an expected call of GetHoldForUpdate.
+func (mr *MockStoreMockRecorder) GetHoldForUpdate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHoldForUpdate", reflect.TypeOf((*MockStore)(nil).GetHoldForUpdate), arg0, arg1)
+}
+
+// GetHouseAccount mocks base method.
+func (m *MockStore) GetHouseAccount(arg0 context.Context, arg1 db.GetHouseAccountParams) (db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHouseAccount", arg0, arg1)
+	ret0, _ := ret[0].(db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHouseAccount indicates an expected call of GetHouseAccount.
+func (mr *MockStoreMockRecorder) GetHouseAccount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHouseAccount", reflect.TypeOf((*MockStore)(nil).GetHouseAccount), arg0, arg1)
+}
+
+// GetIdempotencyKey mocks base method.
+func (m *MockStore) GetIdempotencyKey(arg0 context.Context, arg1 db.GetIdempotencyKeyParams) (db.IdempotencyKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIdempotencyKey", arg0, arg1)
+	ret0, _ := ret[0].(db.IdempotencyKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIdempotencyKey indicates an expected call of GetIdempotencyKey.
+func (mr *MockStoreMockRecorder) GetIdempotencyKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIdempotencyKey", reflect.TypeOf((*MockStore)(nil).GetIdempotencyKey), arg0, arg1)
+}
+
+// GetInterestRate mocks base method.
+func (m *MockStore) GetInterestRate(arg0 context.Context, arg1 db.GetInterestRateParams) (db.InterestRate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInterestRate", arg0, arg1)
+	ret0, _ := ret[0].(db.InterestRate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInterestRate indicates an expected call of GetInterestRate.
+func (mr *MockStoreMockRecorder) GetInterestRate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInterestRate", reflect.TypeOf((*MockStore)(nil).GetInterestRate), arg0, arg1)
+}
+
+// GetJournal mocks base method.
+func (m *MockStore) GetJournal(arg0 context.Context, arg1 int64) (db.Journal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJournal", arg0, arg1)
+	ret0, _ := ret[0].(db.Journal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJournal indicates an expected call of GetJournal.
+func (mr *MockStoreMockRecorder) GetJournal(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJournal", reflect.TypeOf((*MockStore)(nil).GetJournal), arg0, arg1)
+}
+
+// GetLastAccountStatusChange mocks base method.
+func (m *MockStore) GetLastAccountStatusChange(arg0 context.Context, arg1 int64) (db.AccountStatusChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLastAccountStatusChange", arg0, arg1)
+	ret0, _ := ret[0].(db.AccountStatusChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLastAccountStatusChange indicates an expected call of GetLastAccountStatusChange.
+func (mr *MockStoreMockRecorder) GetLastAccountStatusChange(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLastAccountStatusChange", reflect.TypeOf((*MockStore)(nil).GetLastAccountStatusChange), arg0, arg1)
+}
+
+// GetLastInterestPosting mocks base method.
+func (m *MockStore) GetLastInterestPosting(arg0 context.Context, arg1 int64) (db.InterestPosting, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLastInterestPosting", arg0, arg1)
+	ret0, _ := ret[0].(db.InterestPosting)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLastInterestPosting indicates an expected call of GetLastInterestPosting.
+func (mr *MockStoreMockRecorder) GetLastInterestPosting(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLastInterestPosting", reflect.TypeOf((*MockStore)(nil).GetLastInterestPosting), arg0, arg1)
+}
+
+// GetScheduledTransfer mocks base method.
+func (m *MockStore) GetScheduledTransfer(arg0 context.Context, arg1 int64) (db.ScheduledTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetScheduledTransfer", arg0, arg1)
+	ret0, _ := ret[0].(db.ScheduledTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetScheduledTransfer indicates an expected call of GetScheduledTransfer.
+func (mr *MockStoreMockRecorder) GetScheduledTransfer(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetScheduledTransfer", reflect.TypeOf((*MockStore)(nil).GetScheduledTransfer), arg0, arg1)
+}
+
+// GetSession mocks base method.
+func (m *MockStore) GetSession(arg0 context.Context, arg1 uuid.UUID) (db.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSession", arg0, arg1)
+	ret0, _ := ret[0].(db.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSession indicates an expected call of GetSession.
+func (mr *MockStoreMockRecorder) GetSession(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSession", reflect.TypeOf((*MockStore)(nil).GetSession), arg0, arg1)
+}
+
+// GetSessionForUpdate mocks base method.
+func (m *MockStore) GetSessionForUpdate(arg0 context.Context, arg1 uuid.UUID) (db.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSessionForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(db.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSessionForUpdate indicates an expected call of GetSessionForUpdate.
+func (mr *MockStoreMockRecorder) GetSessionForUpdate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSessionForUpdate", reflect.TypeOf((*MockStore)(nil).GetSessionForUpdate), arg0, arg1)
+}
+
+// GetStatement mocks base method.
+func (m *MockStore) GetStatement(arg0 context.Context, arg1 db.GetStatementParams) (db.Statement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStatement", arg0, arg1)
+	ret0, _ := ret[0].(db.Statement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStatement indicates an expected call of GetStatement.
+func (mr *MockStoreMockRecorder) GetStatement(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStatement", reflect.TypeOf((*MockStore)(nil).GetStatement), arg0, arg1)
+}
+
+// GetTotpSecret mocks base method.
+func (m *MockStore) GetTotpSecret(arg0 context.Context, arg1 string) (db.TotpSecret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTotpSecret", arg0, arg1)
+	ret0, _ := ret[0].(db.TotpSecret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTotpSecret indicates an expected call of GetTotpSecret.
+func (mr *MockStoreMockRecorder) GetTotpSecret(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTotpSecret", reflect.TypeOf((*MockStore)(nil).GetTotpSecret), arg0, arg1)
+}
+
+// GetTransfer mocks base method.
+func (m *MockStore) GetTransfer(arg0 context.Context, arg1 int64) (db.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransfer", arg0, arg1)
+	ret0, _ := ret[0].(db.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransfer indicates an expected call of GetTransfer.
+func (mr *MockStoreMockRecorder) GetTransfer(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransfer", reflect.TypeOf((*MockStore)(nil).GetTransfer), arg0, arg1)
+}
+
+// GetTransferForUpdate mocks base method.
+func (m *MockStore) GetTransferForUpdate(arg0 context.Context, arg1 int64) (db.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransferForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(db.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransferForUpdate indicates an expected call of GetTransferForUpdate.
+func (mr *MockStoreMockRecorder) GetTransferForUpdate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransferForUpdate", reflect.TypeOf((*MockStore)(nil).GetTransferForUpdate), arg0, arg1)
+}
+
+// GetTransferLimit mocks base method.
+func (m *MockStore) GetTransferLimit(arg0 context.Context, arg1 db.GetTransferLimitParams) (db.TransferLimit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransferLimit", arg0, arg1)
+	ret0, _ := ret[0].(db.TransferLimit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransferLimit indicates an expected call of GetTransferLimit.
+func (mr *MockStoreMockRecorder) GetTransferLimit(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransferLimit", reflect.TypeOf((*MockStore)(nil).GetTransferLimit), arg0, arg1)
+}
+
+// GetUser mocks base method.
+func (m *MockStore) GetUser(arg0 context.Context, arg1 string) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUser", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUser indicates an expected call of GetUser.
+func (mr *MockStoreMockRecorder) GetUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockStore)(nil).GetUser), arg0, arg1)
+}
+
+// GetUserForUpdate mocks base method.
+func (m *MockStore) GetUserForUpdate(arg0 context.Context, arg1 string) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserForUpdate indicates an expected call of GetUserForUpdate.
+func (mr *MockStoreMockRecorder) GetUserForUpdate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserForUpdate", reflect.TypeOf((*MockStore)(nil).GetUserForUpdate), arg0, arg1)
+}
+
+// GetWithdraw mocks base method.
+func (m *MockStore) GetWithdraw(arg0 context.Context, arg1 int64) (db.Withdraw, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWithdraw", arg0, arg1)
+	ret0, _ := ret[0].(db.Withdraw)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWithdraw indicates an expected call of GetWithdraw.
+func (mr *MockStoreMockRecorder) GetWithdraw(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWithdraw", reflect.TypeOf((*MockStore)(nil).GetWithdraw), arg0, arg1)
+}
+
+// ListAccountMembers mocks base method.
+func (m *MockStore) ListAccountMembers(arg0 context.Context, arg1 int64) ([]db.AccountMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccountMembers", arg0, arg1)
+	ret0, _ := ret[0].([]db.AccountMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccountMembers indicates an expected call of ListAccountMembers.
+func (mr *MockStoreMockRecorder) ListAccountMembers(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccountMembers", reflect.TypeOf((*MockStore)(nil).ListAccountMembers), arg0, arg1)
+}
+
+// ListAccountStatusChanges mocks base method.
+func (m *MockStore) ListAccountStatusChanges(arg0 context.Context, arg1 db.ListAccountStatusChangesParams) ([]db.AccountStatusChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccountStatusChanges", arg0, arg1)
+	ret0, _ := ret[0].([]db.AccountStatusChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccountStatusChanges indicates an expected call of ListAccountStatusChanges.
+func (mr *MockStoreMockRecorder) ListAccountStatusChanges(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccountStatusChanges", reflect.TypeOf((*MockStore)(nil).ListAccountStatusChanges), arg0, arg1)
+}
+
+// ListAccounts mocks base method.
+func (m *MockStore) ListAccounts(arg0 context.Context, arg1 db.ListAccountsParams) ([]db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccounts", arg0, arg1)
+	ret0, _ := ret[0].([]db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccounts indicates an expected call of ListAccounts.
+func (mr *MockStoreMockRecorder) ListAccounts(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccounts", reflect.TypeOf((*MockStore)(nil).ListAccounts), arg0, arg1)
+}
+
+// ListAccountsWithUnpostedInterest mocks base method.
+func (m *MockStore) ListAccountsWithUnpostedInterest(arg0 context.Context, arg1 time.Time) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccountsWithUnpostedInterest", arg0, arg1)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccountsWithUnpostedInterest indicates an expected call of ListAccountsWithUnpostedInterest.
+func (mr *MockStoreMockRecorder) ListAccountsWithUnpostedInterest(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccountsWithUnpostedInterest", reflect.TypeOf((*MockStore)(nil).ListAccountsWithUnpostedInterest), arg0, arg1)
+}
+
+// ListAccountsWithoutStatement mocks base method.
+func (m *MockStore) ListAccountsWithoutStatement(arg0 context.Context, arg1 db.ListAccountsWithoutStatementParams) ([]db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccountsWithoutStatement", arg0, arg1)
+	ret0, _ := ret[0].([]db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccountsWithoutStatement indicates an expected call of ListAccountsWithoutStatement.
+func (mr *MockStoreMockRecorder) ListAccountsWithoutStatement(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccountsWithoutStatement", reflect.TypeOf((*MockStore)(nil).ListAccountsWithoutStatement), arg0, arg1)
+}
+
+// ListActiveSessions mocks base method.
+func (m *MockStore) ListActiveSessions(arg0 context.Context, arg1 db.ListActiveSessionsParams) ([]db.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListActiveSessions", arg0, arg1)
+	ret0, _ := ret[0].([]db.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListActiveSessions indicates an expected call of ListActiveSessions.
+func (mr *MockStoreMockRecorder) ListActiveSessions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListActiveSessions", reflect.TypeOf((*MockStore)(nil).ListActiveSessions), arg0, arg1)
+}
+
+// ListBalanceMismatches mocks base method.
+func (m *MockStore) ListBalanceMismatches(arg0 context.Context, arg1 int32) ([]db.ListBalanceMismatchesRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBalanceMismatches", arg0, arg1)
+	ret0, _ := ret[0].([]db.ListBalanceMismatchesRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBalanceMismatches indicates an expected call of ListBalanceMismatches.
+func (mr *MockStoreMockRecorder) ListBalanceMismatches(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBalanceMismatches", reflect.TypeOf((*MockStore)(nil).ListBalanceMismatches), arg0, arg1)
+}
+
+// ListCurrencyImbalances mocks base method.
+func (m *MockStore) ListCurrencyImbalances(arg0 context.Context) ([]db.ListCurrencyImbalancesRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCurrencyImbalances", arg0)
+	ret0, _ := ret[0].([]db.ListCurrencyImbalancesRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCurrencyImbalances indicates an expected call of ListCurrencyImbalances.
+func (mr *MockStoreMockRecorder) ListCurrencyImbalances(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCurrencyImbalances", reflect.TypeOf((*MockStore)(nil).ListCurrencyImbalances), arg0)
+}
+
+// ListDepositEntryMismatches mocks base method.
+func (m *MockStore) ListDepositEntryMismatches(arg0 context.Context, arg1 int32) ([]db.ListDepositEntryMismatchesRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDepositEntryMismatches", arg0, arg1)
+	ret0, _ := ret[0].([]db.ListDepositEntryMismatchesRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDepositEntryMismatches indicates an expected call of ListDepositEntryMismatches.
+func (mr *MockStoreMockRecorder) ListDepositEntryMismatches(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDepositEntryMismatches", reflect.TypeOf((*MockStore)(nil).ListDepositEntryMismatches), arg0, arg1)
+}
+
+// ListDeposits mocks base method.
+func (m *MockStore) ListDeposits(arg0 context.Context, arg1 db.ListDepositsParams) ([]db.Deposit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDeposits", arg0, arg1)
+	ret0, _ := ret[0].([]db.Deposit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDeposits indicates an expected call of ListDeposits.
+func (mr *MockStoreMockRecorder) ListDeposits(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeposits", reflect.TypeOf((*MockStore)(nil).ListDeposits), arg0, arg1)
+}
+
+// ListEntries mocks base method.
+func (m *MockStore) ListEntries(arg0 context.Context, arg1 db.ListEntriesParams) ([]db.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListEntries", arg0, arg1)
+	ret0, _ := ret[0].([]db.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListEntries indicates an expected call of ListEntries.
+func (mr *MockStoreMockRecorder) ListEntries(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEntries", reflect.TypeOf((*MockStore)(nil).ListEntries), arg0, arg1)
+}
+
+// ListExpiredHolds mocks base method.
+func (m *MockStore) ListExpiredHolds(arg0 context.Context, arg1 int32) ([]db.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListExpiredHolds", arg0, arg1)
+	ret0, _ := ret[0].([]db.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListExpiredHolds indicates an expected call of ListExpiredHolds.
+func (mr *MockStoreMockRecorder) ListExpiredHolds(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListExpiredHolds", reflect.TypeOf((*MockStore)(nil).ListExpiredHolds), arg0, arg1)
+}
+
+// ListHolds mocks base method.
+func (m *MockStore) ListHolds(arg0 context.Context, arg1 db.ListHoldsParams) ([]db.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListHolds", arg0, arg1)
+	ret0, _ := ret[0].([]db.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListHolds indicates an expected call of ListHolds.
+func (mr *MockStoreMockRecorder) ListHolds(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListHolds", reflect.TypeOf((*MockStore)(nil).ListHolds), arg0, arg1)
+}
+
+// ListInterestAccruals mocks base method.
+func (m *MockStore) ListInterestAccruals(arg0 context.Context, arg1 db.ListInterestAccrualsParams) ([]db.InterestAccrual, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListInterestAccruals", arg0, arg1)
+	ret0, _ := ret[0].([]db.InterestAccrual)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListInterestAccruals indicates an expected call of ListInterestAccruals.
+func (mr *MockStoreMockRecorder) ListInterestAccruals(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListInterestAccruals", reflect.TypeOf((*MockStore)(nil).ListInterestAccruals), arg0, arg1)
+}
+
+// ListJournalEntries mocks base method.
+func (m *MockStore) ListJournalEntries(arg0 context.Context, arg1 int64) ([]db.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListJournalEntries", arg0, arg1)
+	ret0, _ := ret[0].([]db.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListJournalEntries indicates an expected call of ListJournalEntries.
+func (mr *MockStoreMockRecorder) ListJournalEntries(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListJournalEntries", reflect.TypeOf((*MockStore)(nil).ListJournalEntries), arg0, arg1)
+}
+
+// ListLedgerEntries mocks base method.
+func (m *MockStore) ListLedgerEntries(arg0 context.Context, arg1 db.ListLedgerEntriesParams) ([]db.ListLedgerEntriesRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListLedgerEntries", arg0, arg1)
+	ret0, _ := ret[0].([]db.ListLedgerEntriesRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListLedgerEntries indicates an expected call of ListLedgerEntries.
+func (mr *MockStoreMockRecorder) ListLedgerEntries(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListLedgerEntries", reflect.TypeOf((*MockStore)(nil).ListLedgerEntries), arg0, arg1)
+}
+
+// ListScheduledTransferRuns mocks base method.
+func (m *MockStore) ListScheduledTransferRuns(arg0 context.Context, arg1 db.ListScheduledTransferRunsParams) ([]db.ScheduledTransferRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListScheduledTransferRuns", arg0, arg1)
+	ret0, _ := ret[0].([]db.ScheduledTransferRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListScheduledTransferRuns indicates an expected call of ListScheduledTransferRuns.
+func (mr *MockStoreMockRecorder) ListScheduledTransferRuns(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListScheduledTransferRuns", reflect.TypeOf((*MockStore)(nil).ListScheduledTransferRuns), arg0, arg1)
+}
+
+// ListScheduledTransfers mocks base method.
+func (m *MockStore) ListScheduledTransfers(arg0 context.Context, arg1 db.ListScheduledTransfersParams) ([]db.ScheduledTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListScheduledTransfers", arg0, arg1)
+	ret0, _ := ret[0].([]db.ScheduledTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListScheduledTransfers indicates an expected call of ListScheduledTransfers.
+func (mr *MockStoreMockRecorder) ListScheduledTransfers(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListScheduledTransfers", reflect.TypeOf((*MockStore)(nil).ListScheduledTransfers), arg0, arg1)
+}
+
+// ListSecurityEvents mocks base method.
+func (m *MockStore) ListSecurityEvents(arg0 context.Context, arg1 db.ListSecurityEventsParams) ([]db.SecurityEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSecurityEvents", arg0, arg1)
+	ret0, _ := ret[0].([]db.SecurityEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSecurityEvents indicates an expected call of ListSecurityEvents.
+func (mr *MockStoreMockRecorder) ListSecurityEvents(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSecurityEvents", reflect.TypeOf((*MockStore)(nil).ListSecurityEvents), arg0, arg1)
+}
+
+// ListStatementEntries mocks base method.
+func (m *MockStore) ListStatementEntries(arg0 context.Context, arg1 db.ListStatementEntriesParams) ([]db.ListStatementEntriesRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListStatementEntries", arg0, arg1)
+	ret0, _ := ret[0].([]db.ListStatementEntriesRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListStatementEntries indicates an expected call of ListStatementEntries.
+func (mr *MockStoreMockRecorder) ListStatementEntries(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListStatementEntries", reflect.TypeOf((*MockStore)(nil).ListStatementEntries), arg0, arg1)
+}
+
+// ListTransferEntryMismatches mocks base method.
+func (m *MockStore) ListTransferEntryMismatches(arg0 context.Context, arg1 int32) ([]db.ListTransferEntryMismatchesRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTransferEntryMismatches", arg0, arg1)
+	ret0, _ := ret[0].([]db.ListTransferEntryMismatchesRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTransferEntryMismatches indicates an expected call of ListTransferEntryMismatches.
+func (mr *MockStoreMockRecorder) ListTransferEntryMismatches(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTransferEntryMismatches", reflect.TypeOf((*MockStore)(nil).ListTransferEntryMismatches), arg0, arg1)
+}
+
+// ListTransfers mocks base method.
+func (m *MockStore) ListTransfers(arg0 context.Context, arg1 db.ListTransfersParams) ([]db.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTransfers", arg0, arg1)
+	ret0, _ := ret[0].([]db.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTransfers indicates an expected call of ListTransfers.
+func (mr *MockStoreMockRecorder) ListTransfers(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTransfers", reflect.TypeOf((*MockStore)(nil).ListTransfers), arg0, arg1)
+}
+
+// ListUnbalancedJournals mocks base method.
+func (m *MockStore) ListUnbalancedJournals(arg0 context.Context, arg1 int32) ([]db.ListUnbalancedJournalsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUnbalancedJournals", arg0, arg1)
+	ret0, _ := ret[0].([]db.ListUnbalancedJournalsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUnbalancedJournals indicates an expected call of ListUnbalancedJournals.
+func (mr *MockStoreMockRecorder) ListUnbalancedJournals(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUnbalancedJournals", reflect.TypeOf((*MockStore)(nil).ListUnbalancedJournals), arg0, arg1)
+}
+
+// ListWithdrawEntryMismatches mocks base method.
+func (m *MockStore) ListWithdrawEntryMismatches(arg0 context.Context, arg1 int32) ([]db.ListWithdrawEntryMismatchesRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWithdrawEntryMismatches", arg0, arg1)
+	ret0, _ := ret[0].([]db.ListWithdrawEntryMismatchesRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWithdrawEntryMismatches indicates an expected call of ListWithdrawEntryMismatches.
+func (mr *MockStoreMockRecorder) ListWithdrawEntryMismatches(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWithdrawEntryMismatches", reflect.TypeOf((*MockStore)(nil).ListWithdrawEntryMismatches), arg0, arg1)
+}
+
+// ListWithdraws mocks base method.
+func (m *MockStore) ListWithdraws(arg0 context.Context, arg1 db.ListWithdrawsParams) ([]db.Withdraw, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWithdraws", arg0, arg1)
+	ret0, _ := ret[0].([]db.Withdraw)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWithdraws indicates an expected call of ListWithdraws.
+func (mr *MockStoreMockRecorder) ListWithdraws(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWithdraws", reflect.TypeOf((*MockStore)(nil).ListWithdraws), arg0, arg1)
+}
+
+// MarkInterestPosted mocks base method.
+func (m *MockStore) MarkInterestPosted(arg0 context.Context, arg1 db.MarkInterestPostedParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkInterestPosted", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkInterestPosted indicates an expected call of MarkInterestPosted.
+func (mr *MockStoreMockRecorder) MarkInterestPosted(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkInterestPosted", reflect.TypeOf((*MockStore)(nil).MarkInterestPosted), arg0, arg1)
+}
+
+// PauseScheduledTransfer mocks base method.
+func (m *MockStore) PauseScheduledTransfer(arg0 context.Context, arg1 int64) (db.ScheduledTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PauseScheduledTransfer", arg0, arg1)
+	ret0, _ := ret[0].(db.ScheduledTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PauseScheduledTransfer indicates an expected call of PauseScheduledTransfer.
+func (mr *MockStoreMockRecorder) PauseScheduledTransfer(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PauseScheduledTransfer", reflect.TypeOf((*MockStore)(nil).PauseScheduledTransfer), arg0, arg1)
+}
+
+// PostInterestTx mocks base method.
+func (m *MockStore) PostInterestTx(arg0 context.Context, arg1 db.PostInterestTxParams) (db.PostInterestTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PostInterestTx", arg0, arg1)
+	ret0, _ := ret[0].(db.PostInterestTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PostInterestTx indicates an expected call of PostInterestTx.
+func (mr *MockStoreMockRecorder) PostInterestTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostInterestTx", reflect.TypeOf((*MockStore)(nil).PostInterestTx), arg0, arg1)
+}
+
+// PreviewTransferTx mocks base method.
+func (m *MockStore) PreviewTransferTx(arg0 context.Context, arg1 db.TransferTxParams) (db.TransferTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PreviewTransferTx", arg0, arg1)
+	ret0, _ := ret[0].(db.TransferTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PreviewTransferTx indicates an expected call of PreviewTransferTx.
+func (mr *MockStoreMockRecorder) PreviewTransferTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PreviewTransferTx", reflect.TypeOf((*MockStore)(nil).PreviewTransferTx), arg0, arg1)
+}
+
+// RecordTotpFailure mocks base method.
+func (m *MockStore) RecordTotpFailure(arg0 context.Context, arg1 db.RecordTotpFailureParams) (db.TotpSecret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordTotpFailure", arg0, arg1)
+	ret0, _ := ret[0].(db.TotpSecret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordTotpFailure indicates an expected call of RecordTotpFailure.
+func (mr *MockStoreMockRecorder) RecordTotpFailure(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordTotpFailure", reflect.TypeOf((*MockStore)(nil).RecordTotpFailure), arg0, arg1)
+}
+
+// ReplaceRecoveryCodesTx mocks base method.
+func (m *MockStore) ReplaceRecoveryCodesTx(arg0 context.Context, arg1 db.RecoveryCodesTxParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceRecoveryCodesTx", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplaceRecoveryCodesTx indicates an expected call of ReplaceRecoveryCodesTx.
+func (mr *MockStoreMockRecorder) ReplaceRecoveryCodesTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceRecoveryCodesTx", reflect.TypeOf((*MockStore)(nil).ReplaceRecoveryCodesTx), arg0, arg1)
+}
+
+// ResetTotpFailures mocks base method.
+func (m *MockStore) ResetTotpFailures(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetTotpFailures", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetTotpFailures indicates an expected call of ResetTotpFailures.
+func (mr *MockStoreMockRecorder) ResetTotpFailures(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetTotpFailures", reflect.TypeOf((*MockStore)(nil).ResetTotpFailures), arg0, arg1)
+}
+
+// ResumeScheduledTransfer mocks base method.
+func (m *MockStore) ResumeScheduledTransfer(arg0 context.Context, arg1 int64) (db.ScheduledTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResumeScheduledTransfer", arg0, arg1)
+	ret0, _ := ret[0].(db.ScheduledTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResumeScheduledTransfer indicates an expected call of ResumeScheduledTransfer.
+func (mr *MockStoreMockRecorder) ResumeScheduledTransfer(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResumeScheduledTransfer", reflect.TypeOf((*MockStore)(nil).ResumeScheduledTransfer), arg0, arg1)
+}
+
+// ReverseTransferTx mocks base method.
+func (m *MockStore) ReverseTransferTx(arg0 context.Context, arg1 db.ReverseTransferTxParams) (db.ReverseTransferTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReverseTransferTx", arg0, arg1)
+	ret0, _ := ret[0].(db.ReverseTransferTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReverseTransferTx indicates an expected call of ReverseTransferTx.
+func (mr *MockStoreMockRecorder) ReverseTransferTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReverseTransferTx", reflect.TypeOf((*MockStore)(nil).ReverseTransferTx), arg0, arg1)
+}
+
+// RotateSession mocks base method.
+func (m *MockStore) RotateSession(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateSession", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RotateSession indicates an expected call of RotateSession.
+func (mr *MockStoreMockRecorder) RotateSession(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateSession", reflect.TypeOf((*MockStore)(nil).RotateSession), arg0, arg1)
+}
+
+// RotateSessionTx mocks base method.
+func (m *MockStore) RotateSessionTx(arg0 context.Context, arg1 db.RotateSessionTxParams) (db.RotateSessionTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateSessionTx", arg0, arg1)
+	ret0, _ := ret[0].(db.RotateSessionTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RotateSessionTx indicates an expected call of RotateSessionTx.
+func (mr *MockStoreMockRecorder) RotateSessionTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateSessionTx", reflect.TypeOf((*MockStore)(nil).RotateSessionTx), arg0, arg1)
+}
+
+// SumUnpostedInterest mocks base method.
+func (m *MockStore) SumUnpostedInterest(arg0 context.Context, arg1 db.SumUnpostedInterestParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SumUnpostedInterest", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SumUnpostedInterest indicates an expected call of SumUnpostedInterest.
+func (mr *MockStoreMockRecorder) SumUnpostedInterest(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SumUnpostedInterest", reflect.TypeOf((*MockStore)(nil).SumUnpostedInterest), arg0, arg1)
+}
+
+// TransferTx mocks base method.
+func (m *MockStore) TransferTx(arg0 context.Context, arg1 db.TransferTxParams) (db.TransferTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TransferTx", arg0, arg1)
+	ret0, _ := ret[0].(db.TransferTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TransferTx indicates an expected call of TransferTx.
+func (mr *MockStoreMockRecorder) TransferTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransferTx", reflect.TypeOf((*MockStore)(nil).TransferTx), arg0, arg1)
+}
+
+// UpdateAccount mocks base method.
+func (m *MockStore) UpdateAccount(arg0 context.Context, arg1 db.UpdateAccountParams) (db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAccount", arg0, arg1)
+	ret0, _ := ret[0].(db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateAccount indicates an expected call of UpdateAccount.
+func (mr *MockStoreMockRecorder) UpdateAccount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAccount", reflect.TypeOf((*MockStore)(nil).UpdateAccount), arg0, arg1)
+}
+
+// UpdateAccountDetails mocks base method.
+func (m *MockStore) UpdateAccountDetails(arg0 context.Context, arg1 db.UpdateAccountDetailsParams) (db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAccountDetails", arg0, arg1)
+	ret0, _ := ret[0].(db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateAccountDetails indicates an expected call of UpdateAccountDetails.
+func (mr *MockStoreMockRecorder) UpdateAccountDetails(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAccountDetails", reflect.TypeOf((*MockStore)(nil).UpdateAccountDetails), arg0, arg1)
+}
+
+// UpdateAccountOverdraftLimit mocks base method.
+func (m *MockStore) UpdateAccountOverdraftLimit(arg0 context.Context, arg1 db.UpdateAccountOverdraftLimitParams) (db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAccountOverdraftLimit", arg0, arg1)
+	ret0, _ := ret[0].(db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateAccountOverdraftLimit indicates an expected call of UpdateAccountOverdraftLimit.
+func (mr *MockStoreMockRecorder) UpdateAccountOverdraftLimit(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAccountOverdraftLimit", reflect.TypeOf((*MockStore)(nil).UpdateAccountOverdraftLimit), arg0, arg1)
+}
+
+// UpdateAccountStatus mocks base method.
+func (m *MockStore) UpdateAccountStatus(arg0 context.Context, arg1 db.UpdateAccountStatusParams) (db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAccountStatus", arg0, arg1)
+	ret0, _ := ret[0].(db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateAccountStatus indicates an expected call of UpdateAccountStatus.
+func (mr *MockStoreMockRecorder) UpdateAccountStatus(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAccountStatus", reflect.TypeOf((*MockStore)(nil).UpdateAccountStatus), arg0, arg1)
+}
+
+// UpdateIdempotencyKeyResponse mocks base method.
+func (m *MockStore) UpdateIdempotencyKeyResponse(arg0 context.Context, arg1 db.UpdateIdempotencyKeyResponseParams) (db.IdempotencyKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateIdempotencyKeyResponse", arg0, arg1)
+	ret0, _ := ret[0].(db.IdempotencyKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateIdempotencyKeyResponse indicates an expected call of UpdateIdempotencyKeyResponse.
+func (mr *MockStoreMockRecorder) UpdateIdempotencyKeyResponse(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateIdempotencyKeyResponse", reflect.TypeOf((*MockStore)(nil).UpdateIdempotencyKeyResponse), arg0, arg1)
+}
+
+// UpdateScheduledTransferRun mocks base method.
+func (m *MockStore) UpdateScheduledTransferRun(arg0 context.Context, arg1 db.UpdateScheduledTransferRunParams) (db.ScheduledTransferRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateScheduledTransferRun", arg0, arg1)
+	ret0, _ := ret[0].(db.ScheduledTransferRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateScheduledTransferRun indicates an expected call of UpdateScheduledTransferRun.
+func (mr *MockStoreMockRecorder) UpdateScheduledTransferRun(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateScheduledTransferRun", reflect.TypeOf((*MockStore)(nil).UpdateScheduledTransferRun), arg0, arg1)
+}
+
+// UpdateUserRole mocks base method.
+func (m *MockStore) UpdateUserRole(arg0 context.Context, arg1 db.UpdateUserRoleParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserRole", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserRole indicates an expected call of UpdateUserRole.
+func (mr *MockStoreMockRecorder) UpdateUserRole(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserRole", reflect.TypeOf((*MockStore)(nil).UpdateUserRole), arg0, arg1)
+}
+
+// UpdateUserRoleTx mocks base method.
+func (m *MockStore) UpdateUserRoleTx(arg0 context.Context, arg1 db.UpdateUserRoleParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserRoleTx", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserRoleTx indicates an expected call of UpdateUserRoleTx.
+func (mr *MockStoreMockRecorder) UpdateUserRoleTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserRoleTx", reflect.TypeOf((*MockStore)(nil).UpdateUserRoleTx), arg0, arg1)
+}
+
+// UpdateUserTier mocks base method.
+func (m *MockStore) UpdateUserTier(arg0 context.Context, arg1 db.UpdateUserTierParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserTier", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserTier indicates an expected call of UpdateUserTier.
+func (mr *MockStoreMockRecorder) UpdateUserTier(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserTier", reflect.TypeOf((*MockStore)(nil).UpdateUserTier), arg0, arg1)
+}
+
+// UpsertTotpSecret mocks base method.
+func (m *MockStore) UpsertTotpSecret(arg0 context.Context, arg1 db.UpsertTotpSecretParams) (db.TotpSecret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertTotpSecret", arg0, arg1)
+	ret0, _ := ret[0].(db.TotpSecret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertTotpSecret indicates an expected call of UpsertTotpSecret.
+func (mr *MockStoreMockRecorder) UpsertTotpSecret(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertTotpSecret", reflect.TypeOf((*MockStore)(nil).UpsertTotpSecret), arg0, arg1)
+}
+
+// UseFxQuote mocks base method.
+func (m *MockStore) UseFxQuote(arg0 context.Context, arg1 int64) (db.FxQuote, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseFxQuote", arg0, arg1)
+	ret0, _ := ret[0].(db.FxQuote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseFxQuote indicates an expected call of UseFxQuote.
+func (mr *MockStoreMockRecorder) UseFxQuote(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseFxQuote", reflect.TypeOf((*MockStore)(nil).UseFxQuote), arg0, arg1)
+}
+
+// UseRecoveryCode mocks base method.
+func (m *MockStore) UseRecoveryCode(arg0 context.Context, arg1 db.UseRecoveryCodeParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRecoveryCode", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseRecoveryCode indicates an expected call of UseRecoveryCode.
+func (mr *MockStoreMockRecorder) UseRecoveryCode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRecoveryCode", reflect.TypeOf((*MockStore)(nil).UseRecoveryCode), arg0, arg1)
+}
+
+// UseTotpStep mocks base method.
+func (m *MockStore) UseTotpStep(arg0 context.Context, arg1 db.UseTotpStepParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseTotpStep", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseTotpStep indicates an expected call of UseTotpStep.
+func (mr *MockStoreMockRecorder) UseTotpStep(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseTotpStep", reflect.TypeOf((*MockStore)(nil).UseTotpStep), arg0, arg1)
+}
+
+// VoidHoldTx mocks base method.
+func (m *MockStore) VoidHoldTx(arg0 context.Context, arg1 int64) (db.HoldTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VoidHoldTx", arg0, arg1)
+	ret0, _ := ret[0].(db.HoldTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VoidHoldTx indicates an expected call of VoidHoldTx.
+func (mr *MockStoreMockRecorder) VoidHoldTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VoidHoldTx", reflect.TypeOf((*MockStore)(nil).VoidHoldTx), arg0, arg1)
+}
+
+// WithdrawTx mocks base method.
+func (m *MockStore) WithdrawTx(arg0 context.Context, arg1 db.WithdrawTxParams) (db.WithdrawTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithdrawTx", arg0, arg1)
+	ret0, _ := ret[0].(db.WithdrawTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WithdrawTx indicates an expected call of WithdrawTx.
+func (mr *MockStoreMockRecorder) WithdrawTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithdrawTx", reflect.TypeOf((*MockStore)(nil).WithdrawTx), arg0, arg1)
 }
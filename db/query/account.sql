@@ -2,9 +2,12 @@
 INSERT INTO accounts (
   owner,
   balance,
-  currency
+  currency,
+  type,
+  name,
+  description
 ) VALUES (
-  $1, $2, $3
+  $1, $2, $3, $4, $5, $6
 ) RETURNING *;
 
 -- name: GetAccount :one
@@ -17,11 +20,20 @@ WHERE id = $1 LIMIT 1
 FOR NO KEY UPDATE;
 
 -- name: ListAccounts :many
+-- Accounts the user owns or has been invited to, the name matching any part of it regardless of case
 SELECT * FROM accounts
-WHERE owner = $1
+WHERE (
+    owner = sqlc.arg(username)
+    OR id IN (
+      SELECT account_id FROM account_members
+      WHERE username = sqlc.arg(username)
+    )
+  )
+  AND (sqlc.narg(currency)::varchar IS NULL OR currency = sqlc.narg(currency))
+  AND (sqlc.narg(name)::varchar IS NULL OR name ILIKE '%' || sqlc.narg(name) || '%')
 ORDER BY id
-LIMIT $2
-OFFSET $3;
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
 
 -- name: UpdateAccount :one
 UPDATE accounts
@@ -35,6 +47,59 @@ SET balance = balance + sqlc.arg(amount)
 WHERE id = sqlc.arg(id)
 RETURNING *;
 
--- name: DeleteAccount :exec
-DELETE FROM accounts
-WHERE id = $1;
+-- name: AddAccountHeldAmount :one
+UPDATE accounts
+SET held_amount = held_amount + sqlc.arg(amount)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: UpdateAccountOverdraftLimit :one
+UPDATE accounts
+SET overdraft_limit = sqlc.arg(overdraft_limit)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: UpdateAccountDetails :one
+-- Fields which are null keep their value
+UPDATE accounts
+SET
+  name = COALESCE(sqlc.narg(name), name),
+  description = COALESCE(sqlc.narg(description), description)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: CountOpenAccounts :one
+SELECT COUNT(*) FROM accounts
+WHERE owner = $1 AND currency = $2 AND status <> 'closed';
+
+-- name: UpdateAccountStatus :one
+UPDATE accounts
+SET status = sqlc.arg(status)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: CreateAccountStatusChange :one
+INSERT INTO account_status_changes (
+  account_id,
+  from_status,
+  to_status,
+  changed_by,
+  reason,
+  payout_transfer_id,
+  by_staff
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+) RETURNING *;
+
+-- name: GetLastAccountStatusChange :one
+SELECT * FROM account_status_changes
+WHERE account_id = $1
+ORDER BY id DESC
+LIMIT 1;
+
+-- name: ListAccountStatusChanges :many
+SELECT * FROM account_status_changes
+WHERE account_id = $1
+ORDER BY id
+LIMIT $2
+OFFSET $3;
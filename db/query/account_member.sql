@@ -0,0 +1,23 @@
+-- name: CreateAccountMember :one
+INSERT INTO account_members (
+  account_id,
+  username,
+  role,
+  spend_limit,
+  invited_by
+) VALUES (
+  $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: GetAccountMember :one
+SELECT * FROM account_members
+WHERE account_id = $1 AND username = $2 LIMIT 1;
+
+-- name: ListAccountMembers :many
+SELECT * FROM account_members
+WHERE account_id = $1
+ORDER BY created_at, username;
+
+-- name: DeleteAccountMember :execrows
+DELETE FROM account_members
+WHERE account_id = $1 AND username = $2;
@@ -0,0 +1,53 @@
+-- name: CreateBalanceSnapshots :execrows
+-- Takes the balance every account had at the end of a day, skipping the accounts which have it already
+INSERT INTO balance_snapshots (
+  account_id,
+  snapshot_date,
+  balance
+)
+SELECT
+  accounts.id,
+  sqlc.arg(snapshot_date)::date,
+  accounts.balance - COALESCE(SUM(entries.amount), 0)::bigint
+FROM accounts
+LEFT JOIN entries
+  ON entries.account_id = accounts.id
+  AND entries.created_at >= (sqlc.arg(snapshot_date)::date + 1)::timestamp AT TIME ZONE 'UTC'
+WHERE accounts.created_at < (sqlc.arg(snapshot_date)::date + 1)::timestamp AT TIME ZONE 'UTC'
+GROUP BY accounts.id
+ON CONFLICT (account_id, snapshot_date) DO NOTHING;
+
+-- name: GetBalanceSnapshot :one
+SELECT * FROM balance_snapshots
+WHERE account_id = $1 AND snapshot_date = $2 LIMIT 1;
+
+-- name: GetAccountBalanceAt :one
+-- The balance of the account at a point in time is the last snapshot taken before it, plus the entries which came
+-- after the snapshot. Without a snapshot, it's the current balance minus the entries which came after that point
+WITH snapshot AS (
+  SELECT
+    balance_snapshots.balance,
+    (balance_snapshots.snapshot_date + 1)::timestamp AT TIME ZONE 'UTC' AS taken_at
+  FROM balance_snapshots
+  WHERE balance_snapshots.account_id = sqlc.arg(account_id)
+    AND balance_snapshots.snapshot_date < (sqlc.arg(at)::timestamptz AT TIME ZONE 'UTC')::date
+  ORDER BY balance_snapshots.snapshot_date DESC
+  LIMIT 1
+)
+SELECT (
+  CASE WHEN EXISTS (SELECT 1 FROM snapshot) THEN
+    (SELECT snapshot.balance FROM snapshot) + COALESCE((
+      SELECT SUM(entries.amount) FROM entries
+      WHERE entries.account_id = accounts.id
+        AND entries.created_at >= (SELECT snapshot.taken_at FROM snapshot)
+        AND entries.created_at < sqlc.arg(at)::timestamptz
+    ), 0)
+  ELSE
+    accounts.balance - COALESCE((
+      SELECT SUM(entries.amount) FROM entries
+      WHERE entries.account_id = accounts.id AND entries.created_at >= sqlc.arg(at)::timestamptz
+    ), 0)
+  END
+)::bigint AS balance
+FROM accounts
+WHERE accounts.id = sqlc.arg(account_id);
@@ -1,9 +1,12 @@
 -- name: CreateEntry :one
 INSERT INTO entries (
   account_id,
-  amount
+  amount,
+  source_type,
+  source_id,
+  journal_id
 ) VALUES (
-  $1, $2
+  $1, $2, $3, $4, $5
 ) RETURNING *;
 
 -- name: GetEntry :one
@@ -16,3 +19,14 @@ WHERE account_id = $1
 ORDER BY id
 LIMIT $2
 OFFSET $3;
+
+-- name: ListLedgerEntries :many
+SELECT
+  *,
+  -- the window is computed before paginating, so every page carries the right balance
+  SUM(amount) OVER (ORDER BY id)::bigint AS running_balance
+FROM entries
+WHERE account_id = $1
+ORDER BY id
+LIMIT $2
+OFFSET $3;
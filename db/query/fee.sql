@@ -0,0 +1,30 @@
+-- name: CreateFeeRule :one
+INSERT INTO fee_rules (
+  currency,
+  transfer_type,
+  min_amount,
+  flat_fee,
+  percentage_bps,
+  min_fee,
+  max_fee
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+) RETURNING *;
+
+-- name: GetFeeRule :one
+-- The rule of the highest tier the amount reaches
+SELECT * FROM fee_rules
+WHERE currency = sqlc.arg(currency)
+  AND transfer_type = sqlc.arg(transfer_type)
+  AND min_amount <= sqlc.arg(amount)
+ORDER BY min_amount DESC
+LIMIT 1;
+
+-- name: DeleteFeeRule :exec
+DELETE FROM fee_rules
+WHERE id = $1;
+
+-- name: GetHouseAccount :one
+SELECT accounts.* FROM accounts
+JOIN house_accounts ON house_accounts.account_id = accounts.id
+WHERE house_accounts.purpose = $1 AND house_accounts.currency = $2 LIMIT 1;
@@ -0,0 +1,25 @@
+-- name: CreateFxQuote :one
+INSERT INTO fx_quotes (
+  username,
+  from_currency,
+  to_currency,
+  from_amount,
+  to_amount,
+  market_rate,
+  spread_bps,
+  rate,
+  expires_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9
+) RETURNING *;
+
+-- name: GetFxQuote :one
+SELECT * FROM fx_quotes
+WHERE id = $1 LIMIT 1;
+
+-- name: UseFxQuote :one
+-- Only a valid quote which wasn't used yet is returned
+UPDATE fx_quotes
+SET used_at = now()
+WHERE id = $1 AND used_at IS NULL AND expires_at > now()
+RETURNING *;
@@ -0,0 +1,91 @@
+-- name: CreateInterestRate :one
+INSERT INTO interest_rates (
+  account_type,
+  currency,
+  annual_rate_bps
+) VALUES (
+  $1, $2, $3
+) RETURNING *;
+
+-- name: GetInterestRate :one
+SELECT * FROM interest_rates
+WHERE account_type = $1 AND currency = $2 LIMIT 1;
+
+-- name: DeleteInterestRate :exec
+DELETE FROM interest_rates
+WHERE account_type = $1 AND currency = $2;
+
+-- name: AccrueInterest :execrows
+-- Accrues the interest of a day for every account with an interest rate.
+-- The balance at the end of the day is the current one minus everything which came after,
+-- so the result doesn't depend on when the day is accrued, and days which were accrued already are skipped
+INSERT INTO interest_accruals (
+  account_id,
+  accrual_date,
+  balance,
+  annual_rate_bps,
+  amount
+)
+SELECT
+  accounts.id,
+  sqlc.arg(accrual_date)::date,
+  day_end.balance,
+  interest_rates.annual_rate_bps,
+  day_end.balance * interest_rates.annual_rate_bps
+FROM accounts
+JOIN interest_rates
+  ON interest_rates.account_type = accounts.type AND interest_rates.currency = accounts.currency
+CROSS JOIN LATERAL (
+  SELECT accounts.balance - COALESCE(SUM(entries.amount), 0)::bigint AS balance
+  FROM entries
+  WHERE entries.account_id = accounts.id
+    AND entries.created_at >= (sqlc.arg(accrual_date)::date + 1)::timestamp AT TIME ZONE 'UTC'
+) AS day_end
+WHERE accounts.created_at < (sqlc.arg(accrual_date)::date + 1)::timestamp AT TIME ZONE 'UTC'
+  AND accounts.status <> 'closed'
+  AND day_end.balance > 0
+ON CONFLICT (account_id, accrual_date) DO NOTHING;
+
+-- name: ListInterestAccruals :many
+SELECT * FROM interest_accruals
+WHERE account_id = $1
+ORDER BY accrual_date
+LIMIT $2
+OFFSET $3;
+
+-- name: ListAccountsWithUnpostedInterest :many
+-- Interest accrued by an account which was closed since is forfeited
+SELECT DISTINCT interest_accruals.account_id FROM interest_accruals
+JOIN accounts ON accounts.id = interest_accruals.account_id
+WHERE interest_accruals.posting_id IS NULL
+  AND interest_accruals.accrual_date < sqlc.arg(period_end)::date
+  AND accounts.status <> 'closed'
+ORDER BY interest_accruals.account_id;
+
+-- name: SumUnpostedInterest :one
+SELECT COALESCE(SUM(amount), 0)::bigint FROM interest_accruals
+WHERE account_id = sqlc.arg(account_id) AND posting_id IS NULL AND accrual_date < sqlc.arg(period_end)::date;
+
+-- name: MarkInterestPosted :execrows
+UPDATE interest_accruals
+SET posting_id = sqlc.arg(posting_id)
+WHERE account_id = sqlc.arg(account_id) AND posting_id IS NULL AND accrual_date < sqlc.arg(period_end)::date;
+
+-- name: CreateInterestPosting :one
+-- Nothing is returned if the period was already posted
+INSERT INTO interest_postings (
+  account_id,
+  period,
+  amount,
+  remainder
+) VALUES (
+  $1, $2, $3, $4
+)
+ON CONFLICT (account_id, period) DO NOTHING
+RETURNING *;
+
+-- name: GetLastInterestPosting :one
+SELECT * FROM interest_postings
+WHERE account_id = $1
+ORDER BY period DESC
+LIMIT 1;
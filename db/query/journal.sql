@@ -0,0 +1,40 @@
+-- name: CreateJournal :one
+INSERT INTO journals (
+  kind,
+  source_id
+) VALUES (
+  $1, $2
+) RETURNING *;
+
+-- name: GetJournal :one
+SELECT * FROM journals
+WHERE id = $1 LIMIT 1;
+
+-- name: ListJournalEntries :many
+SELECT * FROM entries
+WHERE journal_id = $1
+ORDER BY id;
+
+-- name: ListUnbalancedJournals :many
+-- Journals whose entries don't add up to zero in one of their currencies
+SELECT
+  entries.journal_id,
+  accounts.currency,
+  SUM(entries.amount)::bigint AS total
+FROM entries
+JOIN accounts ON accounts.id = entries.account_id
+GROUP BY entries.journal_id, accounts.currency
+HAVING SUM(entries.amount) <> 0
+ORDER BY entries.journal_id, accounts.currency
+LIMIT sqlc.arg('limit');
+
+-- name: ListCurrencyImbalances :many
+-- Currencies whose entries don't add up to zero
+SELECT
+  accounts.currency,
+  SUM(entries.amount)::bigint AS total
+FROM entries
+JOIN accounts ON accounts.id = entries.account_id
+GROUP BY accounts.currency
+HAVING SUM(entries.amount) <> 0
+ORDER BY accounts.currency;
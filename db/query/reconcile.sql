@@ -0,0 +1,67 @@
+-- name: ListBalanceMismatches :many
+-- Accounts whose balance isn't the sum of their entries
+SELECT
+  accounts.id AS account_id,
+  accounts.balance,
+  COALESCE(SUM(entries.amount), 0)::bigint AS entries_total
+FROM accounts
+LEFT JOIN entries ON entries.account_id = accounts.id
+GROUP BY accounts.id
+HAVING accounts.balance <> COALESCE(SUM(entries.amount), 0)
+ORDER BY accounts.id
+LIMIT sqlc.arg('limit');
+
+-- name: ListTransferEntryMismatches :many
+-- Transfers which don't have exactly one entry taking the amount out of the origin account
+-- and one entry putting it into the destination account. The lines of the house accounts are
+-- checked along with every other journal
+SELECT
+  transfers.id AS transfer_id,
+  COUNT(entries.id)::int AS entry_count,
+  COUNT(entries.id) FILTER (
+    WHERE (entries.account_id = transfers.from_account_id AND entries.amount = -transfers.amount)
+       OR (entries.account_id = transfers.to_account_id AND entries.amount = transfers.to_amount)
+  )::int AS matching_count
+FROM transfers
+LEFT JOIN entries ON entries.source_type = 'transfer' AND entries.source_id = transfers.id
+  AND entries.account_id IN (transfers.from_account_id, transfers.to_account_id)
+GROUP BY transfers.id
+HAVING COUNT(entries.id) <> 2
+  OR COUNT(entries.id) FILTER (WHERE entries.account_id = transfers.from_account_id AND entries.amount = -transfers.amount) <> 1
+  OR COUNT(entries.id) FILTER (WHERE entries.account_id = transfers.to_account_id AND entries.amount = transfers.to_amount) <> 1
+ORDER BY transfers.id
+LIMIT sqlc.arg('limit');
+
+-- name: ListDepositEntryMismatches :many
+-- Deposits which don't have exactly one entry putting the amount into the account
+SELECT
+  deposits.id AS deposit_id,
+  COUNT(entries.id)::int AS entry_count,
+  COUNT(entries.id) FILTER (
+    WHERE entries.account_id = deposits.account_id AND entries.amount = deposits.amount
+  )::int AS matching_count
+FROM deposits
+LEFT JOIN entries ON entries.source_type = 'deposit' AND entries.source_id = deposits.id
+  AND entries.account_id = deposits.account_id
+GROUP BY deposits.id
+HAVING COUNT(entries.id) <> 1
+  OR COUNT(entries.id) FILTER (WHERE entries.account_id = deposits.account_id AND entries.amount = deposits.amount) <> 1
+ORDER BY deposits.id
+LIMIT sqlc.arg('limit');
+
+-- name: ListWithdrawEntryMismatches :many
+-- Withdrawals which don't have exactly one entry taking the amount out of the account
+SELECT
+  withdraws.id AS withdraw_id,
+  COUNT(entries.id)::int AS entry_count,
+  COUNT(entries.id) FILTER (
+    WHERE entries.account_id = withdraws.account_id AND entries.amount = -withdraws.amount
+  )::int AS matching_count
+FROM withdraws
+LEFT JOIN entries ON entries.source_type = 'withdraw' AND entries.source_id = withdraws.id
+  AND entries.account_id = withdraws.account_id
+GROUP BY withdraws.id
+HAVING COUNT(entries.id) <> 1
+  OR COUNT(entries.id) FILTER (WHERE entries.account_id = withdraws.account_id AND entries.amount = -withdraws.amount) <> 1
+ORDER BY withdraws.id
+LIMIT sqlc.arg('limit');
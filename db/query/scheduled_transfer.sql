@@ -0,0 +1,113 @@
+-- name: CreateScheduledTransfer :one
+INSERT INTO scheduled_transfers (
+  owner,
+  from_account_id,
+  to_account_id,
+  amount,
+  frequency,
+  start_at,
+  next_run_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $6
+) RETURNING *;
+
+-- name: GetScheduledTransfer :one
+SELECT * FROM scheduled_transfers
+WHERE id = $1 LIMIT 1;
+
+-- name: ListScheduledTransfers :many
+SELECT * FROM scheduled_transfers
+WHERE owner = $1
+ORDER BY id
+LIMIT $2
+OFFSET $3;
+
+-- name: PauseScheduledTransfer :one
+-- Orders being executed by a worker can't be changed until the run is over
+UPDATE scheduled_transfers
+SET status = 'paused'
+WHERE id = $1
+  AND status = 'active'
+  AND (locked_until IS NULL OR locked_until < now())
+RETURNING *;
+
+-- name: ResumeScheduledTransfer :one
+-- Resumed orders start again with a clean record of failures
+UPDATE scheduled_transfers
+SET
+  status = 'active',
+  failure_count = 0
+WHERE id = $1
+  AND status = 'paused'
+  AND (locked_until IS NULL OR locked_until < now())
+RETURNING *;
+
+-- name: CancelScheduledTransfer :one
+UPDATE scheduled_transfers
+SET status = 'cancelled'
+WHERE id = $1
+  AND status IN ('active', 'paused')
+  AND (locked_until IS NULL OR locked_until < now())
+RETURNING *;
+
+-- name: ClaimDueScheduledTransfers :many
+-- Orders locked by another worker are skipped, so several workers can run at the same time.
+-- Orders made by members who can no longer spend their amount from the account are left alone
+UPDATE scheduled_transfers
+SET locked_until = sqlc.arg(locked_until)::timestamptz
+WHERE id IN (
+  SELECT st.id FROM scheduled_transfers st
+  JOIN accounts a ON a.id = st.from_account_id
+  WHERE st.status = 'active'
+    AND st.next_run_at <= now()
+    AND (st.locked_until IS NULL OR st.locked_until < now())
+    AND (
+      st.owner = a.owner
+      OR EXISTS (
+        SELECT 1 FROM account_members m
+        WHERE m.account_id = st.from_account_id
+          AND m.username = st.owner
+          AND (m.role = 'owner' OR (m.role = 'spender' AND st.amount <= m.spend_limit))
+      )
+    )
+  ORDER BY st.next_run_at
+  LIMIT sqlc.arg('limit')
+  FOR UPDATE OF st SKIP LOCKED
+)
+RETURNING *;
+
+-- name: FinishScheduledTransferRun :one
+UPDATE scheduled_transfers
+SET
+  next_run_at = $2,
+  status = $3,
+  failure_count = $4,
+  last_run_at = now(),
+  locked_until = NULL
+WHERE id = $1
+RETURNING *;
+
+-- name: CreateScheduledTransferRun :one
+INSERT INTO scheduled_transfer_runs (
+  scheduled_transfer_id,
+  scheduled_for,
+  status
+) VALUES (
+  $1, $2, $3
+) RETURNING *;
+
+-- name: UpdateScheduledTransferRun :one
+UPDATE scheduled_transfer_runs
+SET
+  status = $2,
+  transfer_id = $3,
+  error = $4
+WHERE id = $1
+RETURNING *;
+
+-- name: ListScheduledTransferRuns :many
+SELECT * FROM scheduled_transfer_runs
+WHERE scheduled_transfer_id = $1
+ORDER BY id
+LIMIT $2
+OFFSET $3;
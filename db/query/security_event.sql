@@ -0,0 +1,17 @@
+-- name: CreateSecurityEvent :one
+INSERT INTO security_events (
+  username,
+  kind,
+  session_family_id,
+  user_agent,
+  client_ip
+) VALUES (
+  $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: ListSecurityEvents :many
+SELECT * FROM security_events
+WHERE username = $1
+ORDER BY id DESC
+LIMIT $2
+OFFSET $3;
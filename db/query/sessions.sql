@@ -6,11 +6,55 @@ INSERT INTO sessions (
   user_agent,
   client_ip,
   is_blocked,
-  expires_at
+  expires_at,
+  family_id,
+  parent_id
 ) VALUES (
-  $1, $2, $3, $4, $5, $6, $7
+  $1, $2, $3, $4, $5, $6, $7, $8, $9
 ) RETURNING *;
 
 -- name: GetSession :one
 SELECT * FROM sessions
 WHERE id = $1 LIMIT 1;
+
+-- name: GetSessionForUpdate :one
+SELECT * FROM sessions
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListActiveSessions :many
+-- Sessions which can still renew access tokens, the most recent first.
+-- Only the last refresh token of each family can, the family standing for a device
+SELECT * FROM sessions
+WHERE username = $1
+  AND is_blocked = false
+  AND rotated_at IS NULL
+  AND expires_at > now()
+ORDER BY created_at DESC
+LIMIT $2
+OFFSET $3;
+
+-- name: BlockSession :execrows
+-- Blocks every refresh token of the family of the session, so the device is signed out
+-- whichever of them its access tokens were issued with
+UPDATE sessions
+SET is_blocked = true
+WHERE family_id = (
+  SELECT family_id FROM sessions AS s
+  WHERE s.id = sqlc.arg(id) AND s.username = sqlc.arg(username)
+);
+
+-- name: BlockSessionFamily :execrows
+UPDATE sessions
+SET is_blocked = true
+WHERE family_id = $1 AND is_blocked = false;
+
+-- name: RotateSession :exec
+UPDATE sessions
+SET rotated_at = now()
+WHERE id = $1;
+
+-- name: BlockUserSessions :execrows
+UPDATE sessions
+SET is_blocked = true
+WHERE username = $1 AND is_blocked = false AND expires_at > now();
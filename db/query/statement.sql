@@ -0,0 +1,48 @@
+-- name: CreateStatement :one
+-- Nothing is returned if the statement was already generated
+INSERT INTO statements (
+  account_id,
+  period,
+  pdf,
+  csv
+) VALUES (
+  $1, $2, $3, $4
+)
+ON CONFLICT (account_id, period) DO NOTHING
+RETURNING *;
+
+-- name: GetStatement :one
+SELECT * FROM statements
+WHERE account_id = $1 AND period = $2 LIMIT 1;
+
+-- name: ListAccountsWithoutStatement :many
+-- Accounts which were open during the period and don't have its statement yet
+SELECT * FROM accounts
+WHERE accounts.type <> 'house'
+  AND accounts.created_at < sqlc.arg(period_end)
+  AND NOT EXISTS (
+    SELECT 1 FROM statements
+    WHERE statements.account_id = accounts.id AND statements.period = sqlc.arg(period)
+  )
+ORDER BY id
+LIMIT sqlc.arg('limit');
+
+-- name: ListStatementEntries :many
+-- Entries posted during the period, with the other account of the transfers
+SELECT
+  entries.*,
+  COALESCE(counterparties.id, 0)::bigint AS counterparty_account_id,
+  COALESCE(counterparties.owner, '')::varchar AS counterparty_owner,
+  transfers.reversal_of_id
+FROM entries
+LEFT JOIN transfers
+  ON entries.source_type = 'transfer' AND transfers.id = entries.source_id
+LEFT JOIN accounts AS counterparties
+  ON counterparties.id = CASE
+    WHEN transfers.from_account_id = entries.account_id THEN transfers.to_account_id
+    ELSE transfers.from_account_id
+  END
+WHERE entries.account_id = sqlc.arg(account_id)
+  AND entries.created_at >= sqlc.arg(period_start)
+  AND entries.created_at < sqlc.arg(period_end)
+ORDER BY entries.id;
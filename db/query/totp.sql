@@ -0,0 +1,76 @@
+-- name: UpsertTotpSecret :one
+-- Starts the enrolment of an authenticator, replacing the secret of an enrolment which wasn't verified.
+-- Nothing is returned when the user already has two-factor authentication enabled
+INSERT INTO totp_secrets (
+  username,
+  encrypted_secret
+) VALUES (
+  $1, $2
+)
+ON CONFLICT (username) DO UPDATE
+SET encrypted_secret = EXCLUDED.encrypted_secret,
+    last_used_step = 0,
+    failed_attempts = 0,
+    last_failed_at = NULL,
+    created_at = now()
+WHERE totp_secrets.enabled_at IS NULL
+RETURNING *;
+
+-- name: GetTotpSecret :one
+SELECT * FROM totp_secrets
+WHERE username = $1 LIMIT 1;
+
+-- name: EnableTotpSecret :one
+UPDATE totp_secrets
+SET enabled_at = now()
+WHERE username = $1 AND enabled_at IS NULL
+RETURNING *;
+
+-- name: UseTotpStep :execrows
+-- Accepts a code of the time step, unless a code of the same or a later step was already accepted
+UPDATE totp_secrets
+SET last_used_step = sqlc.arg(step),
+    failed_attempts = 0
+WHERE username = sqlc.arg(username) AND last_used_step < sqlc.arg(step);
+
+-- name: RecordTotpFailure :one
+-- Counts a refused code, the count starting over when the last one was refused before the window started
+UPDATE totp_secrets
+SET failed_attempts = CASE
+      WHEN last_failed_at > sqlc.arg(window_start)::timestamptz THEN failed_attempts + 1
+      ELSE 1
+    END,
+    last_failed_at = now()
+WHERE username = sqlc.arg(username)
+RETURNING *;
+
+-- name: ResetTotpFailures :exec
+UPDATE totp_secrets
+SET failed_attempts = 0
+WHERE username = $1;
+
+-- name: DeleteTotpSecret :execrows
+DELETE FROM totp_secrets
+WHERE username = $1;
+
+-- name: CreateRecoveryCode :one
+INSERT INTO recovery_codes (
+  username,
+  hashed_code
+) VALUES (
+  $1, $2
+) RETURNING *;
+
+-- name: UseRecoveryCode :execrows
+UPDATE recovery_codes
+SET used_at = now()
+WHERE username = $1 AND hashed_code = $2 AND used_at IS NULL;
+
+-- name: CountRecoveryCodes :one
+-- Recovery codes of the user which weren't used yet
+SELECT count(*) FROM recovery_codes
+WHERE username = $1 AND used_at IS NULL;
+
+-- name: DeleteRecoveryCodes :exec
+DELETE FROM recovery_codes
+WHERE username = $1;
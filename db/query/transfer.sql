@@ -2,20 +2,45 @@
 INSERT INTO transfers (
   from_account_id,
   to_account_id,
-  amount
+  amount,
+  from_currency,
+  to_currency,
+  to_amount,
+  rate,
+  spread_bps,
+  quote_id,
+  reversal_of_id,
+  fee
 ) VALUES (
-  $1, $2, $3
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
 ) RETURNING *;
 
 -- name: GetTransfer :one
 SELECT * FROM transfers
 WHERE id = $1 LIMIT 1;
 
+-- name: GetTransferForUpdate :one
+SELECT * FROM transfers
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: AddTransferReversedAmount :one
+UPDATE transfers
+SET reversed_amount = reversed_amount + sqlc.arg(amount)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
 -- name: ListTransfers :many
 SELECT * FROM transfers
-WHERE 
-    from_account_id = $1 OR
-    to_account_id = $2
+WHERE
+    (
+        (sqlc.arg(direction)::text IN ('out', 'all') AND from_account_id = sqlc.arg(account_id)) OR
+        (sqlc.arg(direction)::text IN ('in', 'all') AND to_account_id = sqlc.arg(account_id))
+    )
+    AND (sqlc.narg(from_date)::timestamptz IS NULL OR created_at >= sqlc.narg(from_date))
+    AND (sqlc.narg(to_date)::timestamptz IS NULL OR created_at < sqlc.narg(to_date))
+    AND (sqlc.narg(min_amount)::bigint IS NULL OR amount >= sqlc.narg(min_amount))
+    AND (sqlc.narg(max_amount)::bigint IS NULL OR amount <= sqlc.narg(max_amount))
 ORDER BY id
-LIMIT $3
-OFFSET $4;
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
@@ -0,0 +1,45 @@
+-- name: CreateTransferLimit :one
+INSERT INTO transfer_limits (
+  tier,
+  currency,
+  max_per_transaction,
+  daily_amount,
+  daily_count
+) VALUES (
+  $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: GetTransferLimit :one
+SELECT * FROM transfer_limits
+WHERE tier = $1 AND currency = $2 LIMIT 1;
+
+-- name: GetDailyOutgoing :one
+-- Reversals give money back, and money moved between the accounts of the owner stays with them,
+-- so they don't count against the limits. Holds count on the day they are authorized, for what is
+-- still held or what was captured, while holds which were voided or expired don't count
+SELECT
+  COALESCE(SUM(o.amount), 0)::bigint AS total_amount,
+  COUNT(*)::int AS total_count
+FROM (
+  SELECT t.amount FROM transfers t
+  JOIN accounts a ON a.id = t.from_account_id
+  JOIN accounts ta ON ta.id = t.to_account_id
+  WHERE a.owner = sqlc.arg(owner)
+    AND ta.owner <> a.owner
+    AND t.from_currency = sqlc.arg(currency)
+    AND t.reversal_of_id IS NULL
+    AND t.created_at >= sqlc.arg(since)
+  UNION ALL
+  SELECT w.amount FROM withdraws w
+  JOIN accounts a ON a.id = w.account_id
+  WHERE a.owner = sqlc.arg(owner)
+    AND a.currency = sqlc.arg(currency)
+    AND w.created_at >= sqlc.arg(since)
+  UNION ALL
+  SELECT CASE WHEN h.status = 'captured' THEN h.captured_amount ELSE h.amount END FROM holds h
+  JOIN accounts a ON a.id = h.account_id
+  WHERE a.owner = sqlc.arg(owner)
+    AND a.currency = sqlc.arg(currency)
+    AND h.status IN ('active', 'captured')
+    AND h.created_at >= sqlc.arg(since)
+) o;
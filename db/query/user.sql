@@ -11,3 +11,20 @@ INSERT INTO users (
 -- name: GetUser :one
 SELECT * FROM users
 WHERE username = $1 LIMIT 1;
+
+-- name: GetUserForUpdate :one
+SELECT * FROM users
+WHERE username = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: UpdateUserTier :one
+UPDATE users
+SET tier = sqlc.arg(tier)
+WHERE username = sqlc.arg(username)
+RETURNING *;
+
+-- name: UpdateUserRole :one
+UPDATE users
+SET role = sqlc.arg(role)
+WHERE username = sqlc.arg(username)
+RETURNING *;
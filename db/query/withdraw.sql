@@ -7,7 +7,7 @@ INSERT INTO withdraws (
   $1, $2, $3
 ) RETURNING *;
 
--- name: Getwithdraw :one
+-- name: GetWithdraw :one
 SELECT * FROM withdraws
 WHERE id = $1 LIMIT 1;
 
@@ -7,13 +7,14 @@ package db
 
 import (
 	"context"
+	"database/sql"
 )
 
 const addAccountBalance = `-- name: AddAccountBalance :one
 UPDATE accounts
 SET balance = balance + $1
 WHERE id = $2
-RETURNING id, owner, balance, currency, created_at
+RETURNING id, owner, balance, currency, created_at, overdraft_limit, held_amount, available_balance, type, status, name, description
 `
 
 type AddAccountBalanceParams struct {
@@ -30,28 +31,97 @@ func (q *Queries) AddAccountBalance(ctx context.Context, arg AddAccountBalancePa
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.HeldAmount,
+		&i.AvailableBalance,
+		&i.Type,
+		&i.Status,
+		&i.Name,
+		&i.Description,
 	)
 	return i, err
 }
 
+const addAccountHeldAmount = `-- name: AddAccountHeldAmount :one
+UPDATE accounts
+SET held_amount = held_amount + $1
+WHERE id = $2
+RETURNING id, owner, balance, currency, created_at, overdraft_limit, held_amount, available_balance, type, status, name, description
+`
+
+type AddAccountHeldAmountParams struct {
+	Amount int64 `json:"amount"`
+	ID     int64 `json:"id"`
+}
+
+func (q *Queries) AddAccountHeldAmount(ctx context.Context, arg AddAccountHeldAmountParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, addAccountHeldAmount, arg.Amount, arg.ID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.HeldAmount,
+		&i.AvailableBalance,
+		&i.Type,
+		&i.Status,
+		&i.Name,
+		&i.Description,
+	)
+	return i, err
+}
+
+const countOpenAccounts = `-- name: CountOpenAccounts :one
+SELECT COUNT(*) FROM accounts
+WHERE owner = $1 AND currency = $2 AND status <> 'closed'
+`
+
+type CountOpenAccountsParams struct {
+	Owner    string `json:"owner"`
+	Currency string `json:"currency"`
+}
+
+func (q *Queries) CountOpenAccounts(ctx context.Context, arg CountOpenAccountsParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countOpenAccounts, arg.Owner, arg.Currency)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createAccount = `-- name: CreateAccount :one
 INSERT INTO accounts (
   owner,
   balance,
-  currency
+  currency,
+  type,
+  name,
+  description
 ) VALUES (
-  $1, $2, $3
-) RETURNING id, owner, balance, currency, created_at
+  $1, $2, $3, $4, $5, $6
+) RETURNING id, owner, balance, currency, created_at, overdraft_limit, held_amount, available_balance, type, status, name, description
 `
 
 type CreateAccountParams struct {
-	Owner    string `json:"owner"`
-	Balance  int64  `json:"balance"`
-	Currency string `json:"currency"`
+	Owner       string `json:"owner"`
+	Balance     int64  `json:"balance"`
+	Currency    string `json:"currency"`
+	Type        string `json:"type"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
 }
 
 func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error) {
-	row := q.db.QueryRowContext(ctx, createAccount, arg.Owner, arg.Balance, arg.Currency)
+	row := q.db.QueryRowContext(ctx, createAccount,
+		arg.Owner,
+		arg.Balance,
+		arg.Currency,
+		arg.Type,
+		arg.Name,
+		arg.Description,
+	)
 	var i Account
 	err := row.Scan(
 		&i.ID,
@@ -59,22 +129,68 @@ func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.HeldAmount,
+		&i.AvailableBalance,
+		&i.Type,
+		&i.Status,
+		&i.Name,
+		&i.Description,
 	)
 	return i, err
 }
 
-const deleteAccount = `-- name: DeleteAccount :exec
-DELETE FROM accounts
-WHERE id = $1
+const createAccountStatusChange = `-- name: CreateAccountStatusChange :one
+INSERT INTO account_status_changes (
+  account_id,
+  from_status,
+  to_status,
+  changed_by,
+  reason,
+  payout_transfer_id,
+  by_staff
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+) RETURNING id, account_id, from_status, to_status, changed_by, reason, payout_transfer_id, created_at, by_staff
 `
 
-func (q *Queries) DeleteAccount(ctx context.Context, id int64) error {
-	_, err := q.db.ExecContext(ctx, deleteAccount, id)
-	return err
+type CreateAccountStatusChangeParams struct {
+	AccountID        int64         `json:"account_id"`
+	FromStatus       string        `json:"from_status"`
+	ToStatus         string        `json:"to_status"`
+	ChangedBy        string        `json:"changed_by"`
+	Reason           string        `json:"reason"`
+	PayoutTransferID sql.NullInt64 `json:"payout_transfer_id"`
+	ByStaff          bool          `json:"by_staff"`
+}
+
+func (q *Queries) CreateAccountStatusChange(ctx context.Context, arg CreateAccountStatusChangeParams) (AccountStatusChange, error) {
+	row := q.db.QueryRowContext(ctx, createAccountStatusChange,
+		arg.AccountID,
+		arg.FromStatus,
+		arg.ToStatus,
+		arg.ChangedBy,
+		arg.Reason,
+		arg.PayoutTransferID,
+		arg.ByStaff,
+	)
+	var i AccountStatusChange
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.FromStatus,
+		&i.ToStatus,
+		&i.ChangedBy,
+		&i.Reason,
+		&i.PayoutTransferID,
+		&i.CreatedAt,
+		&i.ByStaff,
+	)
+	return i, err
 }
 
 const getAccount = `-- name: GetAccount :one
-SELECT id, owner, balance, currency, created_at FROM accounts
+SELECT id, owner, balance, currency, created_at, overdraft_limit, held_amount, available_balance, type, status, name, description FROM accounts
 WHERE id = $1 LIMIT 1
 `
 
@@ -87,12 +203,19 @@ func (q *Queries) GetAccount(ctx context.Context, id int64) (Account, error) {
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.HeldAmount,
+		&i.AvailableBalance,
+		&i.Type,
+		&i.Status,
+		&i.Name,
+		&i.Description,
 	)
 	return i, err
 }
 
 const getAccountForUpdate = `-- name: GetAccountForUpdate :one
-SELECT id, owner, balance, currency, created_at FROM accounts
+SELECT id, owner, balance, currency, created_at, overdraft_limit, held_amount, available_balance, type, status, name, description FROM accounts
 WHERE id = $1 LIMIT 1
 FOR NO KEY UPDATE
 `
@@ -106,26 +229,121 @@ func (q *Queries) GetAccountForUpdate(ctx context.Context, id int64) (Account, e
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.HeldAmount,
+		&i.AvailableBalance,
+		&i.Type,
+		&i.Status,
+		&i.Name,
+		&i.Description,
 	)
 	return i, err
 }
 
-const listAccounts = `-- name: ListAccounts :many
-SELECT id, owner, balance, currency, created_at FROM accounts
-WHERE owner = $1
+const getLastAccountStatusChange = `-- name: GetLastAccountStatusChange :one
+SELECT id, account_id, from_status, to_status, changed_by, reason, payout_transfer_id, created_at, by_staff FROM account_status_changes
+WHERE account_id = $1
+ORDER BY id DESC
+LIMIT 1
+`
+
+func (q *Queries) GetLastAccountStatusChange(ctx context.Context, accountID int64) (AccountStatusChange, error) {
+	row := q.db.QueryRowContext(ctx, getLastAccountStatusChange, accountID)
+	var i AccountStatusChange
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.FromStatus,
+		&i.ToStatus,
+		&i.ChangedBy,
+		&i.Reason,
+		&i.PayoutTransferID,
+		&i.CreatedAt,
+		&i.ByStaff,
+	)
+	return i, err
+}
+
+const listAccountStatusChanges = `-- name: ListAccountStatusChanges :many
+SELECT id, account_id, from_status, to_status, changed_by, reason, payout_transfer_id, created_at, by_staff FROM account_status_changes
+WHERE account_id = $1
 ORDER BY id
 LIMIT $2
 OFFSET $3
 `
 
+type ListAccountStatusChangesParams struct {
+	AccountID int64 `json:"account_id"`
+	Limit     int32 `json:"limit"`
+	Offset    int32 `json:"offset"`
+}
+
+func (q *Queries) ListAccountStatusChanges(ctx context.Context, arg ListAccountStatusChangesParams) ([]AccountStatusChange, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountStatusChanges, arg.AccountID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []AccountStatusChange{}
+	for rows.Next() {
+		var i AccountStatusChange
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.FromStatus,
+			&i.ToStatus,
+			&i.ChangedBy,
+			&i.Reason,
+			&i.PayoutTransferID,
+			&i.CreatedAt,
+			&i.ByStaff,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAccounts = `-- name: ListAccounts :many
+SELECT id, owner, balance, currency, created_at, overdraft_limit, held_amount, available_balance, type, status, name, description FROM accounts
+WHERE (
+    owner = $1
+    OR id IN (
+      SELECT account_id FROM account_members
+      WHERE username = $1
+    )
+  )
+  AND ($2::varchar IS NULL OR currency = $2)
+  AND ($3::varchar IS NULL OR name ILIKE '%' || $3 || '%')
+ORDER BY id
+LIMIT $5
+OFFSET $4
+`
+
 type ListAccountsParams struct {
-	Owner  string `json:"owner"`
-	Limit  int32  `json:"limit"`
-	Offset int32  `json:"offset"`
+	Username string         `json:"username"`
+	Currency sql.NullString `json:"currency"`
+	Name     sql.NullString `json:"name"`
+	Offset   int32          `json:"offset"`
+	Limit    int32          `json:"limit"`
 }
 
+// Accounts the user owns or has been invited to, the name matching any part of it regardless of case
 func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error) {
-	rows, err := q.db.QueryContext(ctx, listAccounts, arg.Owner, arg.Limit, arg.Offset)
+	rows, err := q.db.QueryContext(ctx, listAccounts,
+		arg.Username,
+		arg.Currency,
+		arg.Name,
+		arg.Offset,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -139,6 +357,13 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 			&i.Balance,
 			&i.Currency,
 			&i.CreatedAt,
+			&i.OverdraftLimit,
+			&i.HeldAmount,
+			&i.AvailableBalance,
+			&i.Type,
+			&i.Status,
+			&i.Name,
+			&i.Description,
 		); err != nil {
 			return nil, err
 		}
@@ -157,7 +382,7 @@ const updateAccount = `-- name: UpdateAccount :one
 UPDATE accounts
 SET balance = $2
 WHERE id = $1
-RETURNING id, owner, balance, currency, created_at
+RETURNING id, owner, balance, currency, created_at, overdraft_limit, held_amount, available_balance, type, status, name, description
 `
 
 type UpdateAccountParams struct {
@@ -174,6 +399,113 @@ func (q *Queries) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.HeldAmount,
+		&i.AvailableBalance,
+		&i.Type,
+		&i.Status,
+		&i.Name,
+		&i.Description,
+	)
+	return i, err
+}
+
+const updateAccountDetails = `-- name: UpdateAccountDetails :one
+UPDATE accounts
+SET
+  name = COALESCE($1, name),
+  description = COALESCE($2, description)
+WHERE id = $3
+RETURNING id, owner, balance, currency, created_at, overdraft_limit, held_amount, available_balance, type, status, name, description
+`
+
+type UpdateAccountDetailsParams struct {
+	Name        sql.NullString `json:"name"`
+	Description sql.NullString `json:"description"`
+	ID          int64          `json:"id"`
+}
+
+// Fields which are null keep their value
+func (q *Queries) UpdateAccountDetails(ctx context.Context, arg UpdateAccountDetailsParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, updateAccountDetails, arg.Name, arg.Description, arg.ID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.HeldAmount,
+		&i.AvailableBalance,
+		&i.Type,
+		&i.Status,
+		&i.Name,
+		&i.Description,
+	)
+	return i, err
+}
+
+const updateAccountOverdraftLimit = `-- name: UpdateAccountOverdraftLimit :one
+UPDATE accounts
+SET overdraft_limit = $1
+WHERE id = $2
+RETURNING id, owner, balance, currency, created_at, overdraft_limit, held_amount, available_balance, type, status, name, description
+`
+
+type UpdateAccountOverdraftLimitParams struct {
+	OverdraftLimit int64 `json:"overdraft_limit"`
+	ID             int64 `json:"id"`
+}
+
+func (q *Queries) UpdateAccountOverdraftLimit(ctx context.Context, arg UpdateAccountOverdraftLimitParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, updateAccountOverdraftLimit, arg.OverdraftLimit, arg.ID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.HeldAmount,
+		&i.AvailableBalance,
+		&i.Type,
+		&i.Status,
+		&i.Name,
+		&i.Description,
+	)
+	return i, err
+}
+
+const updateAccountStatus = `-- name: UpdateAccountStatus :one
+UPDATE accounts
+SET status = $1
+WHERE id = $2
+RETURNING id, owner, balance, currency, created_at, overdraft_limit, held_amount, available_balance, type, status, name, description
+`
+
+type UpdateAccountStatusParams struct {
+	Status string `json:"status"`
+	ID     int64  `json:"id"`
+}
+
+func (q *Queries) UpdateAccountStatus(ctx context.Context, arg UpdateAccountStatusParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, updateAccountStatus, arg.Status, arg.ID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.HeldAmount,
+		&i.AvailableBalance,
+		&i.Type,
+		&i.Status,
+		&i.Name,
+		&i.Description,
 	)
 	return i, err
 }
@@ -0,0 +1,120 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomAccountMember(t *testing.T, account Account, role string) AccountMember {
+	user := createRandomUser(t)
+
+	arg := CreateAccountMemberParams{
+		AccountID: account.ID,
+		Username:  user.Username,
+		Role:      role,
+		InvitedBy: account.Owner,
+	}
+	if role == AccountRoleSpender {
+		arg.SpendLimit = 100
+	}
+
+	member, err := testQueries.CreateAccountMember(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.AccountID, member.AccountID)
+	require.Equal(t, arg.Username, member.Username)
+	require.Equal(t, arg.Role, member.Role)
+	require.Equal(t, arg.SpendLimit, member.SpendLimit)
+	require.Equal(t, arg.InvitedBy, member.InvitedBy)
+	require.NotZero(t, member.CreatedAt)
+
+	return member
+}
+
+func TestCreateAccountMember(t *testing.T) {
+	account := createRandomAccount(t)
+	member := createRandomAccountMember(t, account, AccountRoleSpender)
+
+	// A user is only added once to an account
+	_, err := testQueries.CreateAccountMember(context.Background(), CreateAccountMemberParams{
+		AccountID: account.ID,
+		Username:  member.Username,
+		Role:      AccountRoleViewer,
+		InvitedBy: account.Owner,
+	})
+	require.Error(t, err)
+	pqErr, ok := err.(*pq.Error)
+	require.True(t, ok)
+	require.Equal(t, "unique_violation", pqErr.Code.Name())
+}
+
+func TestGetAccountMember(t *testing.T) {
+	account := createRandomAccount(t)
+	member1 := createRandomAccountMember(t, account, AccountRoleViewer)
+
+	member2, err := testQueries.GetAccountMember(context.Background(), GetAccountMemberParams{
+		AccountID: account.ID,
+		Username:  member1.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, member1, member2)
+
+	// The owner isn't stored as a member of their own account
+	_, err = testQueries.GetAccountMember(context.Background(), GetAccountMemberParams{
+		AccountID: account.ID,
+		Username:  account.Owner,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestDeleteAccountMember(t *testing.T) {
+	account := createRandomAccount(t)
+	member := createRandomAccountMember(t, account, AccountRoleOwner)
+	createRandomAccountMember(t, account, AccountRoleViewer)
+
+	members, err := testQueries.ListAccountMembers(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Len(t, members, 2)
+
+	arg := DeleteAccountMemberParams{
+		AccountID: account.ID,
+		Username:  member.Username,
+	}
+	rows, err := testQueries.DeleteAccountMember(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), rows)
+
+	rows, err = testQueries.DeleteAccountMember(context.Background(), arg)
+	require.NoError(t, err)
+	require.Zero(t, rows)
+
+	members, err = testQueries.ListAccountMembers(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Len(t, members, 1)
+}
+
+func TestListAccountsOfMember(t *testing.T) {
+	account := createRandomAccount(t)
+	member := createRandomAccountMember(t, account, AccountRoleViewer)
+
+	// Shared accounts are listed along with the ones the user owns
+	owned, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    member.Username,
+		Currency: account.Currency,
+		Type:     AccountTypeChecking,
+	})
+	require.NoError(t, err)
+
+	accounts, err := testQueries.ListAccounts(context.Background(), ListAccountsParams{
+		Username: member.Username,
+		Limit:    5,
+		Offset:   0,
+	})
+	require.NoError(t, err)
+	require.Len(t, accounts, 2)
+	require.Equal(t, account.ID, accounts[0].ID)
+	require.Equal(t, owned.ID, accounts[1].ID)
+}
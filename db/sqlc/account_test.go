@@ -12,13 +12,24 @@ import (
 
 // Function to be used during the tests
 func createRandomAccount(t *testing.T) Account {
+	return createRandomAccountWithCurrency(t, util.RandomCurrency())
+}
+
+// createRandomAccountWithCurrency is used when accounts must share a currency, such as for transfers
+func createRandomAccountWithCurrency(t *testing.T, currency string) Account {
+	return createRandomAccountWithType(t, currency, AccountTypeChecking)
+}
+
+// createRandomAccountWithType is used when the account must be a savings account, such as for interest
+func createRandomAccountWithType(t *testing.T, currency string, accountType string) Account {
 	user := createRandomUser(t)
 
 	// Setting up data to be used during the account's creation
 	arg := CreateAccountParams{
 		Owner:    user.Username,
 		Balance:  util.RandomMoney(),
-		Currency: util.RandomCurrency(),
+		Currency: currency,
+		Type:     accountType,
 	}
 
 	// Creating the account item
@@ -30,7 +41,12 @@ func createRandomAccount(t *testing.T) Account {
 	// And it should meet the required data
 	require.Equal(t, arg.Owner, account.Owner)
 	require.Equal(t, arg.Balance, account.Balance)
+	require.Equal(t, AccountStatusActive, account.Status)
+	// Nothing is held on a new account
+	require.Zero(t, account.HeldAmount)
+	require.Equal(t, arg.Balance, account.AvailableBalance)
 	require.Equal(t, arg.Currency, account.Currency)
+	require.Equal(t, arg.Type, account.Type)
 
 	// Cheking if ID and "created at" fields were filled
 	require.NotZero(t, account.ID)
@@ -84,21 +100,6 @@ func TestUpdateAccount(t *testing.T) {
 	require.WithinDuration(t, account1.CreatedAt, account2.CreatedAt, time.Second)
 }
 
-func TestDeleteAccount(t *testing.T) {
-	// First, we should create an account
-	account1 := createRandomAccount(t)
-
-	err := testQueries.DeleteAccount(context.Background(), account1.ID)
-	// In order for the tests to pass, there should be no errors
-	require.NoError(t, err)
-
-	// Now, when querying the data, it should be empty
-	account2, err := testQueries.GetAccount(context.Background(), account1.ID)
-	require.Error(t, err)
-	require.EqualError(t, err, sql.ErrNoRows.Error())
-	require.Empty(t, account2)
-}
-
 func TestListAccounts(t *testing.T) {
 	var lastAccount Account
 	// First, we create random accounts
@@ -108,9 +109,9 @@ func TestListAccounts(t *testing.T) {
 
 	// Defining pagination settings
 	arg := ListAccountsParams{
-		Owner:  lastAccount.Owner,
-		Limit:  5,
-		Offset: 0,
+		Username: lastAccount.Owner,
+		Limit:    5,
+		Offset:   0,
 	}
 
 	accounts, err := testQueries.ListAccounts(context.Background(), arg)
@@ -125,3 +126,107 @@ func TestListAccounts(t *testing.T) {
 		require.Equal(t, lastAccount.Owner, account.Owner)
 	}
 }
+
+func TestCreateAccountTx(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccountWithCurrency(t, util.USD)
+
+	arg := CreateAccountTxParams{
+		CreateAccountParams: CreateAccountParams{
+			Owner:       account.Owner,
+			Currency:    util.USD,
+			Type:        AccountTypeChecking,
+			Name:        "Vacation fund",
+			Description: "Saving up for the summer",
+		},
+		MaxPerCurrency: 2,
+	}
+
+	// Several accounts of the same type can be opened in a currency
+	account2, err := store.CreateAccountTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Name, account2.Name)
+	require.Equal(t, arg.Description, account2.Description)
+
+	_, err = store.CreateAccountTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrAccountLimitReached)
+
+	// Other currencies have their own limit
+	arg.Currency = util.EUR
+	_, err = store.CreateAccountTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	// Closed accounts don't count
+	_, err = testQueries.UpdateAccountStatus(context.Background(), UpdateAccountStatusParams{
+		ID:     account2.ID,
+		Status: AccountStatusClosed,
+	})
+	require.NoError(t, err)
+
+	arg.Currency = util.USD
+	_, err = store.CreateAccountTx(context.Background(), arg)
+	require.NoError(t, err)
+}
+
+func TestUpdateAccountDetails(t *testing.T) {
+	account1 := createRandomAccount(t)
+
+	account2, err := testQueries.UpdateAccountDetails(context.Background(), UpdateAccountDetailsParams{
+		ID:          account1.ID,
+		Name:        sql.NullString{String: "Vacation fund", Valid: true},
+		Description: sql.NullString{String: "Saving up for the summer", Valid: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "Vacation fund", account2.Name)
+	require.Equal(t, "Saving up for the summer", account2.Description)
+
+	// Only the name changes
+	account3, err := testQueries.UpdateAccountDetails(context.Background(), UpdateAccountDetailsParams{
+		ID:   account1.ID,
+		Name: sql.NullString{String: "Rainy day fund", Valid: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "Rainy day fund", account3.Name)
+	require.Equal(t, account2.Description, account3.Description)
+	require.Equal(t, account1.Balance, account3.Balance)
+}
+
+func TestListAccountsFiltered(t *testing.T) {
+	account1 := createRandomAccountWithCurrency(t, util.USD)
+
+	account2, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    account1.Owner,
+		Currency: util.USD,
+		Type:     AccountTypeSavings,
+		Name:     "Vacation fund",
+	})
+	require.NoError(t, err)
+
+	_, err = testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    account1.Owner,
+		Currency: util.EUR,
+		Type:     AccountTypeSavings,
+		Name:     "Vacation fund",
+	})
+	require.NoError(t, err)
+
+	accounts, err := testQueries.ListAccounts(context.Background(), ListAccountsParams{
+		Username: account1.Owner,
+		Currency: sql.NullString{String: util.USD, Valid: true},
+		Name:     sql.NullString{String: "VACATION", Valid: true},
+		Limit:    5,
+		Offset:   0,
+	})
+	require.NoError(t, err)
+	require.Len(t, accounts, 1)
+	require.Equal(t, account2.ID, accounts[0].ID)
+
+	accounts, err = testQueries.ListAccounts(context.Background(), ListAccountsParams{
+		Username: account1.Owner,
+		Currency: sql.NullString{String: util.USD, Valid: true},
+		Limit:    5,
+		Offset:   0,
+	})
+	require.NoError(t, err)
+	require.Len(t, accounts, 2)
+}
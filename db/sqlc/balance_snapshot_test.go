@@ -0,0 +1,99 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAccountBalanceAt(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+	before := time.Now()
+
+	result, err := store.DepositTx(context.Background(), DepositTxParams{
+		AccountID: account.ID,
+		Amount:    100,
+		User:      account.Owner,
+	})
+	require.NoError(t, err)
+
+	// Entries posted after the point in time are taken out of the balance
+	balance, err := testQueries.GetAccountBalanceAt(context.Background(), GetAccountBalanceAtParams{
+		At:        before,
+		AccountID: account.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, account.Balance, balance)
+
+	balance, err = testQueries.GetAccountBalanceAt(context.Background(), GetAccountBalanceAtParams{
+		At:        result.Entry.CreatedAt.Add(time.Second),
+		AccountID: account.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, account.Balance+100, balance)
+}
+
+func TestCreateBalanceSnapshots(t *testing.T) {
+	account := createRandomAccount(t)
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	taken, err := testQueries.CreateBalanceSnapshots(context.Background(), today)
+	require.NoError(t, err)
+	require.Positive(t, taken)
+
+	snapshot, err := testQueries.GetBalanceSnapshot(context.Background(), GetBalanceSnapshotParams{
+		AccountID:    account.ID,
+		SnapshotDate: today,
+	})
+	require.NoError(t, err)
+	require.Equal(t, account.Balance, snapshot.Balance)
+
+	// Taking the snapshots of the same day again keeps the first ones
+	fundAccount(t, account, 100)
+	_, err = testQueries.CreateBalanceSnapshots(context.Background(), today)
+	require.NoError(t, err)
+
+	snapshot, err = testQueries.GetBalanceSnapshot(context.Background(), GetBalanceSnapshotParams{
+		AccountID:    account.ID,
+		SnapshotDate: today,
+	})
+	require.NoError(t, err)
+	require.Equal(t, account.Balance, snapshot.Balance)
+
+	// Nothing is taken for the days before the account was opened
+	_, err = testQueries.GetBalanceSnapshot(context.Background(), GetBalanceSnapshotParams{
+		AccountID:    account.ID,
+		SnapshotDate: today.AddDate(0, 0, -1),
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestGetAccountBalanceAtWithSnapshot(t *testing.T) {
+	account := createRandomAccount(t)
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	_, err := testQueries.CreateBalanceSnapshots(context.Background(), today)
+	require.NoError(t, err)
+
+	// Money added without entries is only seen by the current balance, not by the snapshot
+	fundAccount(t, account, 100)
+
+	balance, err := testQueries.GetAccountBalanceAt(context.Background(), GetAccountBalanceAtParams{
+		At:        today.AddDate(0, 0, 1).Add(time.Hour),
+		AccountID: account.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, account.Balance, balance)
+
+	// Before the end of the day, the snapshot can't be used
+	balance, err = testQueries.GetAccountBalanceAt(context.Background(), GetAccountBalanceAtParams{
+		At:        time.Now().Add(time.Minute),
+		AccountID: account.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, account.Balance+100, balance)
+}
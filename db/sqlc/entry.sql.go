@@ -7,36 +7,52 @@ package db
 
 import (
 	"context"
+	"time"
 )
 
 const createEntry = `-- name: CreateEntry :one
 INSERT INTO entries (
   account_id,
-  amount
+  amount,
+  source_type,
+  source_id,
+  journal_id
 ) VALUES (
-  $1, $2
-) RETURNING id, account_id, amount, created_at
+  $1, $2, $3, $4, $5
+) RETURNING id, account_id, amount, created_at, source_type, source_id, journal_id
 `
 
 type CreateEntryParams struct {
-	AccountID int64 `json:"account_id"`
-	Amount    int64 `json:"amount"`
+	AccountID  int64  `json:"account_id"`
+	Amount     int64  `json:"amount"`
+	SourceType string `json:"source_type"`
+	SourceID   int64  `json:"source_id"`
+	JournalID  int64  `json:"journal_id"`
 }
 
 func (q *Queries) CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error) {
-	row := q.db.QueryRowContext(ctx, createEntry, arg.AccountID, arg.Amount)
+	row := q.db.QueryRowContext(ctx, createEntry,
+		arg.AccountID,
+		arg.Amount,
+		arg.SourceType,
+		arg.SourceID,
+		arg.JournalID,
+	)
 	var i Entry
 	err := row.Scan(
 		&i.ID,
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.SourceType,
+		&i.SourceID,
+		&i.JournalID,
 	)
 	return i, err
 }
 
 const getEntry = `-- name: GetEntry :one
-SELECT id, account_id, amount, created_at FROM entries
+SELECT id, account_id, amount, created_at, source_type, source_id, journal_id FROM entries
 WHERE id = $1 LIMIT 1
 `
 
@@ -48,12 +64,15 @@ func (q *Queries) GetEntry(ctx context.Context, id int64) (Entry, error) {
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.SourceType,
+		&i.SourceID,
+		&i.JournalID,
 	)
 	return i, err
 }
 
 const listEntries = `-- name: ListEntries :many
-SELECT id, account_id, amount, created_at FROM entries
+SELECT id, account_id, amount, created_at, source_type, source_id, journal_id FROM entries
 WHERE account_id = $1
 ORDER BY id
 LIMIT $2
@@ -80,6 +99,70 @@ func (q *Queries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Ent
 			&i.AccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.SourceType,
+			&i.SourceID,
+			&i.JournalID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listLedgerEntries = `-- name: ListLedgerEntries :many
+SELECT
+  id, account_id, amount, created_at, source_type, source_id, journal_id,
+  -- the window is computed before paginating, so every page carries the right balance
+  SUM(amount) OVER (ORDER BY id)::bigint AS running_balance
+FROM entries
+WHERE account_id = $1
+ORDER BY id
+LIMIT $2
+OFFSET $3
+`
+
+type ListLedgerEntriesParams struct {
+	AccountID int64 `json:"account_id"`
+	Limit     int32 `json:"limit"`
+	Offset    int32 `json:"offset"`
+}
+
+type ListLedgerEntriesRow struct {
+	ID             int64     `json:"id"`
+	AccountID      int64     `json:"account_id"`
+	Amount         int64     `json:"amount"`
+	CreatedAt      time.Time `json:"created_at"`
+	SourceType     string    `json:"source_type"`
+	SourceID       int64     `json:"source_id"`
+	JournalID      int64     `json:"journal_id"`
+	RunningBalance int64     `json:"running_balance"`
+}
+
+func (q *Queries) ListLedgerEntries(ctx context.Context, arg ListLedgerEntriesParams) ([]ListLedgerEntriesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listLedgerEntries, arg.AccountID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListLedgerEntriesRow{}
+	for rows.Next() {
+		var i ListLedgerEntriesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.SourceType,
+			&i.SourceID,
+			&i.JournalID,
+			&i.RunningBalance,
 		); err != nil {
 			return nil, err
 		}
@@ -11,17 +11,36 @@ import (
 )
 
 func createRandomEntry(t *testing.T, account Account) Entry {
-	arg := CreateEntryParams{
-		AccountID: account.ID,
-		Amount:    util.RandomMoney(),
-	}
+	cash, err := testQueries.GetHouseAccount(context.Background(), GetHouseAccountParams{
+		Purpose:  HousePurposeCash,
+		Currency: account.Currency,
+	})
+	require.NoError(t, err)
 
-	entry, err := testQueries.CreateEntry(context.Background(), arg)
+	amount := util.RandomMoney()
+	sourceID := util.RandomInt(1, 1000)
+
+	// The entry is balanced by the cash account, since the database refuses to commit a journal which isn't
+	var entry Entry
+	err = NewStore(testDB).(*SQLStore).execTx(context.Background(), func(q *Queries) error {
+		entries, _, err := postJournal(context.Background(), q, EntrySourceDeposit, sourceID,
+			journalLine{AccountID: account.ID, Currency: account.Currency, Amount: amount},
+			journalLine{AccountID: cash.ID, Currency: account.Currency, Amount: -amount},
+		)
+		if err != nil {
+			return err
+		}
+		entry = entries[0]
+		return nil
+	})
 	require.NoError(t, err)
 	require.NotEmpty(t, entry)
 
-	require.Equal(t, arg.AccountID, entry.AccountID)
-	require.Equal(t, arg.Amount, entry.Amount)
+	require.Equal(t, account.ID, entry.AccountID)
+	require.Equal(t, amount, entry.Amount)
+	require.Equal(t, EntrySourceDeposit, entry.SourceType)
+	require.Equal(t, sourceID, entry.SourceID)
+	require.NotZero(t, entry.JournalID)
 
 	require.NotZero(t, entry.ID)
 	require.NotZero(t, entry.CreatedAt)
@@ -68,3 +87,32 @@ func TestListEntries(t *testing.T) {
 		require.Equal(t, arg.AccountID, entry.AccountID)
 	}
 }
+
+func TestListLedgerEntries(t *testing.T) {
+	account := createRandomAccount(t)
+
+	n := 10
+	balances := make([]int64, n)
+	var balance int64
+	for i := 0; i < n; i++ {
+		entry := createRandomEntry(t, account)
+		balance += entry.Amount
+		balances[i] = balance
+	}
+
+	arg := ListLedgerEntriesParams{
+		AccountID: account.ID,
+		Limit:     5,
+		Offset:    5,
+	}
+
+	entries, err := testQueries.ListLedgerEntries(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, entries, 5)
+
+	// The running balance also accounts for the entries in the previous pages
+	for i, entry := range entries {
+		require.Equal(t, arg.AccountID, entry.AccountID)
+		require.Equal(t, balances[5+i], entry.RunningBalance)
+	}
+}
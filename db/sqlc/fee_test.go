@@ -0,0 +1,130 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"simplebank/util"
+
+	"github.com/stretchr/testify/require"
+)
+
+// addFeeRule adds a fee rule which is deleted once the test is over, since it applies to every transfer
+func addFeeRule(t *testing.T, arg CreateFeeRuleParams) FeeRule {
+	rule, err := testQueries.CreateFeeRule(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Currency, rule.Currency)
+	require.Equal(t, arg.TransferType, rule.TransferType)
+	require.Equal(t, arg.MinAmount, rule.MinAmount)
+	require.NotZero(t, rule.ID)
+
+	t.Cleanup(func() {
+		err := testQueries.DeleteFeeRule(context.Background(), rule.ID)
+		require.NoError(t, err)
+	})
+
+	return rule
+}
+
+func TestGetFeeRule(t *testing.T) {
+	low := addFeeRule(t, CreateFeeRuleParams{
+		Currency:     util.CAD,
+		TransferType: TransferTypeCrossCurrency,
+		FlatFee:      1,
+	})
+	high := addFeeRule(t, CreateFeeRuleParams{
+		Currency:      util.CAD,
+		TransferType:  TransferTypeCrossCurrency,
+		MinAmount:     1000,
+		PercentageBps: 100,
+	})
+
+	// The rule of the highest tier reached by the amount applies
+	rule, err := testQueries.GetFeeRule(context.Background(), GetFeeRuleParams{
+		Currency:     util.CAD,
+		TransferType: TransferTypeCrossCurrency,
+		Amount:       999,
+	})
+	require.NoError(t, err)
+	require.Equal(t, low.ID, rule.ID)
+
+	rule, err = testQueries.GetFeeRule(context.Background(), GetFeeRuleParams{
+		Currency:     util.CAD,
+		TransferType: TransferTypeCrossCurrency,
+		Amount:       1000,
+	})
+	require.NoError(t, err)
+	require.Equal(t, high.ID, rule.ID)
+}
+
+func TestGetHouseAccount(t *testing.T) {
+	for _, currency := range []string{util.USD, util.EUR, util.CAD} {
+		account, err := testQueries.GetHouseAccount(context.Background(), GetHouseAccountParams{
+			Purpose:  HousePurposeFeeRevenue,
+			Currency: currency,
+		})
+		require.NoError(t, err)
+		require.Equal(t, currency, account.Currency)
+		require.Equal(t, AccountTypeHouse, account.Type)
+	}
+}
+
+func TestCalculateFee(t *testing.T) {
+	testCases := []struct {
+		name     string
+		rule     FeeRule
+		amount   int64
+		expected int64
+	}{
+		{
+			name:     "Flat",
+			rule:     FeeRule{FlatFee: 25},
+			amount:   1000,
+			expected: 25,
+		},
+		{
+			name:     "Percentage",
+			rule:     FeeRule{PercentageBps: 150},
+			amount:   1000,
+			expected: 15,
+		},
+		{
+			name:     "FlatAndPercentage",
+			rule:     FeeRule{FlatFee: 10, PercentageBps: 150},
+			amount:   1000,
+			expected: 25,
+		},
+		{
+			name:     "PercentageRoundsDown",
+			rule:     FeeRule{PercentageBps: 150},
+			amount:   99,
+			expected: 1,
+		},
+		{
+			name:     "MinFee",
+			rule:     FeeRule{PercentageBps: 100, MinFee: 5},
+			amount:   100,
+			expected: 5,
+		},
+		{
+			name:     "MaxFee",
+			rule:     FeeRule{PercentageBps: 100, MaxFee: 50},
+			amount:   1_000_000,
+			expected: 50,
+		},
+		{
+			name:     "NoCap",
+			rule:     FeeRule{PercentageBps: 100},
+			amount:   1_000_000,
+			expected: 10_000,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, calculateFee(tc.rule, tc.amount))
+		})
+	}
+}
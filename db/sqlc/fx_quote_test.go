@@ -0,0 +1,88 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"simplebank/fx"
+	"simplebank/util"
+
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomFxQuote(t *testing.T, username string, fromCurrency string, toCurrency string, duration time.Duration) FxQuote {
+	amount := util.RandomInt(1, 100)
+	marketRate := util.RandomInt(1, 2) * fx.RateScale
+	rate := fx.ApplySpread(marketRate, 50)
+
+	arg := CreateFxQuoteParams{
+		Username:     username,
+		FromCurrency: fromCurrency,
+		ToCurrency:   toCurrency,
+		FromAmount:   amount,
+		ToAmount:     fx.Convert(amount, rate),
+		MarketRate:   marketRate,
+		SpreadBps:    50,
+		Rate:         rate,
+		ExpiresAt:    time.Now().Add(duration),
+	}
+
+	quote, err := testQueries.CreateFxQuote(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, quote)
+
+	require.Equal(t, arg.Username, quote.Username)
+	require.Equal(t, arg.FromCurrency, quote.FromCurrency)
+	require.Equal(t, arg.ToCurrency, quote.ToCurrency)
+	require.Equal(t, arg.FromAmount, quote.FromAmount)
+	require.Equal(t, arg.ToAmount, quote.ToAmount)
+	require.Equal(t, arg.MarketRate, quote.MarketRate)
+	require.Equal(t, arg.SpreadBps, quote.SpreadBps)
+	require.Equal(t, arg.Rate, quote.Rate)
+	require.WithinDuration(t, arg.ExpiresAt, quote.ExpiresAt, time.Second)
+	require.False(t, quote.UsedAt.Valid)
+
+	require.NotZero(t, quote.ID)
+	require.NotZero(t, quote.CreatedAt)
+
+	return quote
+}
+
+func TestCreateFxQuote(t *testing.T) {
+	user := createRandomUser(t)
+	createRandomFxQuote(t, user.Username, util.USD, util.EUR, time.Minute)
+}
+
+func TestGetFxQuote(t *testing.T) {
+	user := createRandomUser(t)
+	quote1 := createRandomFxQuote(t, user.Username, util.USD, util.EUR, time.Minute)
+
+	quote2, err := testQueries.GetFxQuote(context.Background(), quote1.ID)
+	require.NoError(t, err)
+	require.Equal(t, quote1.ID, quote2.ID)
+	require.Equal(t, quote1.Rate, quote2.Rate)
+	require.Equal(t, quote1.ToAmount, quote2.ToAmount)
+}
+
+func TestUseFxQuote(t *testing.T) {
+	user := createRandomUser(t)
+	quote := createRandomFxQuote(t, user.Username, util.USD, util.EUR, time.Minute)
+
+	usedQuote, err := testQueries.UseFxQuote(context.Background(), quote.ID)
+	require.NoError(t, err)
+	require.True(t, usedQuote.UsedAt.Valid)
+
+	// A quote can only be used once
+	_, err = testQueries.UseFxQuote(context.Background(), quote.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestUseExpiredFxQuote(t *testing.T) {
+	user := createRandomUser(t)
+	quote := createRandomFxQuote(t, user.Username, util.USD, util.EUR, -time.Minute)
+
+	_, err := testQueries.UseFxQuote(context.Background(), quote.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
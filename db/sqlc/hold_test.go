@@ -0,0 +1,86 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"simplebank/util"
+
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomHold(t *testing.T, account Account, expiresAt time.Time) Hold {
+	arg := CreateHoldParams{
+		AccountID: account.ID,
+		Amount:    util.RandomMoney(),
+		User:      account.Owner,
+		ExpiresAt: expiresAt,
+	}
+
+	hold, err := testQueries.CreateHold(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, hold)
+
+	require.Equal(t, arg.AccountID, hold.AccountID)
+	require.Equal(t, arg.Amount, hold.Amount)
+	require.Equal(t, arg.User, hold.User)
+	require.WithinDuration(t, arg.ExpiresAt, hold.ExpiresAt, time.Second)
+	require.Equal(t, HoldActive, hold.Status)
+	require.Zero(t, hold.CapturedAmount)
+	require.False(t, hold.FinishedAt.Valid)
+
+	require.NotZero(t, hold.ID)
+	require.NotZero(t, hold.CreatedAt)
+
+	return hold
+}
+
+func TestCreateHold(t *testing.T) {
+	createRandomHold(t, createRandomAccount(t), time.Now().Add(time.Hour))
+}
+
+func TestGetHold(t *testing.T) {
+	hold1 := createRandomHold(t, createRandomAccount(t), time.Now().Add(time.Hour))
+
+	hold2, err := testQueries.GetHold(context.Background(), hold1.ID)
+	require.NoError(t, err)
+	require.Equal(t, hold1.ID, hold2.ID)
+	require.Equal(t, hold1.Amount, hold2.Amount)
+	require.WithinDuration(t, hold1.CreatedAt, hold2.CreatedAt, time.Second)
+
+	_, err = testQueries.GetHold(context.Background(), 0)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestListExpiredHolds(t *testing.T) {
+	account := createRandomAccount(t)
+	expired := createRandomHold(t, account, time.Now().Add(-time.Minute))
+	active := createRandomHold(t, account, time.Now().Add(time.Hour))
+
+	holds, err := testQueries.ListExpiredHolds(context.Background(), 1000)
+	require.NoError(t, err)
+	require.True(t, containsHold(holds, expired.ID))
+	require.False(t, containsHold(holds, active.ID))
+
+	// Finished holds are never expired
+	_, err = testQueries.FinishHold(context.Background(), FinishHoldParams{
+		ID:     expired.ID,
+		Status: HoldVoided,
+	})
+	require.NoError(t, err)
+
+	holds, err = testQueries.ListExpiredHolds(context.Background(), 1000)
+	require.NoError(t, err)
+	require.False(t, containsHold(holds, expired.ID))
+}
+
+func containsHold(holds []Hold, id int64) bool {
+	for _, hold := range holds {
+		if hold.ID == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"simplebank/util"
+
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomIdempotencyKey(t *testing.T, user User, expiresAt time.Time) IdempotencyKey {
+	arg := CreateIdempotencyKeyParams{
+		Username:    user.Username,
+		Key:         util.RandomString(32),
+		RequestHash: util.RandomString(64),
+		ExpiresAt:   expiresAt,
+	}
+
+	idempotencyKey, err := testQueries.CreateIdempotencyKey(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Username, idempotencyKey.Username)
+	require.Equal(t, arg.Key, idempotencyKey.Key)
+	require.Equal(t, arg.RequestHash, idempotencyKey.RequestHash)
+	require.Zero(t, idempotencyKey.ResponseStatus)
+	require.Empty(t, idempotencyKey.ResponseBody)
+	require.WithinDuration(t, arg.ExpiresAt, idempotencyKey.ExpiresAt, time.Second)
+
+	return idempotencyKey
+}
+
+func TestCreateIdempotencyKey(t *testing.T) {
+	user := createRandomUser(t)
+	idempotencyKey := createRandomIdempotencyKey(t, user, time.Now().Add(time.Hour))
+
+	// An active key can't be claimed again
+	_, err := testQueries.CreateIdempotencyKey(context.Background(), CreateIdempotencyKeyParams{
+		Username:    user.Username,
+		Key:         idempotencyKey.Key,
+		RequestHash: util.RandomString(64),
+		ExpiresAt:   time.Now().Add(time.Hour),
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	// But the same key can be used by another user
+	createRandomIdempotencyKey(t, createRandomUser(t), time.Now().Add(time.Hour))
+}
+
+func TestCreateIdempotencyKeyExpired(t *testing.T) {
+	user := createRandomUser(t)
+	idempotencyKey := createRandomIdempotencyKey(t, user, time.Now().Add(-time.Minute))
+
+	// An expired key is taken over by the new request
+	arg := CreateIdempotencyKeyParams{
+		Username:    user.Username,
+		Key:         idempotencyKey.Key,
+		RequestHash: util.RandomString(64),
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}
+	newIdempotencyKey, err := testQueries.CreateIdempotencyKey(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.RequestHash, newIdempotencyKey.RequestHash)
+}
+
+func TestUpdateIdempotencyKeyResponse(t *testing.T) {
+	user := createRandomUser(t)
+	idempotencyKey := createRandomIdempotencyKey(t, user, time.Now().Add(time.Hour))
+
+	arg := UpdateIdempotencyKeyResponseParams{
+		Username:       idempotencyKey.Username,
+		Key:            idempotencyKey.Key,
+		ResponseStatus: 200,
+		ResponseBody:   []byte(`{"id":1}`),
+	}
+	updatedIdempotencyKey, err := testQueries.UpdateIdempotencyKeyResponse(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.ResponseStatus, updatedIdempotencyKey.ResponseStatus)
+	require.Equal(t, arg.ResponseBody, updatedIdempotencyKey.ResponseBody)
+
+	gotIdempotencyKey, err := testQueries.GetIdempotencyKey(context.Background(), GetIdempotencyKeyParams{
+		Username: idempotencyKey.Username,
+		Key:      idempotencyKey.Key,
+	})
+	require.NoError(t, err)
+	require.Equal(t, updatedIdempotencyKey.ResponseBody, gotIdempotencyKey.ResponseBody)
+}
+
+func TestDeleteExpiredIdempotencyKeys(t *testing.T) {
+	user := createRandomUser(t)
+	expiredKey := createRandomIdempotencyKey(t, user, time.Now().Add(-time.Minute))
+	activeKey := createRandomIdempotencyKey(t, user, time.Now().Add(time.Hour))
+
+	deleted, err := testQueries.DeleteExpiredIdempotencyKeys(context.Background())
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, deleted, int64(1))
+
+	_, err = testQueries.GetIdempotencyKey(context.Background(), GetIdempotencyKeyParams{
+		Username: expiredKey.Username,
+		Key:      expiredKey.Key,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	// Keys which can still be replayed are kept
+	_, err = testQueries.GetIdempotencyKey(context.Background(), GetIdempotencyKeyParams{
+		Username: activeKey.Username,
+		Key:      activeKey.Key,
+	})
+	require.NoError(t, err)
+}
@@ -0,0 +1,177 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"simplebank/util"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetInterestRate(t *testing.T) {
+	rate, err := testQueries.GetInterestRate(context.Background(), GetInterestRateParams{
+		AccountType: AccountTypeSavings,
+		Currency:    util.USD,
+	})
+	require.NoError(t, err)
+	require.Positive(t, rate.AnnualRateBps)
+}
+
+func TestAccrueInterest(t *testing.T) {
+	savings := createRandomAccountWithType(t, util.USD, AccountTypeSavings)
+	savings = fundAccount(t, savings, 1_000_000)
+	// Checking accounts don't earn interest
+	checking := createRandomAccountWithCurrency(t, util.USD)
+
+	rate, err := testQueries.GetInterestRate(context.Background(), GetInterestRateParams{
+		AccountType: AccountTypeSavings,
+		Currency:    util.USD,
+	})
+	require.NoError(t, err)
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	accrued, err := testQueries.AccrueInterest(context.Background(), today)
+	require.NoError(t, err)
+	require.Positive(t, accrued)
+
+	accruals := accrualsOf(t, savings.ID)
+	require.Len(t, accruals, 1)
+	require.Equal(t, savings.Balance, accruals[0].Balance)
+	require.Equal(t, rate.AnnualRateBps, accruals[0].AnnualRateBps)
+	require.Equal(t, savings.Balance*int64(rate.AnnualRateBps), accruals[0].Amount)
+	require.False(t, accruals[0].PostingID.Valid)
+
+	require.Empty(t, accrualsOf(t, checking.ID))
+
+	// Accruing the same day again doesn't pay the interest twice
+	_, err = testQueries.AccrueInterest(context.Background(), today)
+	require.NoError(t, err)
+	require.Len(t, accrualsOf(t, savings.ID), 1)
+
+	// Nothing is accrued for the days before the account was opened
+	_, err = testQueries.AccrueInterest(context.Background(), today.AddDate(0, 0, -1))
+	require.NoError(t, err)
+	require.Len(t, accrualsOf(t, savings.ID), 1)
+}
+
+func TestPostInterestTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	savings := createRandomAccountWithType(t, util.EUR, AccountTypeSavings)
+	savings = fundAccount(t, savings, 1_000_000)
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	_, err := testQueries.AccrueInterest(context.Background(), today)
+	require.NoError(t, err)
+	accruals := accrualsOf(t, savings.ID)
+	require.Len(t, accruals, 1)
+
+	houseAccount, err := testQueries.GetHouseAccount(context.Background(), GetHouseAccountParams{
+		Purpose:  HousePurposeInterestExpense,
+		Currency: util.EUR,
+	})
+	require.NoError(t, err)
+
+	period := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+	arg := PostInterestTxParams{
+		AccountID: savings.ID,
+		Period:    period,
+	}
+	result, err := store.PostInterestTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	posting := result.Posting
+	require.Equal(t, savings.ID, posting.AccountID)
+	require.Equal(t, accruals[0].Amount/InterestScale, posting.Amount)
+	require.Equal(t, accruals[0].Amount%InterestScale, posting.Remainder)
+	require.Positive(t, posting.Amount)
+
+	require.Equal(t, savings.ID, result.Entry.AccountID)
+	require.Equal(t, posting.Amount, result.Entry.Amount)
+	require.Equal(t, EntrySourceInterest, result.Entry.SourceType)
+	require.Equal(t, posting.ID, result.Entry.SourceID)
+	require.Equal(t, savings.Balance+posting.Amount, result.Account.Balance)
+
+	// The interest is paid by the bank
+	updatedHouseAccount, err := testQueries.GetAccount(context.Background(), houseAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, houseAccount.Balance-posting.Amount, updatedHouseAccount.Balance)
+
+	accruals = accrualsOf(t, savings.ID)
+	require.True(t, accruals[0].PostingID.Valid)
+	require.Equal(t, posting.ID, accruals[0].PostingID.Int64)
+
+	// Posting the same month again doesn't pay the interest twice
+	_, err = store.PostInterestTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrInterestAlreadyPosted)
+
+	account, err := testQueries.GetAccount(context.Background(), savings.ID)
+	require.NoError(t, err)
+	require.Equal(t, result.Account.Balance, account.Balance)
+
+	// What couldn't be paid is carried over to the next month
+	result, err = store.PostInterestTx(context.Background(), PostInterestTxParams{
+		AccountID: savings.ID,
+		Period:    period.AddDate(0, 1, 0),
+	})
+	require.NoError(t, err)
+	require.Zero(t, result.Posting.Amount)
+	require.Equal(t, posting.Remainder, result.Posting.Remainder)
+	require.Empty(t, result.Entry)
+}
+
+func TestPostInterestTxConcurrent(t *testing.T) {
+	store := NewStore(testDB)
+
+	savings := createRandomAccountWithType(t, util.CAD, AccountTypeSavings)
+	savings = fundAccount(t, savings, 1_000_000)
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	_, err := testQueries.AccrueInterest(context.Background(), today)
+	require.NoError(t, err)
+
+	n := 5
+	errs := make(chan error)
+	arg := PostInterestTxParams{
+		AccountID: savings.ID,
+		Period:    time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := store.PostInterestTx(context.Background(), arg)
+			errs <- err
+		}()
+	}
+
+	// Only one of the postings pays the interest
+	succeeded := 0
+	for i := 0; i < n; i++ {
+		err := <-errs
+		if err == nil {
+			succeeded++
+			continue
+		}
+		require.ErrorIs(t, err, ErrInterestAlreadyPosted)
+	}
+	require.Equal(t, 1, succeeded)
+
+	accruals := accrualsOf(t, savings.ID)
+	require.Len(t, accruals, 1)
+
+	account, err := testQueries.GetAccount(context.Background(), savings.ID)
+	require.NoError(t, err)
+	require.Equal(t, savings.Balance+accruals[0].Amount/InterestScale, account.Balance)
+}
+
+func accrualsOf(t *testing.T, accountID int64) []InterestAccrual {
+	accruals, err := testQueries.ListInterestAccruals(context.Background(), ListInterestAccrualsParams{
+		AccountID: accountID,
+		Limit:     10,
+		Offset:    0,
+	})
+	require.NoError(t, err)
+	return accruals
+}
@@ -0,0 +1,90 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnbalancedJournal(t *testing.T) {
+	store := NewStore(testDB).(*SQLStore)
+	account := createRandomAccount(t)
+
+	// The journal is only checked when the transaction commits
+	err := store.execTx(context.Background(), func(q *Queries) error {
+		journal, err := q.CreateJournal(context.Background(), CreateJournalParams{
+			Kind:     EntrySourceDeposit,
+			SourceID: 1,
+		})
+		require.NoError(t, err)
+
+		_, err = q.CreateEntry(context.Background(), CreateEntryParams{
+			AccountID:  account.ID,
+			Amount:     10,
+			SourceType: EntrySourceDeposit,
+			SourceID:   1,
+			JournalID:  journal.ID,
+		})
+		return err
+	})
+	require.Error(t, err)
+
+	pqErr, ok := err.(*pq.Error)
+	require.True(t, ok)
+	require.Equal(t, "check_violation", pqErr.Code.Name())
+	require.Equal(t, "journal_balanced", pqErr.Constraint)
+
+	entries, err := testQueries.ListEntries(context.Background(), ListEntriesParams{
+		AccountID: account.ID,
+		Limit:     5,
+	})
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
+
+func TestPostJournalUnbalanced(t *testing.T) {
+	account := createRandomAccount(t)
+
+	_, _, err := postJournal(context.Background(), testQueries, EntrySourceDeposit, 1,
+		journalLine{AccountID: account.ID, Currency: account.Currency, Amount: 10},
+	)
+	require.EqualError(t, err, "deposit journal of 1 is off by 10 "+account.Currency)
+}
+
+func TestLedgerBalances(t *testing.T) {
+	// Every committed journal balances, so the ledger does too
+	unbalanced, err := testQueries.ListUnbalancedJournals(context.Background(), 10)
+	require.NoError(t, err)
+	require.Empty(t, unbalanced)
+
+	imbalances, err := testQueries.ListCurrencyImbalances(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, imbalances)
+}
+
+// requireBalancedJournal checks that the entries of a journal add up to zero in each currency, and returns them
+func requireBalancedJournal(t *testing.T, journalID int64) []Entry {
+	journal, err := testQueries.GetJournal(context.Background(), journalID)
+	require.NoError(t, err)
+
+	entries, err := testQueries.ListJournalEntries(context.Background(), journal.ID)
+	require.NoError(t, err)
+	require.NotEmpty(t, entries)
+
+	totals := make(map[string]int64)
+	for _, entry := range entries {
+		require.Equal(t, journal.Kind, entry.SourceType)
+		require.Equal(t, journal.SourceID, entry.SourceID)
+
+		account, err := testQueries.GetAccount(context.Background(), entry.AccountID)
+		require.NoError(t, err)
+		totals[account.Currency] += entry.Amount
+	}
+
+	for currency, total := range totals {
+		require.Zero(t, total, currency)
+	}
+	return entries
+}
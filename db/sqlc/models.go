@@ -5,17 +5,67 @@
 package db
 
 import (
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 type Account struct {
-	ID        int64     `json:"id"`
-	Owner     string    `json:"owner"`
+	ID    int64  `json:"id"`
+	Owner string `json:"owner"`
+	// balance - held_amount must not go below -overdraft_limit
 	Balance   int64     `json:"balance"`
 	Currency  string    `json:"currency"`
 	CreatedAt time.Time `json:"created_at"`
+	// how far below zero the balance is allowed to go
+	OverdraftLimit int64 `json:"overdraft_limit"`
+	// sum of the active holds on the account
+	HeldAmount int64 `json:"held_amount"`
+	// generated as balance - held_amount
+	AvailableBalance int64 `json:"available_balance"`
+	// checking or savings, and house for the accounts of the bank
+	Type string `json:"type"`
+	// active, frozen or closed, only active accounts can move money
+	Status string `json:"status"`
+	// nickname given by the owner, such as vacation fund
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type AccountMember struct {
+	AccountID int64 `json:"account_id"`
+	// user the account is shared with, besides its owner
+	Username string `json:"username"`
+	// owner, spender or viewer
+	Role string `json:"role"`
+	// largest amount a spender can take out of the account at once
+	SpendLimit int64     `json:"spend_limit"`
+	InvitedBy  string    `json:"invited_by"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type AccountStatusChange struct {
+	ID         int64  `json:"id"`
+	AccountID  int64  `json:"account_id"`
+	FromStatus string `json:"from_status"`
+	ToStatus   string `json:"to_status"`
+	// owner of the account or the admin who changed its status
+	ChangedBy string `json:"changed_by"`
+	Reason    string `json:"reason"`
+	// transfer which paid out the balance of a closed account
+	PayoutTransferID sql.NullInt64 `json:"payout_transfer_id"`
+	CreatedAt        time.Time     `json:"created_at"`
+	// made by a banker or an admin, whatever their role is now
+	ByStaff bool `json:"by_staff"`
+}
+
+type BalanceSnapshot struct {
+	AccountID    int64     `json:"account_id"`
+	SnapshotDate time.Time `json:"snapshot_date"`
+	// balance of the account at the end of the day, in UTC
+	Balance   int64     `json:"balance"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 type Deposit struct {
@@ -32,6 +82,176 @@ type Entry struct {
 	// can be negative or positive
 	Amount    int64     `json:"amount"`
 	CreatedAt time.Time `json:"created_at"`
+	// kind of transaction which created the entry
+	SourceType string `json:"source_type"`
+	// id of the transaction which created the entry
+	SourceID int64 `json:"source_id"`
+	// posting the entry is a line of, whose entries add up to zero in every currency
+	JournalID int64 `json:"journal_id"`
+}
+
+type FeeRule struct {
+	ID       int64  `json:"id"`
+	Currency string `json:"currency"`
+	// same_currency or cross_currency
+	TransferType string `json:"transfer_type"`
+	// the rule applies to transfers of at least this amount, until the rule with the next tier
+	MinAmount int64 `json:"min_amount"`
+	FlatFee   int64 `json:"flat_fee"`
+	// charged on the amount, in basis points
+	PercentageBps int32 `json:"percentage_bps"`
+	MinFee        int64 `json:"min_fee"`
+	// zero when the fee has no cap
+	MaxFee    int64     `json:"max_fee"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type FxQuote struct {
+	ID           int64  `json:"id"`
+	Username     string `json:"username"`
+	FromCurrency string `json:"from_currency"`
+	ToCurrency   string `json:"to_currency"`
+	FromAmount   int64  `json:"from_amount"`
+	ToAmount     int64  `json:"to_amount"`
+	// rate from the provider, scaled by 10^8
+	MarketRate int64 `json:"market_rate"`
+	// spread taken out of the market rate, in basis points
+	SpreadBps int32 `json:"spread_bps"`
+	// rate applied to the transfer, scaled by 10^8
+	Rate      int64     `json:"rate"`
+	ExpiresAt time.Time `json:"expires_at"`
+	// a quote can be used by a single transfer
+	UsedAt    sql.NullTime `json:"used_at"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+type Hold struct {
+	ID        int64 `json:"id"`
+	AccountID int64 `json:"account_id"`
+	// must be positive, reserved until the hold is captured, voided or expired
+	Amount int64 `json:"amount"`
+	// how much was taken from the account, up to the amount held
+	CapturedAmount int64 `json:"captured_amount"`
+	// active, captured, voided or expired
+	Status     string       `json:"status"`
+	User       string       `json:"user"`
+	ExpiresAt  time.Time    `json:"expires_at"`
+	FinishedAt sql.NullTime `json:"finished_at"`
+	CreatedAt  time.Time    `json:"created_at"`
+}
+
+type HouseAccount struct {
+	Currency string `json:"currency"`
+	// account which collects the fees charged in the currency
+	AccountID int64 `json:"account_id"`
+	// fee_revenue or interest_expense
+	Purpose string `json:"purpose"`
+}
+
+type IdempotencyKey struct {
+	Username string `json:"username"`
+	Key      string `json:"key"`
+	// hash of the method, path and body of the original request
+	RequestHash string `json:"request_hash"`
+	// zero while the original request is still being processed
+	ResponseStatus int32     `json:"response_status"`
+	ResponseBody   []byte    `json:"response_body"`
+	ExpiresAt      time.Time `json:"expires_at"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type InterestAccrual struct {
+	ID          int64     `json:"id"`
+	AccountID   int64     `json:"account_id"`
+	AccrualDate time.Time `json:"accrual_date"`
+	// balance of the account at the end of the day
+	Balance       int64 `json:"balance"`
+	AnnualRateBps int32 `json:"annual_rate_bps"`
+	// balance * annual_rate_bps, which is the interest of the day in 1/3650000 of the smallest unit
+	Amount int64 `json:"amount"`
+	// set once the interest is paid
+	PostingID sql.NullInt64 `json:"posting_id"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+type InterestPosting struct {
+	ID        int64 `json:"id"`
+	AccountID int64 `json:"account_id"`
+	// first day of the month the interest was accrued in
+	Period time.Time `json:"period"`
+	// interest credited to the account
+	Amount int64 `json:"amount"`
+	// fraction of the smallest unit left over, carried to the next posting
+	Remainder int64     `json:"remainder"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type InterestRate struct {
+	AccountType string `json:"account_type"`
+	Currency    string `json:"currency"`
+	// yearly interest rate, in basis points
+	AnnualRateBps int32     `json:"annual_rate_bps"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type Journal struct {
+	ID int64 `json:"id"`
+	// kind of transaction which made the posting
+	Kind string `json:"kind"`
+	// id of the transaction which made the posting
+	SourceID  int64     `json:"source_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type RecoveryCode struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	// SHA-256 of the code, which can be used once instead of a TOTP code
+	HashedCode string       `json:"hashed_code"`
+	UsedAt     sql.NullTime `json:"used_at"`
+	CreatedAt  time.Time    `json:"created_at"`
+}
+
+type ScheduledTransfer struct {
+	ID            int64  `json:"id"`
+	Owner         string `json:"owner"`
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	Amount        int64  `json:"amount"`
+	// once, daily, weekly or monthly
+	Frequency string    `json:"frequency"`
+	StartAt   time.Time `json:"start_at"`
+	NextRunAt time.Time `json:"next_run_at"`
+	// active, paused, completed or cancelled
+	Status string `json:"status"`
+	// consecutive failed runs, the order is paused after too many of them
+	FailureCount int32 `json:"failure_count"`
+	// set while a worker is executing the order
+	LockedUntil sql.NullTime `json:"locked_until"`
+	LastRunAt   sql.NullTime `json:"last_run_at"`
+	CreatedAt   time.Time    `json:"created_at"`
+}
+
+type ScheduledTransferRun struct {
+	ID                  int64     `json:"id"`
+	ScheduledTransferID int64     `json:"scheduled_transfer_id"`
+	ScheduledFor        time.Time `json:"scheduled_for"`
+	// pending, succeeded or failed
+	Status     string        `json:"status"`
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	Error      string        `json:"error"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
+type SecurityEvent struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	// refresh_token_reused
+	Kind            string    `json:"kind"`
+	SessionFamilyID uuid.UUID `json:"session_family_id"`
+	UserAgent       string    `json:"user_agent"`
+	ClientIp        string    `json:"client_ip"`
+	CreatedAt       time.Time `json:"created_at"`
 }
 
 type Session struct {
@@ -43,15 +263,71 @@ type Session struct {
 	IsBlocked    bool      `json:"is_blocked"`
 	ExpiresAt    time.Time `json:"expires_at"`
 	CreatedAt    time.Time `json:"created_at"`
+	// session the refresh token was first issued for at login, shared by every rotation of it
+	FamilyID uuid.UUID `json:"family_id"`
+	// session of the refresh token this one replaced
+	ParentID uuid.NullUUID `json:"parent_id"`
+	// when the refresh token was exchanged for a new one, after which it must not be used again
+	RotatedAt sql.NullTime `json:"rotated_at"`
+}
+
+type Statement struct {
+	ID        int64 `json:"id"`
+	AccountID int64 `json:"account_id"`
+	// first day of the month of the statement
+	Period    time.Time `json:"period"`
+	Pdf       []byte    `json:"pdf"`
+	Csv       []byte    `json:"csv"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type TotpSecret struct {
+	Username string `json:"username"`
+	// encrypted with the TOTP encryption key of the config
+	EncryptedSecret []byte `json:"encrypted_secret"`
+	// time step of the last code accepted, earlier codes being refused so they can't be replayed
+	LastUsedStep int64 `json:"last_used_step"`
+	// codes refused in a row, checking codes being locked for a while when there are too many
+	FailedAttempts int32        `json:"failed_attempts"`
+	LastFailedAt   sql.NullTime `json:"last_failed_at"`
+	// when the first code was verified, from which codes are asked at login and for sensitive operations
+	EnabledAt sql.NullTime `json:"enabled_at"`
+	CreatedAt time.Time    `json:"created_at"`
 }
 
 type Transfer struct {
 	ID            int64 `json:"id"`
 	FromAccountID int64 `json:"from_account_id"`
 	ToAccountID   int64 `json:"to_account_id"`
-	// must be positive
-	Amount    int64     `json:"amount"`
-	CreatedAt time.Time `json:"created_at"`
+	// must be positive, in the currency of the origin account
+	Amount       int64     `json:"amount"`
+	CreatedAt    time.Time `json:"created_at"`
+	FromCurrency string    `json:"from_currency"`
+	ToCurrency   string    `json:"to_currency"`
+	// amount credited, in the currency of the destination account
+	ToAmount int64 `json:"to_amount"`
+	// rate applied to the transfer, scaled by 10^8
+	Rate      int64         `json:"rate"`
+	SpreadBps int32         `json:"spread_bps"`
+	QuoteID   sql.NullInt64 `json:"quote_id"`
+	// how much of to_amount was refunded, up to all of it
+	ReversedAmount int64 `json:"reversed_amount"`
+	// the transfer which is refunded by this one
+	ReversalOfID sql.NullInt64 `json:"reversal_of_id"`
+	// charged to the origin account on top of the amount, in its currency
+	Fee int64 `json:"fee"`
+}
+
+type TransferLimit struct {
+	Tier     string `json:"tier"`
+	Currency string `json:"currency"`
+	// largest amount a single transfer or withdrawal can take out
+	MaxPerTransaction int64 `json:"max_per_transaction"`
+	// total which can be taken out of the accounts of the user in a day
+	DailyAmount int64 `json:"daily_amount"`
+	// how many transfers and withdrawals the user can make in a day
+	DailyCount int32     `json:"daily_count"`
+	CreatedAt  time.Time `json:"created_at"`
 }
 
 type User struct {
@@ -61,6 +337,10 @@ type User struct {
 	Email             string    `json:"email"`
 	PasswordChangedAt time.Time `json:"password_changed_at"`
 	CreatedAt         time.Time `json:"created_at"`
+	// picks the transfer limits which apply to the user
+	Tier string `json:"tier"`
+	// customer, banker or admin
+	Role string `json:"role"`
 }
 
 type Withdraw struct {
@@ -6,34 +6,168 @@ package db
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type Querier interface {
+	// Accrues the interest of a day for every account with an interest rate.
+	// The balance at the end of the day is the current one minus everything which came after,
+	// so the result doesn't depend on when the day is accrued, and days which were accrued already are skipped
+	AccrueInterest(ctx context.Context, accrualDate time.Time) (int64, error)
 	AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error)
+	AddAccountHeldAmount(ctx context.Context, arg AddAccountHeldAmountParams) (Account, error)
+	AddTransferReversedAmount(ctx context.Context, arg AddTransferReversedAmountParams) (Transfer, error)
+	// Blocks every refresh token of the family of the session, so the device is signed out
+	// whichever of them its access tokens were issued with
+	BlockSession(ctx context.Context, arg BlockSessionParams) (int64, error)
+	BlockSessionFamily(ctx context.Context, familyID uuid.UUID) (int64, error)
+	BlockUserSessions(ctx context.Context, username string) (int64, error)
+	CancelScheduledTransfer(ctx context.Context, id int64) (ScheduledTransfer, error)
+	// Orders locked by another worker are skipped, so several workers can run at the same time.
+	// Orders made by members who can no longer spend their amount from the account are left alone
+	ClaimDueScheduledTransfers(ctx context.Context, arg ClaimDueScheduledTransfersParams) ([]ScheduledTransfer, error)
+	CountOpenAccounts(ctx context.Context, arg CountOpenAccountsParams) (int64, error)
+	// Recovery codes of the user which weren't used yet
+	CountRecoveryCodes(ctx context.Context, username string) (int64, error)
 	CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error)
+	CreateAccountMember(ctx context.Context, arg CreateAccountMemberParams) (AccountMember, error)
+	CreateAccountStatusChange(ctx context.Context, arg CreateAccountStatusChangeParams) (AccountStatusChange, error)
+	// Takes the balance every account had at the end of a day, skipping the accounts which have it already
+	CreateBalanceSnapshots(ctx context.Context, snapshotDate time.Time) (int64, error)
 	CreateDeposit(ctx context.Context, arg CreateDepositParams) (Deposit, error)
 	CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error)
+	CreateFeeRule(ctx context.Context, arg CreateFeeRuleParams) (FeeRule, error)
+	CreateFxQuote(ctx context.Context, arg CreateFxQuoteParams) (FxQuote, error)
+	CreateHold(ctx context.Context, arg CreateHoldParams) (Hold, error)
+	// An expired key can be taken over by a new request
+	CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (IdempotencyKey, error)
+	// Nothing is returned if the period was already posted
+	CreateInterestPosting(ctx context.Context, arg CreateInterestPostingParams) (InterestPosting, error)
+	CreateInterestRate(ctx context.Context, arg CreateInterestRateParams) (InterestRate, error)
+	CreateJournal(ctx context.Context, arg CreateJournalParams) (Journal, error)
+	CreateRecoveryCode(ctx context.Context, arg CreateRecoveryCodeParams) (RecoveryCode, error)
+	CreateScheduledTransfer(ctx context.Context, arg CreateScheduledTransferParams) (ScheduledTransfer, error)
+	CreateScheduledTransferRun(ctx context.Context, arg CreateScheduledTransferRunParams) (ScheduledTransferRun, error)
+	CreateSecurityEvent(ctx context.Context, arg CreateSecurityEventParams) (SecurityEvent, error)
 	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
+	// Nothing is returned if the statement was already generated
+	CreateStatement(ctx context.Context, arg CreateStatementParams) (Statement, error)
 	CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error)
+	CreateTransferLimit(ctx context.Context, arg CreateTransferLimitParams) (TransferLimit, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
 	CreateWithdraw(ctx context.Context, arg CreateWithdrawParams) (Withdraw, error)
-	DeleteAccount(ctx context.Context, id int64) error
+	DeleteAccountMember(ctx context.Context, arg DeleteAccountMemberParams) (int64, error)
+	DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error)
+	DeleteFeeRule(ctx context.Context, id int64) error
+	DeleteIdempotencyKey(ctx context.Context, arg DeleteIdempotencyKeyParams) error
+	DeleteInterestRate(ctx context.Context, arg DeleteInterestRateParams) error
+	DeleteRecoveryCodes(ctx context.Context, username string) error
+	DeleteTotpSecret(ctx context.Context, username string) (int64, error)
+	EnableTotpSecret(ctx context.Context, username string) (TotpSecret, error)
+	FinishHold(ctx context.Context, arg FinishHoldParams) (Hold, error)
+	FinishScheduledTransferRun(ctx context.Context, arg FinishScheduledTransferRunParams) (ScheduledTransfer, error)
 	GetAccount(ctx context.Context, id int64) (Account, error)
+	// The balance of the account at a point in time is the last snapshot taken before it, plus the entries which came
+	// after the snapshot. Without a snapshot, it's the current balance minus the entries which came after that point
+	GetAccountBalanceAt(ctx context.Context, arg GetAccountBalanceAtParams) (int64, error)
 	GetAccountForUpdate(ctx context.Context, id int64) (Account, error)
+	GetAccountMember(ctx context.Context, arg GetAccountMemberParams) (AccountMember, error)
+	GetBalanceSnapshot(ctx context.Context, arg GetBalanceSnapshotParams) (BalanceSnapshot, error)
+	// Reversals give money back, and money moved between the accounts of the owner stays with them,
+	// so they don't count against the limits
+	GetDailyOutgoing(ctx context.Context, arg GetDailyOutgoingParams) (GetDailyOutgoingRow, error)
 	GetDeposit(ctx context.Context, id int64) (Deposit, error)
 	GetEntry(ctx context.Context, id int64) (Entry, error)
+	// The rule of the highest tier the amount reaches
+	GetFeeRule(ctx context.Context, arg GetFeeRuleParams) (FeeRule, error)
+	GetFxQuote(ctx context.Context, id int64) (FxQuote, error)
+	GetHold(ctx context.Context, id int64) (Hold, error)
+	GetHoldForUpdate(ctx context.Context, id int64) (Hold, error)
+	GetHouseAccount(ctx context.Context, arg GetHouseAccountParams) (Account, error)
+	GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error)
+	GetInterestRate(ctx context.Context, arg GetInterestRateParams) (InterestRate, error)
+	GetJournal(ctx context.Context, id int64) (Journal, error)
+	GetLastAccountStatusChange(ctx context.Context, accountID int64) (AccountStatusChange, error)
+	GetLastInterestPosting(ctx context.Context, accountID int64) (InterestPosting, error)
+	GetScheduledTransfer(ctx context.Context, id int64) (ScheduledTransfer, error)
 	GetSession(ctx context.Context, id uuid.UUID) (Session, error)
+	GetSessionForUpdate(ctx context.Context, id uuid.UUID) (Session, error)
+	GetStatement(ctx context.Context, arg GetStatementParams) (Statement, error)
+	GetTotpSecret(ctx context.Context, username string) (TotpSecret, error)
 	GetTransfer(ctx context.Context, id int64) (Transfer, error)
+	GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error)
+	GetTransferLimit(ctx context.Context, arg GetTransferLimitParams) (TransferLimit, error)
 	GetUser(ctx context.Context, username string) (User, error)
-	Getwithdraw(ctx context.Context, id int64) (Withdraw, error)
+	GetUserForUpdate(ctx context.Context, username string) (User, error)
+	GetWithdraw(ctx context.Context, id int64) (Withdraw, error)
+	ListAccountMembers(ctx context.Context, accountID int64) ([]AccountMember, error)
+	ListAccountStatusChanges(ctx context.Context, arg ListAccountStatusChangesParams) ([]AccountStatusChange, error)
+	// Accounts the user owns or has been invited to, the name matching any part of it regardless of case
 	ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error)
+	// Interest accrued by an account which was closed since is forfeited
+	ListAccountsWithUnpostedInterest(ctx context.Context, periodEnd time.Time) ([]int64, error)
+	// Accounts which were open during the period and don't have its statement yet
+	ListAccountsWithoutStatement(ctx context.Context, arg ListAccountsWithoutStatementParams) ([]Account, error)
+	// Sessions which can still renew access tokens, the most recent first.
+	// Only the last refresh token of each family can, the family standing for a device
+	ListActiveSessions(ctx context.Context, arg ListActiveSessionsParams) ([]Session, error)
+	// Accounts whose balance isn't the sum of their entries
+	ListBalanceMismatches(ctx context.Context, limit int32) ([]ListBalanceMismatchesRow, error)
+	// Currencies whose entries don't add up to zero
+	ListCurrencyImbalances(ctx context.Context) ([]ListCurrencyImbalancesRow, error)
+	// Deposits which don't have exactly one entry putting the amount into the account
+	ListDepositEntryMismatches(ctx context.Context, limit int32) ([]ListDepositEntryMismatchesRow, error)
 	ListDeposits(ctx context.Context, arg ListDepositsParams) ([]Deposit, error)
 	ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error)
+	ListExpiredHolds(ctx context.Context, limit int32) ([]Hold, error)
+	ListHolds(ctx context.Context, arg ListHoldsParams) ([]Hold, error)
+	ListInterestAccruals(ctx context.Context, arg ListInterestAccrualsParams) ([]InterestAccrual, error)
+	ListJournalEntries(ctx context.Context, journalID int64) ([]Entry, error)
+	ListLedgerEntries(ctx context.Context, arg ListLedgerEntriesParams) ([]ListLedgerEntriesRow, error)
+	ListScheduledTransferRuns(ctx context.Context, arg ListScheduledTransferRunsParams) ([]ScheduledTransferRun, error)
+	ListScheduledTransfers(ctx context.Context, arg ListScheduledTransfersParams) ([]ScheduledTransfer, error)
+	ListSecurityEvents(ctx context.Context, arg ListSecurityEventsParams) ([]SecurityEvent, error)
+	// Entries posted during the period, with the other account of the transfers
+	ListStatementEntries(ctx context.Context, arg ListStatementEntriesParams) ([]ListStatementEntriesRow, error)
+	// Transfers which don't have exactly one entry taking the amount out of the origin account
+	// and one entry putting it into the destination account. The lines of the house accounts are
+	// checked along with every other journal
+	ListTransferEntryMismatches(ctx context.Context, limit int32) ([]ListTransferEntryMismatchesRow, error)
 	ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error)
+	// Journals whose entries don't add up to zero in one of their currencies
+	ListUnbalancedJournals(ctx context.Context, limit int32) ([]ListUnbalancedJournalsRow, error)
+	// Withdrawals which don't have exactly one entry taking the amount out of the account
+	ListWithdrawEntryMismatches(ctx context.Context, limit int32) ([]ListWithdrawEntryMismatchesRow, error)
 	ListWithdraws(ctx context.Context, arg ListWithdrawsParams) ([]Withdraw, error)
+	MarkInterestPosted(ctx context.Context, arg MarkInterestPostedParams) (int64, error)
+	// Orders being executed by a worker can't be changed until the run is over
+	PauseScheduledTransfer(ctx context.Context, id int64) (ScheduledTransfer, error)
+	// Counts a refused code, the count starting over when the last one was refused before the window started
+	RecordTotpFailure(ctx context.Context, arg RecordTotpFailureParams) (TotpSecret, error)
+	ResetTotpFailures(ctx context.Context, username string) error
+	// Resumed orders start again with a clean record of failures
+	ResumeScheduledTransfer(ctx context.Context, id int64) (ScheduledTransfer, error)
+	RotateSession(ctx context.Context, id uuid.UUID) error
+	SumUnpostedInterest(ctx context.Context, arg SumUnpostedInterestParams) (int64, error)
 	UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error)
+	// Fields which are null keep their value
+	UpdateAccountDetails(ctx context.Context, arg UpdateAccountDetailsParams) (Account, error)
+	UpdateAccountOverdraftLimit(ctx context.Context, arg UpdateAccountOverdraftLimitParams) (Account, error)
+	UpdateAccountStatus(ctx context.Context, arg UpdateAccountStatusParams) (Account, error)
+	UpdateIdempotencyKeyResponse(ctx context.Context, arg UpdateIdempotencyKeyResponseParams) (IdempotencyKey, error)
+	UpdateScheduledTransferRun(ctx context.Context, arg UpdateScheduledTransferRunParams) (ScheduledTransferRun, error)
+	UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (User, error)
+	UpdateUserTier(ctx context.Context, arg UpdateUserTierParams) (User, error)
+	// Starts the enrolment of an authenticator, replacing the secret of an enrolment which wasn't verified.
+	// Nothing is returned when the user already has two-factor authentication enabled
+	UpsertTotpSecret(ctx context.Context, arg UpsertTotpSecretParams) (TotpSecret, error)
+	// Only a valid quote which wasn't used yet is returned
+	UseFxQuote(ctx context.Context, id int64) (FxQuote, error)
+	UseRecoveryCode(ctx context.Context, arg UseRecoveryCodeParams) (int64, error)
+	// Accepts a code of the time step, unless a code of the same or a later step was already accepted
+	UseTotpStep(ctx context.Context, arg UseTotpStepParams) (int64, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -0,0 +1,122 @@
+package db
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"simplebank/fx"
+	"simplebank/util"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Other tests leave many broken records behind, so every violation is listed
+const allViolations = math.MaxInt32
+
+func TestListBalanceMismatches(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+
+	// Money which is added without an entry makes the balance drift
+	fundAccount(t, account, 100)
+	require.True(t, containsBalanceMismatch(t, account.ID))
+
+	_, err := testQueries.UpdateAccount(context.Background(), UpdateAccountParams{
+		ID:      account.ID,
+		Balance: 0,
+	})
+	require.NoError(t, err)
+	require.False(t, containsBalanceMismatch(t, account.ID))
+
+	// Money which is moved along with its entries keeps the account reconciled
+	_, err = store.DepositTx(context.Background(), DepositTxParams{
+		AccountID: account.ID,
+		Amount:    100,
+		User:      account.Owner,
+	})
+	require.NoError(t, err)
+	require.False(t, containsBalanceMismatch(t, account.ID))
+}
+
+func TestListTransferEntryMismatches(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccountWithCurrency(t, util.USD)
+	account2 := createRandomAccountWithCurrency(t, util.USD)
+	account1 = fundAccount(t, account1, 1000)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	// A transfer which was made without its entries is reported
+	broken, err := testQueries.CreateTransfer(context.Background(), CreateTransferParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+		FromCurrency:  util.USD,
+		ToCurrency:    util.USD,
+		ToAmount:      10,
+		Rate:          fx.RateScale,
+	})
+	require.NoError(t, err)
+
+	mismatches, err := testQueries.ListTransferEntryMismatches(context.Background(), allViolations)
+	require.NoError(t, err)
+
+	var found bool
+	for _, mismatch := range mismatches {
+		require.NotEqual(t, result.Transfer.ID, mismatch.TransferID)
+		if mismatch.TransferID == broken.ID {
+			found = true
+			require.Zero(t, mismatch.EntryCount)
+		}
+	}
+	require.True(t, found)
+}
+
+func TestListDepositEntryMismatches(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+
+	result, err := store.DepositTx(context.Background(), DepositTxParams{
+		AccountID: account.ID,
+		Amount:    100,
+		User:      account.Owner,
+	})
+	require.NoError(t, err)
+
+	broken, err := testQueries.CreateDeposit(context.Background(), CreateDepositParams{
+		AccountID: account.ID,
+		Amount:    100,
+		User:      account.Owner,
+	})
+	require.NoError(t, err)
+
+	mismatches, err := testQueries.ListDepositEntryMismatches(context.Background(), allViolations)
+	require.NoError(t, err)
+
+	var found bool
+	for _, mismatch := range mismatches {
+		require.NotEqual(t, result.Deposit.ID, mismatch.DepositID)
+		if mismatch.DepositID == broken.ID {
+			found = true
+		}
+	}
+	require.True(t, found)
+}
+
+func containsBalanceMismatch(t *testing.T, accountID int64) bool {
+	mismatches, err := testQueries.ListBalanceMismatches(context.Background(), allViolations)
+	require.NoError(t, err)
+
+	for _, mismatch := range mismatches {
+		if mismatch.AccountID == accountID {
+			return true
+		}
+	}
+	return false
+}
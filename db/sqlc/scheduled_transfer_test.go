@@ -0,0 +1,239 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"simplebank/util"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomScheduledTransfer(t *testing.T, startAt time.Time) ScheduledTransfer {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccountWithCurrency(t, account1.Currency)
+
+	arg := CreateScheduledTransferParams{
+		Owner:         account1.Owner,
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        util.RandomMoney(),
+		Frequency:     FrequencyMonthly,
+		StartAt:       startAt,
+	}
+
+	scheduledTransfer, err := testQueries.CreateScheduledTransfer(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, scheduledTransfer)
+
+	require.Equal(t, arg.Owner, scheduledTransfer.Owner)
+	require.Equal(t, arg.FromAccountID, scheduledTransfer.FromAccountID)
+	require.Equal(t, arg.ToAccountID, scheduledTransfer.ToAccountID)
+	require.Equal(t, arg.Amount, scheduledTransfer.Amount)
+	require.Equal(t, arg.Frequency, scheduledTransfer.Frequency)
+	require.WithinDuration(t, arg.StartAt, scheduledTransfer.StartAt, time.Second)
+	// The first run happens at the start time
+	require.WithinDuration(t, arg.StartAt, scheduledTransfer.NextRunAt, time.Second)
+	require.Equal(t, ScheduledTransferActive, scheduledTransfer.Status)
+	require.Zero(t, scheduledTransfer.FailureCount)
+	require.False(t, scheduledTransfer.LockedUntil.Valid)
+
+	require.NotZero(t, scheduledTransfer.ID)
+	require.NotZero(t, scheduledTransfer.CreatedAt)
+
+	return scheduledTransfer
+}
+
+func TestCreateScheduledTransfer(t *testing.T) {
+	createRandomScheduledTransfer(t, time.Now().Add(time.Hour))
+}
+
+func TestClaimDueScheduledTransfers(t *testing.T) {
+	due := createRandomScheduledTransfer(t, time.Now().Add(-time.Minute))
+	notDue := createRandomScheduledTransfer(t, time.Now().Add(time.Hour))
+
+	arg := ClaimDueScheduledTransfersParams{
+		LockedUntil: time.Now().Add(time.Minute),
+		Limit:       1000,
+	}
+
+	claimed, err := testQueries.ClaimDueScheduledTransfers(context.Background(), arg)
+	require.NoError(t, err)
+	require.True(t, containsScheduledTransfer(claimed, due.ID))
+	require.False(t, containsScheduledTransfer(claimed, notDue.ID))
+
+	// Claimed orders can't be claimed again until they are finished
+	claimed, err = testQueries.ClaimDueScheduledTransfers(context.Background(), arg)
+	require.NoError(t, err)
+	require.False(t, containsScheduledTransfer(claimed, due.ID))
+
+	nextRunAt := time.Now().Add(24 * time.Hour)
+	finished, err := testQueries.FinishScheduledTransferRun(context.Background(), FinishScheduledTransferRunParams{
+		ID:           due.ID,
+		NextRunAt:    nextRunAt,
+		Status:       ScheduledTransferActive,
+		FailureCount: 0,
+	})
+	require.NoError(t, err)
+	require.WithinDuration(t, nextRunAt, finished.NextRunAt, time.Second)
+	require.False(t, finished.LockedUntil.Valid)
+	require.True(t, finished.LastRunAt.Valid)
+}
+
+func TestClaimDueScheduledTransfersOfSpender(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccountWithCurrency(t, account1.Currency)
+	spender := createRandomAccountMember(t, account1, AccountRoleSpender)
+
+	createOrder := func(amount int64) ScheduledTransfer {
+		scheduledTransfer, err := testQueries.CreateScheduledTransfer(context.Background(), CreateScheduledTransferParams{
+			Owner:         spender.Username,
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        amount,
+			Frequency:     FrequencyMonthly,
+			StartAt:       time.Now().Add(-time.Minute),
+		})
+		require.NoError(t, err)
+		return scheduledTransfer
+	}
+
+	withinLimit := createOrder(spender.SpendLimit)
+	overLimit := createOrder(spender.SpendLimit + 1)
+
+	claimed, err := testQueries.ClaimDueScheduledTransfers(context.Background(), ClaimDueScheduledTransfersParams{
+		LockedUntil: time.Now().Add(time.Minute),
+		Limit:       1000,
+	})
+	require.NoError(t, err)
+	require.True(t, containsScheduledTransfer(claimed, withinLimit.ID))
+	// The spend limit is checked again on every run
+	require.False(t, containsScheduledTransfer(claimed, overLimit.ID))
+}
+
+func TestCreateScheduledTransferRun(t *testing.T) {
+	scheduledTransfer := createRandomScheduledTransfer(t, time.Now())
+
+	arg := CreateScheduledTransferRunParams{
+		ScheduledTransferID: scheduledTransfer.ID,
+		ScheduledFor:        scheduledTransfer.NextRunAt,
+		Status:              RunPending,
+	}
+
+	run, err := testQueries.CreateScheduledTransferRun(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.ScheduledTransferID, run.ScheduledTransferID)
+	require.Equal(t, RunPending, run.Status)
+
+	// The same occurrence can't be executed twice
+	_, err = testQueries.CreateScheduledTransferRun(context.Background(), arg)
+	require.Error(t, err)
+	pqErr, ok := err.(*pq.Error)
+	require.True(t, ok)
+	require.Equal(t, "unique_violation", pqErr.Code.Name())
+
+	// But a failed attempt can be retried
+	run, err = testQueries.UpdateScheduledTransferRun(context.Background(), UpdateScheduledTransferRunParams{
+		ID:     run.ID,
+		Status: RunFailed,
+		Error:  ErrInsufficientFunds.Error(),
+	})
+	require.NoError(t, err)
+	require.Equal(t, RunFailed, run.Status)
+	require.False(t, run.TransferID.Valid)
+
+	_, err = testQueries.CreateScheduledTransferRun(context.Background(), arg)
+	require.NoError(t, err)
+
+	runs, err := testQueries.ListScheduledTransferRuns(context.Background(), ListScheduledTransferRunsParams{
+		ScheduledTransferID: scheduledTransfer.ID,
+		Limit:               5,
+		Offset:              0,
+	})
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+}
+
+func TestGetScheduledTransfer(t *testing.T) {
+	scheduledTransfer1 := createRandomScheduledTransfer(t, time.Now())
+
+	scheduledTransfer2, err := testQueries.GetScheduledTransfer(context.Background(), scheduledTransfer1.ID)
+	require.NoError(t, err)
+	require.Equal(t, scheduledTransfer1.ID, scheduledTransfer2.ID)
+	require.Equal(t, scheduledTransfer1.Owner, scheduledTransfer2.Owner)
+
+	_, err = testQueries.GetScheduledTransfer(context.Background(), 0)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestPauseAndResumeScheduledTransfer(t *testing.T) {
+	scheduledTransfer := createRandomScheduledTransfer(t, time.Now().Add(time.Hour))
+
+	paused, err := testQueries.PauseScheduledTransfer(context.Background(), scheduledTransfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, ScheduledTransferPaused, paused.Status)
+
+	// Only active orders can be paused
+	_, err = testQueries.PauseScheduledTransfer(context.Background(), scheduledTransfer.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	// Orders paused by the worker after too many failures start again from zero
+	_, err = testQueries.FinishScheduledTransferRun(context.Background(), FinishScheduledTransferRunParams{
+		ID:           scheduledTransfer.ID,
+		NextRunAt:    scheduledTransfer.NextRunAt,
+		Status:       ScheduledTransferPaused,
+		FailureCount: 3,
+	})
+	require.NoError(t, err)
+
+	resumed, err := testQueries.ResumeScheduledTransfer(context.Background(), scheduledTransfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, ScheduledTransferActive, resumed.Status)
+	require.Zero(t, resumed.FailureCount)
+
+	_, err = testQueries.ResumeScheduledTransfer(context.Background(), scheduledTransfer.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestCancelScheduledTransfer(t *testing.T) {
+	scheduledTransfer := createRandomScheduledTransfer(t, time.Now().Add(-time.Minute))
+
+	// Orders being executed by a worker can't be changed
+	claimed, err := testQueries.ClaimDueScheduledTransfers(context.Background(), ClaimDueScheduledTransfersParams{
+		LockedUntil: time.Now().Add(time.Minute),
+		Limit:       1000,
+	})
+	require.NoError(t, err)
+	require.True(t, containsScheduledTransfer(claimed, scheduledTransfer.ID))
+
+	_, err = testQueries.CancelScheduledTransfer(context.Background(), scheduledTransfer.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	_, err = testQueries.FinishScheduledTransferRun(context.Background(), FinishScheduledTransferRunParams{
+		ID:           scheduledTransfer.ID,
+		NextRunAt:    time.Now().Add(time.Hour),
+		Status:       ScheduledTransferActive,
+		FailureCount: 0,
+	})
+	require.NoError(t, err)
+
+	cancelled, err := testQueries.CancelScheduledTransfer(context.Background(), scheduledTransfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, ScheduledTransferCancelled, cancelled.Status)
+
+	// Cancelled orders are never executed again
+	_, err = testQueries.ResumeScheduledTransfer(context.Background(), scheduledTransfer.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func containsScheduledTransfer(scheduledTransfers []ScheduledTransfer, id int64) bool {
+	for _, scheduledTransfer := range scheduledTransfers {
+		if scheduledTransfer.ID == id {
+			return true
+		}
+	}
+	return false
+}
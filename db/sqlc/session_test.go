@@ -0,0 +1,197 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"simplebank/util"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomSession(t *testing.T, username string) Session {
+	id := uuid.New()
+	arg := CreateSessionParams{
+		ID:           id,
+		Username:     username,
+		RefreshToken: util.RandomString(32),
+		UserAgent:    util.RandomString(10),
+		ClientIp:     "127.0.0.1",
+		ExpiresAt:    time.Now().Add(time.Hour),
+		FamilyID:     id,
+	}
+
+	session, err := testQueries.CreateSession(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.ID, session.ID)
+	require.Equal(t, arg.Username, session.Username)
+	require.Equal(t, arg.FamilyID, session.FamilyID)
+	require.False(t, session.IsBlocked)
+	require.False(t, session.RotatedAt.Valid)
+
+	return session
+}
+
+func TestBlockSession(t *testing.T) {
+	user := createRandomUser(t)
+	session1 := createRandomSession(t, user.Username)
+
+	// Sessions can only be blocked by their user
+	rows, err := testQueries.BlockSession(context.Background(), BlockSessionParams{
+		ID:       session1.ID,
+		Username: createRandomUser(t).Username,
+	})
+	require.NoError(t, err)
+	require.Zero(t, rows)
+
+	rows, err = testQueries.BlockSession(context.Background(), BlockSessionParams{
+		ID:       session1.ID,
+		Username: user.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), rows)
+
+	session2, err := testQueries.GetSession(context.Background(), session1.ID)
+	require.NoError(t, err)
+	require.True(t, session2.IsBlocked)
+}
+
+func TestBlockUserSessions(t *testing.T) {
+	user := createRandomUser(t)
+	for i := 0; i < 3; i++ {
+		createRandomSession(t, user.Username)
+	}
+	other := createRandomSession(t, createRandomUser(t).Username)
+
+	arg := ListActiveSessionsParams{
+		Username: user.Username,
+		Limit:    5,
+		Offset:   0,
+	}
+	sessions, err := testQueries.ListActiveSessions(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, sessions, 3)
+
+	rows, err := testQueries.BlockUserSessions(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), rows)
+
+	sessions, err = testQueries.ListActiveSessions(context.Background(), arg)
+	require.NoError(t, err)
+	require.Empty(t, sessions)
+
+	// Sessions of other users are left alone
+	other, err = testQueries.GetSession(context.Background(), other.ID)
+	require.NoError(t, err)
+	require.False(t, other.IsBlocked)
+}
+
+// createRotatedSession exchanges the refresh token of the session for a new one
+func createRotatedSession(t *testing.T, session Session) RotateSessionTxResult {
+	store := NewStore(testDB)
+
+	result, err := store.RotateSessionTx(context.Background(), RotateSessionTxParams{
+		ID: session.ID,
+		Session: CreateSessionParams{
+			ID:           uuid.New(),
+			Username:     session.Username,
+			RefreshToken: util.RandomString(32),
+			UserAgent:    util.RandomString(10),
+			ClientIp:     "127.0.0.1",
+			ExpiresAt:    time.Now().Add(time.Hour),
+		},
+	})
+	require.NoError(t, err)
+	return result
+}
+
+func TestRotateSessionTx(t *testing.T) {
+	user := createRandomUser(t)
+	session1 := createRandomSession(t, user.Username)
+
+	result := createRotatedSession(t, session1)
+	require.Nil(t, result.ReuseEvent)
+	session2 := result.Session
+	require.Equal(t, session1.FamilyID, session2.FamilyID)
+	require.Equal(t, session1.ID, session2.ParentID.UUID)
+	require.False(t, session2.RotatedAt.Valid)
+
+	session3 := createRotatedSession(t, session2).Session
+	require.Equal(t, session1.FamilyID, session3.FamilyID)
+	require.Equal(t, session2.ID, session3.ParentID.UUID)
+
+	rotated, err := testQueries.GetSession(context.Background(), session1.ID)
+	require.NoError(t, err)
+	require.True(t, rotated.RotatedAt.Valid)
+	require.False(t, rotated.IsBlocked)
+
+	// The family is listed once, as its last session
+	sessions, err := testQueries.ListActiveSessions(context.Background(), ListActiveSessionsParams{
+		Username: user.Username,
+		Limit:    5,
+		Offset:   0,
+	})
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	require.Equal(t, session3.ID, sessions[0].ID)
+}
+
+func TestRotateSessionTxReuse(t *testing.T) {
+	user := createRandomUser(t)
+	session1 := createRandomSession(t, user.Username)
+	session2 := createRotatedSession(t, session1).Session
+	other := createRandomSession(t, user.Username)
+
+	// Exchanging the first refresh token again revokes the whole family
+	result := createRotatedSession(t, session1)
+	require.NotNil(t, result.ReuseEvent)
+	require.Zero(t, result.Session)
+	require.Equal(t, user.Username, result.ReuseEvent.Username)
+	require.Equal(t, SecurityEventRefreshTokenReused, result.ReuseEvent.Kind)
+	require.Equal(t, session1.FamilyID, result.ReuseEvent.SessionFamilyID)
+
+	for _, id := range []uuid.UUID{session1.ID, session2.ID} {
+		session, err := testQueries.GetSession(context.Background(), id)
+		require.NoError(t, err)
+		require.True(t, session.IsBlocked)
+	}
+
+	events, err := testQueries.ListSecurityEvents(context.Background(), ListSecurityEventsParams{
+		Username: user.Username,
+		Limit:    5,
+		Offset:   0,
+	})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, *result.ReuseEvent, events[0])
+
+	// Other devices of the user stay signed in
+	other, err = testQueries.GetSession(context.Background(), other.ID)
+	require.NoError(t, err)
+	require.False(t, other.IsBlocked)
+
+	// Once the family is blocked, its sessions can't be exchanged at all
+	store := NewStore(testDB)
+	_, err = store.RotateSessionTx(context.Background(), RotateSessionTxParams{ID: session2.ID})
+	require.ErrorIs(t, err, ErrSessionBlocked)
+}
+
+func TestBlockSessionOfFamily(t *testing.T) {
+	user := createRandomUser(t)
+	session1 := createRandomSession(t, user.Username)
+	session2 := createRotatedSession(t, session1).Session
+
+	// Signing out of the device blocks the refresh tokens its access tokens were issued with before
+	rows, err := testQueries.BlockSession(context.Background(), BlockSessionParams{
+		ID:       session2.ID,
+		Username: user.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), rows)
+
+	session1, err = testQueries.GetSession(context.Background(), session1.ID)
+	require.NoError(t, err)
+	require.True(t, session1.IsBlocked)
+}
@@ -12,6 +12,58 @@ import (
 	"github.com/google/uuid"
 )
 
+const blockSession = `-- name: BlockSession :execrows
+UPDATE sessions
+SET is_blocked = true
+WHERE family_id = (
+  SELECT family_id FROM sessions AS s
+  WHERE s.id = $1 AND s.username = $2
+)
+`
+
+type BlockSessionParams struct {
+	ID       uuid.UUID `json:"id"`
+	Username string    `json:"username"`
+}
+
+// Blocks every refresh token of the family of the session, so the device is signed out
+// whichever of them its access tokens were issued with
+func (q *Queries) BlockSession(ctx context.Context, arg BlockSessionParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, blockSession, arg.ID, arg.Username)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const blockSessionFamily = `-- name: BlockSessionFamily :execrows
+UPDATE sessions
+SET is_blocked = true
+WHERE family_id = $1 AND is_blocked = false
+`
+
+func (q *Queries) BlockSessionFamily(ctx context.Context, familyID uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, blockSessionFamily, familyID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const blockUserSessions = `-- name: BlockUserSessions :execrows
+UPDATE sessions
+SET is_blocked = true
+WHERE username = $1 AND is_blocked = false AND expires_at > now()
+`
+
+func (q *Queries) BlockUserSessions(ctx context.Context, username string) (int64, error) {
+	result, err := q.db.ExecContext(ctx, blockUserSessions, username)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const createSession = `-- name: CreateSession :one
 INSERT INTO sessions (
   id,
@@ -20,20 +72,24 @@ INSERT INTO sessions (
   user_agent,
   client_ip,
   is_blocked,
-  expires_at
+  expires_at,
+  family_id,
+  parent_id
 ) VALUES (
-  $1, $2, $3, $4, $5, $6, $7
-) RETURNING id, username, refresh_token, user_agent, client_ip, is_blocked, expires_at, created_at
+  $1, $2, $3, $4, $5, $6, $7, $8, $9
+) RETURNING id, username, refresh_token, user_agent, client_ip, is_blocked, expires_at, created_at, family_id, parent_id, rotated_at
 `
 
 type CreateSessionParams struct {
-	ID           uuid.UUID `json:"id"`
-	Username     string    `json:"username"`
-	RefreshToken string    `json:"refresh_token"`
-	UserAgent    string    `json:"user_agent"`
-	ClientIp     string    `json:"client_ip"`
-	IsBlocked    bool      `json:"is_blocked"`
-	ExpiresAt    time.Time `json:"expires_at"`
+	ID           uuid.UUID     `json:"id"`
+	Username     string        `json:"username"`
+	RefreshToken string        `json:"refresh_token"`
+	UserAgent    string        `json:"user_agent"`
+	ClientIp     string        `json:"client_ip"`
+	IsBlocked    bool          `json:"is_blocked"`
+	ExpiresAt    time.Time     `json:"expires_at"`
+	FamilyID     uuid.UUID     `json:"family_id"`
+	ParentID     uuid.NullUUID `json:"parent_id"`
 }
 
 func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error) {
@@ -45,6 +101,8 @@ func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (S
 		arg.ClientIp,
 		arg.IsBlocked,
 		arg.ExpiresAt,
+		arg.FamilyID,
+		arg.ParentID,
 	)
 	var i Session
 	err := row.Scan(
@@ -56,12 +114,15 @@ func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (S
 		&i.IsBlocked,
 		&i.ExpiresAt,
 		&i.CreatedAt,
+		&i.FamilyID,
+		&i.ParentID,
+		&i.RotatedAt,
 	)
 	return i, err
 }
 
 const getSession = `-- name: GetSession :one
-SELECT id, username, refresh_token, user_agent, client_ip, is_blocked, expires_at, created_at FROM sessions
+SELECT id, username, refresh_token, user_agent, client_ip, is_blocked, expires_at, created_at, family_id, parent_id, rotated_at FROM sessions
 WHERE id = $1 LIMIT 1
 `
 
@@ -77,6 +138,99 @@ func (q *Queries) GetSession(ctx context.Context, id uuid.UUID) (Session, error)
 		&i.IsBlocked,
 		&i.ExpiresAt,
 		&i.CreatedAt,
+		&i.FamilyID,
+		&i.ParentID,
+		&i.RotatedAt,
+	)
+	return i, err
+}
+
+const getSessionForUpdate = `-- name: GetSessionForUpdate :one
+SELECT id, username, refresh_token, user_agent, client_ip, is_blocked, expires_at, created_at, family_id, parent_id, rotated_at FROM sessions
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetSessionForUpdate(ctx context.Context, id uuid.UUID) (Session, error) {
+	row := q.db.QueryRowContext(ctx, getSessionForUpdate, id)
+	var i Session
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.RefreshToken,
+		&i.UserAgent,
+		&i.ClientIp,
+		&i.IsBlocked,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+		&i.FamilyID,
+		&i.ParentID,
+		&i.RotatedAt,
 	)
 	return i, err
 }
+
+const listActiveSessions = `-- name: ListActiveSessions :many
+SELECT id, username, refresh_token, user_agent, client_ip, is_blocked, expires_at, created_at, family_id, parent_id, rotated_at FROM sessions
+WHERE username = $1
+  AND is_blocked = false
+  AND rotated_at IS NULL
+  AND expires_at > now()
+ORDER BY created_at DESC
+LIMIT $2
+OFFSET $3
+`
+
+type ListActiveSessionsParams struct {
+	Username string `json:"username"`
+	Limit    int32  `json:"limit"`
+	Offset   int32  `json:"offset"`
+}
+
+// Sessions which can still renew access tokens, the most recent first.
+// Only the last refresh token of each family can, the family standing for a device
+func (q *Queries) ListActiveSessions(ctx context.Context, arg ListActiveSessionsParams) ([]Session, error) {
+	rows, err := q.db.QueryContext(ctx, listActiveSessions, arg.Username, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Session{}
+	for rows.Next() {
+		var i Session
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.RefreshToken,
+			&i.UserAgent,
+			&i.ClientIp,
+			&i.IsBlocked,
+			&i.ExpiresAt,
+			&i.CreatedAt,
+			&i.FamilyID,
+			&i.ParentID,
+			&i.RotatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const rotateSession = `-- name: RotateSession :exec
+UPDATE sessions
+SET rotated_at = now()
+WHERE id = $1
+`
+
+func (q *Queries) RotateSession(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, rotateSession, id)
+	return err
+}
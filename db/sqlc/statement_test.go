@@ -0,0 +1,90 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"simplebank/util"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestListStatementEntries(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccountWithCurrency(t, util.USD)
+	account2 := createRandomAccountWithCurrency(t, util.USD)
+	account1 = fundAccount(t, account1, 1000)
+	start := time.Now().Add(-time.Minute)
+
+	transfer, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	_, err = store.DepositTx(context.Background(), DepositTxParams{
+		AccountID: account1.ID,
+		Amount:    100,
+		User:      account1.Owner,
+	})
+	require.NoError(t, err)
+
+	entries, err := testQueries.ListStatementEntries(context.Background(), ListStatementEntriesParams{
+		AccountID:   account1.ID,
+		PeriodStart: start,
+		PeriodEnd:   time.Now().Add(time.Minute),
+	})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	// Transfers come with the other account
+	require.Equal(t, transfer.FromEntry.ID, entries[0].ID)
+	require.Equal(t, account2.ID, entries[0].CounterpartyAccountID)
+	require.Equal(t, account2.Owner, entries[0].CounterpartyOwner)
+	require.False(t, entries[0].ReversalOfID.Valid)
+
+	require.Equal(t, EntrySourceDeposit, entries[1].SourceType)
+	require.Zero(t, entries[1].CounterpartyAccountID)
+	require.Empty(t, entries[1].CounterpartyOwner)
+
+	// The other account sees the transfer from the other side
+	entries, err = testQueries.ListStatementEntries(context.Background(), ListStatementEntriesParams{
+		AccountID:   account2.ID,
+		PeriodStart: start,
+		PeriodEnd:   time.Now().Add(time.Minute),
+	})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, account1.ID, entries[0].CounterpartyAccountID)
+}
+
+func TestCreateStatement(t *testing.T) {
+	account := createRandomAccount(t)
+	period := time.Date(2026, time.September, 1, 0, 0, 0, 0, time.UTC)
+
+	arg := CreateStatementParams{
+		AccountID: account.ID,
+		Period:    period,
+		Pdf:       []byte("%PDF-1.4"),
+		Csv:       []byte("date"),
+	}
+	statement, err := testQueries.CreateStatement(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Pdf, statement.Pdf)
+	require.Equal(t, arg.Csv, statement.Csv)
+
+	// A statement is only stored once
+	_, err = testQueries.CreateStatement(context.Background(), arg)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	stored, err := testQueries.GetStatement(context.Background(), GetStatementParams{
+		AccountID: account.ID,
+		Period:    period,
+	})
+	require.NoError(t, err)
+	require.Equal(t, statement.ID, stored.ID)
+	require.Equal(t, period, stored.Period.UTC())
+}
@@ -3,14 +3,217 @@ package db
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"sort"
+	"time"
+
+	"simplebank/fx"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// ErrInsufficientFunds is returned when a transaction would take an account
+// balance below its overdraft limit
+var ErrInsufficientFunds = errors.New("insufficient funds")
+
+// Errors returned when a transfer between accounts with different currencies can't be made
+var (
+	ErrCurrencyMismatch = errors.New("accounts have different currencies and no fx quote was provided")
+	ErrQuoteUnavailable = errors.New("fx quote doesn't exist, has expired or was already used")
+	ErrQuoteMismatch    = errors.New("fx quote doesn't match the transfer")
+)
+
+// Errors returned when a transfer can't be reversed
+var (
+	ErrTransferAlreadyReversed = errors.New("transfer was already fully reversed")
+	ErrReversalExceedsTransfer = errors.New("reversal amount exceeds what is left of the transfer")
+	ErrReversalOfReversal      = errors.New("a reversal can't be reversed")
+	ErrReversalTooSmall        = errors.New("reversal amount is too small to be refunded")
+)
+
+// Errors returned when a hold can't be captured, voided or expired
+var (
+	ErrHoldNotActive      = errors.New("hold was already captured, voided or expired")
+	ErrHoldExpired        = errors.New("hold has expired")
+	ErrCaptureExceedsHold = errors.New("capture amount exceeds the amount held")
+	ErrHoldNotExpiredYet  = errors.New("hold hasn't expired yet")
+)
+
+// Errors returned when money can't move in or out of an account because of its status
+var (
+	ErrAccountFrozen = errors.New("account is frozen")
+	ErrAccountClosed = errors.New("account is closed")
+)
+
+// Errors returned when the status of an account can't be changed
+var (
+	ErrInvalidStatusChange = errors.New("account can't change from its current status to the requested one")
+	ErrAccountNotEmpty     = errors.New("account balance must be zero, or paid out to another account, to close it")
+	ErrAccountHasHolds     = errors.New("account has active holds")
+	ErrInvalidPayout       = errors.New("payout account must be another active account of the same owner with the same currency")
+)
+
+// ErrAccountLimitReached is returned when a user already has as many open accounts in a currency as allowed
+var ErrAccountLimitReached = errors.New("too many open accounts in the currency")
+
+// ErrSessionBlocked is returned when the refresh token of a blocked session is exchanged
+var ErrSessionBlocked = errors.New("session is blocked")
+
+// LimitExceededError is returned when a transfer or withdrawal would go over one of the
+// limits of the tier of the account owner
+type LimitExceededError struct {
+	Limit string
+	// Max is the value of the limit, and Remaining how much of it is left for today
+	Max       int64
+	Remaining int64
+}
+
+func (err *LimitExceededError) Error() string {
+	return fmt.Sprintf("%s limit of %d exceeded, %d remaining", err.Limit, err.Max, err.Remaining)
+}
+
+// Tiers of users which are given limits when the database is created, new users are standard
+const (
+	TierStandard = "standard"
+	TierPremium  = "premium"
+)
+
+// Roles of users, bankers and admins are staff of the bank who can act on the accounts of customers,
+// and only admins can reconcile the ledger and change the role of users
+const (
+	UserRoleCustomer = "customer"
+	UserRoleBanker   = "banker"
+	UserRoleAdmin    = "admin"
+)
+
+// Limits which can be exceeded
+const (
+	LimitPerTransaction = "per_transaction"
+	LimitDailyAmount    = "daily_amount"
+	LimitDailyCount     = "daily_count"
+)
+
+// Kinds of transactions which post journals, the entries of a journal point to the same transaction
+const (
+	EntrySourceTransfer = "transfer"
+	EntrySourceDeposit  = "deposit"
+	EntrySourceWithdraw = "withdraw"
+	EntrySourceHold     = "hold"
+	// EntrySourceTransferFee entries point to the transfer which was charged
+	EntrySourceTransferFee = "transfer_fee"
+	// EntrySourceInterest entries point to the interest posting which paid them
+	EntrySourceInterest = "interest"
+)
+
+// Types of accounts, users can only open checking and savings accounts
+const (
+	AccountTypeChecking = "checking"
+	AccountTypeSavings  = "savings"
+	AccountTypeHouse    = "house"
+)
+
+// Statuses of accounts, only active accounts can move money
+const (
+	AccountStatusActive = "active"
+	AccountStatusFrozen = "frozen"
+	AccountStatusClosed = "closed"
+)
+
+// Roles of the members an account is shared with, an owner can do everything the account owner can,
+// a spender can move money up to their spend limit at once, and a viewer can only look at the account
+const (
+	AccountRoleOwner   = "owner"
+	AccountRoleSpender = "spender"
+	AccountRoleViewer  = "viewer"
+)
+
+// Kinds of security events recorded for users
+const (
+	// SecurityEventRefreshTokenReused is recorded when a refresh token which was already exchanged is
+	// presented again, most likely because it was stolen
+	SecurityEventRefreshTokenReused = "refresh_token_reused"
+)
+
+// What the house accounts of the bank are used for
+const (
+	HousePurposeFeeRevenue      = "fee_revenue"
+	HousePurposeInterestExpense = "interest_expense"
+	// HousePurposeCash accounts are where deposits come from and where withdrawals and captured holds go
+	HousePurposeCash = "cash"
+	// HousePurposeFxConversion accounts take the money in one currency and give it out in another
+	HousePurposeFxConversion = "fx_conversion"
+)
+
+// InterestScale is what the interest accruals are scaled by, since they are the balance times a yearly
+// rate in basis points, while only a 365th of it is earned in a day
+const InterestScale int64 = 10_000 * 365
+
+// ErrInterestAlreadyPosted is returned when the interest of an account was already paid for the period
+var ErrInterestAlreadyPosted = errors.New("interest was already posted for the period")
+
+// Types of transfers which fee rules apply to
+const (
+	TransferTypeSameCurrency  = "same_currency"
+	TransferTypeCrossCurrency = "cross_currency"
+)
+
+// errPreviewRollback is returned from a transaction to undo everything it did, once the result is known
+var errPreviewRollback = errors.New("preview is always rolled back")
+
+// Statuses of holds
+const (
+	HoldActive   = "active"
+	HoldCaptured = "captured"
+	HoldVoided   = "voided"
+	HoldExpired  = "expired"
+)
+
+// How often a scheduled transfer is executed
+const (
+	FrequencyOnce    = "once"
+	FrequencyDaily   = "daily"
+	FrequencyWeekly  = "weekly"
+	FrequencyMonthly = "monthly"
+)
+
+// Statuses of scheduled transfers and of their runs
+const (
+	ScheduledTransferActive    = "active"
+	ScheduledTransferPaused    = "paused"
+	ScheduledTransferCompleted = "completed"
+	ScheduledTransferCancelled = "cancelled"
+
+	RunPending   = "pending"
+	RunSucceeded = "succeeded"
+	RunFailed    = "failed"
 )
 
+// balanceCheckConstraint is the database constraint which keeps account balances above the overdraft limit
+const balanceCheckConstraint = "accounts_balance_check"
+
 // Store defines all functions to execute db queries and transactions
 type Store interface {
 	Querier
 	TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error)
+	PreviewTransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error)
 	DepositTx(ctx context.Context, arg DepositTxParams) (DepositTxResult, error)
+	WithdrawTx(ctx context.Context, arg WithdrawTxParams) (WithdrawTxResult, error)
+	ReverseTransferTx(ctx context.Context, arg ReverseTransferTxParams) (ReverseTransferTxResult, error)
+	AuthorizeHoldTx(ctx context.Context, arg AuthorizeHoldTxParams) (HoldTxResult, error)
+	CaptureHoldTx(ctx context.Context, arg CaptureHoldTxParams) (CaptureHoldTxResult, error)
+	VoidHoldTx(ctx context.Context, holdID int64) (HoldTxResult, error)
+	ExpireHoldTx(ctx context.Context, holdID int64) (HoldTxResult, error)
+	BatchTransferTx(ctx context.Context, arg BatchTransferTxParams) (BatchTransferTxResult, error)
+	PostInterestTx(ctx context.Context, arg PostInterestTxParams) (PostInterestTxResult, error)
+	ChangeAccountStatusTx(ctx context.Context, arg ChangeAccountStatusTxParams) (ChangeAccountStatusTxResult, error)
+	CreateAccountTx(ctx context.Context, arg CreateAccountTxParams) (Account, error)
+	RotateSessionTx(ctx context.Context, arg RotateSessionTxParams) (RotateSessionTxResult, error)
+	EnableTwoFactorTx(ctx context.Context, arg RecoveryCodesTxParams) (TotpSecret, error)
+	ReplaceRecoveryCodesTx(ctx context.Context, arg RecoveryCodesTxParams) error
+	DisableTwoFactorTx(ctx context.Context, username string) error
+	UpdateUserRoleTx(ctx context.Context, arg UpdateUserRoleParams) (User, error)
 }
 
 // SQLStore provides all functions to execute SQL queries and transactions
@@ -36,11 +239,11 @@ func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) erro
 	}
 
 	q := New(tx)
-	err = fn(q)
+	err = translateError(fn(q))
 	// If something goes worng, we'll rollback
 	if err != nil {
 		if rbErr := tx.Rollback(); rbErr != nil {
-			return fmt.Errorf("tx err: %v, rb err: %v", err, rbErr)
+			return fmt.Errorf("tx err: %w, rb err: %v", err, rbErr)
 		}
 		return err
 	}
@@ -49,11 +252,27 @@ func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) erro
 	return tx.Commit()
 }
 
+// translateError maps errors raised by database constraints to the store errors
+func translateError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) &&
+		pqErr.Code.Name() == "check_violation" &&
+		pqErr.Constraint == balanceCheckConstraint {
+		return ErrInsufficientFunds
+	}
+	return err
+}
+
 // TransferTxParams contains the input parameters of the transfer transaction
 type TransferTxParams struct {
 	FromAccountID int64 `json:"from_account_id"`
 	ToAccountID   int64 `json:"to_account_id"`
 	Amount        int64 `json:"amount"`
+	// QuoteID is required when the accounts have different currencies
+	QuoteID int64 `json:"quote_id"`
+	// User is who makes the transfer, which may be a member of the origin account rather than its owner.
+	// The fx quote must have been given to them
+	User string `json:"user"`
 }
 
 // TransferTxResult is the result of the transfer transaction
@@ -63,6 +282,8 @@ type TransferTxResult struct {
 	ToAccount   Account  `json:"to_account"`
 	FromEntry   Entry    `json:"from_entry"`
 	ToEntry     Entry    `json:"to_entry"`
+	// FeeEntry is only set when the transfer was charged a fee
+	FeeEntry Entry `json:"fee_entry"`
 }
 
 // DepositTxParams contains the input parameters of the deposit transaction
@@ -78,70 +299,309 @@ type DepositTxResult struct {
 	Entry   Entry   `json:"entry"`
 }
 
+// WithdrawTxParams contains the input parameters of the withdraw transaction
+type WithdrawTxParams struct {
+	AccountID int64  `json:"account_id"`
+	Amount    int64  `json:"amount"`
+	User      string `json:"user"`
+}
+
+// WithdrawTxResult is the result of the withdraw transaction
+type WithdrawTxResult struct {
+	Withdraw Withdraw `json:"withdraw"`
+	Account  Account  `json:"account"`
+	Entry    Entry    `json:"entry"`
+}
+
+// ReverseTransferTxParams contains the input parameters of the reverse transfer transaction
+type ReverseTransferTxParams struct {
+	TransferID int64 `json:"transfer_id"`
+	// Amount is in the currency of the recipient, zero reverses all that is left of the transfer
+	Amount int64 `json:"amount"`
+}
+
+// ReverseTransferTxResult is the result of the reverse transfer transaction
+type ReverseTransferTxResult struct {
+	OriginalTransfer Transfer         `json:"original_transfer"`
+	Reversal         TransferTxResult `json:"reversal"`
+}
+
+// BatchTransferLeg is one of the transfers made by a batch
+type BatchTransferLeg struct {
+	ToAccountID int64 `json:"to_account_id"`
+	Amount      int64 `json:"amount"`
+}
+
+// BatchTransferTxParams contains the input parameters of the batch transfer transaction
+type BatchTransferTxParams struct {
+	FromAccountID int64              `json:"from_account_id"`
+	Legs          []BatchTransferLeg `json:"legs"`
+	// BestEffort commits the legs which succeed, instead of rolling back the whole batch
+	BestEffort bool `json:"best_effort"`
+}
+
+// BatchTransferLegResult is the outcome of one leg of the batch, Err is nil if it succeeded
+type BatchTransferLegResult struct {
+	TransferTxResult
+	Err error `json:"-"`
+}
+
+// BatchTransferTxResult is the result of the batch transfer transaction, with the legs in the same order as the params
+type BatchTransferTxResult struct {
+	Legs []BatchTransferLegResult `json:"legs"`
+}
+
+// BatchLegError is returned when a leg fails and the whole batch is rolled back
+type BatchLegError struct {
+	Index int
+	Err   error
+}
+
+func (err *BatchLegError) Error() string {
+	return fmt.Sprintf("batch leg %d failed: %v", err.Index, err.Err)
+}
+
+func (err *BatchLegError) Unwrap() error {
+	return err.Err
+}
+
+// AuthorizeHoldTxParams contains the input parameters of the authorize hold transaction
+type AuthorizeHoldTxParams struct {
+	AccountID int64     `json:"account_id"`
+	Amount    int64     `json:"amount"`
+	User      string    `json:"user"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// HoldTxResult is the result of the authorize, void and expire hold transactions
+type HoldTxResult struct {
+	Hold    Hold    `json:"hold"`
+	Account Account `json:"account"`
+}
+
+// CaptureHoldTxParams contains the input parameters of the capture hold transaction
+type CaptureHoldTxParams struct {
+	HoldID int64 `json:"hold_id"`
+	// Amount can be less than the amount held, zero captures all of it
+	Amount int64 `json:"amount"`
+}
+
+// CaptureHoldTxResult is the result of the capture hold transaction
+type CaptureHoldTxResult struct {
+	Hold    Hold    `json:"hold"`
+	Account Account `json:"account"`
+	Entry   Entry   `json:"entry"`
+}
+
 // TransferTx performs a money transfer from one account to the other.
-// It creates the transfer, add account entries, and update accounts' balance within a database transaction
+// It creates the transfer, add account entries, and update accounts' balance within a database transaction.
+// The fee of the transfer is taken from the origin account as well.
+// ErrInsufficientFunds is returned if the transfer would overdraw the origin account
 func (store *SQLStore) TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error) {
 	var result TransferTxResult
 
 	err := store.execTx(ctx, func(q *Queries) error {
 		var err error
+		result, err = makeTransfer(ctx, q, arg)
+		return err
+	})
 
-		// Creating transfer object
-		result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
-			FromAccountID: arg.FromAccountID,
-			ToAccountID:   arg.ToAccountID,
-			Amount:        arg.Amount,
-		})
+	return result, err
+}
+
+// PreviewTransferTx works out a transfer exactly like TransferTx, but rolls it back instead of committing it.
+// The IDs in the result don't exist, but the fee and balances are the ones the transfer would give
+func (store *SQLStore) PreviewTransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error) {
+	var result TransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		result, err = makeTransfer(ctx, q, arg)
 		if err != nil {
 			return err
 		}
+		return errPreviewRollback
+	})
+	if err == errPreviewRollback {
+		return result, nil
+	}
 
-		// Creating origin account entry object
-		result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.FromAccountID,
-			Amount:    -arg.Amount,
-		})
+	return result, err
+}
+
+// makeTransfer makes the transfer and charges its fee
+func makeTransfer(ctx context.Context, q *Queries, arg TransferTxParams) (TransferTxResult, error) {
+	transfer, err := prepareTransfer(ctx, q, arg)
+	if err != nil {
+		return TransferTxResult{}, err
+	}
+
+	transfer.Fee, err = transferFee(ctx, q, transfer)
+	if err != nil {
+		return TransferTxResult{}, err
+	}
+
+	result, err := postTransfer(ctx, q, transfer)
+	if err != nil {
+		return result, err
+	}
+
+	err = chargeFee(ctx, q, &result)
+	return result, err
+}
+
+// postTransfer creates the transfer, adds the account entries and updates the accounts' balance
+func postTransfer(ctx context.Context, q *Queries, transfer CreateTransferParams) (TransferTxResult, error) {
+	var result TransferTxResult
+	var err error
+
+	// Creating transfer object
+	result.Transfer, err = q.CreateTransfer(ctx, transfer)
+	if err != nil {
+		return result, err
+	}
+
+	// The accounts are updated in ID order, avoiding deadlocks
+	from, to := 0, 1
+	if transfer.ToAccountID < transfer.FromAccountID {
+		from, to = 1, 0
+	}
+
+	lines := make([]journalLine, 2, 4)
+	lines[from] = journalLine{AccountID: transfer.FromAccountID, Currency: transfer.FromCurrency, Amount: -transfer.Amount}
+	lines[to] = journalLine{AccountID: transfer.ToAccountID, Currency: transfer.ToCurrency, Amount: transfer.ToAmount}
+
+	// Money changing currency goes through the conversion accounts, so each currency balances on its own
+	if transfer.FromCurrency != transfer.ToCurrency {
+		fxLines, err := fxConversionLines(ctx, q, transfer)
 		if err != nil {
-			return err
+			return result, err
 		}
+		lines = append(lines, fxLines...)
+	}
 
-		// Creating destination account entry object
-		result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.ToAccountID,
-			Amount:    arg.Amount,
-		})
+	entries, accounts, err := postJournal(ctx, q, EntrySourceTransfer, result.Transfer.ID, lines...)
+	if err != nil {
+		return result, err
+	}
+
+	result.FromEntry, result.ToEntry = entries[from], entries[to]
+	result.FromAccount, result.ToAccount = accounts[from], accounts[to]
+	return result, requireActive(result.FromAccount, result.ToAccount)
+}
+
+// requireActive returns ErrAccountFrozen or ErrAccountClosed if one of the accounts can't move money.
+// It's given the accounts returned by the balance updates, which are locked until the transaction ends,
+// so an account can't be frozen or closed while its money is moving
+func requireActive(accounts ...Account) error {
+	for _, account := range accounts {
+		switch account.Status {
+		case AccountStatusFrozen:
+			return ErrAccountFrozen
+		case AccountStatusClosed:
+			return ErrAccountClosed
+		}
+	}
+	return nil
+}
+
+// fxConversionLines moves the amount of a transfer between currencies into the conversion account of
+// the origin currency, and what the destination account receives out of the one of the destination currency
+func fxConversionLines(ctx context.Context, q *Queries, transfer CreateTransferParams) ([]journalLine, error) {
+	fromHouse, err := houseAccountFor(ctx, q, HousePurposeFxConversion, transfer.FromCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	toHouse, err := houseAccountFor(ctx, q, HousePurposeFxConversion, transfer.ToCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := []journalLine{
+		{AccountID: fromHouse.ID, Currency: transfer.FromCurrency, Amount: transfer.Amount},
+		{AccountID: toHouse.ID, Currency: transfer.ToCurrency, Amount: -transfer.ToAmount},
+	}
+
+	// Transfers in opposite directions update the same conversion accounts, so they go in ID order too
+	if toHouse.ID < fromHouse.ID {
+		lines[0], lines[1] = lines[1], lines[0]
+	}
+	return lines, nil
+}
+
+// prepareTransfer checks the limits of the sender and works out how much the destination
+// account receives, using up the fx quote when the accounts have different currencies
+func prepareTransfer(ctx context.Context, q *Queries, arg TransferTxParams) (CreateTransferParams, error) {
+	fromAccount, err := q.GetAccount(ctx, arg.FromAccountID)
+	if err != nil {
+		return CreateTransferParams{}, err
+	}
+
+	toAccount, err := q.GetAccount(ctx, arg.ToAccountID)
+	if err != nil {
+		return CreateTransferParams{}, err
+	}
+
+	// Moving money between the accounts of the same owner isn't limited
+	if fromAccount.Owner != toAccount.Owner {
+		err = checkTransferLimits(ctx, q, fromAccount, arg.Amount)
 		if err != nil {
-			return err
+			return CreateTransferParams{}, err
 		}
+	}
+
+	transfer := CreateTransferParams{
+		FromAccountID: arg.FromAccountID,
+		ToAccountID:   arg.ToAccountID,
+		Amount:        arg.Amount,
+		FromCurrency:  fromAccount.Currency,
+		ToCurrency:    toAccount.Currency,
+		ToAmount:      arg.Amount,
+		Rate:          fx.RateScale,
+	}
 
-		// Updating accounts balance, avoiding deadlocks
-		if arg.FromAccountID < arg.ToAccountID {
-			result.FromAccount, result.ToAccount, err = addMoney(
-				ctx, q, arg.FromAccountID,
-				-arg.Amount, // money is moving out
-				arg.ToAccountID, arg.Amount,
-			)
-		} else {
-			result.ToAccount, result.FromAccount, err = addMoney(
-				ctx, q, arg.ToAccountID,
-				arg.Amount, // money is moving in
-				arg.FromAccountID, -arg.Amount,
-			)
+	if arg.QuoteID == 0 {
+		if fromAccount.Currency != toAccount.Currency {
+			return CreateTransferParams{}, ErrCurrencyMismatch
 		}
+		return transfer, nil
+	}
 
-		return err
-	})
+	// The quote is marked as used, which is undone if the transaction fails
+	quote, err := q.UseFxQuote(ctx, arg.QuoteID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return CreateTransferParams{}, ErrQuoteUnavailable
+		}
+		return CreateTransferParams{}, err
+	}
 
-	return result, err
+	if quote.Username != arg.User ||
+		quote.FromCurrency != fromAccount.Currency ||
+		quote.ToCurrency != toAccount.Currency ||
+		quote.FromAmount != arg.Amount {
+		return CreateTransferParams{}, ErrQuoteMismatch
+	}
+
+	transfer.ToAmount = quote.ToAmount
+	transfer.Rate = quote.Rate
+	transfer.SpreadBps = quote.SpreadBps
+	transfer.QuoteID = sql.NullInt64{Int64: quote.ID, Valid: true}
+
+	return transfer, nil
 }
 
-// DepositTo performs a money deposit to one account.
-// It creates the deposit, add account entries, and update accounts' balance within a database transaction
+// DepositTx performs a money deposit to one account.
+// It creates the deposit, and posts its journal out of the cash account of the currency within a database transaction
 func (store *SQLStore) DepositTx(ctx context.Context, arg DepositTxParams) (DepositTxResult, error) {
 	var result DepositTxResult
 
 	err := store.execTx(ctx, func(q *Queries) error {
-		var err error
+		account, err := q.GetAccount(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
 
 		// Creating deposit object
 		result.Deposit, err = q.CreateDeposit(ctx, CreateDepositParams{
@@ -153,52 +613,1045 @@ func (store *SQLStore) DepositTx(ctx context.Context, arg DepositTxParams) (Depo
 			return err
 		}
 
-		// Creating account entry object
-		result.Entry, err = q.CreateEntry(ctx, CreateEntryParams{
+		cash, err := houseAccountFor(ctx, q, HousePurposeCash, account.Currency)
+		if err != nil {
+			return err
+		}
+
+		entries, accounts, err := postJournal(ctx, q, EntrySourceDeposit, result.Deposit.ID,
+			journalLine{AccountID: account.ID, Currency: account.Currency, Amount: arg.Amount},
+			journalLine{AccountID: cash.ID, Currency: account.Currency, Amount: -arg.Amount},
+		)
+		if err != nil {
+			return err
+		}
+
+		result.Entry = entries[0]
+		return requireActive(accounts[0])
+	})
+
+	return result, err
+}
+
+// journalLine is one of the entries of a journal
+type journalLine struct {
+	AccountID int64
+	Currency  string
+	Amount    int64
+}
+
+// postJournal creates a journal with an entry for each line, and adds the lines to the balance of their accounts.
+// The lines must add up to zero in each currency, and the database refuses to commit a journal which doesn't.
+// The accounts are updated in the order of the lines, so callers put the accounts of customers first,
+// in ID order, and the house accounts last, like every other transaction does, avoiding deadlocks
+func postJournal(ctx context.Context, q *Queries, kind string, sourceID int64, lines ...journalLine) ([]Entry, []Account, error) {
+	totals := make(map[string]int64)
+	for _, line := range lines {
+		totals[line.Currency] += line.Amount
+	}
+	for currency, total := range totals {
+		if total != 0 {
+			return nil, nil, fmt.Errorf("%s journal of %d is off by %d %s", kind, sourceID, total, currency)
+		}
+	}
+
+	journal, err := q.CreateJournal(ctx, CreateJournalParams{
+		Kind:     kind,
+		SourceID: sourceID,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	entries := make([]Entry, len(lines))
+	for i, line := range lines {
+		entries[i], err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID:  line.AccountID,
+			Amount:     line.Amount,
+			SourceType: kind,
+			SourceID:   sourceID,
+			JournalID:  journal.ID,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	accounts := make([]Account, len(lines))
+	for i, line := range lines {
+		// The database refuses to overdraw the account
+		accounts[i], err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
+			ID:     line.AccountID,
+			Amount: line.Amount,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return entries, accounts, nil
+}
+
+// houseAccountFor returns the account the bank uses for the purpose in the currency
+func houseAccountFor(ctx context.Context, q *Queries, purpose string, currency string) (Account, error) {
+	account, err := q.GetHouseAccount(ctx, GetHouseAccountParams{
+		Purpose:  purpose,
+		Currency: currency,
+	})
+	if err != nil {
+		return account, fmt.Errorf("cannot get %s account for %s: %w", purpose, currency, err)
+	}
+	return account, nil
+}
+
+// WithdrawTx performs a money withdrawal from one account.
+// It creates the withdraw, and posts its journal into the cash account of the currency within a database transaction.
+// ErrInsufficientFunds is returned if the withdrawal would overdraw the account
+func (store *SQLStore) WithdrawTx(ctx context.Context, arg WithdrawTxParams) (WithdrawTxResult, error) {
+	var result WithdrawTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		account, err := q.GetAccount(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		err = checkTransferLimits(ctx, q, account, arg.Amount)
+		if err != nil {
+			return err
+		}
+
+		// Creating withdraw object
+		result.Withdraw, err = q.CreateWithdraw(ctx, CreateWithdrawParams{
 			AccountID: arg.AccountID,
 			Amount:    arg.Amount,
+			User:      arg.User,
 		})
 		if err != nil {
 			return err
 		}
 
-		// Updating account balance
-		_, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
-			ID:     arg.AccountID,
-			Amount: arg.Amount,
+		cash, err := houseAccountFor(ctx, q, HousePurposeCash, account.Currency)
+		if err != nil {
+			return err
+		}
+
+		entries, accounts, err := postJournal(ctx, q, EntrySourceWithdraw, result.Withdraw.ID,
+			journalLine{AccountID: account.ID, Currency: account.Currency, Amount: -arg.Amount},
+			journalLine{AccountID: cash.ID, Currency: account.Currency, Amount: arg.Amount},
+		)
+		if err != nil {
+			return err
+		}
+
+		result.Entry, result.Account = entries[0], accounts[0]
+		return requireActive(result.Account)
+	})
+
+	return result, err
+}
+
+// ReverseTransferTx sends money back from the recipient of a transfer to its sender.
+// It creates a compensating transfer linked to the original one, which may refund only part of it.
+// Transfers between currencies are refunded at their original rate
+func (store *SQLStore) ReverseTransferTx(ctx context.Context, arg ReverseTransferTxParams) (ReverseTransferTxResult, error) {
+	var result ReverseTransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		// Locking the transfer, so concurrent reversals can't refund it twice
+		original, err := q.GetTransferForUpdate(ctx, arg.TransferID)
+		if err != nil {
+			return err
+		}
+
+		if original.ReversalOfID.Valid {
+			return ErrReversalOfReversal
+		}
+
+		remaining := original.ToAmount - original.ReversedAmount
+		if remaining == 0 {
+			return ErrTransferAlreadyReversed
+		}
+
+		amount := arg.Amount
+		if amount == 0 {
+			amount = remaining
+		}
+		if amount > remaining {
+			return ErrReversalExceedsTransfer
+		}
+
+		// Refunds are worked out from the total reversed so far, so rounding never
+		// makes a full reversal give back more or less than the original amount
+		refund := fx.MulDiv(original.ReversedAmount+amount, original.Amount, original.ToAmount) -
+			fx.MulDiv(original.ReversedAmount, original.Amount, original.ToAmount)
+		if refund <= 0 {
+			return ErrReversalTooSmall
+		}
+
+		result.Reversal, err = postTransfer(ctx, q, CreateTransferParams{
+			FromAccountID: original.ToAccountID,
+			ToAccountID:   original.FromAccountID,
+			Amount:        amount,
+			FromCurrency:  original.ToCurrency,
+			ToCurrency:    original.FromCurrency,
+			ToAmount:      refund,
+			Rate:          fx.MulDiv(original.Amount, fx.RateScale, original.ToAmount),
+			ReversalOfID:  sql.NullInt64{Int64: original.ID, Valid: true},
 		})
 		if err != nil {
 			return err
 		}
 
+		result.OriginalTransfer, err = q.AddTransferReversedAmount(ctx, AddTransferReversedAmountParams{
+			ID:     original.ID,
+			Amount: amount,
+		})
 		return err
 	})
 
 	return result, err
 }
 
-// addMoney encapsulates the functions to add two account's balances, avoiding deadlocks
-func addMoney(
-	ctx context.Context,
-	q *Queries,
-	accountID1 int64,
-	amount1 int64,
-	accountID2 int64,
-	amount2 int64,
-) (account1 Account, account2 Account, err error) {
-	// Adding balance to the first account
-	account1, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
-		ID:     accountID1,
-		Amount: amount1,
-	})
-	if err != nil {
-		return // this is the same as: return account1, account2, err
-	}
+// AuthorizeHoldTx reserves money on an account, so it can't be spent until the hold is captured, voided or expired.
+// Holds count against the limits of the account owner from the moment they are authorized.
+// ErrInsufficientFunds is returned if the available balance of the account isn't enough
+func (store *SQLStore) AuthorizeHoldTx(ctx context.Context, arg AuthorizeHoldTxParams) (HoldTxResult, error) {
+	var result HoldTxResult
 
-	// Adding balance to the second account
-	account2, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
-		ID:     accountID2,
-		Amount: amount2,
+	err := store.execTx(ctx, func(q *Queries) error {
+		account, err := q.GetAccount(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		err = checkTransferLimits(ctx, q, account, arg.Amount)
+		if err != nil {
+			return err
+		}
+
+		result.Hold, err = q.CreateHold(ctx, CreateHoldParams{
+			AccountID: arg.AccountID,
+			Amount:    arg.Amount,
+			User:      arg.User,
+			ExpiresAt: arg.ExpiresAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		// The database refuses to hold more than the available balance
+		result.Account, err = q.AddAccountHeldAmount(ctx, AddAccountHeldAmountParams{
+			ID:     arg.AccountID,
+			Amount: arg.Amount,
+		})
+		if err != nil {
+			return err
+		}
+
+		return requireActive(result.Account)
+	})
+
+	return result, err
+}
+
+// CaptureHoldTx takes the money of a hold out of its account, releasing whatever isn't captured.
+// The money goes to the cash account of the currency, within a database transaction.
+// The limits aren't checked again, since the hold counted against them when it was authorized
+func (store *SQLStore) CaptureHoldTx(ctx context.Context, arg CaptureHoldTxParams) (CaptureHoldTxResult, error) {
+	var result CaptureHoldTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		// Locking the hold, so it can't be captured and voided at the same time
+		hold, err := q.GetHoldForUpdate(ctx, arg.HoldID)
+		if err != nil {
+			return err
+		}
+
+		if hold.Status != HoldActive {
+			return ErrHoldNotActive
+		}
+		if !hold.ExpiresAt.After(time.Now()) {
+			return ErrHoldExpired
+		}
+
+		amount := arg.Amount
+		if amount == 0 {
+			amount = hold.Amount
+		}
+		if amount > hold.Amount {
+			return ErrCaptureExceedsHold
+		}
+
+		result.Hold, err = q.FinishHold(ctx, FinishHoldParams{
+			ID:             hold.ID,
+			Status:         HoldCaptured,
+			CapturedAmount: amount,
+		})
+		if err != nil {
+			return err
+		}
+
+		// Releasing the hold first, since the money it reserved is what is taken out
+		account, err := q.AddAccountHeldAmount(ctx, AddAccountHeldAmountParams{
+			ID:     hold.AccountID,
+			Amount: -hold.Amount,
+		})
+		if err != nil {
+			return err
+		}
+
+		cash, err := houseAccountFor(ctx, q, HousePurposeCash, account.Currency)
+		if err != nil {
+			return err
+		}
+
+		entries, accounts, err := postJournal(ctx, q, EntrySourceHold, hold.ID,
+			journalLine{AccountID: account.ID, Currency: account.Currency, Amount: -amount},
+			journalLine{AccountID: cash.ID, Currency: account.Currency, Amount: amount},
+		)
+		if err != nil {
+			return err
+		}
+
+		result.Entry, result.Account = entries[0], accounts[0]
+		return requireActive(result.Account)
+	})
+
+	return result, err
+}
+
+// VoidHoldTx releases the money of a hold without taking it out of the account
+func (store *SQLStore) VoidHoldTx(ctx context.Context, holdID int64) (HoldTxResult, error) {
+	var result HoldTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		result, err = releaseHold(ctx, q, holdID, HoldVoided)
+		return err
+	})
+
+	return result, err
+}
+
+// ExpireHoldTx releases the money of a hold which wasn't captured in time.
+// ErrHoldNotExpiredYet is returned if the hold can still be captured
+func (store *SQLStore) ExpireHoldTx(ctx context.Context, holdID int64) (HoldTxResult, error) {
+	var result HoldTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		result, err = releaseHold(ctx, q, holdID, HoldExpired)
+		return err
+	})
+
+	return result, err
+}
+
+// releaseHold finishes an active hold with the given status, giving its money back to the available balance
+func releaseHold(ctx context.Context, q *Queries, holdID int64, status string) (HoldTxResult, error) {
+	var result HoldTxResult
+
+	hold, err := q.GetHoldForUpdate(ctx, holdID)
+	if err != nil {
+		return result, err
+	}
+
+	if hold.Status != HoldActive {
+		return result, ErrHoldNotActive
+	}
+	if status == HoldExpired && hold.ExpiresAt.After(time.Now()) {
+		return result, ErrHoldNotExpiredYet
+	}
+
+	result.Hold, err = q.FinishHold(ctx, FinishHoldParams{
+		ID:     hold.ID,
+		Status: status,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	result.Account, err = q.AddAccountHeldAmount(ctx, AddAccountHeldAmountParams{
+		ID:     hold.AccountID,
+		Amount: -hold.Amount,
+	})
+	return result, err
+}
+
+// BatchTransferTx performs money transfers from one account to many others within a single database transaction.
+// Each leg is charged the same fee as a transfer on its own would be.
+// Unless the batch is best effort, a *BatchLegError is returned and nothing is committed if any leg fails.
+// The legs can't convert between currencies
+func (store *SQLStore) BatchTransferTx(ctx context.Context, arg BatchTransferTxParams) (BatchTransferTxResult, error) {
+	var result BatchTransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		result.Legs = make([]BatchTransferLegResult, 0, len(arg.Legs))
+
+		// Locking the owner before the accounts, in the same order as the other transactions,
+		// since every leg checks the limits of the owner
+		origin, err := q.GetAccount(ctx, arg.FromAccountID)
+		if err != nil {
+			return err
+		}
+		_, err = q.GetUserForUpdate(ctx, origin.Owner)
+		if err != nil {
+			return err
+		}
+
+		accounts, err := lockBatchAccounts(ctx, q, arg)
+		if err != nil {
+			return err
+		}
+
+		fromAccount, ok := accounts[arg.FromAccountID]
+		if !ok {
+			return sql.ErrNoRows
+		}
+
+		for i, leg := range arg.Legs {
+			var legResult BatchTransferLegResult
+
+			makeLeg := func() error {
+				toAccount, ok := accounts[leg.ToAccountID]
+				if !ok {
+					return sql.ErrNoRows
+				}
+				if fromAccount.Currency != toAccount.Currency {
+					return ErrCurrencyMismatch
+				}
+
+				if fromAccount.Owner != toAccount.Owner {
+					err := checkTransferLimits(ctx, q, fromAccount, leg.Amount)
+					if err != nil {
+						return err
+					}
+				}
+
+				transfer := CreateTransferParams{
+					FromAccountID: fromAccount.ID,
+					ToAccountID:   toAccount.ID,
+					Amount:        leg.Amount,
+					FromCurrency:  fromAccount.Currency,
+					ToCurrency:    toAccount.Currency,
+					ToAmount:      leg.Amount,
+					Rate:          fx.RateScale,
+				}
+
+				// Every leg is charged the fee of a transfer of its own
+				transfer.Fee, err = transferFee(ctx, q, transfer)
+				if err != nil {
+					return err
+				}
+
+				legResult.TransferTxResult, err = postTransfer(ctx, q, transfer)
+				if err != nil {
+					return translateError(err)
+				}
+
+				return translateError(chargeFee(ctx, q, &legResult.TransferTxResult))
+			}
+
+			if arg.BestEffort {
+				// A leg which fails is undone on its own, the transaction goes on with the others
+				legResult.Err = savepoint(ctx, q, makeLeg)
+			} else {
+				legResult.Err = makeLeg()
+			}
+
+			result.Legs = append(result.Legs, legResult)
+			if legResult.Err != nil && !arg.BestEffort {
+				return &BatchLegError{Index: i, Err: legResult.Err}
+			}
+		}
+
+		return nil
+	})
+
+	return result, err
+}
+
+// lockBatchAccounts locks every account of the batch in ID order, so concurrent batches can't deadlock.
+// Accounts which don't exist are left out of the result
+func lockBatchAccounts(ctx context.Context, q *Queries, arg BatchTransferTxParams) (map[int64]Account, error) {
+	ids := []int64{arg.FromAccountID}
+	for _, leg := range arg.Legs {
+		ids = append(ids, leg.ToAccountID)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	accounts := make(map[int64]Account)
+	for i, id := range ids {
+		if i > 0 && ids[i-1] == id {
+			continue
+		}
+
+		account, err := q.GetAccountForUpdate(ctx, id)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				continue
+			}
+			return nil, err
+		}
+		accounts[id] = account
+	}
+
+	return accounts, nil
+}
+
+// savepoint runs fn within a savepoint of the current transaction, rolling back only
+// what fn did if it fails
+func savepoint(ctx context.Context, q *Queries, fn func() error) error {
+	if _, err := q.db.ExecContext(ctx, "SAVEPOINT batch_leg"); err != nil {
+		return err
+	}
+
+	if err := fn(); err != nil {
+		if _, rbErr := q.db.ExecContext(ctx, "ROLLBACK TO SAVEPOINT batch_leg"); rbErr != nil {
+			return fmt.Errorf("savepoint err: %w, rb err: %v", err, rbErr)
+		}
+		return err
+	}
+
+	_, err := q.db.ExecContext(ctx, "RELEASE SAVEPOINT batch_leg")
+	return err
+}
+
+// checkTransferLimits makes sure that taking the amount out of the account keeps its owner within the
+// limits of their tier. The owner is locked, so concurrent transactions can't go over the limits together
+func checkTransferLimits(ctx context.Context, q *Queries, account Account, amount int64) error {
+	user, err := q.GetUserForUpdate(ctx, account.Owner)
+	if err != nil {
+		return err
+	}
+
+	limit, err := q.GetTransferLimit(ctx, GetTransferLimitParams{
+		Tier:     user.Tier,
+		Currency: account.Currency,
+	})
+	if err != nil {
+		// Tiers without limits for the currency aren't limited
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return err
+	}
+
+	if amount > limit.MaxPerTransaction {
+		return &LimitExceededError{
+			Limit:     LimitPerTransaction,
+			Max:       limit.MaxPerTransaction,
+			Remaining: limit.MaxPerTransaction,
+		}
+	}
+
+	// Days start at midnight UTC
+	usage, err := q.GetDailyOutgoing(ctx, GetDailyOutgoingParams{
+		Owner:    account.Owner,
+		Currency: account.Currency,
+		Since:    time.Now().UTC().Truncate(24 * time.Hour),
+	})
+	if err != nil {
+		return err
+	}
+
+	if usage.TotalCount >= limit.DailyCount {
+		return &LimitExceededError{
+			Limit:     LimitDailyCount,
+			Max:       int64(limit.DailyCount),
+			Remaining: 0,
+		}
+	}
+
+	if usage.TotalAmount+amount > limit.DailyAmount {
+		remaining := limit.DailyAmount - usage.TotalAmount
+		if remaining < 0 {
+			remaining = 0
+		}
+		return &LimitExceededError{
+			Limit:     LimitDailyAmount,
+			Max:       limit.DailyAmount,
+			Remaining: remaining,
+		}
+	}
+
+	return nil
+}
+
+// transferFee works out the fee of a transfer with the rule of its currency, type and amount tier
+func transferFee(ctx context.Context, q *Queries, transfer CreateTransferParams) (int64, error) {
+	transferType := TransferTypeSameCurrency
+	if transfer.FromCurrency != transfer.ToCurrency {
+		transferType = TransferTypeCrossCurrency
+	}
+
+	rule, err := q.GetFeeRule(ctx, GetFeeRuleParams{
+		Currency:     transfer.FromCurrency,
+		TransferType: transferType,
+		Amount:       transfer.Amount,
+	})
+	if err != nil {
+		// Transfers without a fee rule are free
+		if err == sql.ErrNoRows {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	return calculateFee(rule, transfer.Amount), nil
+}
+
+// calculateFee applies a fee rule to an amount, rounding the percentage down
+func calculateFee(rule FeeRule, amount int64) int64 {
+	fee := rule.FlatFee + fx.MulDiv(amount, int64(rule.PercentageBps), 10_000)
+
+	if fee < rule.MinFee {
+		fee = rule.MinFee
+	}
+	if rule.MaxFee > 0 && fee > rule.MaxFee {
+		fee = rule.MaxFee
+	}
+
+	return fee
+}
+
+// chargeFee moves the fee of the transfer from the origin account to the house account of its currency
+func chargeFee(ctx context.Context, q *Queries, result *TransferTxResult) error {
+	transfer := result.Transfer
+	if transfer.Fee == 0 {
+		return nil
+	}
+
+	houseAccount, err := houseAccountFor(ctx, q, HousePurposeFeeRevenue, transfer.FromCurrency)
+	if err != nil {
+		return err
+	}
+
+	// The house account is updated after the accounts of the transfer, like in every other transfer
+	entries, accounts, err := postJournal(ctx, q, EntrySourceTransferFee, transfer.ID,
+		journalLine{AccountID: transfer.FromAccountID, Currency: transfer.FromCurrency, Amount: -transfer.Fee},
+		journalLine{AccountID: houseAccount.ID, Currency: transfer.FromCurrency, Amount: transfer.Fee},
+	)
+	if err != nil {
+		return err
+	}
+
+	result.FeeEntry, result.FromAccount = entries[0], accounts[0]
+	return nil
+}
+
+// PostInterestTxParams contains the input parameters of the interest posting
+type PostInterestTxParams struct {
+	AccountID int64 `json:"account_id"`
+	// Period is the first day of the month whose interest is paid.
+	// Accruals of earlier months which weren't paid yet are paid along with it
+	Period time.Time `json:"period"`
+}
+
+// PostInterestTxResult is the result of the interest posting
+type PostInterestTxResult struct {
+	Posting InterestPosting `json:"posting"`
+	Account Account         `json:"account"`
+	// Entry is empty when the interest was less than the smallest unit of the currency
+	Entry Entry `json:"entry"`
+}
+
+// PostInterestTx pays the interest an account accrued until the end of a month, out of the interest
+// expense account of its currency. The fraction of the smallest unit which can't be paid is carried to the next month.
+// ErrInterestAlreadyPosted is returned if the month was already paid, so the posting can be rerun safely
+func (store *SQLStore) PostInterestTx(ctx context.Context, arg PostInterestTxParams) (PostInterestTxResult, error) {
+	var result PostInterestTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		// Locking the account so the accruals can't be paid twice by concurrent postings
+		account, err := q.GetAccountForUpdate(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		periodEnd := arg.Period.AddDate(0, 1, 0)
+		accrued, err := q.SumUnpostedInterest(ctx, SumUnpostedInterestParams{
+			AccountID: arg.AccountID,
+			PeriodEnd: periodEnd,
+		})
+		if err != nil {
+			return err
+		}
+
+		var carried int64
+		lastPosting, err := q.GetLastInterestPosting(ctx, arg.AccountID)
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
+		if err == nil {
+			carried = lastPosting.Remainder
+		}
+
+		total := accrued + carried
+		result.Posting, err = q.CreateInterestPosting(ctx, CreateInterestPostingParams{
+			AccountID: arg.AccountID,
+			Period:    arg.Period,
+			Amount:    total / InterestScale,
+			Remainder: total % InterestScale,
+		})
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrInterestAlreadyPosted
+			}
+			return err
+		}
+
+		_, err = q.MarkInterestPosted(ctx, MarkInterestPostedParams{
+			PostingID: sql.NullInt64{Int64: result.Posting.ID, Valid: true},
+			AccountID: arg.AccountID,
+			PeriodEnd: periodEnd,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Account = account
+		if result.Posting.Amount == 0 {
+			return nil
+		}
+
+		houseAccount, err := houseAccountFor(ctx, q, HousePurposeInterestExpense, account.Currency)
+		if err != nil {
+			return err
+		}
+
+		// The account was locked first, so the house account is updated last, like when fees are charged
+		entries, accounts, err := postJournal(ctx, q, EntrySourceInterest, result.Posting.ID,
+			journalLine{AccountID: account.ID, Currency: account.Currency, Amount: result.Posting.Amount},
+			journalLine{AccountID: houseAccount.ID, Currency: account.Currency, Amount: -result.Posting.Amount},
+		)
+		if err != nil {
+			return err
+		}
+
+		result.Entry, result.Account = entries[0], accounts[0]
+		return nil
+	})
+
+	return result, err
+}
+
+// ChangeAccountStatusTxParams contains the input parameters of the account status change
+type ChangeAccountStatusTxParams struct {
+	AccountID int64  `json:"account_id"`
+	Status    string `json:"status"`
+	ChangedBy string `json:"changed_by"`
+	// ByStaff is set when a banker or an admin changes the status of the account of a customer
+	ByStaff bool   `json:"by_staff"`
+	Reason  string `json:"reason"`
+	// PayoutAccountID receives the balance of an account which is closed, zero if there is nothing to pay out
+	PayoutAccountID int64 `json:"payout_account_id"`
+}
+
+// ChangeAccountStatusTxResult is the result of the account status change
+type ChangeAccountStatusTxResult struct {
+	Account Account             `json:"account"`
+	Change  AccountStatusChange `json:"change"`
+	// Payout is only set when the balance of a closed account was paid out
+	Payout *TransferTxResult `json:"payout,omitempty"`
+}
+
+// ChangeAccountStatusTx freezes, unfreezes or closes an account, recording who did it and why.
+// Active accounts can be frozen or closed, and frozen accounts can only be made active again.
+// The balance of an account which is closed is transferred to the payout account, which must belong to the
+// same owner, so it's moved without fees or limits like any other move between the accounts of an owner.
+// Interest it accrued and which wasn't posted yet is forfeited
+func (store *SQLStore) ChangeAccountStatusTx(ctx context.Context, arg ChangeAccountStatusTxParams) (ChangeAccountStatusTxResult, error) {
+	var result ChangeAccountStatusTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		// Locking the accounts in ID order, like transfers do, avoiding deadlocks with the payout
+		if arg.Status == AccountStatusClosed && arg.PayoutAccountID != 0 && arg.PayoutAccountID < arg.AccountID {
+			_, err := q.GetAccountForUpdate(ctx, arg.PayoutAccountID)
+			if err != nil && err != sql.ErrNoRows {
+				return err
+			}
+		}
+
+		// Locking the account, so money can't move while its status changes
+		account, err := q.GetAccountForUpdate(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		if !validStatusChange(account.Status, arg.Status) {
+			return ErrInvalidStatusChange
+		}
+
+		var payoutTransferID sql.NullInt64
+		if arg.Status == AccountStatusClosed {
+			result.Payout, err = payOut(ctx, q, account, arg.PayoutAccountID)
+			if err != nil {
+				return err
+			}
+			if result.Payout != nil {
+				payoutTransferID = sql.NullInt64{Int64: result.Payout.Transfer.ID, Valid: true}
+			}
+		}
+
+		result.Account, err = q.UpdateAccountStatus(ctx, UpdateAccountStatusParams{
+			ID:     account.ID,
+			Status: arg.Status,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Change, err = q.CreateAccountStatusChange(ctx, CreateAccountStatusChangeParams{
+			AccountID:        account.ID,
+			FromStatus:       account.Status,
+			ToStatus:         arg.Status,
+			ChangedBy:        arg.ChangedBy,
+			Reason:           arg.Reason,
+			PayoutTransferID: payoutTransferID,
+			ByStaff:          arg.ByStaff,
+		})
+		return err
 	})
-	return
+
+	return result, err
+}
+
+// validStatusChange tells if an account can go from one status to the other
+func validStatusChange(from string, to string) bool {
+	switch to {
+	case AccountStatusFrozen, AccountStatusClosed:
+		return from == AccountStatusActive
+	case AccountStatusActive:
+		return from == AccountStatusFrozen
+	default:
+		return false
+	}
+}
+
+// payOut empties an account which is being closed into another account of its owner.
+// Nothing is paid out when the balance is already zero
+func payOut(ctx context.Context, q *Queries, account Account, payoutAccountID int64) (*TransferTxResult, error) {
+	if account.HeldAmount > 0 {
+		return nil, ErrAccountHasHolds
+	}
+	if account.Balance == 0 {
+		return nil, nil
+	}
+	if account.Balance < 0 || payoutAccountID == 0 {
+		return nil, ErrAccountNotEmpty
+	}
+
+	payoutAccount, err := q.GetAccount(ctx, payoutAccountID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrInvalidPayout
+		}
+		return nil, err
+	}
+	if payoutAccount.ID == account.ID ||
+		payoutAccount.Owner != account.Owner ||
+		payoutAccount.Type == AccountTypeHouse ||
+		payoutAccount.Currency != account.Currency ||
+		payoutAccount.Status != AccountStatusActive {
+		return nil, ErrInvalidPayout
+	}
+
+	result, err := postTransfer(ctx, q, CreateTransferParams{
+		FromAccountID: account.ID,
+		ToAccountID:   payoutAccount.ID,
+		Amount:        account.Balance,
+		FromCurrency:  account.Currency,
+		ToCurrency:    payoutAccount.Currency,
+		ToAmount:      account.Balance,
+		Rate:          fx.RateScale,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// CreateAccountTxParams contains the input parameters of the create account transaction
+type CreateAccountTxParams struct {
+	CreateAccountParams
+	// MaxPerCurrency is how many open accounts the owner can have in the currency
+	MaxPerCurrency int64 `json:"max_per_currency"`
+}
+
+// CreateAccountTx opens a new account, unless the owner already has MaxPerCurrency open accounts in the
+// currency, returning ErrAccountLimitReached then
+func (store *SQLStore) CreateAccountTx(ctx context.Context, arg CreateAccountTxParams) (Account, error) {
+	var account Account
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		// The owner is locked, so concurrent requests can't open more accounts than allowed together
+		_, err := q.GetUserForUpdate(ctx, arg.Owner)
+		if err != nil {
+			return err
+		}
+
+		count, err := q.CountOpenAccounts(ctx, CountOpenAccountsParams{
+			Owner:    arg.Owner,
+			Currency: arg.Currency,
+		})
+		if err != nil {
+			return err
+		}
+		if count >= arg.MaxPerCurrency {
+			return ErrAccountLimitReached
+		}
+
+		account, err = q.CreateAccount(ctx, arg.CreateAccountParams)
+		return err
+	})
+
+	return account, err
+}
+
+// RotateSessionTxParams contains the input parameters of the session rotation transaction
+type RotateSessionTxParams struct {
+	// ID is the session of the refresh token which is exchanged
+	ID uuid.UUID `json:"id"`
+	// Session is the session of the new refresh token, its family and parent are taken from the exchanged one
+	Session CreateSessionParams `json:"session"`
+}
+
+// RotateSessionTxResult is the result of the session rotation
+type RotateSessionTxResult struct {
+	Session Session `json:"session"`
+	// ReuseEvent is only set when the refresh token was already exchanged, no session being created then
+	ReuseEvent *SecurityEvent `json:"reuse_event,omitempty"`
+}
+
+// RotateSessionTx exchanges the refresh token of a session for a new one in the same family, after which
+// the old one can't be used anymore. When it was already exchanged, which means someone else holds it,
+// the whole family is blocked and a security event is recorded for the user instead.
+// It returns ErrSessionBlocked when the session is blocked
+func (store *SQLStore) RotateSessionTx(ctx context.Context, arg RotateSessionTxParams) (RotateSessionTxResult, error) {
+	var result RotateSessionTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		// Locking the session, so it's only exchanged once when the same token is presented concurrently
+		session, err := q.GetSessionForUpdate(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+		if session.IsBlocked {
+			return ErrSessionBlocked
+		}
+
+		if session.RotatedAt.Valid {
+			_, err = q.BlockSessionFamily(ctx, session.FamilyID)
+			if err != nil {
+				return err
+			}
+
+			event, err := q.CreateSecurityEvent(ctx, CreateSecurityEventParams{
+				Username:        session.Username,
+				Kind:            SecurityEventRefreshTokenReused,
+				SessionFamilyID: session.FamilyID,
+				UserAgent:       arg.Session.UserAgent,
+				ClientIp:        arg.Session.ClientIp,
+			})
+			result.ReuseEvent = &event
+			return err
+		}
+
+		err = q.RotateSession(ctx, session.ID)
+		if err != nil {
+			return err
+		}
+
+		newSession := arg.Session
+		newSession.FamilyID = session.FamilyID
+		newSession.ParentID = uuid.NullUUID{UUID: session.ID, Valid: true}
+		result.Session, err = q.CreateSession(ctx, newSession)
+		return err
+	})
+
+	return result, err
+}
+
+// UpdateUserRoleTx gives a user another role and blocks every session of theirs, so no token
+// carrying the old role can be refreshed once the role has changed
+func (store *SQLStore) UpdateUserRoleTx(ctx context.Context, arg UpdateUserRoleParams) (User, error) {
+	var user User
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		user, err = q.UpdateUserRole(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		_, err = q.BlockUserSessions(ctx, user.Username)
+		return err
+	})
+
+	return user, err
+}
+
+// RecoveryCodesTxParams contains the input parameters of the transactions which give a user new recovery codes
+type RecoveryCodesTxParams struct {
+	Username string `json:"username"`
+	// HashedCodes are the hashes of the new recovery codes, which replace every previous one
+	HashedCodes []string `json:"hashed_codes"`
+}
+
+// EnableTwoFactorTx enables two-factor authentication once the authenticator of the user was verified,
+// along with their first recovery codes
+func (store *SQLStore) EnableTwoFactorTx(ctx context.Context, arg RecoveryCodesTxParams) (TotpSecret, error) {
+	var secret TotpSecret
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		secret, err = q.EnableTotpSecret(ctx, arg.Username)
+		if err != nil {
+			return err
+		}
+
+		return replaceRecoveryCodes(ctx, q, arg)
+	})
+
+	return secret, err
+}
+
+// ReplaceRecoveryCodesTx gives the user new recovery codes, the previous ones can't be used anymore
+func (store *SQLStore) ReplaceRecoveryCodesTx(ctx context.Context, arg RecoveryCodesTxParams) error {
+	return store.execTx(ctx, func(q *Queries) error {
+		return replaceRecoveryCodes(ctx, q, arg)
+	})
+}
+
+// DisableTwoFactorTx forgets the authenticator and the recovery codes of the user
+func (store *SQLStore) DisableTwoFactorTx(ctx context.Context, username string) error {
+	return store.execTx(ctx, func(q *Queries) error {
+		err := q.DeleteRecoveryCodes(ctx, username)
+		if err != nil {
+			return err
+		}
+
+		_, err = q.DeleteTotpSecret(ctx, username)
+		return err
+	})
+}
+
+func replaceRecoveryCodes(ctx context.Context, q *Queries, arg RecoveryCodesTxParams) error {
+	err := q.DeleteRecoveryCodes(ctx, arg.Username)
+	if err != nil {
+		return err
+	}
+
+	for _, hashedCode := range arg.HashedCodes {
+		_, err = q.CreateRecoveryCode(ctx, CreateRecoveryCodeParams{
+			Username:   arg.Username,
+			HashedCode: hashedCode,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
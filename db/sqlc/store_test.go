@@ -2,8 +2,12 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"testing"
+	"time"
+
+	"simplebank/util"
 
 	"github.com/stretchr/testify/require"
 )
@@ -11,13 +15,14 @@ import (
 func TestTransferTx(t *testing.T) {
 	store := NewStore(testDB)
 
-	account1 := createRandomAccount(t)
-	account2 := createRandomAccount(t)
-	fmt.Println(">> before:", account1.Balance, account2.Balance)
-
 	n := 5
 	amount := int64(10)
 
+	// The origin account must be able to afford all the transfers
+	account1 := fundAccount(t, createRandomAccount(t), int64(n)*amount)
+	account2 := createRandomAccountWithCurrency(t, account1.Currency)
+	fmt.Println(">> before:", account1.Balance, account2.Balance)
+
 	// Creating a channel to receive possible errors and the results
 	errs := make(chan error)
 	results := make(chan TransferTxResult)
@@ -55,6 +60,9 @@ func TestTransferTx(t *testing.T) {
 		require.Equal(t, account1.ID, transfer.FromAccountID)
 		require.Equal(t, account2.ID, transfer.ToAccountID)
 		require.Equal(t, amount, transfer.Amount)
+		require.Equal(t, account1.Currency, transfer.FromCurrency)
+		require.Equal(t, account2.Currency, transfer.ToCurrency)
+		require.Equal(t, amount, transfer.ToAmount)
 		require.NotZero(t, transfer.ID)
 		require.NotZero(t, transfer.CreatedAt)
 
@@ -67,6 +75,8 @@ func TestTransferTx(t *testing.T) {
 		require.NotEmpty(t, fromEntry)
 		require.Equal(t, account1.ID, fromEntry.AccountID)
 		require.Equal(t, -amount, fromEntry.Amount)
+		require.Equal(t, EntrySourceTransfer, fromEntry.SourceType)
+		require.Equal(t, transfer.ID, fromEntry.SourceID)
 		require.NotZero(t, fromEntry.ID)
 		require.NotZero(t, fromEntry.CreatedAt)
 
@@ -79,6 +89,8 @@ func TestTransferTx(t *testing.T) {
 		require.NotEmpty(t, toEntry)
 		require.Equal(t, account2.ID, toEntry.AccountID)
 		require.Equal(t, amount, toEntry.Amount)
+		require.Equal(t, EntrySourceTransfer, toEntry.SourceType)
+		require.Equal(t, transfer.ID, toEntry.SourceID)
 		require.NotZero(t, toEntry.ID)
 		require.NotZero(t, toEntry.CreatedAt)
 
@@ -123,12 +135,13 @@ func TestTransferTx(t *testing.T) {
 func TestTransferTxDeadlock(t *testing.T) {
 	store := NewStore(testDB)
 
-	account1 := createRandomAccount(t)
-	account2 := createRandomAccount(t)
-	fmt.Println(">> before:", account1.Balance, account2.Balance)
-
 	n := 10
 	amount := int64(10)
+
+	// Both accounts must be able to afford all the transfers in their direction
+	account1 := fundAccount(t, createRandomAccount(t), int64(n)*amount)
+	account2 := fundAccount(t, createRandomAccountWithCurrency(t, account1.Currency), int64(n)*amount)
+	fmt.Println(">> before:", account1.Balance, account2.Balance)
 	errs := make(chan error)
 
 	for i := 0; i < n; i++ {
@@ -172,3 +185,1243 @@ func TestTransferTxDeadlock(t *testing.T) {
 	require.Equal(t, account1.Balance, updatedAccount1.Balance)
 	require.Equal(t, account2.Balance, updatedAccount2.Balance)
 }
+
+func TestTransferTxInsufficientFunds(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccountWithCurrency(t, account1.Currency)
+
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        account1.Balance + 1,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	// Nothing should have changed
+	updatedAccount1, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, updatedAccount1.Balance)
+
+	updatedAccount2, err := store.GetAccount(context.Background(), account2.ID)
+	require.NoError(t, err)
+	require.Equal(t, account2.Balance, updatedAccount2.Balance)
+}
+
+func TestTransferTxOverdraftLimit(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccountWithCurrency(t, account1.Currency)
+
+	overdraftLimit := int64(100)
+	account1, err := store.UpdateAccountOverdraftLimit(context.Background(), UpdateAccountOverdraftLimitParams{
+		ID:             account1.ID,
+		OverdraftLimit: overdraftLimit,
+	})
+	require.NoError(t, err)
+
+	// The balance can go below zero, down to the overdraft limit
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        account1.Balance + overdraftLimit,
+	})
+	require.NoError(t, err)
+	require.Equal(t, -overdraftLimit, result.FromAccount.Balance)
+
+	// But not any further
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        1,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+}
+
+func TestTransferTxCurrencyMismatch(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountWithCurrency(t, util.USD)
+	account2 := createRandomAccountWithCurrency(t, util.EUR)
+
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.ErrorIs(t, err, ErrCurrencyMismatch)
+}
+
+func TestTransferTxWithQuote(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountWithCurrency(t, util.USD)
+	account2 := createRandomAccountWithCurrency(t, util.EUR)
+	quote := createRandomFxQuote(t, account1.Owner, account1.Currency, account2.Currency, time.Minute)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        quote.FromAmount,
+		QuoteID:       quote.ID,
+		User:          account1.Owner,
+	})
+	require.NoError(t, err)
+
+	// Each leg is recorded in the currency of its account
+	transfer := result.Transfer
+	require.Equal(t, quote.FromAmount, transfer.Amount)
+	require.Equal(t, util.USD, transfer.FromCurrency)
+	require.Equal(t, quote.ToAmount, transfer.ToAmount)
+	require.Equal(t, util.EUR, transfer.ToCurrency)
+	require.Equal(t, quote.Rate, transfer.Rate)
+	require.Equal(t, quote.SpreadBps, transfer.SpreadBps)
+	require.Equal(t, quote.ID, transfer.QuoteID.Int64)
+
+	require.Equal(t, -quote.FromAmount, result.FromEntry.Amount)
+	require.Equal(t, quote.ToAmount, result.ToEntry.Amount)
+	require.Equal(t, account1.Balance-quote.FromAmount, result.FromAccount.Balance)
+	require.Equal(t, account2.Balance+quote.ToAmount, result.ToAccount.Balance)
+
+	// Each currency balances through its conversion account
+	require.Equal(t, result.FromEntry.JournalID, result.ToEntry.JournalID)
+	entries := requireBalancedJournal(t, result.FromEntry.JournalID)
+	require.Len(t, entries, 4)
+
+	// A quote can't be used twice
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        quote.FromAmount,
+		QuoteID:       quote.ID,
+		User:          account1.Owner,
+	})
+	require.ErrorIs(t, err, ErrQuoteUnavailable)
+}
+
+func TestTransferTxWithQuoteBySpender(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := fundAccount(t, createRandomAccountWithCurrency(t, util.USD), 100)
+	account2 := createRandomAccountWithCurrency(t, util.EUR)
+	spender := createRandomAccountMember(t, account1, AccountRoleSpender)
+
+	// A quote given to the owner of the account can't be used by someone else
+	ownerQuote := createRandomFxQuote(t, account1.Owner, account1.Currency, account2.Currency, time.Minute)
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        ownerQuote.FromAmount,
+		QuoteID:       ownerQuote.ID,
+		User:          spender.Username,
+	})
+	require.ErrorIs(t, err, ErrQuoteMismatch)
+
+	// The spender converts the money with a quote of their own
+	quote := createRandomFxQuote(t, spender.Username, account1.Currency, account2.Currency, time.Minute)
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        quote.FromAmount,
+		QuoteID:       quote.ID,
+		User:          spender.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, quote.ID, result.Transfer.QuoteID.Int64)
+	require.Equal(t, quote.ToAmount, result.ToEntry.Amount)
+	require.Equal(t, account1.Balance-quote.FromAmount, result.FromAccount.Balance)
+}
+
+func TestTransferTxQuoteMismatch(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountWithCurrency(t, util.USD)
+	account2 := createRandomAccountWithCurrency(t, util.EUR)
+	quote := createRandomFxQuote(t, account1.Owner, account1.Currency, account2.Currency, time.Minute)
+
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        quote.FromAmount + 1,
+		QuoteID:       quote.ID,
+		User:          account1.Owner,
+	})
+	require.ErrorIs(t, err, ErrQuoteMismatch)
+
+	// The failed transfer must not use up the quote
+	quote, err = store.GetFxQuote(context.Background(), quote.ID)
+	require.NoError(t, err)
+	require.False(t, quote.UsedAt.Valid)
+}
+
+func TestTransferTxExpiredQuote(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountWithCurrency(t, util.USD)
+	account2 := createRandomAccountWithCurrency(t, util.EUR)
+	quote := createRandomFxQuote(t, account1.Owner, account1.Currency, account2.Currency, -time.Minute)
+
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        quote.FromAmount,
+		QuoteID:       quote.ID,
+		User:          account1.Owner,
+	})
+	require.ErrorIs(t, err, ErrQuoteUnavailable)
+}
+
+func TestDepositTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := createRandomAccount(t)
+	user := createRandomUser(t)
+	amount := int64(10)
+
+	cash, err := store.GetHouseAccount(context.Background(), GetHouseAccountParams{
+		Purpose:  HousePurposeCash,
+		Currency: account.Currency,
+	})
+	require.NoError(t, err)
+
+	result, err := store.DepositTx(context.Background(), DepositTxParams{
+		AccountID: account.ID,
+		Amount:    amount,
+		User:      user.Username,
+	})
+	require.NoError(t, err)
+
+	deposit := result.Deposit
+	require.Equal(t, account.ID, deposit.AccountID)
+	require.Equal(t, amount, deposit.Amount)
+
+	entry := result.Entry
+	require.Equal(t, account.ID, entry.AccountID)
+	require.Equal(t, amount, entry.Amount)
+	require.Equal(t, EntrySourceDeposit, entry.SourceType)
+	require.Equal(t, deposit.ID, entry.SourceID)
+
+	// The money comes out of the cash account
+	entries := requireBalancedJournal(t, entry.JournalID)
+	require.Len(t, entries, 2)
+	require.Equal(t, cash.ID, entries[1].AccountID)
+	require.Equal(t, -amount, entries[1].Amount)
+
+	updatedAccount, err := store.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, account.Balance+amount, updatedAccount.Balance)
+}
+
+func TestWithdrawTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	n := 5
+	amount := int64(10)
+
+	// Making sure the account can afford all the withdrawals
+	account := fundAccount(t, createRandomAccount(t), int64(n)*amount)
+	user := createRandomUser(t)
+
+	errs := make(chan error)
+	results := make(chan WithdrawTxResult)
+
+	// run n concurrent withdraw transactions
+	for i := 0; i < n; i++ {
+		go func() {
+			result, err := store.WithdrawTx(context.Background(), WithdrawTxParams{
+				AccountID: account.ID,
+				Amount:    amount,
+				User:      user.Username,
+			})
+
+			errs <- err
+			results <- result
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		err := <-errs
+		require.NoError(t, err)
+
+		result := <-results
+		require.NotEmpty(t, result)
+
+		// Checking withdraw
+		withdraw := result.Withdraw
+		require.Equal(t, account.ID, withdraw.AccountID)
+		require.Equal(t, amount, withdraw.Amount)
+		require.Equal(t, user.Username, withdraw.User)
+		require.NotZero(t, withdraw.ID)
+		require.NotZero(t, withdraw.CreatedAt)
+
+		_, err = store.GetWithdraw(context.Background(), withdraw.ID)
+		require.NoError(t, err)
+
+		// Checking entry
+		entry := result.Entry
+		require.Equal(t, account.ID, entry.AccountID)
+		require.Equal(t, -amount, entry.Amount)
+		require.Equal(t, EntrySourceWithdraw, entry.SourceType)
+		require.Equal(t, withdraw.ID, entry.SourceID)
+
+		_, err = store.GetEntry(context.Background(), entry.ID)
+		require.NoError(t, err)
+
+		entries := requireBalancedJournal(t, entry.JournalID)
+		require.Len(t, entries, 2)
+
+		// Checking account
+		diff := account.Balance - result.Account.Balance
+		require.True(t, diff > 0)
+		require.True(t, diff%amount == 0)
+	}
+
+	updatedAccount, err := store.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, account.Balance-int64(n)*amount, updatedAccount.Balance)
+}
+
+func TestWithdrawTxInsufficientFunds(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := createRandomAccount(t)
+	user := createRandomUser(t)
+
+	_, err := store.WithdrawTx(context.Background(), WithdrawTxParams{
+		AccountID: account.ID,
+		Amount:    account.Balance + 1,
+		User:      user.Username,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	// Balance must be left untouched
+	updatedAccount, err := store.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, account.Balance, updatedAccount.Balance)
+}
+
+// fundAccount adds money to an account, so it can afford the tested transactions
+func TestReverseTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccountWithCurrency(t, account1.Currency)
+	amount := int64(10)
+
+	transfer, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        amount,
+	})
+	require.NoError(t, err)
+
+	// Refunding part of the transfer
+	result, err := store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: transfer.Transfer.ID,
+		Amount:     4,
+	})
+	require.NoError(t, err)
+
+	reversal := result.Reversal.Transfer
+	require.Equal(t, account2.ID, reversal.FromAccountID)
+	require.Equal(t, account1.ID, reversal.ToAccountID)
+	require.Equal(t, int64(4), reversal.Amount)
+	require.Equal(t, int64(4), reversal.ToAmount)
+	require.Equal(t, transfer.Transfer.ID, reversal.ReversalOfID.Int64)
+	require.Equal(t, int64(4), result.OriginalTransfer.ReversedAmount)
+
+	require.Equal(t, account1.Balance-amount+4, result.Reversal.ToAccount.Balance)
+	require.Equal(t, account2.Balance+amount-4, result.Reversal.FromAccount.Balance)
+
+	// No more than what is left can be refunded
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: transfer.Transfer.ID,
+		Amount:     amount,
+	})
+	require.ErrorIs(t, err, ErrReversalExceedsTransfer)
+
+	// Refunding the rest of the transfer
+	result, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: transfer.Transfer.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, amount-4, result.Reversal.Transfer.Amount)
+	require.Equal(t, amount, result.OriginalTransfer.ReversedAmount)
+	require.Equal(t, account1.Balance, result.Reversal.ToAccount.Balance)
+	require.Equal(t, account2.Balance, result.Reversal.FromAccount.Balance)
+
+	// The reversal state is visible on the original transfer
+	original, err := testQueries.GetTransfer(context.Background(), transfer.Transfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, amount, original.ReversedAmount)
+
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: transfer.Transfer.ID,
+	})
+	require.ErrorIs(t, err, ErrTransferAlreadyReversed)
+
+	// A reversal can't be reversed itself
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: result.Reversal.Transfer.ID,
+	})
+	require.ErrorIs(t, err, ErrReversalOfReversal)
+}
+
+func TestReverseTransferTxConcurrent(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccountWithCurrency(t, account1.Currency)
+
+	transfer, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	n := 5
+	errs := make(chan error)
+
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+				TransferID: transfer.Transfer.ID,
+			})
+			errs <- err
+		}()
+	}
+
+	// Only one of the full reversals goes through
+	succeeded := 0
+	for i := 0; i < n; i++ {
+		err := <-errs
+		if err == nil {
+			succeeded++
+			continue
+		}
+		require.ErrorIs(t, err, ErrTransferAlreadyReversed)
+	}
+	require.Equal(t, 1, succeeded)
+}
+
+func TestReverseTransferTxWithQuote(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountWithCurrency(t, util.USD)
+	account2 := createRandomAccountWithCurrency(t, util.EUR)
+	quote := createRandomFxQuote(t, account1.Owner, account1.Currency, account2.Currency, time.Minute)
+
+	transfer, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        quote.FromAmount,
+		QuoteID:       quote.ID,
+		User:          account1.Owner,
+	})
+	require.NoError(t, err)
+
+	// The refund is made at the original rate, in both currencies
+	result, err := store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: transfer.Transfer.ID,
+	})
+	require.NoError(t, err)
+
+	reversal := result.Reversal.Transfer
+	require.Equal(t, util.EUR, reversal.FromCurrency)
+	require.Equal(t, quote.ToAmount, reversal.Amount)
+	require.Equal(t, util.USD, reversal.ToCurrency)
+	require.Equal(t, quote.FromAmount, reversal.ToAmount)
+	require.False(t, reversal.QuoteID.Valid)
+
+	require.Equal(t, account1.Balance, result.Reversal.ToAccount.Balance)
+	require.Equal(t, account2.Balance, result.Reversal.FromAccount.Balance)
+}
+
+func TestAuthorizeHoldTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := fundAccount(t, createRandomAccount(t), 100)
+	amount := account.Balance - 1
+
+	result, err := store.AuthorizeHoldTx(context.Background(), AuthorizeHoldTxParams{
+		AccountID: account.ID,
+		Amount:    amount,
+		User:      account.Owner,
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	require.Equal(t, amount, result.Hold.Amount)
+	require.Equal(t, HoldActive, result.Hold.Status)
+
+	// The money stays on the account, but it can't be spent anymore
+	require.Equal(t, account.Balance, result.Account.Balance)
+	require.Equal(t, amount, result.Account.HeldAmount)
+	require.Equal(t, int64(1), result.Account.AvailableBalance)
+
+	_, err = store.AuthorizeHoldTx(context.Background(), AuthorizeHoldTxParams{
+		AccountID: account.ID,
+		Amount:    2,
+		User:      account.Owner,
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	_, err = store.WithdrawTx(context.Background(), WithdrawTxParams{
+		AccountID: account.ID,
+		Amount:    2,
+		User:      account.Owner,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	account2 := createRandomAccountWithCurrency(t, account.Currency)
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account.ID,
+		ToAccountID:   account2.ID,
+		Amount:        2,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+}
+
+func TestCaptureHoldTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := fundAccount(t, createRandomAccount(t), 100)
+	hold, err := store.AuthorizeHoldTx(context.Background(), AuthorizeHoldTxParams{
+		AccountID: account.ID,
+		Amount:    10,
+		User:      account.Owner,
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	_, err = store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{
+		HoldID: hold.Hold.ID,
+		Amount: 11,
+	})
+	require.ErrorIs(t, err, ErrCaptureExceedsHold)
+
+	// Capturing part of the hold releases the rest of it
+	result, err := store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{
+		HoldID: hold.Hold.ID,
+		Amount: 6,
+	})
+	require.NoError(t, err)
+	require.Equal(t, HoldCaptured, result.Hold.Status)
+	require.Equal(t, int64(6), result.Hold.CapturedAmount)
+	require.True(t, result.Hold.FinishedAt.Valid)
+
+	require.Equal(t, int64(-6), result.Entry.Amount)
+	require.Equal(t, EntrySourceHold, result.Entry.SourceType)
+	require.Equal(t, hold.Hold.ID, result.Entry.SourceID)
+
+	require.Equal(t, account.Balance-6, result.Account.Balance)
+	require.Zero(t, result.Account.HeldAmount)
+	require.Equal(t, account.Balance-6, result.Account.AvailableBalance)
+
+	_, err = store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{
+		HoldID: hold.Hold.ID,
+	})
+	require.ErrorIs(t, err, ErrHoldNotActive)
+}
+
+func TestVoidHoldTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := fundAccount(t, createRandomAccount(t), 100)
+	hold, err := store.AuthorizeHoldTx(context.Background(), AuthorizeHoldTxParams{
+		AccountID: account.ID,
+		Amount:    10,
+		User:      account.Owner,
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	// Expiring a hold which can still be captured isn't allowed
+	_, err = store.ExpireHoldTx(context.Background(), hold.Hold.ID)
+	require.ErrorIs(t, err, ErrHoldNotExpiredYet)
+
+	result, err := store.VoidHoldTx(context.Background(), hold.Hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, HoldVoided, result.Hold.Status)
+	require.Zero(t, result.Hold.CapturedAmount)
+	require.Equal(t, account.Balance, result.Account.Balance)
+	require.Equal(t, account.Balance, result.Account.AvailableBalance)
+
+	_, err = store.VoidHoldTx(context.Background(), hold.Hold.ID)
+	require.ErrorIs(t, err, ErrHoldNotActive)
+}
+
+func TestExpireHoldTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := fundAccount(t, createRandomAccount(t), 100)
+	hold, err := store.AuthorizeHoldTx(context.Background(), AuthorizeHoldTxParams{
+		AccountID: account.ID,
+		Amount:    10,
+		User:      account.Owner,
+		ExpiresAt: time.Now().Add(-time.Second),
+	})
+	require.NoError(t, err)
+
+	// An expired hold can't be captured anymore
+	_, err = store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{
+		HoldID: hold.Hold.ID,
+	})
+	require.ErrorIs(t, err, ErrHoldExpired)
+
+	result, err := store.ExpireHoldTx(context.Background(), hold.Hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, HoldExpired, result.Hold.Status)
+	require.Equal(t, account.Balance, result.Account.AvailableBalance)
+}
+
+func TestBatchTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := fundAccount(t, createRandomAccount(t), 100)
+	account2 := createRandomAccountWithCurrency(t, account1.Currency)
+	account3 := createRandomAccountWithCurrency(t, account1.Currency)
+
+	result, err := store.BatchTransferTx(context.Background(), BatchTransferTxParams{
+		FromAccountID: account1.ID,
+		Legs: []BatchTransferLeg{
+			{ToAccountID: account2.ID, Amount: 10},
+			{ToAccountID: account3.ID, Amount: 20},
+			{ToAccountID: account2.ID, Amount: 30},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Legs, 3)
+
+	for _, leg := range result.Legs {
+		require.NoError(t, leg.Err)
+		require.NotZero(t, leg.Transfer.ID)
+		require.Equal(t, account1.ID, leg.Transfer.FromAccountID)
+	}
+
+	last := result.Legs[2]
+	require.Equal(t, account1.Balance-60, last.FromAccount.Balance)
+	require.Equal(t, account2.Balance+40, last.ToAccount.Balance)
+	require.Equal(t, account3.Balance+20, result.Legs[1].ToAccount.Balance)
+}
+
+func TestBatchTransferTxAllOrNothing(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := fundAccount(t, createRandomAccount(t), 100)
+	account2 := createRandomAccountWithCurrency(t, account1.Currency)
+	account3 := createRandomAccountWithCurrency(t, account1.Currency)
+
+	_, err := store.BatchTransferTx(context.Background(), BatchTransferTxParams{
+		FromAccountID: account1.ID,
+		Legs: []BatchTransferLeg{
+			{ToAccountID: account2.ID, Amount: 10},
+			{ToAccountID: account3.ID, Amount: account1.Balance},
+		},
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	var legErr *BatchLegError
+	require.ErrorAs(t, err, &legErr)
+	require.Equal(t, 1, legErr.Index)
+
+	// The leg which succeeded was rolled back as well
+	account2After, err := testQueries.GetAccount(context.Background(), account2.ID)
+	require.NoError(t, err)
+	require.Equal(t, account2.Balance, account2After.Balance)
+
+	account1After, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, account1After.Balance)
+}
+
+func TestBatchTransferTxBestEffort(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := fundAccount(t, createRandomAccount(t), 100)
+	account2 := createRandomAccountWithCurrency(t, account1.Currency)
+	account3 := createRandomAccountWithCurrency(t, account1.Currency)
+
+	result, err := store.BatchTransferTx(context.Background(), BatchTransferTxParams{
+		FromAccountID: account1.ID,
+		Legs: []BatchTransferLeg{
+			{ToAccountID: account2.ID, Amount: 10},
+			{ToAccountID: account3.ID, Amount: account1.Balance},
+			{ToAccountID: account3.ID, Amount: 20},
+		},
+		BestEffort: true,
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Legs, 3)
+
+	require.NoError(t, result.Legs[0].Err)
+	require.ErrorIs(t, result.Legs[1].Err, ErrInsufficientFunds)
+	require.NoError(t, result.Legs[2].Err)
+
+	// Only the legs which succeeded were committed
+	account1After, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance-30, account1After.Balance)
+
+	account3After, err := testQueries.GetAccount(context.Background(), account3.ID)
+	require.NoError(t, err)
+	require.Equal(t, account3.Balance+20, account3After.Balance)
+}
+
+func TestBatchTransferTxDeadlock(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := fundAccount(t, createRandomAccount(t), 100)
+	account2 := fundAccount(t, createRandomAccountWithCurrency(t, account1.Currency), 100)
+	account3 := createRandomAccountWithCurrency(t, account1.Currency)
+
+	n := 10
+	errs := make(chan error)
+
+	// Batches sending money in opposite directions between the same accounts
+	for i := 0; i < n; i++ {
+		fromAccount, toAccount := account1, account2
+		if i%2 == 1 {
+			fromAccount, toAccount = account2, account1
+		}
+
+		go func() {
+			_, err := store.BatchTransferTx(context.Background(), BatchTransferTxParams{
+				FromAccountID: fromAccount.ID,
+				Legs: []BatchTransferLeg{
+					{ToAccountID: account3.ID, Amount: 1},
+					{ToAccountID: toAccount.ID, Amount: 5},
+				},
+			})
+			errs <- err
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		require.NoError(t, <-errs)
+	}
+
+	account1After, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance-5, account1After.Balance)
+}
+
+func TestBatchTransferTxWithFee(t *testing.T) {
+	store := NewStore(testDB)
+
+	addFeeRule(t, CreateFeeRuleParams{
+		Currency:      util.CAD,
+		TransferType:  TransferTypeSameCurrency,
+		FlatFee:       1,
+		PercentageBps: 100,
+	})
+
+	account1 := fundAccount(t, createRandomAccountWithCurrency(t, util.CAD), 1000)
+	account2 := createRandomAccountWithCurrency(t, util.CAD)
+	account3 := createRandomAccountWithCurrency(t, util.CAD)
+	houseAccount, err := testQueries.GetHouseAccount(context.Background(), GetHouseAccountParams{
+		Purpose:  HousePurposeFeeRevenue,
+		Currency: util.CAD,
+	})
+	require.NoError(t, err)
+
+	result, err := store.BatchTransferTx(context.Background(), BatchTransferTxParams{
+		FromAccountID: account1.ID,
+		Legs: []BatchTransferLeg{
+			{ToAccountID: account2.ID, Amount: 500},
+			{ToAccountID: account3.ID, Amount: 100},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Legs, 2)
+
+	// Every leg pays the fee a transfer on its own would
+	require.Equal(t, int64(6), result.Legs[0].Transfer.Fee)
+	require.Equal(t, int64(-6), result.Legs[0].FeeEntry.Amount)
+	require.Equal(t, EntrySourceTransferFee, result.Legs[0].FeeEntry.SourceType)
+	require.Equal(t, int64(2), result.Legs[1].Transfer.Fee)
+	require.Equal(t, int64(-2), result.Legs[1].FeeEntry.Amount)
+	require.Equal(t, account1.Balance-608, result.Legs[1].FromAccount.Balance)
+
+	houseAccountAfter, err := testQueries.GetAccount(context.Background(), houseAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, houseAccount.Balance+8, houseAccountAfter.Balance)
+
+	// A leg which can't pay its fee fails like the transfer would
+	result, err = store.BatchTransferTx(context.Background(), BatchTransferTxParams{
+		FromAccountID: account1.ID,
+		Legs: []BatchTransferLeg{
+			{ToAccountID: account2.ID, Amount: account1.Balance - 608},
+		},
+		BestEffort: true,
+	})
+	require.NoError(t, err)
+	require.ErrorIs(t, result.Legs[0].Err, ErrInsufficientFunds)
+}
+
+func TestTransferTxLimits(t *testing.T) {
+	store := NewStore(testDB)
+
+	limit := createRandomTransferLimit(t, util.USD)
+	account1 := createLimitedAccount(t, limit)
+	account2 := createRandomAccountWithCurrency(t, util.USD)
+
+	transfer := func(amount int64) error {
+		_, err := store.TransferTx(context.Background(), TransferTxParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        amount,
+		})
+		return err
+	}
+
+	var limitErr *LimitExceededError
+	err := transfer(limit.MaxPerTransaction + 1)
+	require.ErrorAs(t, err, &limitErr)
+	require.Equal(t, LimitPerTransaction, limitErr.Limit)
+	require.Equal(t, limit.MaxPerTransaction, limitErr.Remaining)
+
+	require.NoError(t, transfer(50))
+	require.NoError(t, transfer(40))
+
+	// Withdrawals count against the same limits
+	_, err = store.WithdrawTx(context.Background(), WithdrawTxParams{
+		AccountID: account1.ID,
+		Amount:    20,
+		User:      account1.Owner,
+	})
+	require.ErrorAs(t, err, &limitErr)
+	require.Equal(t, LimitDailyAmount, limitErr.Limit)
+	require.Equal(t, limit.DailyAmount, limitErr.Max)
+	require.Equal(t, int64(10), limitErr.Remaining)
+
+	require.NoError(t, transfer(10))
+
+	err = transfer(1)
+	require.ErrorAs(t, err, &limitErr)
+	require.Equal(t, LimitDailyCount, limitErr.Limit)
+	require.Zero(t, limitErr.Remaining)
+}
+
+func TestAuthorizeHoldTxLimits(t *testing.T) {
+	store := NewStore(testDB)
+
+	limit := createRandomTransferLimit(t, util.USD)
+	account := createLimitedAccount(t, limit)
+
+	authorize := func(amount int64) (HoldTxResult, error) {
+		return store.AuthorizeHoldTx(context.Background(), AuthorizeHoldTxParams{
+			AccountID: account.ID,
+			Amount:    amount,
+			User:      account.Owner,
+			ExpiresAt: time.Now().Add(time.Hour),
+		})
+	}
+
+	// A hold over the limits is rejected, without reserving anything
+	var limitErr *LimitExceededError
+	_, err := authorize(limit.MaxPerTransaction + 1)
+	require.ErrorAs(t, err, &limitErr)
+	require.Equal(t, LimitPerTransaction, limitErr.Limit)
+
+	accountAfter, err := testQueries.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Zero(t, accountAfter.HeldAmount)
+
+	hold1, err := authorize(50)
+	require.NoError(t, err)
+	hold2, err := authorize(40)
+	require.NoError(t, err)
+
+	// Captured holds keep counting for what was captured, while voided holds stop counting
+	_, err = store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{
+		HoldID: hold1.Hold.ID,
+		Amount: 30,
+	})
+	require.NoError(t, err)
+	_, err = store.VoidHoldTx(context.Background(), hold2.Hold.ID)
+	require.NoError(t, err)
+
+	usage, err := testQueries.GetDailyOutgoing(context.Background(), GetDailyOutgoingParams{
+		Owner:    account.Owner,
+		Currency: util.USD,
+		Since:    time.Now().UTC().Truncate(24 * time.Hour),
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(30), usage.TotalAmount)
+	require.Equal(t, int32(1), usage.TotalCount)
+
+	// Active holds count for the whole amount held
+	_, err = authorize(50)
+	require.NoError(t, err)
+
+	_, err = store.WithdrawTx(context.Background(), WithdrawTxParams{
+		AccountID: account.ID,
+		Amount:    21,
+		User:      account.Owner,
+	})
+	require.ErrorAs(t, err, &limitErr)
+	require.Equal(t, LimitDailyAmount, limitErr.Limit)
+	require.Equal(t, int64(20), limitErr.Remaining)
+}
+
+func TestTransferTxBetweenOwnAccounts(t *testing.T) {
+	store := NewStore(testDB)
+
+	limit := createRandomTransferLimit(t, util.USD)
+	account1 := createLimitedAccount(t, limit)
+	account2, err := store.CreateAccountTx(context.Background(), CreateAccountTxParams{
+		CreateAccountParams: CreateAccountParams{
+			Owner:    account1.Owner,
+			Currency: util.USD,
+			Type:     AccountTypeSavings,
+			Name:     "Vacation fund",
+		},
+		MaxPerCurrency: 5,
+	})
+	require.NoError(t, err)
+
+	// Moving money between the accounts of the owner goes over the limits of their tier
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        limit.MaxPerTransaction + 1,
+	})
+	require.NoError(t, err)
+
+	// And it doesn't count against them
+	usage, err := testQueries.GetDailyOutgoing(context.Background(), GetDailyOutgoingParams{
+		Owner:    account1.Owner,
+		Currency: util.USD,
+		Since:    time.Now().UTC().Truncate(24 * time.Hour),
+	})
+	require.NoError(t, err)
+	require.Zero(t, usage.TotalAmount)
+	require.Zero(t, usage.TotalCount)
+}
+
+func TestTransferTxLimitsConcurrent(t *testing.T) {
+	store := NewStore(testDB)
+
+	limit := createRandomTransferLimit(t, util.USD)
+	account1 := createLimitedAccount(t, limit)
+	account2 := createRandomAccountWithCurrency(t, util.USD)
+
+	n := 5
+	errs := make(chan error)
+
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := store.TransferTx(context.Background(), TransferTxParams{
+				FromAccountID: account1.ID,
+				ToAccountID:   account2.ID,
+				Amount:        10,
+			})
+			errs <- err
+		}()
+	}
+
+	// Transfers made at the same time can't go over the daily count together
+	succeeded := 0
+	for i := 0; i < n; i++ {
+		err := <-errs
+		if err == nil {
+			succeeded++
+			continue
+		}
+		var limitErr *LimitExceededError
+		require.ErrorAs(t, err, &limitErr)
+	}
+	require.Equal(t, int(limit.DailyCount), succeeded)
+}
+
+func TestTransferTxWithFee(t *testing.T) {
+	store := NewStore(testDB)
+
+	addFeeRule(t, CreateFeeRuleParams{
+		Currency:      util.CAD,
+		TransferType:  TransferTypeSameCurrency,
+		FlatFee:       1,
+		PercentageBps: 100,
+	})
+
+	account1 := fundAccount(t, createRandomAccountWithCurrency(t, util.CAD), 1000)
+	account2 := createRandomAccountWithCurrency(t, util.CAD)
+	houseAccount, err := testQueries.GetHouseAccount(context.Background(), GetHouseAccountParams{
+		Purpose:  HousePurposeFeeRevenue,
+		Currency: util.CAD,
+	})
+	require.NoError(t, err)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        500,
+	})
+	require.NoError(t, err)
+
+	// The fee is paid on top of the amount sent
+	require.Equal(t, int64(6), result.Transfer.Fee)
+	require.Equal(t, int64(500), result.ToEntry.Amount)
+	require.Equal(t, account1.Balance-506, result.FromAccount.Balance)
+	require.Equal(t, account2.Balance+500, result.ToAccount.Balance)
+
+	require.Equal(t, int64(-6), result.FeeEntry.Amount)
+	require.Equal(t, EntrySourceTransferFee, result.FeeEntry.SourceType)
+	require.Equal(t, result.Transfer.ID, result.FeeEntry.SourceID)
+
+	houseAccountAfter, err := testQueries.GetAccount(context.Background(), houseAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, houseAccount.Balance+6, houseAccountAfter.Balance)
+
+	// The fee can't overdraw the account either
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        result.FromAccount.Balance,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+}
+
+func TestPreviewTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	addFeeRule(t, CreateFeeRuleParams{
+		Currency:     util.CAD,
+		TransferType: TransferTypeSameCurrency,
+		FlatFee:      3,
+	})
+
+	account1 := fundAccount(t, createRandomAccountWithCurrency(t, util.CAD), 100)
+	account2 := createRandomAccountWithCurrency(t, util.CAD)
+
+	result, err := store.PreviewTransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(3), result.Transfer.Fee)
+	require.Equal(t, account1.Balance-13, result.FromAccount.Balance)
+	require.Equal(t, account2.Balance+10, result.ToAccount.Balance)
+
+	// Nothing was committed
+	_, err = testQueries.GetTransfer(context.Background(), result.Transfer.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	account1After, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, account1After.Balance)
+
+	// Errors are reported like for a real transfer
+	_, err = store.PreviewTransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        account1.Balance,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+}
+
+func fundAccount(t *testing.T, account Account, amount int64) Account {
+	account, err := testQueries.AddAccountBalance(context.Background(), AddAccountBalanceParams{
+		ID:     account.ID,
+		Amount: amount,
+	})
+	require.NoError(t, err)
+	return account
+}
+
+func TestChangeAccountStatusTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := fundAccount(t, createRandomAccount(t), 10)
+	account2 := fundAccount(t, createRandomAccountWithCurrency(t, account1.Currency), 10)
+	admin := createRandomUser(t)
+
+	result, err := store.ChangeAccountStatusTx(context.Background(), ChangeAccountStatusTxParams{
+		AccountID: account1.ID,
+		Status:    AccountStatusFrozen,
+		ChangedBy: admin.Username,
+		ByStaff:   true,
+		Reason:    "suspected fraud",
+	})
+	require.NoError(t, err)
+	require.Equal(t, AccountStatusFrozen, result.Account.Status)
+	require.Nil(t, result.Payout)
+
+	change := result.Change
+	require.Equal(t, account1.ID, change.AccountID)
+	require.Equal(t, AccountStatusActive, change.FromStatus)
+	require.Equal(t, AccountStatusFrozen, change.ToStatus)
+	require.Equal(t, admin.Username, change.ChangedBy)
+	require.True(t, change.ByStaff)
+	require.Equal(t, "suspected fraud", change.Reason)
+	require.False(t, change.PayoutTransferID.Valid)
+
+	// Money can't move in or out of a frozen account
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        1,
+	})
+	require.ErrorIs(t, err, ErrAccountFrozen)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account2.ID,
+		ToAccountID:   account1.ID,
+		Amount:        1,
+	})
+	require.ErrorIs(t, err, ErrAccountFrozen)
+
+	_, err = store.DepositTx(context.Background(), DepositTxParams{
+		AccountID: account1.ID,
+		Amount:    1,
+		User:      admin.Username,
+	})
+	require.ErrorIs(t, err, ErrAccountFrozen)
+
+	_, err = store.WithdrawTx(context.Background(), WithdrawTxParams{
+		AccountID: account1.ID,
+		Amount:    1,
+		User:      admin.Username,
+	})
+	require.ErrorIs(t, err, ErrAccountFrozen)
+
+	// A frozen account can't be frozen again or closed
+	for _, status := range []string{AccountStatusFrozen, AccountStatusClosed} {
+		_, err = store.ChangeAccountStatusTx(context.Background(), ChangeAccountStatusTxParams{
+			AccountID: account1.ID,
+			Status:    status,
+			ChangedBy: admin.Username,
+			Reason:    "again",
+		})
+		require.ErrorIs(t, err, ErrInvalidStatusChange)
+	}
+
+	result, err = store.ChangeAccountStatusTx(context.Background(), ChangeAccountStatusTxParams{
+		AccountID: account1.ID,
+		Status:    AccountStatusActive,
+		ChangedBy: admin.Username,
+		Reason:    "investigation closed",
+	})
+	require.NoError(t, err)
+	require.Equal(t, AccountStatusActive, result.Account.Status)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        1,
+	})
+	require.NoError(t, err)
+
+	changes, err := store.ListAccountStatusChanges(context.Background(), ListAccountStatusChangesParams{
+		AccountID: account1.ID,
+		Limit:     5,
+	})
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+
+	last, err := store.GetLastAccountStatusChange(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, AccountStatusActive, last.ToStatus)
+}
+
+func TestCloseAccountTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := fundAccount(t, createRandomAccount(t), 10)
+	payoutAccount, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    account.Owner,
+		Currency: account.Currency,
+		Type:     AccountTypeSavings,
+		Name:     "Savings",
+	})
+	require.NoError(t, err)
+
+	otherCurrency := util.USD
+	if account.Currency == util.USD {
+		otherCurrency = util.EUR
+	}
+	otherCurrencyAccount, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    account.Owner,
+		Currency: otherCurrency,
+		Type:     AccountTypeChecking,
+	})
+	require.NoError(t, err)
+
+	// The balance can't be paid out to someone else
+	foreignAccount := createRandomAccountWithCurrency(t, account.Currency)
+
+	arg := ChangeAccountStatusTxParams{
+		AccountID: account.ID,
+		Status:    AccountStatusClosed,
+		ChangedBy: account.Owner,
+		Reason:    "moving abroad",
+	}
+
+	// The balance must go somewhere
+	_, err = store.ChangeAccountStatusTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrAccountNotEmpty)
+
+	for _, id := range []int64{account.ID, otherCurrencyAccount.ID, foreignAccount.ID} {
+		arg.PayoutAccountID = id
+		_, err = store.ChangeAccountStatusTx(context.Background(), arg)
+		require.ErrorIs(t, err, ErrInvalidPayout)
+	}
+
+	arg.PayoutAccountID = payoutAccount.ID
+	result, err := store.ChangeAccountStatusTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, AccountStatusClosed, result.Account.Status)
+	require.Zero(t, result.Account.Balance)
+
+	require.NotNil(t, result.Payout)
+	require.Equal(t, account.Balance, result.Payout.Transfer.Amount)
+	require.Zero(t, result.Payout.Transfer.Fee)
+	require.Equal(t, payoutAccount.Balance+account.Balance, result.Payout.ToAccount.Balance)
+	require.Equal(t, result.Payout.Transfer.ID, result.Change.PayoutTransferID.Int64)
+
+	foreignAccountAfter, err := testQueries.GetAccount(context.Background(), foreignAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, foreignAccount.Balance, foreignAccountAfter.Balance)
+
+	// Nothing can move into a closed account, and it can't be opened again
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: payoutAccount.ID,
+		ToAccountID:   account.ID,
+		Amount:        1,
+	})
+	require.ErrorIs(t, err, ErrAccountClosed)
+
+	_, err = store.ChangeAccountStatusTx(context.Background(), ChangeAccountStatusTxParams{
+		AccountID: account.ID,
+		Status:    AccountStatusActive,
+		ChangedBy: account.Owner,
+		Reason:    "changed my mind",
+	})
+	require.ErrorIs(t, err, ErrInvalidStatusChange)
+}
+
+func TestCloseAccountTxWithHolds(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := fundAccount(t, createRandomAccount(t), 10)
+	_, err := store.AuthorizeHoldTx(context.Background(), AuthorizeHoldTxParams{
+		AccountID: account.ID,
+		Amount:    10,
+		User:      account.Owner,
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	_, err = store.ChangeAccountStatusTx(context.Background(), ChangeAccountStatusTxParams{
+		AccountID: account.ID,
+		Status:    AccountStatusClosed,
+		ChangedBy: account.Owner,
+		Reason:    "moving abroad",
+	})
+	require.ErrorIs(t, err, ErrAccountHasHolds)
+}
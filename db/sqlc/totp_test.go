@@ -0,0 +1,184 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"simplebank/util"
+
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomTotpSecret(t *testing.T, username string) TotpSecret {
+	arg := UpsertTotpSecretParams{
+		Username:        username,
+		EncryptedSecret: []byte(util.RandomString(48)),
+	}
+
+	secret, err := testQueries.UpsertTotpSecret(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Username, secret.Username)
+	require.Equal(t, arg.EncryptedSecret, secret.EncryptedSecret)
+	require.Zero(t, secret.LastUsedStep)
+	require.False(t, secret.EnabledAt.Valid)
+
+	return secret
+}
+
+func TestUpsertTotpSecret(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	createRandomTotpSecret(t, user.Username)
+
+	// An enrolment which wasn't verified can start over with another secret
+	secret := createRandomTotpSecret(t, user.Username)
+
+	_, err := store.EnableTwoFactorTx(context.Background(), RecoveryCodesTxParams{Username: user.Username})
+	require.NoError(t, err)
+
+	// Once enabled, the secret can't be replaced without disabling two-factor authentication first
+	_, err = testQueries.UpsertTotpSecret(context.Background(), UpsertTotpSecretParams{
+		Username:        user.Username,
+		EncryptedSecret: []byte(util.RandomString(48)),
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	got, err := testQueries.GetTotpSecret(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Equal(t, secret.EncryptedSecret, got.EncryptedSecret)
+	require.True(t, got.EnabledAt.Valid)
+}
+
+func TestUseTotpStep(t *testing.T) {
+	user := createRandomUser(t)
+	createRandomTotpSecret(t, user.Username)
+
+	arg := UseTotpStepParams{
+		Username: user.Username,
+		Step:     util.RandomInt(1000, 2000),
+	}
+	rows, err := testQueries.UseTotpStep(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), rows)
+
+	// The same code can't be used twice, nor the one of an earlier step
+	rows, err = testQueries.UseTotpStep(context.Background(), arg)
+	require.NoError(t, err)
+	require.Zero(t, rows)
+
+	arg.Step--
+	rows, err = testQueries.UseTotpStep(context.Background(), arg)
+	require.NoError(t, err)
+	require.Zero(t, rows)
+}
+
+func TestRecordTotpFailure(t *testing.T) {
+	user := createRandomUser(t)
+	createRandomTotpSecret(t, user.Username)
+
+	arg := RecordTotpFailureParams{
+		Username:    user.Username,
+		WindowStart: time.Now().Add(-time.Minute),
+	}
+	for i := 1; i <= 3; i++ {
+		secret, err := testQueries.RecordTotpFailure(context.Background(), arg)
+		require.NoError(t, err)
+		require.Equal(t, int32(i), secret.FailedAttempts)
+		require.True(t, secret.LastFailedAt.Valid)
+	}
+
+	// Failures before the window started are forgotten
+	arg.WindowStart = time.Now().Add(time.Minute)
+	secret, err := testQueries.RecordTotpFailure(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), secret.FailedAttempts)
+
+	// An accepted code starts the count over
+	_, err = testQueries.UseTotpStep(context.Background(), UseTotpStepParams{
+		Username: user.Username,
+		Step:     1,
+	})
+	require.NoError(t, err)
+
+	secret, err = testQueries.GetTotpSecret(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Zero(t, secret.FailedAttempts)
+}
+
+func TestRecoveryCodesTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	createRandomTotpSecret(t, user.Username)
+
+	codes := []string{util.RandomString(64), util.RandomString(64)}
+	secret, err := store.EnableTwoFactorTx(context.Background(), RecoveryCodesTxParams{
+		Username:    user.Username,
+		HashedCodes: codes,
+	})
+	require.NoError(t, err)
+	require.True(t, secret.EnabledAt.Valid)
+
+	count, err := testQueries.CountRecoveryCodes(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), count)
+
+	// Each code can only be used once
+	arg := UseRecoveryCodeParams{
+		Username:   user.Username,
+		HashedCode: codes[0],
+	}
+	rows, err := testQueries.UseRecoveryCode(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), rows)
+
+	rows, err = testQueries.UseRecoveryCode(context.Background(), arg)
+	require.NoError(t, err)
+	require.Zero(t, rows)
+
+	count, err = testQueries.CountRecoveryCodes(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), count)
+
+	// New codes replace every previous one
+	newCodes := []string{util.RandomString(64)}
+	err = store.ReplaceRecoveryCodesTx(context.Background(), RecoveryCodesTxParams{
+		Username:    user.Username,
+		HashedCodes: newCodes,
+	})
+	require.NoError(t, err)
+
+	rows, err = testQueries.UseRecoveryCode(context.Background(), UseRecoveryCodeParams{
+		Username:   user.Username,
+		HashedCode: codes[1],
+	})
+	require.NoError(t, err)
+	require.Zero(t, rows)
+
+	count, err = testQueries.CountRecoveryCodes(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), count)
+}
+
+func TestDisableTwoFactorTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	createRandomTotpSecret(t, user.Username)
+
+	_, err := store.EnableTwoFactorTx(context.Background(), RecoveryCodesTxParams{
+		Username:    user.Username,
+		HashedCodes: []string{util.RandomString(64)},
+	})
+	require.NoError(t, err)
+
+	err = store.DisableTwoFactorTx(context.Background(), user.Username)
+	require.NoError(t, err)
+
+	_, err = testQueries.GetTotpSecret(context.Background(), user.Username)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	count, err := testQueries.CountRecoveryCodes(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Zero(t, count)
+}
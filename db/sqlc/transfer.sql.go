@@ -7,26 +7,89 @@ package db
 
 import (
 	"context"
+	"database/sql"
 )
 
+const addTransferReversedAmount = `-- name: AddTransferReversedAmount :one
+UPDATE transfers
+SET reversed_amount = reversed_amount + $1
+WHERE id = $2
+RETURNING id, from_account_id, to_account_id, amount, created_at, from_currency, to_currency, to_amount, rate, spread_bps, quote_id, reversed_amount, reversal_of_id, fee
+`
+
+type AddTransferReversedAmountParams struct {
+	Amount int64 `json:"amount"`
+	ID     int64 `json:"id"`
+}
+
+func (q *Queries) AddTransferReversedAmount(ctx context.Context, arg AddTransferReversedAmountParams) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, addTransferReversedAmount, arg.Amount, arg.ID)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.FromCurrency,
+		&i.ToCurrency,
+		&i.ToAmount,
+		&i.Rate,
+		&i.SpreadBps,
+		&i.QuoteID,
+		&i.ReversedAmount,
+		&i.ReversalOfID,
+		&i.Fee,
+	)
+	return i, err
+}
+
 const createTransfer = `-- name: CreateTransfer :one
 INSERT INTO transfers (
   from_account_id,
   to_account_id,
-  amount
+  amount,
+  from_currency,
+  to_currency,
+  to_amount,
+  rate,
+  spread_bps,
+  quote_id,
+  reversal_of_id,
+  fee
 ) VALUES (
-  $1, $2, $3
-) RETURNING id, from_account_id, to_account_id, amount, created_at
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+) RETURNING id, from_account_id, to_account_id, amount, created_at, from_currency, to_currency, to_amount, rate, spread_bps, quote_id, reversed_amount, reversal_of_id, fee
 `
 
 type CreateTransferParams struct {
-	FromAccountID int64 `json:"from_account_id"`
-	ToAccountID   int64 `json:"to_account_id"`
-	Amount        int64 `json:"amount"`
+	FromAccountID int64         `json:"from_account_id"`
+	ToAccountID   int64         `json:"to_account_id"`
+	Amount        int64         `json:"amount"`
+	FromCurrency  string        `json:"from_currency"`
+	ToCurrency    string        `json:"to_currency"`
+	ToAmount      int64         `json:"to_amount"`
+	Rate          int64         `json:"rate"`
+	SpreadBps     int32         `json:"spread_bps"`
+	QuoteID       sql.NullInt64 `json:"quote_id"`
+	ReversalOfID  sql.NullInt64 `json:"reversal_of_id"`
+	Fee           int64         `json:"fee"`
 }
 
 func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error) {
-	row := q.db.QueryRowContext(ctx, createTransfer, arg.FromAccountID, arg.ToAccountID, arg.Amount)
+	row := q.db.QueryRowContext(ctx, createTransfer,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.FromCurrency,
+		arg.ToCurrency,
+		arg.ToAmount,
+		arg.Rate,
+		arg.SpreadBps,
+		arg.QuoteID,
+		arg.ReversalOfID,
+		arg.Fee,
+	)
 	var i Transfer
 	err := row.Scan(
 		&i.ID,
@@ -34,12 +97,21 @@ func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams)
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.FromCurrency,
+		&i.ToCurrency,
+		&i.ToAmount,
+		&i.Rate,
+		&i.SpreadBps,
+		&i.QuoteID,
+		&i.ReversedAmount,
+		&i.ReversalOfID,
+		&i.Fee,
 	)
 	return i, err
 }
 
 const getTransfer = `-- name: GetTransfer :one
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
+SELECT id, from_account_id, to_account_id, amount, created_at, from_currency, to_currency, to_amount, rate, spread_bps, quote_id, reversed_amount, reversal_of_id, fee FROM transfers
 WHERE id = $1 LIMIT 1
 `
 
@@ -52,33 +124,84 @@ func (q *Queries) GetTransfer(ctx context.Context, id int64) (Transfer, error) {
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.FromCurrency,
+		&i.ToCurrency,
+		&i.ToAmount,
+		&i.Rate,
+		&i.SpreadBps,
+		&i.QuoteID,
+		&i.ReversedAmount,
+		&i.ReversalOfID,
+		&i.Fee,
+	)
+	return i, err
+}
+
+const getTransferForUpdate = `-- name: GetTransferForUpdate :one
+SELECT id, from_account_id, to_account_id, amount, created_at, from_currency, to_currency, to_amount, rate, spread_bps, quote_id, reversed_amount, reversal_of_id, fee FROM transfers
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, getTransferForUpdate, id)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.FromCurrency,
+		&i.ToCurrency,
+		&i.ToAmount,
+		&i.Rate,
+		&i.SpreadBps,
+		&i.QuoteID,
+		&i.ReversedAmount,
+		&i.ReversalOfID,
+		&i.Fee,
 	)
 	return i, err
 }
 
 const listTransfers = `-- name: ListTransfers :many
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
-WHERE 
-    from_account_id = $1 OR
-    to_account_id = $2
+SELECT id, from_account_id, to_account_id, amount, created_at, from_currency, to_currency, to_amount, rate, spread_bps, quote_id, reversed_amount, reversal_of_id, fee FROM transfers
+WHERE
+    (
+        ($1::text IN ('out', 'all') AND from_account_id = $2) OR
+        ($1::text IN ('in', 'all') AND to_account_id = $2)
+    )
+    AND ($3::timestamptz IS NULL OR created_at >= $3)
+    AND ($4::timestamptz IS NULL OR created_at < $4)
+    AND ($5::bigint IS NULL OR amount >= $5)
+    AND ($6::bigint IS NULL OR amount <= $6)
 ORDER BY id
-LIMIT $3
-OFFSET $4
+LIMIT $8
+OFFSET $7
 `
 
 type ListTransfersParams struct {
-	FromAccountID int64 `json:"from_account_id"`
-	ToAccountID   int64 `json:"to_account_id"`
-	Limit         int32 `json:"limit"`
-	Offset        int32 `json:"offset"`
+	Direction string        `json:"direction"`
+	AccountID int64         `json:"account_id"`
+	FromDate  sql.NullTime  `json:"from_date"`
+	ToDate    sql.NullTime  `json:"to_date"`
+	MinAmount sql.NullInt64 `json:"min_amount"`
+	MaxAmount sql.NullInt64 `json:"max_amount"`
+	Offset    int32         `json:"offset"`
+	Limit     int32         `json:"limit"`
 }
 
 func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error) {
 	rows, err := q.db.QueryContext(ctx, listTransfers,
-		arg.FromAccountID,
-		arg.ToAccountID,
-		arg.Limit,
+		arg.Direction,
+		arg.AccountID,
+		arg.FromDate,
+		arg.ToDate,
+		arg.MinAmount,
+		arg.MaxAmount,
 		arg.Offset,
+		arg.Limit,
 	)
 	if err != nil {
 		return nil, err
@@ -93,6 +216,15 @@ func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([
 			&i.ToAccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.FromCurrency,
+			&i.ToCurrency,
+			&i.ToAmount,
+			&i.Rate,
+			&i.SpreadBps,
+			&i.QuoteID,
+			&i.ReversedAmount,
+			&i.ReversalOfID,
+			&i.Fee,
 		); err != nil {
 			return nil, err
 		}
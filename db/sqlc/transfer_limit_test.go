@@ -0,0 +1,96 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"simplebank/util"
+
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomTransferLimit(t *testing.T, currency string) TransferLimit {
+	arg := CreateTransferLimitParams{
+		Tier:              util.RandomString(8),
+		Currency:          currency,
+		MaxPerTransaction: 50,
+		DailyAmount:       100,
+		DailyCount:        3,
+	}
+
+	limit, err := testQueries.CreateTransferLimit(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Tier, limit.Tier)
+	require.Equal(t, arg.Currency, limit.Currency)
+	require.Equal(t, arg.MaxPerTransaction, limit.MaxPerTransaction)
+	require.Equal(t, arg.DailyAmount, limit.DailyAmount)
+	require.Equal(t, arg.DailyCount, limit.DailyCount)
+	require.NotZero(t, limit.CreatedAt)
+
+	return limit
+}
+
+// createLimitedAccount creates an account whose owner is in a tier with the given limits
+func createLimitedAccount(t *testing.T, limit TransferLimit) Account {
+	account := fundAccount(t, createRandomAccountWithCurrency(t, limit.Currency), 1000)
+
+	user, err := testQueries.UpdateUserTier(context.Background(), UpdateUserTierParams{
+		Username: account.Owner,
+		Tier:     limit.Tier,
+	})
+	require.NoError(t, err)
+	require.Equal(t, limit.Tier, user.Tier)
+
+	return account
+}
+
+func TestGetTransferLimit(t *testing.T) {
+	limit1 := createRandomTransferLimit(t, util.USD)
+
+	limit2, err := testQueries.GetTransferLimit(context.Background(), GetTransferLimitParams{
+		Tier:     limit1.Tier,
+		Currency: limit1.Currency,
+	})
+	require.NoError(t, err)
+	require.Equal(t, limit1, limit2)
+
+	_, err = testQueries.GetTransferLimit(context.Background(), GetTransferLimitParams{
+		Tier:     limit1.Tier,
+		Currency: util.EUR,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestGetDailyOutgoing(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccountWithCurrency(t, account1.Currency)
+	transfer := createRandomTransfer(t, account1, account2)
+
+	withdraw, err := testQueries.CreateWithdraw(context.Background(), CreateWithdrawParams{
+		AccountID: account1.ID,
+		Amount:    util.RandomMoney(),
+		User:      account1.Owner,
+	})
+	require.NoError(t, err)
+
+	usage, err := testQueries.GetDailyOutgoing(context.Background(), GetDailyOutgoingParams{
+		Owner:    account1.Owner,
+		Currency: account1.Currency,
+		Since:    time.Now().Add(-time.Minute),
+	})
+	require.NoError(t, err)
+	require.Equal(t, transfer.Amount+withdraw.Amount, usage.TotalAmount)
+	require.Equal(t, int32(2), usage.TotalCount)
+
+	// Money received doesn't count
+	usage, err = testQueries.GetDailyOutgoing(context.Background(), GetDailyOutgoingParams{
+		Owner:    account2.Owner,
+		Currency: account2.Currency,
+		Since:    time.Now().Add(-time.Minute),
+	})
+	require.NoError(t, err)
+	require.Zero(t, usage.TotalAmount)
+	require.Zero(t, usage.TotalCount)
+}
@@ -2,19 +2,26 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"testing"
 	"time"
 
+	"simplebank/fx"
 	"simplebank/util"
 
 	"github.com/stretchr/testify/require"
 )
 
 func createRandomTransfer(t *testing.T, account1, account2 Account) Transfer {
+	amount := util.RandomMoney()
 	arg := CreateTransferParams{
 		FromAccountID: account1.ID,
 		ToAccountID:   account2.ID,
-		Amount:        util.RandomMoney(),
+		Amount:        amount,
+		FromCurrency:  account1.Currency,
+		ToCurrency:    account2.Currency,
+		ToAmount:      amount,
+		Rate:          fx.RateScale,
 	}
 
 	transfer, err := testQueries.CreateTransfer(context.Background(), arg)
@@ -24,6 +31,11 @@ func createRandomTransfer(t *testing.T, account1, account2 Account) Transfer {
 	require.Equal(t, arg.FromAccountID, transfer.FromAccountID)
 	require.Equal(t, arg.ToAccountID, transfer.ToAccountID)
 	require.Equal(t, arg.Amount, transfer.Amount)
+	require.Equal(t, arg.FromCurrency, transfer.FromCurrency)
+	require.Equal(t, arg.ToCurrency, transfer.ToCurrency)
+	require.Equal(t, arg.ToAmount, transfer.ToAmount)
+	require.Equal(t, arg.Rate, transfer.Rate)
+	require.False(t, transfer.QuoteID.Valid)
 
 	require.NotZero(t, transfer.ID)
 	require.NotZero(t, transfer.CreatedAt)
@@ -63,10 +75,10 @@ func TestListTransfer(t *testing.T) {
 	}
 
 	arg := ListTransfersParams{
-		FromAccountID: account1.ID,
-		ToAccountID:   account1.ID,
-		Limit:         5,
-		Offset:        5,
+		AccountID: account1.ID,
+		Direction: "all",
+		Limit:     5,
+		Offset:    5,
 	}
 
 	transfers, err := testQueries.ListTransfers(context.Background(), arg)
@@ -78,3 +90,68 @@ func TestListTransfer(t *testing.T) {
 		require.True(t, transfer.FromAccountID == account1.ID || transfer.ToAccountID == account1.ID)
 	}
 }
+
+func TestListTransferDirection(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	for i := 0; i < 5; i++ {
+		createRandomTransfer(t, account1, account2)
+		createRandomTransfer(t, account2, account1)
+	}
+
+	arg := ListTransfersParams{
+		AccountID: account1.ID,
+		Direction: "out",
+		Limit:     10,
+		Offset:    0,
+	}
+
+	transfers, err := testQueries.ListTransfers(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, transfers, 5)
+	for _, transfer := range transfers {
+		require.Equal(t, account1.ID, transfer.FromAccountID)
+	}
+
+	arg.Direction = "in"
+	transfers, err = testQueries.ListTransfers(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, transfers, 5)
+	for _, transfer := range transfers {
+		require.Equal(t, account1.ID, transfer.ToAccountID)
+	}
+}
+
+func TestListTransferFilters(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	for i := 0; i < 5; i++ {
+		createRandomTransfer(t, account1, account2)
+	}
+
+	arg := ListTransfersParams{
+		AccountID: account1.ID,
+		Direction: "all",
+		FromDate:  sql.NullTime{Time: time.Now().Add(-time.Hour), Valid: true},
+		ToDate:    sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true},
+		MinAmount: sql.NullInt64{Int64: 100, Valid: true},
+		MaxAmount: sql.NullInt64{Int64: 500, Valid: true},
+		Limit:     10,
+		Offset:    0,
+	}
+
+	transfers, err := testQueries.ListTransfers(context.Background(), arg)
+	require.NoError(t, err)
+	for _, transfer := range transfers {
+		require.True(t, transfer.Amount >= 100 && transfer.Amount <= 500)
+	}
+
+	// Nothing was transferred in the future
+	arg.FromDate = sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true}
+	arg.ToDate = sql.NullTime{Time: time.Now().Add(2 * time.Hour), Valid: true}
+	transfers, err = testQueries.ListTransfers(context.Background(), arg)
+	require.NoError(t, err)
+	require.Empty(t, transfers)
+}
@@ -29,6 +29,8 @@ func createRandomUser(t *testing.T) User {
 	require.Equal(t, arg.HashedPassword, user.HashedPassword)
 	require.Equal(t, arg.FullName, user.FullName)
 	require.Equal(t, arg.Email, user.Email)
+	require.Equal(t, TierStandard, user.Tier)
+	require.Equal(t, UserRoleCustomer, user.Role)
 
 	require.True(t, user.PasswordChangedAt.IsZero())
 	require.NotZero(t, user.CreatedAt)
@@ -53,3 +55,34 @@ func TestGetUser(t *testing.T) {
 	require.WithinDuration(t, user1.PasswordChangedAt, user2.PasswordChangedAt, time.Second)
 	require.WithinDuration(t, user1.CreatedAt, user2.CreatedAt, time.Second)
 }
+
+func TestUpdateUserRole(t *testing.T) {
+	user1 := createRandomUser(t)
+
+	user2, err := testQueries.UpdateUserRole(context.Background(), UpdateUserRoleParams{
+		Username: user1.Username,
+		Role:     UserRoleBanker,
+	})
+	require.NoError(t, err)
+	require.Equal(t, user1.Username, user2.Username)
+	require.Equal(t, UserRoleBanker, user2.Role)
+}
+
+func TestUpdateUserRoleTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	user1 := createRandomUser(t)
+	session := createRandomSession(t, user1.Username)
+
+	user2, err := store.UpdateUserRoleTx(context.Background(), UpdateUserRoleParams{
+		Username: user1.Username,
+		Role:     UserRoleAdmin,
+	})
+	require.NoError(t, err)
+	require.Equal(t, UserRoleAdmin, user2.Role)
+
+	// Tokens carrying the old role can't be refreshed anymore
+	session, err = testQueries.GetSession(context.Background(), session.ID)
+	require.NoError(t, err)
+	require.True(t, session.IsBlocked)
+}
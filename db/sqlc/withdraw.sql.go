@@ -38,13 +38,13 @@ func (q *Queries) CreateWithdraw(ctx context.Context, arg CreateWithdrawParams)
 	return i, err
 }
 
-const getwithdraw = `-- name: Getwithdraw :one
+const getWithdraw = `-- name: GetWithdraw :one
 SELECT id, account_id, amount, "user", created_at FROM withdraws
 WHERE id = $1 LIMIT 1
 `
 
-func (q *Queries) Getwithdraw(ctx context.Context, id int64) (Withdraw, error) {
-	row := q.db.QueryRowContext(ctx, getwithdraw, id)
+func (q *Queries) GetWithdraw(ctx context.Context, id int64) (Withdraw, error) {
+	row := q.db.QueryRowContext(ctx, getWithdraw, id)
 	var i Withdraw
 	err := row.Scan(
 		&i.ID,
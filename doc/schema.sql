@@ -8,7 +8,9 @@ CREATE TABLE "users" (
   "full_name" varchar NOT NULL,
   "email" varchar UNIQUE NOT NULL,
   "password_changed_at" timestamptz NOT NULL DEFAULT '0001-01-01 00:00:00Z',
-  "created_at" timestamptz NOT NULL DEFAULT 'now()'
+  "created_at" timestamptz NOT NULL DEFAULT 'now()',
+  "tier" varchar NOT NULL DEFAULT 'standard',
+  "role" varchar NOT NULL DEFAULT 'customer'
 );
 
 CREATE TABLE "accounts" (
@@ -16,13 +18,23 @@ CREATE TABLE "accounts" (
   "owner" varchar NOT NULL,
   "balance" bigint NOT NULL,
   "currency" varchar NOT NULL,
-  "created_at" timestamptz NOT NULL DEFAULT 'now()'
+  "overdraft_limit" bigint NOT NULL DEFAULT 0,
+  "created_at" timestamptz NOT NULL DEFAULT 'now()',
+  "held_amount" bigint NOT NULL DEFAULT 0,
+  "available_balance" bigint NOT NULL,
+  "type" varchar NOT NULL DEFAULT 'checking',
+  "status" varchar NOT NULL DEFAULT 'active',
+  "name" varchar NOT NULL DEFAULT '',
+  "description" varchar NOT NULL DEFAULT ''
 );
 
 CREATE TABLE "entries" (
   "id" bigserial PRIMARY KEY,
   "account_id" bigserial NOT NULL,
   "amount" bigint NOT NULL,
+  "source_type" varchar NOT NULL,
+  "source_id" bigint NOT NULL,
+  "journal_id" bigint NOT NULL,
   "created_at" timestamptz NOT NULL DEFAULT 'now()'
 );
 
@@ -31,7 +43,16 @@ CREATE TABLE "transfers" (
   "from_account_id" bigserial NOT NULL,
   "to_account_id" bigserial NOT NULL,
   "amount" bigint NOT NULL,
-  "created_at" timestamptz NOT NULL DEFAULT 'now()'
+  "created_at" timestamptz NOT NULL DEFAULT 'now()',
+  "from_currency" varchar NOT NULL,
+  "to_currency" varchar NOT NULL,
+  "to_amount" bigint NOT NULL,
+  "rate" bigint NOT NULL,
+  "spread_bps" int NOT NULL,
+  "quote_id" bigint UNIQUE,
+  "reversed_amount" bigint NOT NULL DEFAULT 0,
+  "reversal_of_id" bigint,
+  "fee" bigint NOT NULL DEFAULT 0
 );
 
 CREATE TABLE "deposits" (
@@ -50,18 +71,221 @@ CREATE TABLE "withdraws" (
   "created_at" timestamptz NOT NULL DEFAULT 'now()'
 );
 
+CREATE TABLE "idempotency_keys" (
+  "username" varchar NOT NULL,
+  "key" varchar NOT NULL,
+  "request_hash" varchar NOT NULL,
+  "response_status" int NOT NULL DEFAULT 0,
+  "response_body" bytea NOT NULL DEFAULT '',
+  "expires_at" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT 'now()',
+  PRIMARY KEY ("username", "key")
+);
+
+CREATE TABLE "fx_quotes" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "from_currency" varchar NOT NULL,
+  "to_currency" varchar NOT NULL,
+  "from_amount" bigint NOT NULL,
+  "to_amount" bigint NOT NULL,
+  "market_rate" bigint NOT NULL,
+  "spread_bps" int NOT NULL,
+  "rate" bigint NOT NULL,
+  "expires_at" timestamptz NOT NULL,
+  "used_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT 'now()'
+);
+
+CREATE TABLE "scheduled_transfers" (
+  "id" bigserial PRIMARY KEY,
+  "owner" varchar NOT NULL,
+  "from_account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "frequency" varchar NOT NULL,
+  "start_at" timestamptz NOT NULL,
+  "next_run_at" timestamptz NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'active',
+  "failure_count" int NOT NULL DEFAULT 0,
+  "locked_until" timestamptz,
+  "last_run_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT 'now()'
+);
+
+CREATE TABLE "scheduled_transfer_runs" (
+  "id" bigserial PRIMARY KEY,
+  "scheduled_transfer_id" bigint NOT NULL,
+  "scheduled_for" timestamptz NOT NULL,
+  "status" varchar NOT NULL,
+  "transfer_id" bigint,
+  "error" varchar NOT NULL DEFAULT '',
+  "created_at" timestamptz NOT NULL DEFAULT 'now()'
+);
+
+CREATE TABLE "holds" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "captured_amount" bigint NOT NULL DEFAULT 0,
+  "status" varchar NOT NULL DEFAULT 'active',
+  "user" varchar NOT NULL,
+  "expires_at" timestamptz NOT NULL,
+  "finished_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT 'now()'
+);
+
+CREATE TABLE "transfer_limits" (
+  "tier" varchar NOT NULL,
+  "currency" varchar NOT NULL,
+  "max_per_transaction" bigint NOT NULL,
+  "daily_amount" bigint NOT NULL,
+  "daily_count" int NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT 'now()',
+  PRIMARY KEY ("tier", "currency")
+);
+
+CREATE TABLE "fee_rules" (
+  "id" bigserial PRIMARY KEY,
+  "currency" varchar NOT NULL,
+  "transfer_type" varchar NOT NULL,
+  "min_amount" bigint NOT NULL DEFAULT 0,
+  "flat_fee" bigint NOT NULL DEFAULT 0,
+  "percentage_bps" int NOT NULL DEFAULT 0,
+  "min_fee" bigint NOT NULL DEFAULT 0,
+  "max_fee" bigint NOT NULL DEFAULT 0,
+  "created_at" timestamptz NOT NULL DEFAULT 'now()'
+);
+
+CREATE TABLE "house_accounts" (
+  "currency" varchar NOT NULL,
+  "account_id" bigint UNIQUE NOT NULL,
+  "purpose" varchar NOT NULL DEFAULT 'fee_revenue',
+  PRIMARY KEY ("purpose", "currency")
+);
+
+CREATE TABLE "interest_rates" (
+  "account_type" varchar NOT NULL,
+  "currency" varchar NOT NULL,
+  "annual_rate_bps" int NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT 'now()',
+  PRIMARY KEY ("account_type", "currency")
+);
+
+CREATE TABLE "interest_accruals" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "accrual_date" date NOT NULL,
+  "balance" bigint NOT NULL,
+  "annual_rate_bps" int NOT NULL,
+  "amount" bigint NOT NULL,
+  "posting_id" bigint,
+  "created_at" timestamptz NOT NULL DEFAULT 'now()'
+);
+
+CREATE TABLE "interest_postings" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "period" date NOT NULL,
+  "amount" bigint NOT NULL,
+  "remainder" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT 'now()'
+);
+
+CREATE TABLE "statements" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "period" date NOT NULL,
+  "pdf" bytea NOT NULL,
+  "csv" bytea NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT 'now()'
+);
+
+CREATE TABLE "balance_snapshots" (
+  "account_id" bigint NOT NULL,
+  "snapshot_date" date NOT NULL,
+  "balance" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT 'now()',
+  PRIMARY KEY ("account_id", "snapshot_date")
+);
+
+CREATE TABLE "journals" (
+  "id" bigserial PRIMARY KEY,
+  "kind" varchar NOT NULL,
+  "source_id" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT 'now()'
+);
+
+CREATE TABLE "account_status_changes" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "from_status" varchar NOT NULL,
+  "to_status" varchar NOT NULL,
+  "changed_by" varchar NOT NULL,
+  "reason" varchar NOT NULL,
+  "payout_transfer_id" bigint,
+  "created_at" timestamptz NOT NULL DEFAULT 'now()',
+  "by_staff" boolean NOT NULL DEFAULT false
+);
+
+CREATE TABLE "account_members" (
+  "account_id" bigint NOT NULL,
+  "username" varchar NOT NULL,
+  "role" varchar NOT NULL,
+  "spend_limit" bigint NOT NULL DEFAULT 0,
+  "invited_by" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("account_id", "username")
+);
+
+CREATE TABLE "security_events" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "kind" varchar NOT NULL,
+  "session_family_id" uuid NOT NULL,
+  "user_agent" varchar NOT NULL,
+  "client_ip" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "totp_secrets" (
+  "username" varchar PRIMARY KEY,
+  "encrypted_secret" bytea NOT NULL,
+  "last_used_step" bigint NOT NULL DEFAULT 0,
+  "failed_attempts" integer NOT NULL DEFAULT 0,
+  "last_failed_at" timestamptz,
+  "enabled_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "recovery_codes" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "hashed_code" varchar NOT NULL,
+  "used_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
 CREATE INDEX ON "accounts" ("owner");
 
-CREATE UNIQUE INDEX ON "accounts" ("owner", "currency");
+CREATE INDEX ON "accounts" ("owner", "currency");
 
 CREATE INDEX ON "entries" ("account_id");
 
+CREATE INDEX ON "entries" ("source_type", "source_id");
+
+CREATE INDEX ON "entries" ("account_id", "created_at");
+
+CREATE INDEX ON "entries" ("journal_id");
+
 CREATE INDEX ON "transfers" ("from_account_id");
 
 CREATE INDEX ON "transfers" ("to_account_id");
 
 CREATE INDEX ON "transfers" ("from_account_id", "to_account_id");
 
+CREATE INDEX ON "transfers" ("reversal_of_id");
+
 CREATE INDEX ON "deposits" ("account_id");
 
 CREATE INDEX ON "deposits" ("user");
@@ -70,14 +294,178 @@ CREATE INDEX ON "withdraws" ("account_id");
 
 CREATE INDEX ON "withdraws" ("user");
 
+CREATE INDEX ON "idempotency_keys" ("expires_at");
+
+CREATE INDEX ON "fx_quotes" ("username");
+
+CREATE INDEX ON "scheduled_transfers" ("owner");
+
+CREATE INDEX ON "scheduled_transfers" ("status", "next_run_at");
+
+CREATE INDEX ON "scheduled_transfer_runs" ("scheduled_transfer_id");
+
+CREATE INDEX ON "scheduled_transfer_runs" ("scheduled_transfer_id", "scheduled_for");
+
+CREATE INDEX ON "holds" ("account_id");
+
+CREATE INDEX ON "holds" ("user");
+
+CREATE INDEX ON "holds" ("expires_at");
+
+CREATE UNIQUE INDEX ON "fee_rules" ("currency", "transfer_type", "min_amount");
+
+CREATE UNIQUE INDEX ON "interest_accruals" ("account_id", "accrual_date");
+
+CREATE INDEX ON "interest_accruals" ("posting_id");
+
+CREATE UNIQUE INDEX ON "interest_postings" ("account_id", "period");
+
+CREATE UNIQUE INDEX ON "statements" ("account_id", "period");
+
+CREATE INDEX ON "journals" ("kind", "source_id");
+
+CREATE INDEX ON "account_status_changes" ("account_id");
+
+CREATE INDEX ON "account_members" ("username");
+
+CREATE INDEX ON "security_events" ("username");
+
+CREATE UNIQUE INDEX ON "recovery_codes" ("username", "hashed_code");
+
+COMMENT ON COLUMN "accounts"."balance" IS 'balance - held_amount must not go below -overdraft_limit';
+
+COMMENT ON COLUMN "accounts"."type" IS 'checking or savings, and house for the accounts of the bank';
+
+COMMENT ON COLUMN "accounts"."status" IS 'active, frozen or closed, only active accounts can move money';
+
+COMMENT ON COLUMN "accounts"."overdraft_limit" IS 'how far below zero the balance is allowed to go';
+
+COMMENT ON COLUMN "accounts"."held_amount" IS 'sum of the active holds on the account';
+
+COMMENT ON COLUMN "accounts"."available_balance" IS 'generated as balance - held_amount';
+
+COMMENT ON COLUMN "accounts"."name" IS 'nickname given by the owner, such as vacation fund';
+
 COMMENT ON COLUMN "entries"."amount" IS 'can be negative or positive';
 
-COMMENT ON COLUMN "transfers"."amount" IS 'must be positive';
+COMMENT ON COLUMN "entries"."source_type" IS 'kind of transaction which created the entry';
+
+COMMENT ON COLUMN "entries"."source_id" IS 'id of the transaction which created the entry';
+
+COMMENT ON COLUMN "entries"."journal_id" IS 'posting the entry is a line of, whose entries add up to zero in every currency';
+
+COMMENT ON COLUMN "transfers"."amount" IS 'must be positive, in the currency of the origin account';
+
+COMMENT ON COLUMN "transfers"."to_amount" IS 'amount credited, in the currency of the destination account';
+
+COMMENT ON COLUMN "transfers"."rate" IS 'rate applied to the transfer, scaled by 10^8';
+
+COMMENT ON COLUMN "transfers"."reversed_amount" IS 'how much of to_amount was refunded, up to all of it';
+
+COMMENT ON COLUMN "transfers"."reversal_of_id" IS 'the transfer which is refunded by this one';
 
 COMMENT ON COLUMN "deposits"."amount" IS 'must be positive';
 
 COMMENT ON COLUMN "withdraws"."amount" IS 'must be positive';
 
+COMMENT ON COLUMN "idempotency_keys"."request_hash" IS 'hash of the method, path and body of the original request';
+
+COMMENT ON COLUMN "idempotency_keys"."response_status" IS 'zero while the original request is still being processed';
+
+COMMENT ON COLUMN "fx_quotes"."market_rate" IS 'rate from the provider, scaled by 10^8';
+
+COMMENT ON COLUMN "fx_quotes"."spread_bps" IS 'spread taken out of the market rate, in basis points';
+
+COMMENT ON COLUMN "fx_quotes"."rate" IS 'rate applied to the transfer, scaled by 10^8';
+
+COMMENT ON COLUMN "fx_quotes"."used_at" IS 'a quote can be used by a single transfer';
+
+COMMENT ON COLUMN "scheduled_transfers"."frequency" IS 'once, daily, weekly or monthly';
+
+COMMENT ON COLUMN "scheduled_transfers"."status" IS 'active, paused, completed or cancelled';
+
+COMMENT ON COLUMN "scheduled_transfers"."failure_count" IS 'consecutive failed runs, the order is paused after too many of them';
+
+COMMENT ON COLUMN "scheduled_transfers"."locked_until" IS 'set while a worker is executing the order';
+
+COMMENT ON COLUMN "scheduled_transfer_runs"."status" IS 'pending, succeeded or failed';
+
+COMMENT ON COLUMN "holds"."amount" IS 'must be positive, reserved until the hold is captured, voided or expired';
+
+COMMENT ON COLUMN "holds"."captured_amount" IS 'how much was taken from the account, up to the amount held';
+
+COMMENT ON COLUMN "holds"."status" IS 'active, captured, voided or expired';
+
+COMMENT ON COLUMN "users"."tier" IS 'picks the transfer limits which apply to the user';
+
+COMMENT ON COLUMN "users"."role" IS 'customer, banker or admin';
+
+COMMENT ON COLUMN "transfer_limits"."max_per_transaction" IS 'largest amount a single transfer or withdrawal can take out';
+
+COMMENT ON COLUMN "transfer_limits"."daily_amount" IS 'total which can be taken out of the accounts of the user in a day';
+
+COMMENT ON COLUMN "transfer_limits"."daily_count" IS 'how many transfers and withdrawals the user can make in a day';
+
+COMMENT ON COLUMN "transfers"."fee" IS 'charged to the origin account on top of the amount, in its currency';
+
+COMMENT ON COLUMN "fee_rules"."transfer_type" IS 'same_currency or cross_currency';
+
+COMMENT ON COLUMN "fee_rules"."min_amount" IS 'the rule applies to transfers of at least this amount, until the rule with the next tier';
+
+COMMENT ON COLUMN "fee_rules"."percentage_bps" IS 'charged on the amount, in basis points';
+
+COMMENT ON COLUMN "fee_rules"."max_fee" IS 'zero when the fee has no cap';
+
+COMMENT ON COLUMN "house_accounts"."account_id" IS 'account which collects the fees charged in the currency';
+
+COMMENT ON COLUMN "house_accounts"."purpose" IS 'fee_revenue, interest_expense, cash or fx_conversion';
+
+COMMENT ON COLUMN "interest_rates"."annual_rate_bps" IS 'yearly interest rate, in basis points';
+
+COMMENT ON COLUMN "interest_accruals"."balance" IS 'balance of the account at the end of the day';
+
+COMMENT ON COLUMN "interest_accruals"."amount" IS 'balance * annual_rate_bps, which is the interest of the day in 1/3650000 of the smallest unit';
+
+COMMENT ON COLUMN "interest_accruals"."posting_id" IS 'set once the interest is paid';
+
+COMMENT ON COLUMN "interest_postings"."period" IS 'first day of the month the interest was accrued in';
+
+COMMENT ON COLUMN "interest_postings"."amount" IS 'interest credited to the account';
+
+COMMENT ON COLUMN "interest_postings"."remainder" IS 'fraction of the smallest unit left over, carried to the next posting';
+
+COMMENT ON COLUMN "statements"."period" IS 'first day of the month of the statement';
+
+COMMENT ON COLUMN "balance_snapshots"."balance" IS 'balance of the account at the end of the day, in UTC';
+
+COMMENT ON COLUMN "journals"."kind" IS 'kind of transaction which made the posting';
+
+COMMENT ON COLUMN "journals"."source_id" IS 'id of the transaction which made the posting';
+
+COMMENT ON COLUMN "account_status_changes"."changed_by" IS 'owner of the account or the admin who changed its status';
+
+COMMENT ON COLUMN "account_status_changes"."payout_transfer_id" IS 'transfer which paid out the balance of a closed account';
+
+COMMENT ON COLUMN "account_status_changes"."by_staff" IS 'made by a banker or an admin, whatever their role is now';
+
+COMMENT ON COLUMN "account_members"."username" IS 'user the account is shared with, besides its owner';
+
+COMMENT ON COLUMN "account_members"."role" IS 'owner, spender or viewer';
+
+COMMENT ON COLUMN "account_members"."spend_limit" IS 'largest amount a spender can take out of the account at once';
+
+COMMENT ON COLUMN "security_events"."kind" IS 'refresh_token_reused';
+
+COMMENT ON COLUMN "totp_secrets"."encrypted_secret" IS 'encrypted with the TOTP encryption key of the config';
+
+COMMENT ON COLUMN "totp_secrets"."last_used_step" IS 'time step of the last code accepted, earlier codes being refused so they can''t be replayed';
+
+COMMENT ON COLUMN "totp_secrets"."failed_attempts" IS 'codes refused in a row, checking codes being locked for a while when there are too many';
+
+COMMENT ON COLUMN "totp_secrets"."enabled_at" IS 'when the first code was verified, from which codes are asked at login and for sensitive operations';
+
+COMMENT ON COLUMN "recovery_codes"."hashed_code" IS 'SHA-256 of the code, which can be used once instead of a TOTP code';
+
 ALTER TABLE "accounts" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
 
 ALTER TABLE "entries" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
@@ -86,6 +474,10 @@ ALTER TABLE "transfers" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts
 
 ALTER TABLE "transfers" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
 
+ALTER TABLE "transfers" ADD FOREIGN KEY ("quote_id") REFERENCES "fx_quotes" ("id");
+
+ALTER TABLE "transfers" ADD FOREIGN KEY ("reversal_of_id") REFERENCES "transfers" ("id");
+
 ALTER TABLE "deposits" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
 
 ALTER TABLE "deposits" ADD FOREIGN KEY ("user") REFERENCES "users" ("username");
@@ -93,3 +485,53 @@ ALTER TABLE "deposits" ADD FOREIGN KEY ("user") REFERENCES "users" ("username");
 ALTER TABLE "withdraws" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
 
 ALTER TABLE "withdraws" ADD FOREIGN KEY ("user") REFERENCES "users" ("username");
+
+ALTER TABLE "idempotency_keys" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "fx_quotes" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "scheduled_transfer_runs" ADD FOREIGN KEY ("scheduled_transfer_id") REFERENCES "scheduled_transfers" ("id");
+
+ALTER TABLE "scheduled_transfer_runs" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "holds" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "holds" ADD FOREIGN KEY ("user") REFERENCES "users" ("username");
+
+ALTER TABLE "house_accounts" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "interest_accruals" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "interest_accruals" ADD FOREIGN KEY ("posting_id") REFERENCES "interest_postings" ("id");
+
+ALTER TABLE "interest_postings" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "statements" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "balance_snapshots" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "entries" ADD FOREIGN KEY ("journal_id") REFERENCES "journals" ("id");
+
+ALTER TABLE "account_status_changes" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "account_status_changes" ADD FOREIGN KEY ("changed_by") REFERENCES "users" ("username");
+
+ALTER TABLE "account_status_changes" ADD FOREIGN KEY ("payout_transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "account_members" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "account_members" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "account_members" ADD FOREIGN KEY ("invited_by") REFERENCES "users" ("username");
+
+ALTER TABLE "security_events" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "totp_secrets" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "recovery_codes" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
@@ -0,0 +1,36 @@
+package fx
+
+import (
+	"context"
+	"errors"
+	"math/big"
+)
+
+// RateScale is the fixed point scale used for exchange rates, so a rate of 1.0 is stored as 100000000
+const RateScale int64 = 100_000_000
+
+// ErrRateUnavailable is returned when a provider has no rate for a currency pair
+var ErrRateUnavailable = errors.New("exchange rate is not available")
+
+// RateProvider is an interface for getting exchange rates between currencies
+type RateProvider interface {
+	// Rate returns how much one unit of the "from" currency is worth in the "to" currency, scaled by RateScale
+	Rate(ctx context.Context, fromCurrency string, toCurrency string) (int64, error)
+}
+
+// ApplySpread takes the spread, in basis points, out of a market rate
+func ApplySpread(rate int64, spreadBps int32) int64 {
+	return MulDiv(rate, 10_000-int64(spreadBps), 10_000)
+}
+
+// Convert returns the amount in the target currency, rounding down any fraction of a cent
+func Convert(amount int64, rate int64) int64 {
+	return MulDiv(amount, rate, RateScale)
+}
+
+// MulDiv calculates a * b / c without overflowing the intermediate product, rounding down
+func MulDiv(a int64, b int64, c int64) int64 {
+	result := new(big.Int).Mul(big.NewInt(a), big.NewInt(b))
+	result.Quo(result, big.NewInt(c))
+	return result.Int64()
+}
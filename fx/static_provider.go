@@ -0,0 +1,65 @@
+package fx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+)
+
+// StaticRateProvider serves a fixed set of exchange rates
+type StaticRateProvider struct {
+	rates map[string]map[string]int64
+}
+
+// NewStaticRateProvider creates a new StaticRateProvider, where rates[from][to] is
+// how much one unit of "from" is worth in "to"
+func NewStaticRateProvider(rates map[string]map[string]float64) *StaticRateProvider {
+	provider := &StaticRateProvider{
+		rates: make(map[string]map[string]int64),
+	}
+
+	for from, targets := range rates {
+		provider.rates[from] = make(map[string]int64)
+		for to, rate := range targets {
+			provider.rates[from][to] = int64(math.Round(rate * float64(RateScale)))
+		}
+	}
+
+	return provider
+}
+
+// LoadStaticRateProvider creates a new StaticRateProvider with the rates from a JSON file,
+// such as {"USD": {"EUR": 0.92}}
+func LoadStaticRateProvider(path string) (*StaticRateProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read rates file: %w", err)
+	}
+
+	var rates map[string]map[string]float64
+	err = json.Unmarshal(data, &rates)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse rates file: %w", err)
+	}
+
+	return NewStaticRateProvider(rates), nil
+}
+
+// Rate returns the configured rate for the currency pair, falling back to the inverse of the opposite pair
+func (provider *StaticRateProvider) Rate(ctx context.Context, fromCurrency string, toCurrency string) (int64, error) {
+	if fromCurrency == toCurrency {
+		return RateScale, nil
+	}
+
+	if rate, ok := provider.rates[fromCurrency][toCurrency]; ok && rate > 0 {
+		return rate, nil
+	}
+
+	if rate, ok := provider.rates[toCurrency][fromCurrency]; ok && rate > 0 {
+		return MulDiv(RateScale, RateScale, rate), nil
+	}
+
+	return 0, ErrRateUnavailable
+}
@@ -0,0 +1,66 @@
+package fx
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"simplebank/util"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStaticRateProvider(t *testing.T) {
+	provider := NewStaticRateProvider(map[string]map[string]float64{
+		util.USD: {util.EUR: 0.8},
+	})
+
+	rate, err := provider.Rate(context.Background(), util.USD, util.EUR)
+	require.NoError(t, err)
+	require.Equal(t, int64(80_000_000), rate)
+
+	// The opposite pair is served with the inverse rate
+	rate, err = provider.Rate(context.Background(), util.EUR, util.USD)
+	require.NoError(t, err)
+	require.Equal(t, int64(125_000_000), rate)
+
+	rate, err = provider.Rate(context.Background(), util.USD, util.USD)
+	require.NoError(t, err)
+	require.Equal(t, RateScale, rate)
+
+	rate, err = provider.Rate(context.Background(), util.USD, util.CAD)
+	require.ErrorIs(t, err, ErrRateUnavailable)
+	require.Zero(t, rate)
+}
+
+func TestLoadStaticRateProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.json")
+	err := os.WriteFile(path, []byte(`{"USD": {"CAD": 1.25}}`), 0600)
+	require.NoError(t, err)
+
+	provider, err := LoadStaticRateProvider(path)
+	require.NoError(t, err)
+
+	rate, err := provider.Rate(context.Background(), util.USD, util.CAD)
+	require.NoError(t, err)
+	require.Equal(t, int64(125_000_000), rate)
+
+	// The rates shipped with the app must be valid
+	_, err = LoadStaticRateProvider("rates.json")
+	require.NoError(t, err)
+
+	_, err = LoadStaticRateProvider(filepath.Join(t.TempDir(), "missing.json"))
+	require.Error(t, err)
+}
+
+func TestConvert(t *testing.T) {
+	rate := ApplySpread(RateScale, 50)
+	require.Equal(t, int64(99_500_000), rate)
+
+	require.Equal(t, int64(995), Convert(1000, rate))
+	// Fractions of a cent are rounded down
+	require.Equal(t, int64(0), Convert(1, rate))
+	// Large amounts don't overflow
+	require.Equal(t, int64(4_000_000_000_000), Convert(2_000_000_000_000, 2*RateScale))
+}
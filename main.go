@@ -1,11 +1,20 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
+	_ "expvar" // registers /debug/vars on the metrics server
+	"flag"
 	"log"
+	"net/http"
+	"os"
 	"simplebank/api"
 	db "simplebank/db/sqlc"
+	"simplebank/fx"
+	"simplebank/reconcile"
 	"simplebank/util"
+	"simplebank/worker"
 
 	_ "github.com/lib/pq"
 )
@@ -25,8 +34,36 @@ func main() {
 
 	// Creating the repository with the database connection
 	store := db.NewStore(conn)
+
+	// "simplebank reconcile" checks the ledger once and exits, instead of starting the API
+	if len(os.Args) > 1 && os.Args[1] == "reconcile" {
+		os.Exit(runReconcile(store, os.Args[2:]))
+	}
+
+	// Running the background jobs, such as scheduled transfers, along with the API
+	worker.NewWorker(config, store).Start(context.Background())
+
+	// Publishing the metrics of the background jobs on /debug/vars, away from the API
+	if config.MetricsAddress != "" {
+		go func() {
+			log.Println("metrics server stopped:", http.ListenAndServe(config.MetricsAddress, nil))
+		}()
+	}
+
+	// Loading the exchange rates used for transfers between currencies
+	rateProvider, err := fx.LoadStaticRateProvider(config.FXRatesFile)
+	if err != nil {
+		log.Fatal("cannot load exchange rates:", err)
+	}
+
+	// Checking that the sessions of access tokens aren't blocked, as announced by every replica
+	revocationCache := api.NewRevocationCache(store, config.SessionCacheDuration)
+	go func() {
+		log.Println("session listener stopped:", revocationCache.Listen(context.Background(), config.DBSource))
+	}()
+
 	// Starting the API with the 'store' object
-	server, err := api.NewServer(config, store)
+	server, err := api.NewServer(config, store, rateProvider, revocationCache)
 	if err != nil {
 		log.Fatal("cannot create server:", err)
 	}
@@ -36,3 +73,34 @@ func main() {
 		log.Fatal("cannot start server:", err)
 	}
 }
+
+// runReconcile prints the reconciliation report of the ledger, and returns the exit code,
+// which is 1 when an invariant is broken
+func runReconcile(store db.Store, args []string) int {
+	flags := flag.NewFlagSet("reconcile", flag.ExitOnError)
+	asJSON := flags.Bool("json", false, "print the report as JSON")
+	flags.Parse(args)
+
+	report, err := reconcile.Run(context.Background(), store)
+	if err != nil {
+		log.Println("cannot reconcile:", err)
+		return 2
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(report)
+	} else {
+		err = report.WriteText(os.Stdout)
+	}
+	if err != nil {
+		log.Println("cannot write report:", err)
+		return 2
+	}
+
+	if !report.Passed {
+		return 1
+	}
+	return 0
+}
@@ -125,7 +125,24 @@ resource "aws_secretsmanager_secret_version" "env_secret_version" {
   "SERVER_ADDRESS": "0.0.0.0:8080",
   "TOKEN_SYMMETRIC_KEY": "34984392010eaaac519278b232d94506224de14db0c4d5d77af8499d1b4e8f5c8375d457aeee187d75cb11305c3a2cea31723ea03aba5bd910967a335d8dcfed",
   "ACCESS_TOKEN_DURATION": "15m",
-  "REFRESH_TOKEN_DURATION": "24h"
+  "REFRESH_TOKEN_DURATION": "24h",
+  "SESSION_CACHE_DURATION": "10s",
+  "CHALLENGE_TOKEN_DURATION": "5m",
+  "TOTP_ENCRYPTION_KEY": "9f4c2e7a1b8d3f60c5a9e2d7b4f1c8a3",
+  "IDEMPOTENCY_KEY_DURATION": "24h",
+  "IDEMPOTENCY_CLEANUP_INTERVAL": "1h",
+  "FX_RATES_FILE": "fx/rates.json",
+  "FX_QUOTE_DURATION": "30s",
+  "FX_SPREAD_BPS": "50",
+  "SCHEDULED_TRANSFER_INTERVAL": "1m",
+  "HOLD_DURATION": "168h",
+  "MAX_ACCOUNTS_PER_CURRENCY": "5",
+  "HOLD_EXPIRY_INTERVAL": "1m",
+  "INTEREST_INTERVAL": "1h",
+  "STATEMENT_INTERVAL": "1h",
+  "BALANCE_SNAPSHOT_INTERVAL": "1h",
+  "RECONCILE_INTERVAL": "24h",
+  "METRICS_ADDRESS": "0.0.0.0:9090"
 }
 EOT
 }
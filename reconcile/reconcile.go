@@ -0,0 +1,215 @@
+package reconcile
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	db "simplebank/db/sqlc"
+)
+
+// maxViolations is how many violations are listed for each check, so a badly broken ledger
+// doesn't produce an endless report
+const maxViolations = 1000
+
+// Names of the invariants which are checked
+const (
+	CheckAccountBalances = "account_balances"
+	CheckTransferEntries = "transfer_entries"
+	CheckDepositEntries  = "deposit_entries"
+	CheckWithdrawEntries = "withdraw_entries"
+	CheckJournalBalances = "journal_balances"
+)
+
+// Report is the outcome of checking every invariant of the ledger
+type Report struct {
+	CheckedAt time.Time `json:"checked_at"`
+	Passed    bool      `json:"passed"`
+	Checks    []Check   `json:"checks"`
+}
+
+// Check is the outcome of checking one invariant
+type Check struct {
+	Name       string      `json:"name"`
+	Passed     bool        `json:"passed"`
+	Violations []Violation `json:"violations"`
+}
+
+// Violation is a record which breaks an invariant
+type Violation struct {
+	// Kind is the table of the record, such as account or transfer
+	Kind    string `json:"kind"`
+	ID      int64  `json:"id"`
+	Message string `json:"message"`
+}
+
+// FailedChecks counts the checks which found violations
+func (report Report) FailedChecks() int {
+	failed := 0
+	for _, check := range report.Checks {
+		if !check.Passed {
+			failed++
+		}
+	}
+	return failed
+}
+
+// Run checks every invariant of the ledger
+func Run(ctx context.Context, store db.Querier) (Report, error) {
+	report := Report{
+		CheckedAt: time.Now(),
+		Passed:    true,
+	}
+
+	checks := []struct {
+		name string
+		run  func(ctx context.Context, store db.Querier) ([]Violation, error)
+	}{
+		{CheckAccountBalances, checkAccountBalances},
+		{CheckTransferEntries, checkTransferEntries},
+		{CheckDepositEntries, checkDepositEntries},
+		{CheckWithdrawEntries, checkWithdrawEntries},
+		{CheckJournalBalances, checkJournalBalances},
+	}
+
+	for _, check := range checks {
+		violations, err := check.run(ctx, store)
+		if err != nil {
+			return Report{}, fmt.Errorf("cannot run %s check: %w", check.name, err)
+		}
+
+		passed := len(violations) == 0
+		report.Checks = append(report.Checks, Check{
+			Name:       check.name,
+			Passed:     passed,
+			Violations: violations,
+		})
+		report.Passed = report.Passed && passed
+	}
+
+	return report, nil
+}
+
+// checkAccountBalances finds the accounts whose balance drifted from the sum of their entries
+func checkAccountBalances(ctx context.Context, store db.Querier) ([]Violation, error) {
+	mismatches, err := store.ListBalanceMismatches(ctx, maxViolations)
+	if err != nil {
+		return nil, err
+	}
+
+	violations := make([]Violation, 0, len(mismatches))
+	for _, mismatch := range mismatches {
+		violations = append(violations, Violation{
+			Kind:    "account",
+			ID:      mismatch.AccountID,
+			Message: fmt.Sprintf("balance is %d but its entries add up to %d", mismatch.Balance, mismatch.EntriesTotal),
+		})
+	}
+	return violations, nil
+}
+
+// checkTransferEntries finds the transfers which don't have exactly their two entries
+func checkTransferEntries(ctx context.Context, store db.Querier) ([]Violation, error) {
+	mismatches, err := store.ListTransferEntryMismatches(ctx, maxViolations)
+	if err != nil {
+		return nil, err
+	}
+
+	violations := make([]Violation, 0, len(mismatches))
+	for _, mismatch := range mismatches {
+		violations = append(violations, Violation{
+			Kind:    "transfer",
+			ID:      mismatch.TransferID,
+			Message: entriesMessage(2, mismatch.EntryCount, mismatch.MatchingCount),
+		})
+	}
+	return violations, nil
+}
+
+// checkDepositEntries finds the deposits which don't have exactly their entry
+func checkDepositEntries(ctx context.Context, store db.Querier) ([]Violation, error) {
+	mismatches, err := store.ListDepositEntryMismatches(ctx, maxViolations)
+	if err != nil {
+		return nil, err
+	}
+
+	violations := make([]Violation, 0, len(mismatches))
+	for _, mismatch := range mismatches {
+		violations = append(violations, Violation{
+			Kind:    "deposit",
+			ID:      mismatch.DepositID,
+			Message: entriesMessage(1, mismatch.EntryCount, mismatch.MatchingCount),
+		})
+	}
+	return violations, nil
+}
+
+// checkWithdrawEntries finds the withdrawals which don't have exactly their entry
+func checkWithdrawEntries(ctx context.Context, store db.Querier) ([]Violation, error) {
+	mismatches, err := store.ListWithdrawEntryMismatches(ctx, maxViolations)
+	if err != nil {
+		return nil, err
+	}
+
+	violations := make([]Violation, 0, len(mismatches))
+	for _, mismatch := range mismatches {
+		violations = append(violations, Violation{
+			Kind:    "withdraw",
+			ID:      mismatch.WithdrawID,
+			Message: entriesMessage(1, mismatch.EntryCount, mismatch.MatchingCount),
+		})
+	}
+	return violations, nil
+}
+
+// checkJournalBalances finds the journals whose entries don't add up to zero in one of their currencies.
+// The database refuses to commit them, so any violation means the ledger was changed by hand
+func checkJournalBalances(ctx context.Context, store db.Querier) ([]Violation, error) {
+	unbalanced, err := store.ListUnbalancedJournals(ctx, maxViolations)
+	if err != nil {
+		return nil, err
+	}
+
+	violations := make([]Violation, 0, len(unbalanced))
+	for _, journal := range unbalanced {
+		violations = append(violations, Violation{
+			Kind:    "journal",
+			ID:      journal.JournalID,
+			Message: fmt.Sprintf("entries add up to %d %s instead of zero", journal.Total, journal.Currency),
+		})
+	}
+	return violations, nil
+}
+
+func entriesMessage(expected int, entryCount int32, matchingCount int32) string {
+	return fmt.Sprintf("expected %d entries, found %d of which %d match", expected, entryCount, matchingCount)
+}
+
+// WriteText writes the report in a form meant to be read by people
+func (report Report) WriteText(w io.Writer) error {
+	status := "passed"
+	if !report.Passed {
+		status = "FAILED"
+	}
+	_, err := fmt.Fprintf(w, "reconciliation %s at %s\n", status, report.CheckedAt.Format(time.RFC3339))
+	if err != nil {
+		return err
+	}
+
+	for _, check := range report.Checks {
+		_, err := fmt.Fprintf(w, "%s: %d violations\n", check.Name, len(check.Violations))
+		if err != nil {
+			return err
+		}
+
+		for _, violation := range check.Violations {
+			_, err := fmt.Fprintf(w, "  %s [%d]: %s\n", violation.Kind, violation.ID, violation.Message)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package reconcile
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"testing"
+
+	mockdb "simplebank/db/mock"
+	db "simplebank/db/sqlc"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		ListBalanceMismatches(gomock.Any(), gomock.Eq(int32(maxViolations))).
+		Times(1).
+		Return([]db.ListBalanceMismatchesRow{{AccountID: 1, Balance: 100, EntriesTotal: 90}}, nil)
+	store.EXPECT().
+		ListTransferEntryMismatches(gomock.Any(), gomock.Eq(int32(maxViolations))).
+		Times(1).
+		Return([]db.ListTransferEntryMismatchesRow{{TransferID: 2, EntryCount: 1, MatchingCount: 1}}, nil)
+	store.EXPECT().ListDepositEntryMismatches(gomock.Any(), gomock.Any()).Times(1)
+	store.EXPECT().ListWithdrawEntryMismatches(gomock.Any(), gomock.Any()).Times(1)
+	store.EXPECT().
+		ListUnbalancedJournals(gomock.Any(), gomock.Eq(int32(maxViolations))).
+		Times(1).
+		Return([]db.ListUnbalancedJournalsRow{{JournalID: 3, Currency: "USD", Total: 10}}, nil)
+
+	report, err := Run(context.Background(), store)
+	require.NoError(t, err)
+	require.False(t, report.Passed)
+	require.Equal(t, 3, report.FailedChecks())
+	require.Len(t, report.Checks, 5)
+
+	require.Equal(t, CheckAccountBalances, report.Checks[0].Name)
+	require.False(t, report.Checks[0].Passed)
+	require.Equal(t, []Violation{{
+		Kind:    "account",
+		ID:      1,
+		Message: "balance is 100 but its entries add up to 90",
+	}}, report.Checks[0].Violations)
+
+	require.Equal(t, CheckTransferEntries, report.Checks[1].Name)
+	require.Equal(t, "expected 2 entries, found 1 of which 1 match", report.Checks[1].Violations[0].Message)
+
+	require.True(t, report.Checks[2].Passed)
+	require.Empty(t, report.Checks[2].Violations)
+	require.True(t, report.Checks[3].Passed)
+
+	require.Equal(t, CheckJournalBalances, report.Checks[4].Name)
+	require.Equal(t, []Violation{{
+		Kind:    "journal",
+		ID:      3,
+		Message: "entries add up to 10 USD instead of zero",
+	}}, report.Checks[4].Violations)
+
+	var buf bytes.Buffer
+	err = report.WriteText(&buf)
+	require.NoError(t, err)
+	require.Contains(t, buf.String(), "reconciliation FAILED")
+	require.Contains(t, buf.String(), "  transfer [2]: expected 2 entries")
+}
+
+func TestRunPassed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().ListBalanceMismatches(gomock.Any(), gomock.Any()).Times(1)
+	store.EXPECT().ListTransferEntryMismatches(gomock.Any(), gomock.Any()).Times(1)
+	store.EXPECT().ListDepositEntryMismatches(gomock.Any(), gomock.Any()).Times(1)
+	store.EXPECT().ListWithdrawEntryMismatches(gomock.Any(), gomock.Any()).Times(1)
+	store.EXPECT().ListUnbalancedJournals(gomock.Any(), gomock.Any()).Times(1)
+
+	report, err := Run(context.Background(), store)
+	require.NoError(t, err)
+	require.True(t, report.Passed)
+	require.Zero(t, report.FailedChecks())
+}
+
+func TestRunError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		ListBalanceMismatches(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(nil, sql.ErrConnDone)
+	store.EXPECT().ListTransferEntryMismatches(gomock.Any(), gomock.Any()).Times(0)
+
+	_, err := Run(context.Background(), store)
+	require.ErrorIs(t, err, sql.ErrConnDone)
+}
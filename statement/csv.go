@@ -0,0 +1,47 @@
+package statement
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strconv"
+	"time"
+)
+
+// CSV renders the statement as one row per entry, between the opening and closing balances
+func (statement Statement) CSV() ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	records := [][]string{
+		{"date", "entry_id", "description", "counterparty_account_id", "counterparty", "amount", "balance"},
+		{statement.PeriodStart.Format(time.RFC3339), "", "Opening balance", "", "", "", formatAmount(statement.OpeningBalance)},
+	}
+
+	for _, line := range statement.Lines {
+		counterpartyAccountID := ""
+		if line.CounterpartyAccountID != 0 {
+			counterpartyAccountID = strconv.FormatInt(line.CounterpartyAccountID, 10)
+		}
+
+		records = append(records, []string{
+			line.Date.UTC().Format(time.RFC3339),
+			strconv.FormatInt(line.EntryID, 10),
+			line.Description,
+			counterpartyAccountID,
+			line.Counterparty,
+			formatAmount(line.Amount),
+			formatAmount(line.Balance),
+		})
+	}
+
+	records = append(records, []string{
+		statement.PeriodEnd.Format(time.RFC3339), "", "Closing balance", "", "", "", formatAmount(statement.ClosingBalance),
+	})
+
+	err := writer.WriteAll(records)
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
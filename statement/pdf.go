@@ -0,0 +1,136 @@
+package statement
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// The PDF is written by hand, with the built-in Courier font, so no font files
+// or C libraries are needed. A4 pages are 595 by 842 points
+const (
+	pdfPageWidth    = 595
+	pdfPageHeight   = 842
+	pdfMargin       = 50
+	pdfFontSize     = 9
+	pdfLineHeight   = 12
+	pdfLinesPerPage = (pdfPageHeight - 2*pdfMargin) / pdfLineHeight
+	// pdfLineWidth is how many Courier characters fit between the margins, since each one is 0.6 of the font size wide
+	pdfLineWidth = (pdfPageWidth - 2*pdfMargin) * 10 / (pdfFontSize * 6)
+)
+
+// PDF renders the statement as a printable document
+func (statement Statement) PDF() ([]byte, error) {
+	lines := []string{
+		fmt.Sprintf("Simple Bank - Statement of account #%d", statement.AccountID),
+		"",
+		fmt.Sprintf("Owner:    %s", statement.Owner),
+		fmt.Sprintf("Currency: %s", statement.Currency),
+		fmt.Sprintf("Period:   %s to %s", statement.PeriodStart.Format("2006-01-02"), statement.PeriodEnd.AddDate(0, 0, -1).Format("2006-01-02")),
+		"",
+		fmt.Sprintf("Opening balance: %s", formatAmount(statement.OpeningBalance)),
+		"",
+		pdfRow("Date", "Description", "Amount", "Balance"),
+		strings.Repeat("-", pdfLineWidth),
+	}
+
+	for _, line := range statement.Lines {
+		description := line.Description
+		if line.Counterparty != "" {
+			description = fmt.Sprintf("%s (%s)", description, line.Counterparty)
+		}
+		lines = append(lines, pdfRow(
+			line.Date.UTC().Format("2006-01-02"),
+			description,
+			formatAmount(line.Amount),
+			formatAmount(line.Balance),
+		))
+	}
+
+	lines = append(lines,
+		strings.Repeat("-", pdfLineWidth),
+		"",
+		fmt.Sprintf("Closing balance: %s", formatAmount(statement.ClosingBalance)),
+	)
+
+	return writePDF(lines), nil
+}
+
+// pdfRow lays out a line of the entries table, cutting descriptions which don't fit
+func pdfRow(date string, description string, amount string, balance string) string {
+	const descriptionWidth = pdfLineWidth - 10 - 2*15 - 3
+	if len(description) > descriptionWidth {
+		description = description[:descriptionWidth-3] + "..."
+	}
+	return fmt.Sprintf("%-10s %-*s %15s %15s", date, descriptionWidth, description, amount, balance)
+}
+
+// writePDF lays out lines of text on as many pages as they need
+func writePDF(lines []string) []byte {
+	var pages [][]string
+	for len(lines) > pdfLinesPerPage {
+		pages = append(pages, lines[:pdfLinesPerPage])
+		lines = lines[pdfLinesPerPage:]
+	}
+	pages = append(pages, lines)
+
+	var buf bytes.Buffer
+	var offsets []int
+	writeObject := func(object string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), object)
+	}
+
+	buf.WriteString("%PDF-1.4\n")
+
+	// The catalog, the page tree and the font come first, then a page and its content for every page
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 4+2*i)
+	}
+	writeObject("<< /Type /Catalog /Pages 2 0 R >>")
+	writeObject(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	writeObject("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+
+	for i, page := range pages {
+		writeObject(fmt.Sprintf(
+			"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, 5+2*i,
+		))
+
+		var content bytes.Buffer
+		fmt.Fprintf(&content, "BT\n/F1 %d Tf\n%d TL\n%d %d Td\n", pdfFontSize, pdfLineHeight, pdfMargin, pdfPageHeight-pdfMargin)
+		for _, line := range page {
+			fmt.Fprintf(&content, "(%s) Tj T*\n", escapePDFText(line))
+		}
+		content.WriteString("ET")
+		writeObject(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.String()))
+	}
+
+	xrefOffset := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xrefOffset)
+
+	return buf.Bytes()
+}
+
+// escapePDFText escapes the characters which are special in PDF strings,
+// replacing the ones the font can't show
+func escapePDFText(text string) string {
+	var builder strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			builder.WriteRune('\\')
+			builder.WriteRune(r)
+		case r < 32 || r > 126:
+			builder.WriteRune('?')
+		default:
+			builder.WriteRune(r)
+		}
+	}
+	return builder.String()
+}
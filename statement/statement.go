@@ -0,0 +1,143 @@
+package statement
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	db "simplebank/db/sqlc"
+)
+
+// Formats which a statement can be rendered to
+const (
+	FormatPDF  = "pdf"
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+)
+
+// MonthLayout is how the month of a statement is written, such as 2026-09
+const MonthLayout = "2006-01"
+
+// ErrMonthNotStarted is returned when a statement is asked for a month which is still to come
+var ErrMonthNotStarted = errors.New("statement month hasn't started yet")
+
+// Statement lists what happened to an account during a month
+type Statement struct {
+	AccountID      int64     `json:"account_id"`
+	Owner          string    `json:"owner"`
+	Currency       string    `json:"currency"`
+	Month          string    `json:"month"`
+	PeriodStart    time.Time `json:"period_start"`
+	PeriodEnd      time.Time `json:"period_end"`
+	OpeningBalance int64     `json:"opening_balance"`
+	ClosingBalance int64     `json:"closing_balance"`
+	Lines          []Line    `json:"lines"`
+}
+
+// Line is an entry of the account, along with the balance right after it
+type Line struct {
+	EntryID     int64     `json:"entry_id"`
+	Date        time.Time `json:"date"`
+	Description string    `json:"description"`
+	// CounterpartyAccountID is zero when the entry isn't a transfer between accounts
+	CounterpartyAccountID int64  `json:"counterparty_account_id,omitempty"`
+	Counterparty          string `json:"counterparty,omitempty"`
+	Amount                int64  `json:"amount"`
+	Balance               int64  `json:"balance"`
+}
+
+// ParseMonth returns the first day of a month written like 2026-09, in UTC
+func ParseMonth(month string) (time.Time, error) {
+	return time.Parse(MonthLayout, month)
+}
+
+// Generate builds the statement of an account for the month which starts at periodStart.
+// The statement of the current month only has the entries posted so far
+func Generate(ctx context.Context, store db.Querier, account db.Account, periodStart time.Time) (Statement, error) {
+	if periodStart.After(time.Now()) {
+		return Statement{}, ErrMonthNotStarted
+	}
+	periodEnd := periodStart.AddDate(0, 1, 0)
+
+	openingBalance, err := store.GetAccountBalanceAt(ctx, db.GetAccountBalanceAtParams{
+		At:        periodStart,
+		AccountID: account.ID,
+	})
+	if err != nil {
+		return Statement{}, fmt.Errorf("cannot get opening balance: %w", err)
+	}
+
+	entries, err := store.ListStatementEntries(ctx, db.ListStatementEntriesParams{
+		AccountID:   account.ID,
+		PeriodStart: periodStart,
+		PeriodEnd:   periodEnd,
+	})
+	if err != nil {
+		return Statement{}, fmt.Errorf("cannot list entries: %w", err)
+	}
+
+	statement := Statement{
+		AccountID:      account.ID,
+		Owner:          account.Owner,
+		Currency:       account.Currency,
+		Month:          periodStart.Format(MonthLayout),
+		PeriodStart:    periodStart,
+		PeriodEnd:      periodEnd,
+		OpeningBalance: openingBalance,
+		Lines:          make([]Line, 0, len(entries)),
+	}
+
+	balance := openingBalance
+	for _, entry := range entries {
+		balance += entry.Amount
+		statement.Lines = append(statement.Lines, Line{
+			EntryID:               entry.ID,
+			Date:                  entry.CreatedAt,
+			Description:           describe(entry),
+			CounterpartyAccountID: entry.CounterpartyAccountID,
+			Counterparty:          entry.CounterpartyOwner,
+			Amount:                entry.Amount,
+			Balance:               balance,
+		})
+	}
+	statement.ClosingBalance = balance
+
+	return statement, nil
+}
+
+// describe tells the customer what an entry was for
+func describe(entry db.ListStatementEntriesRow) string {
+	switch entry.SourceType {
+	case db.EntrySourceTransfer:
+		if entry.ReversalOfID.Valid {
+			return fmt.Sprintf("Reversal of transfer #%d", entry.ReversalOfID.Int64)
+		}
+		if entry.Amount < 0 {
+			return fmt.Sprintf("Transfer to account #%d", entry.CounterpartyAccountID)
+		}
+		return fmt.Sprintf("Transfer from account #%d", entry.CounterpartyAccountID)
+	case db.EntrySourceTransferFee:
+		return fmt.Sprintf("Fee for transfer #%d", entry.SourceID)
+	case db.EntrySourceDeposit:
+		return "Deposit"
+	case db.EntrySourceWithdraw:
+		return "Withdrawal"
+	case db.EntrySourceHold:
+		return fmt.Sprintf("Capture of hold #%d", entry.SourceID)
+	case db.EntrySourceInterest:
+		return "Interest"
+	default:
+		return entry.SourceType
+	}
+}
+
+// formatAmount writes an amount in the smallest unit with two decimals, like every supported currency has
+func formatAmount(amount int64) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, amount/100, amount%100)
+}
@@ -0,0 +1,198 @@
+package statement
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	mockdb "simplebank/db/mock"
+	db "simplebank/db/sqlc"
+	"simplebank/util"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func randomStatement(n int) (db.Account, time.Time, []db.ListStatementEntriesRow) {
+	account := db.Account{
+		ID:       util.RandomInt(1, 1000),
+		Owner:    util.RandomOwner(),
+		Currency: util.USD,
+	}
+	periodStart := time.Date(2026, time.September, 1, 0, 0, 0, 0, time.UTC)
+
+	entries := make([]db.ListStatementEntriesRow, n)
+	for i := range entries {
+		entries[i] = db.ListStatementEntriesRow{
+			ID:                    int64(i + 1),
+			AccountID:             account.ID,
+			Amount:                util.RandomInt(-1000, 1000),
+			CreatedAt:             periodStart.Add(time.Duration(i) * time.Hour),
+			SourceType:            db.EntrySourceTransfer,
+			SourceID:              int64(i + 1),
+			CounterpartyAccountID: account.ID + 1,
+			CounterpartyOwner:     util.RandomOwner(),
+		}
+	}
+
+	return account, periodStart, entries
+}
+
+func TestGenerate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	account, periodStart, entries := randomStatement(3)
+	entries[1].SourceType = db.EntrySourceDeposit
+	entries[1].CounterpartyAccountID = 0
+	entries[1].CounterpartyOwner = ""
+	entries[2].ReversalOfID = sql.NullInt64{Int64: 10, Valid: true}
+	openingBalance := util.RandomMoney()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		GetAccountBalanceAt(gomock.Any(), gomock.Eq(db.GetAccountBalanceAtParams{
+			At:        periodStart,
+			AccountID: account.ID,
+		})).
+		Times(1).
+		Return(openingBalance, nil)
+	store.EXPECT().
+		ListStatementEntries(gomock.Any(), gomock.Eq(db.ListStatementEntriesParams{
+			AccountID:   account.ID,
+			PeriodStart: periodStart,
+			PeriodEnd:   periodStart.AddDate(0, 1, 0),
+		})).
+		Times(1).
+		Return(entries, nil)
+
+	statement, err := Generate(context.Background(), store, account, periodStart)
+	require.NoError(t, err)
+	require.Equal(t, "2026-09", statement.Month)
+	require.Equal(t, openingBalance, statement.OpeningBalance)
+	require.Len(t, statement.Lines, len(entries))
+
+	// Every line carries the balance right after it
+	balance := openingBalance
+	for i, line := range statement.Lines {
+		balance += entries[i].Amount
+		require.Equal(t, entries[i].ID, line.EntryID)
+		require.Equal(t, balance, line.Balance)
+	}
+	require.Equal(t, balance, statement.ClosingBalance)
+
+	require.Equal(t, fmt.Sprintf("Transfer %s account #%d", direction(entries[0].Amount), account.ID+1), statement.Lines[0].Description)
+	require.Equal(t, entries[0].CounterpartyOwner, statement.Lines[0].Counterparty)
+	require.Equal(t, "Deposit", statement.Lines[1].Description)
+	require.Zero(t, statement.Lines[1].CounterpartyAccountID)
+	require.Equal(t, "Reversal of transfer #10", statement.Lines[2].Description)
+}
+
+func TestGenerateMonthNotStarted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetAccountBalanceAt(gomock.Any(), gomock.Any()).Times(0)
+
+	nextMonth := time.Now().UTC().AddDate(0, 1, 0)
+	periodStart := time.Date(nextMonth.Year(), nextMonth.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	_, err := Generate(context.Background(), store, db.Account{ID: 1}, periodStart)
+	require.ErrorIs(t, err, ErrMonthNotStarted)
+}
+
+func TestCSV(t *testing.T) {
+	statement := Statement{
+		AccountID:      1,
+		PeriodStart:    time.Date(2026, time.September, 1, 0, 0, 0, 0, time.UTC),
+		PeriodEnd:      time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC),
+		OpeningBalance: 10_000,
+		ClosingBalance: 9_950,
+		Lines: []Line{
+			{
+				EntryID:               7,
+				Date:                  time.Date(2026, time.September, 2, 10, 0, 0, 0, time.UTC),
+				Description:           "Transfer to account #2",
+				CounterpartyAccountID: 2,
+				Counterparty:          "bob",
+				Amount:                -50,
+				Balance:               9_950,
+			},
+		},
+	}
+
+	data, err := statement.CSV()
+	require.NoError(t, err)
+
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	require.NoError(t, err)
+	require.Equal(t, [][]string{
+		{"date", "entry_id", "description", "counterparty_account_id", "counterparty", "amount", "balance"},
+		{"2026-09-01T00:00:00Z", "", "Opening balance", "", "", "", "100.00"},
+		{"2026-09-02T10:00:00Z", "7", "Transfer to account #2", "2", "bob", "-0.50", "99.50"},
+		{"2026-10-01T00:00:00Z", "", "Closing balance", "", "", "", "99.50"},
+	}, records)
+}
+
+func TestPDF(t *testing.T) {
+	account, periodStart, entries := randomStatement(200)
+
+	statement := Statement{
+		AccountID:   account.ID,
+		Owner:       account.Owner,
+		Currency:    account.Currency,
+		PeriodStart: periodStart,
+		PeriodEnd:   periodStart.AddDate(0, 1, 0),
+	}
+	for _, entry := range entries {
+		statement.Lines = append(statement.Lines, Line{
+			EntryID: entry.ID,
+			Date:    entry.CreatedAt,
+			// Parentheses have to be escaped in PDF strings
+			Description: "Transfer (test)",
+			Amount:      entry.Amount,
+		})
+	}
+
+	data, err := statement.PDF()
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(data, []byte("%PDF-1.4\n")))
+	require.True(t, bytes.HasSuffix(data, []byte("%%EOF\n")))
+	require.Contains(t, string(data), `Transfer \(test\)`)
+
+	// 200 entries don't fit in one page
+	count := regexp.MustCompile(`/Count (\d+)`).FindSubmatch(data)
+	require.NotNil(t, count)
+	pages, err := strconv.Atoi(string(count[1]))
+	require.NoError(t, err)
+	require.Greater(t, pages, 1)
+
+	// Readers find the objects through the offsets of the cross-reference table
+	startxref := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(data)
+	require.NotNil(t, startxref)
+	xrefOffset, err := strconv.Atoi(string(startxref[1]))
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(data[xrefOffset:], []byte("xref\n")))
+
+	offsets := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(data[xrefOffset:], -1)
+	require.Len(t, offsets, 3+2*pages)
+	for i, offset := range offsets {
+		position, err := strconv.Atoi(string(offset[1]))
+		require.NoError(t, err)
+		require.True(t, bytes.HasPrefix(data[position:], []byte(fmt.Sprintf("%d 0 obj\n", i+1))))
+	}
+}
+
+func direction(amount int64) string {
+	if amount < 0 {
+		return "to"
+	}
+	return "from"
+}
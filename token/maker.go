@@ -1,11 +1,16 @@
 package token
 
-import "time"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 // Maker is an interface for managing tokens
 type Maker interface {
-	// CreateToken creates a new token for a specific username and duration
-	CreateToken(username string, duration time.Duration) (string, *Payload, error)
+	// CreateToken creates a new token for a specific username, role, session, type and duration.
+	// Every token of a session is revoked along with it
+	CreateToken(username string, role string, sessionID uuid.UUID, tokenType string, duration time.Duration) (string, *Payload, error)
 
 	// VerifyToken checks if a token is valid
 	VerifyToken(token string) (*Payload, error)
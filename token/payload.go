@@ -13,16 +13,26 @@ var (
 	ErrInvalidToken = errors.New("token is not valid")
 )
 
+// Types of tokens, each of them can only be used for what it was issued for
+const (
+	TypeAccess    = "access"
+	TypeRefresh   = "refresh"
+	TypeChallenge = "challenge"
+)
+
 // Payload contains the payload data of the token
 type Payload struct {
 	ID        uuid.UUID `json:"id"`
+	SessionID uuid.UUID `json:"session_id"`
 	Username  string    `json:"username"`
+	Role      string    `json:"role"`
+	Type      string    `json:"type"`
 	IssuedAt  time.Time `json:"issued_at"`
 	ExpiredAt time.Time `json:"expired_at"`
 }
 
-// NewPayload creates a new token payload with a specific username and duration
-func NewPayload(username string, duration time.Duration) (*Payload, error) {
+// NewPayload creates a new token payload with a specific username, role, session, type and duration
+func NewPayload(username string, role string, sessionID uuid.UUID, tokenType string, duration time.Duration) (*Payload, error) {
 	tokenID, err := uuid.NewRandom()
 	if err != nil {
 		return nil, err
@@ -30,7 +40,10 @@ func NewPayload(username string, duration time.Duration) (*Payload, error) {
 
 	payload := &Payload{
 		ID:        tokenID,
+		SessionID: sessionID,
 		Username:  username,
+		Role:      role,
+		Type:      tokenType,
 		IssuedAt:  time.Now(),
 		ExpiredAt: time.Now().Add(duration),
 	}
@@ -0,0 +1,66 @@
+package totp
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+)
+
+// KeySize is the size of the key secrets are encrypted with, for AES-256
+const KeySize = 32
+
+// ErrInvalidCiphertext is returned when an encrypted secret can't be decrypted with the key
+var ErrInvalidCiphertext = errors.New("encrypted secret is not valid")
+
+// Cipher encrypts secrets before they are stored, so that reading the database isn't enough
+// to generate the codes of users
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+// NewCipher creates a new Cipher, which encrypts with AES-GCM
+func NewCipher(key string) (*Cipher, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("invalid key size: must be %d characters", KeySize)
+	}
+
+	block, err := aes.NewCipher([]byte(key))
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Cipher{aead: aead}, nil
+}
+
+// Encrypt encrypts the secret of a user, the username being authenticated along with it
+// so the secret can't be moved to another user
+func (c *Cipher) Encrypt(secret []byte, username string) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	_, err := rand.Read(nonce)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	return c.aead.Seal(nonce, nonce, secret, []byte(username)), nil
+}
+
+// Decrypt decrypts the secret of a user encrypted by Encrypt
+func (c *Cipher) Decrypt(ciphertext []byte, username string) ([]byte, error) {
+	nonceSize := c.aead.NonceSize()
+	if len(ciphertext) < nonceSize {
+		return nil, ErrInvalidCiphertext
+	}
+
+	secret, err := c.aead.Open(nil, ciphertext[:nonceSize], ciphertext[nonceSize:], []byte(username))
+	if err != nil {
+		return nil, ErrInvalidCiphertext
+	}
+	return secret, nil
+}
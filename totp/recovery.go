@@ -0,0 +1,40 @@
+package totp
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// RecoveryCodeCount is how many recovery codes users are given at once
+const RecoveryCodeCount = 10
+
+// recoveryCodeSize is the number of random bytes of each recovery code, 40 bits which are 8 characters once encoded
+const recoveryCodeSize = 5
+
+// GenerateRecoveryCodes generates codes which can each be used once instead of a TOTP code,
+// for users who lost their authenticator. They are shown as two groups of 4 characters
+func GenerateRecoveryCodes() ([]string, error) {
+	codes := make([]string, RecoveryCodeCount)
+	for i := range codes {
+		b := make([]byte, recoveryCodeSize)
+		_, err := rand.Read(b)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+
+		code := strings.ToLower(encoding.EncodeToString(b))
+		codes[i] = code[:4] + "-" + code[4:]
+	}
+	return codes, nil
+}
+
+// HashRecoveryCode returns the hash of the code which is stored. Unlike passwords the codes are random
+// and long enough that a fast hash is safe, so a code can be looked up by its hash
+func HashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
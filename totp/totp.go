@@ -0,0 +1,96 @@
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+const (
+	// Period is how long each code is valid for, as recommended by RFC 6238
+	Period = 30 * time.Second
+	// Digits is the length of the codes, which most authenticator apps expect
+	Digits = 6
+	// SecretSize is the size of the generated secrets, matching the output of HMAC-SHA1
+	SecretSize = 20
+	// Skew is how many periods before and after the current one are still accepted,
+	// for the clocks of phones which drift a little
+	Skew = 1
+)
+
+// encoding is how secrets are shown to users, authenticator apps expecting base32 without padding
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateSecret generates a new random secret to share with an authenticator app
+func GenerateSecret() ([]byte, error) {
+	secret := make([]byte, SecretSize)
+	_, err := rand.Read(secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate secret: %w", err)
+	}
+	return secret, nil
+}
+
+// EncodeSecret returns the secret as users type it in authenticator apps
+func EncodeSecret(secret []byte) string {
+	return encoding.EncodeToString(secret)
+}
+
+// URL returns the otpauth URL of the secret, which authenticator apps read from a QR code
+func URL(issuer string, accountName string, secret []byte) string {
+	query := url.Values{}
+	query.Set("secret", EncodeSecret(secret))
+	query.Set("issuer", issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(Digits))
+	query.Set("period", fmt.Sprint(int(Period.Seconds())))
+
+	label := url.PathEscape(issuer + ":" + accountName)
+	return fmt.Sprintf("otpauth://totp/%s?%s", label, query.Encode())
+}
+
+// Step returns the time step of the moment, which the codes are derived from
+func Step(t time.Time) int64 {
+	return t.Unix() / int64(Period.Seconds())
+}
+
+// Code returns the code of the secret for a time step, as described in RFC 4226 and RFC 6238
+func Code(secret []byte, step int64) string {
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(step))
+
+	mac := hmac.New(sha1.New, secret)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	modulo := uint32(1)
+	for i := 0; i < Digits; i++ {
+		modulo *= 10
+	}
+	return fmt.Sprintf("%0*d", Digits, value%modulo)
+}
+
+// Validate checks the code against the time steps around the moment, and returns the step it matched.
+// Callers must remember the step and refuse codes of earlier or equal steps, so a code can't be replayed
+func Validate(secret []byte, code string, t time.Time) (int64, bool) {
+	if len(code) != Digits {
+		return 0, false
+	}
+
+	current := Step(t)
+	for step := current - Skew; step <= current+Skew; step++ {
+		if subtle.ConstantTimeCompare([]byte(Code(secret, step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,117 @@
+package totp
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCode(t *testing.T) {
+	// Test vectors of RFC 6238 for SHA1, keeping the last 6 of their 8 digits
+	secret := []byte("12345678901234567890")
+	testCases := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, tc := range testCases {
+		step := Step(time.Unix(tc.unix, 0))
+		require.Equal(t, tc.code, Code(secret, step))
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+	require.Len(t, secret, SecretSize)
+
+	now := time.Now()
+	step := Step(now)
+
+	matched, ok := Validate(secret, Code(secret, step), now)
+	require.True(t, ok)
+	require.Equal(t, step, matched)
+
+	// The code of the previous period is still accepted for drifting clocks
+	matched, ok = Validate(secret, Code(secret, step-1), now)
+	require.True(t, ok)
+	require.Equal(t, step-1, matched)
+
+	_, ok = Validate(secret, Code(secret, step-2), now)
+	require.False(t, ok)
+
+	_, ok = Validate(secret, "12345", now)
+	require.False(t, ok)
+
+	otherSecret, err := GenerateSecret()
+	require.NoError(t, err)
+	_, ok = Validate(otherSecret, Code(secret, step), now)
+	require.False(t, ok)
+}
+
+func TestURL(t *testing.T) {
+	secret := []byte("12345678901234567890")
+
+	u, err := url.Parse(URL("Simple Bank", "alice", secret))
+	require.NoError(t, err)
+	require.Equal(t, "otpauth", u.Scheme)
+	require.Equal(t, "totp", u.Host)
+	require.Equal(t, "/Simple Bank:alice", u.Path)
+	require.Equal(t, "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ", u.Query().Get("secret"))
+	require.Equal(t, "Simple Bank", u.Query().Get("issuer"))
+}
+
+func TestCipher(t *testing.T) {
+	cipher, err := NewCipher(strings.Repeat("k", KeySize))
+	require.NoError(t, err)
+
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+
+	ciphertext, err := cipher.Encrypt(secret, "alice")
+	require.NoError(t, err)
+	require.NotContains(t, string(ciphertext), string(secret))
+
+	decrypted, err := cipher.Decrypt(ciphertext, "alice")
+	require.NoError(t, err)
+	require.Equal(t, secret, decrypted)
+
+	// The secret of a user can't be decrypted as another user's
+	_, err = cipher.Decrypt(ciphertext, "bob")
+	require.ErrorIs(t, err, ErrInvalidCiphertext)
+
+	otherCipher, err := NewCipher(strings.Repeat("o", KeySize))
+	require.NoError(t, err)
+	_, err = otherCipher.Decrypt(ciphertext, "alice")
+	require.ErrorIs(t, err, ErrInvalidCiphertext)
+
+	_, err = NewCipher("short")
+	require.Error(t, err)
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes()
+	require.NoError(t, err)
+	require.Len(t, codes, RecoveryCodeCount)
+
+	seen := make(map[string]bool)
+	for _, code := range codes {
+		require.Len(t, code, 9)
+		require.False(t, seen[code])
+		seen[code] = true
+	}
+
+	// Codes are matched however they are typed
+	code := codes[0]
+	require.Equal(t, HashRecoveryCode(code), HashRecoveryCode(strings.ToUpper(strings.ReplaceAll(code, "-", ""))))
+	require.NotEqual(t, HashRecoveryCode(codes[0]), HashRecoveryCode(codes[1]))
+}
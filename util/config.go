@@ -9,12 +9,30 @@ import (
 // Config stores all configuration of the application.
 // The values are read by viper from a config file or environment variable.
 type Config struct {
-	DBDriver             string        `mapstructure:"DB_DRIVER"`
-	DBSource             string        `mapstructure:"DB_SOURCE"`
-	ServerAddress        string        `mapstructure:"SERVER_ADDRESS"`
-	TokenSymmetricKey    string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
-	AccessTokenDuration  time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
-	RefreshTokenDuration time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
+	DBDriver                   string        `mapstructure:"DB_DRIVER"`
+	DBSource                   string        `mapstructure:"DB_SOURCE"`
+	ServerAddress              string        `mapstructure:"SERVER_ADDRESS"`
+	TokenSymmetricKey          string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
+	AccessTokenDuration        time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
+	RefreshTokenDuration       time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
+	SessionCacheDuration       time.Duration `mapstructure:"SESSION_CACHE_DURATION"`
+	ChallengeTokenDuration     time.Duration `mapstructure:"CHALLENGE_TOKEN_DURATION"`
+	TOTPEncryptionKey          string        `mapstructure:"TOTP_ENCRYPTION_KEY"`
+	IdempotencyKeyDuration     time.Duration `mapstructure:"IDEMPOTENCY_KEY_DURATION"`
+	IdempotencyCleanupInterval time.Duration `mapstructure:"IDEMPOTENCY_CLEANUP_INTERVAL"`
+	FXRatesFile                string        `mapstructure:"FX_RATES_FILE"`
+	FXQuoteDuration            time.Duration `mapstructure:"FX_QUOTE_DURATION"`
+	FXSpreadBps                int32         `mapstructure:"FX_SPREAD_BPS"`
+	ScheduledTransferInterval  time.Duration `mapstructure:"SCHEDULED_TRANSFER_INTERVAL"`
+	HoldDuration               time.Duration `mapstructure:"HOLD_DURATION"`
+	MaxAccountsPerCurrency     int64         `mapstructure:"MAX_ACCOUNTS_PER_CURRENCY"`
+	HoldExpiryInterval         time.Duration `mapstructure:"HOLD_EXPIRY_INTERVAL"`
+	InterestInterval           time.Duration `mapstructure:"INTEREST_INTERVAL"`
+	StatementInterval          time.Duration `mapstructure:"STATEMENT_INTERVAL"`
+	BalanceSnapshotInterval    time.Duration `mapstructure:"BALANCE_SNAPSHOT_INTERVAL"`
+	// ReconcileInterval is how often the ledger is reconciled in the background, which is never when zero
+	ReconcileInterval time.Duration `mapstructure:"RECONCILE_INTERVAL"`
+	MetricsAddress    string        `mapstructure:"METRICS_ADDRESS"`
 }
 
 // LoadConfig reads configuration from file or environment variables.
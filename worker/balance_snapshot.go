@@ -0,0 +1,37 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+const (
+	// balanceSnapshotCatchUpDays is how many past days are snapshotted on every run,
+	// so the days the worker was down for still get their snapshots
+	balanceSnapshotCatchUpDays = 7
+	// balanceSnapshotDelay is how long after the end of a day its snapshots are taken, so transactions
+	// which started before midnight have committed their entries by then
+	balanceSnapshotDelay = time.Hour
+)
+
+// snapshotBalances takes the end of day balances of every account for the last days.
+// Days which have their snapshots already are skipped, so it can run as often as needed
+func (worker *Worker) snapshotBalances(ctx context.Context) error {
+	lastDay := startOfDay(time.Now().Add(-balanceSnapshotDelay)).AddDate(0, 0, -1)
+
+	// The oldest day is taken first
+	for days := balanceSnapshotCatchUpDays - 1; days >= 0; days-- {
+		date := lastDay.AddDate(0, 0, -days)
+		taken, err := worker.store.CreateBalanceSnapshots(ctx, date)
+		if err != nil {
+			return fmt.Errorf("cannot take balance snapshots for %s: %w", date.Format("2006-01-02"), err)
+		}
+		if taken > 0 {
+			log.Printf("took balance snapshots of %d accounts for %s", taken, date.Format("2006-01-02"))
+		}
+	}
+
+	return nil
+}
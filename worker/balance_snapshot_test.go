@@ -0,0 +1,35 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	mockdb "simplebank/db/mock"
+	"simplebank/util"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotBalances(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	lastDay := startOfDay(time.Now().Add(-balanceSnapshotDelay)).AddDate(0, 0, -1)
+
+	// Every past day is taken, from the oldest, once it has been over for a while
+	var calls []*gomock.Call
+	for days := balanceSnapshotCatchUpDays - 1; days >= 0; days-- {
+		calls = append(calls, store.EXPECT().
+			CreateBalanceSnapshots(gomock.Any(), gomock.Eq(lastDay.AddDate(0, 0, -days))).
+			Times(1).
+			Return(int64(0), nil))
+	}
+	gomock.InOrder(calls...)
+
+	worker := NewWorker(util.Config{}, store)
+	err := worker.snapshotBalances(context.Background())
+	require.NoError(t, err)
+}
@@ -0,0 +1,41 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	db "simplebank/db/sqlc"
+)
+
+const holdBatchSize = 100
+
+// expireHolds releases the money of every hold which wasn't captured or voided in time
+func (worker *Worker) expireHolds(ctx context.Context) error {
+	for {
+		holds, err := worker.store.ListExpiredHolds(ctx, holdBatchSize)
+		if err != nil {
+			return fmt.Errorf("cannot list expired holds: %w", err)
+		}
+
+		expired := 0
+		for _, hold := range holds {
+			_, err := worker.store.ExpireHoldTx(ctx, hold.ID)
+			if err != nil {
+				// The hold was captured or voided since it was listed
+				if errors.Is(err, db.ErrHoldNotActive) {
+					continue
+				}
+				log.Printf("cannot expire hold [%d]: %v", hold.ID, err)
+				continue
+			}
+			expired++
+		}
+
+		// Holds which keep failing are left for the next run, instead of being listed over and over
+		if len(holds) < holdBatchSize || expired == 0 {
+			return nil
+		}
+	}
+}
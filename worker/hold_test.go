@@ -0,0 +1,37 @@
+package worker
+
+import (
+	"context"
+	"testing"
+
+	mockdb "simplebank/db/mock"
+	db "simplebank/db/sqlc"
+	"simplebank/util"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpireHolds(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	hold1 := db.Hold{ID: util.RandomInt(1, 1000)}
+	hold2 := db.Hold{ID: hold1.ID + 1}
+
+	store.EXPECT().
+		ListExpiredHolds(gomock.Any(), gomock.Eq(int32(holdBatchSize))).
+		Times(1).
+		Return([]db.Hold{hold1, hold2}, nil)
+	store.EXPECT().ExpireHoldTx(gomock.Any(), gomock.Eq(hold1.ID)).Times(1)
+	// A hold which was captured in the meantime is skipped
+	store.EXPECT().
+		ExpireHoldTx(gomock.Any(), gomock.Eq(hold2.ID)).
+		Times(1).
+		Return(db.HoldTxResult{}, db.ErrHoldNotActive)
+
+	worker := NewWorker(util.Config{}, store)
+	err := worker.expireHolds(context.Background())
+	require.NoError(t, err)
+}
@@ -0,0 +1,20 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log"
+)
+
+// deleteExpiredIdempotencyKeys forgets the keys whose responses can't be replayed anymore
+func (worker *Worker) deleteExpiredIdempotencyKeys(ctx context.Context) error {
+	deleted, err := worker.store.DeleteExpiredIdempotencyKeys(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot delete expired idempotency keys: %w", err)
+	}
+	if deleted > 0 {
+		log.Printf("deleted %d expired idempotency keys", deleted)
+	}
+
+	return nil
+}
@@ -0,0 +1,27 @@
+package worker
+
+import (
+	"context"
+	"testing"
+
+	mockdb "simplebank/db/mock"
+	"simplebank/util"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteExpiredIdempotencyKeys(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		DeleteExpiredIdempotencyKeys(gomock.Any()).
+		Times(1).
+		Return(int64(3), nil)
+
+	worker := NewWorker(util.Config{}, store)
+	err := worker.deleteExpiredIdempotencyKeys(context.Background())
+	require.NoError(t, err)
+}
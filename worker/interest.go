@@ -0,0 +1,70 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	db "simplebank/db/sqlc"
+)
+
+// interestCatchUpDays is how many past days are accrued on every run, so the interest
+// of the days the worker was down for is still paid
+const interestCatchUpDays = 7
+
+// accrueInterest records the interest every account earned on the last days.
+// Days which were accrued already are skipped, so it can run as often as needed
+func (worker *Worker) accrueInterest(ctx context.Context) error {
+	today := startOfDay(time.Now())
+
+	// The oldest day is accrued first, and today is left until it's over
+	for days := interestCatchUpDays; days > 0; days-- {
+		date := today.AddDate(0, 0, -days)
+		accrued, err := worker.store.AccrueInterest(ctx, date)
+		if err != nil {
+			return fmt.Errorf("cannot accrue interest for %s: %w", date.Format("2006-01-02"), err)
+		}
+		if accrued > 0 {
+			log.Printf("accrued interest of %d accounts for %s", accrued, date.Format("2006-01-02"))
+		}
+	}
+
+	return nil
+}
+
+// postInterest pays the interest accrued until the end of the last month.
+// Accounts which were already paid for the month are skipped
+func (worker *Worker) postInterest(ctx context.Context) error {
+	today := startOfDay(time.Now())
+	periodEnd := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+	period := periodEnd.AddDate(0, -1, 0)
+
+	accountIDs, err := worker.store.ListAccountsWithUnpostedInterest(ctx, periodEnd)
+	if err != nil {
+		return fmt.Errorf("cannot list accounts with interest to post: %w", err)
+	}
+
+	for _, accountID := range accountIDs {
+		_, err := worker.store.PostInterestTx(ctx, db.PostInterestTxParams{
+			AccountID: accountID,
+			Period:    period,
+		})
+		if err != nil {
+			// Accruals which are still unpaid after the month was posted are left for the next month
+			if errors.Is(err, db.ErrInterestAlreadyPosted) {
+				continue
+			}
+			log.Printf("cannot post interest of account [%d]: %v", accountID, err)
+		}
+	}
+
+	return nil
+}
+
+// startOfDay returns the start of the day of a time, in UTC like the interest accruals
+func startOfDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,71 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	mockdb "simplebank/db/mock"
+	db "simplebank/db/sqlc"
+	"simplebank/util"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccrueInterest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	today := startOfDay(time.Now())
+
+	// Every past day is accrued, from the oldest, but today isn't over yet
+	var calls []*gomock.Call
+	for days := interestCatchUpDays; days > 0; days-- {
+		calls = append(calls, store.EXPECT().
+			AccrueInterest(gomock.Any(), gomock.Eq(today.AddDate(0, 0, -days))).
+			Times(1).
+			Return(int64(0), nil))
+	}
+	gomock.InOrder(calls...)
+
+	worker := NewWorker(util.Config{}, store)
+	err := worker.accrueInterest(context.Background())
+	require.NoError(t, err)
+}
+
+func TestPostInterest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	today := startOfDay(time.Now())
+	periodEnd := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	accountID1 := util.RandomInt(1, 1000)
+	accountID2 := accountID1 + 1
+
+	store.EXPECT().
+		ListAccountsWithUnpostedInterest(gomock.Any(), gomock.Eq(periodEnd)).
+		Times(1).
+		Return([]int64{accountID1, accountID2}, nil)
+	store.EXPECT().
+		PostInterestTx(gomock.Any(), gomock.Eq(db.PostInterestTxParams{
+			AccountID: accountID1,
+			Period:    periodEnd.AddDate(0, -1, 0),
+		})).
+		Times(1)
+	// An account which was paid by another worker is skipped
+	store.EXPECT().
+		PostInterestTx(gomock.Any(), gomock.Eq(db.PostInterestTxParams{
+			AccountID: accountID2,
+			Period:    periodEnd.AddDate(0, -1, 0),
+		})).
+		Times(1).
+		Return(db.PostInterestTxResult{}, db.ErrInterestAlreadyPosted)
+
+	worker := NewWorker(util.Config{}, store)
+	err := worker.postInterest(context.Background())
+	require.NoError(t, err)
+}
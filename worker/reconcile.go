@@ -0,0 +1,39 @@
+package worker
+
+import (
+	"context"
+	"expvar"
+	"log"
+	"time"
+
+	"simplebank/reconcile"
+)
+
+// Metrics of the last reconciliation, published on /debug/vars.
+// Alerts should fire when reconcile_failed_checks isn't zero
+var (
+	reconcileFailedChecks = expvar.NewInt("reconcile_failed_checks")
+	reconcileViolations   = expvar.NewInt("reconcile_violations")
+	reconcileLastRun      = expvar.NewString("reconcile_last_run")
+)
+
+// reconcileLedger checks the invariants of the ledger and publishes how many of them are broken
+func (worker *Worker) reconcileLedger(ctx context.Context) error {
+	report, err := reconcile.Run(ctx, worker.store)
+	if err != nil {
+		return err
+	}
+
+	violations := 0
+	for _, check := range report.Checks {
+		violations += len(check.Violations)
+		if !check.Passed {
+			log.Printf("reconciliation check %s found %d violations", check.Name, len(check.Violations))
+		}
+	}
+
+	reconcileFailedChecks.Set(int64(report.FailedChecks()))
+	reconcileViolations.Set(int64(violations))
+	reconcileLastRun.Set(report.CheckedAt.Format(time.RFC3339))
+	return nil
+}
@@ -0,0 +1,37 @@
+package worker
+
+import (
+	"context"
+	"testing"
+
+	mockdb "simplebank/db/mock"
+	db "simplebank/db/sqlc"
+	"simplebank/util"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconcileLedger(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		ListBalanceMismatches(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return([]db.ListBalanceMismatchesRow{{AccountID: 1}, {AccountID: 2}}, nil)
+	store.EXPECT().ListTransferEntryMismatches(gomock.Any(), gomock.Any()).Times(1)
+	store.EXPECT().ListDepositEntryMismatches(gomock.Any(), gomock.Any()).Times(1)
+	store.EXPECT().ListWithdrawEntryMismatches(gomock.Any(), gomock.Any()).Times(1)
+	store.EXPECT().ListUnbalancedJournals(gomock.Any(), gomock.Any()).Times(1)
+
+	worker := NewWorker(util.Config{}, store)
+	err := worker.reconcileLedger(context.Background())
+	require.NoError(t, err)
+
+	// The alert metric is raised
+	require.Equal(t, int64(1), reconcileFailedChecks.Value())
+	require.Equal(t, int64(2), reconcileViolations.Value())
+	require.NotEmpty(t, reconcileLastRun.Value())
+}
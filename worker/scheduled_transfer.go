@@ -0,0 +1,147 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	db "simplebank/db/sqlc"
+
+	"github.com/lib/pq"
+)
+
+const (
+	scheduledTransferBatchSize = 10
+	// scheduledTransferLease is how long an order stays claimed by a worker
+	scheduledTransferLease      = 5 * time.Minute
+	scheduledTransferRetryDelay = time.Hour
+	// maxScheduledTransferFailures is how many runs in a row can fail before the order is paused
+	maxScheduledTransferFailures = 3
+)
+
+// executeScheduledTransfers runs all the scheduled transfers which are due
+func (worker *Worker) executeScheduledTransfers(ctx context.Context) error {
+	for {
+		orders, err := worker.store.ClaimDueScheduledTransfers(ctx, db.ClaimDueScheduledTransfersParams{
+			LockedUntil: time.Now().Add(scheduledTransferLease),
+			Limit:       scheduledTransferBatchSize,
+		})
+		if err != nil {
+			return fmt.Errorf("cannot claim scheduled transfers: %w", err)
+		}
+
+		for _, order := range orders {
+			// An order which couldn't be updated stays claimed until the lease is over
+			if err := worker.executeScheduledTransfer(ctx, order); err != nil {
+				log.Printf("cannot execute scheduled transfer [%d]: %v", order.ID, err)
+			}
+		}
+
+		if len(orders) < scheduledTransferBatchSize {
+			return nil
+		}
+	}
+}
+
+// executeScheduledTransfer makes the transfer of a claimed order and schedules its next run
+func (worker *Worker) executeScheduledTransfer(ctx context.Context, order db.ScheduledTransfer) error {
+	// The attempt is recorded before the transfer, so an occurrence which a crashed worker
+	// may have already executed is never made again
+	run, err := worker.store.CreateScheduledTransferRun(ctx, db.CreateScheduledTransferRunParams{
+		ScheduledTransferID: order.ID,
+		ScheduledFor:        order.NextRunAt,
+		Status:              db.RunPending,
+	})
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "unique_violation" {
+			// The outcome of the previous attempt is unknown, so the order has to be checked before going on
+			return worker.finishScheduledTransferRun(ctx, order, order.NextRunAt, db.ScheduledTransferPaused, order.FailureCount)
+		}
+		return err
+	}
+
+	result, transferErr := worker.store.TransferTx(ctx, db.TransferTxParams{
+		FromAccountID: order.FromAccountID,
+		ToAccountID:   order.ToAccountID,
+		Amount:        order.Amount,
+		User:          order.Owner,
+	})
+	if transferErr != nil {
+		_, err = worker.store.UpdateScheduledTransferRun(ctx, db.UpdateScheduledTransferRunParams{
+			ID:     run.ID,
+			Status: db.RunFailed,
+			Error:  transferErr.Error(),
+		})
+		if err != nil {
+			return err
+		}
+
+		failureCount := order.FailureCount + 1
+		if failureCount >= maxScheduledTransferFailures {
+			return worker.finishScheduledTransferRun(ctx, order, order.NextRunAt, db.ScheduledTransferPaused, failureCount)
+		}
+		return worker.finishScheduledTransferRun(ctx, order, time.Now().Add(scheduledTransferRetryDelay), db.ScheduledTransferActive, failureCount)
+	}
+
+	_, err = worker.store.UpdateScheduledTransferRun(ctx, db.UpdateScheduledTransferRunParams{
+		ID:         run.ID,
+		Status:     db.RunSucceeded,
+		TransferID: sql.NullInt64{Int64: result.Transfer.ID, Valid: true},
+	})
+	if err != nil {
+		return err
+	}
+
+	if order.Frequency == db.FrequencyOnce {
+		return worker.finishScheduledTransferRun(ctx, order, order.NextRunAt, db.ScheduledTransferCompleted, 0)
+	}
+	return worker.finishScheduledTransferRun(ctx, order, nextRunAt(order.Frequency, order.StartAt, time.Now()), db.ScheduledTransferActive, 0)
+}
+
+func (worker *Worker) finishScheduledTransferRun(ctx context.Context, order db.ScheduledTransfer, next time.Time, status string, failureCount int32) error {
+	_, err := worker.store.FinishScheduledTransferRun(ctx, db.FinishScheduledTransferRunParams{
+		ID:           order.ID,
+		NextRunAt:    next,
+		Status:       status,
+		FailureCount: failureCount,
+	})
+	return err
+}
+
+// nextRunAt returns the first occurrence of a recurring order after the given time.
+// Occurrences missed while no worker was running are skipped
+func nextRunAt(frequency string, startAt time.Time, after time.Time) time.Time {
+	for n := 1; ; n++ {
+		next := occurrence(frequency, startAt, n)
+		if next.After(after) {
+			return next
+		}
+	}
+}
+
+// occurrence returns when the n-th run after the start of an order happens
+func occurrence(frequency string, startAt time.Time, n int) time.Time {
+	switch frequency {
+	case db.FrequencyDaily:
+		return startAt.AddDate(0, 0, n)
+	case db.FrequencyWeekly:
+		return startAt.AddDate(0, 0, 7*n)
+	default:
+		return addMonths(startAt, n)
+	}
+}
+
+// addMonths keeps the day of the month, using the last day of shorter months
+func addMonths(t time.Time, months int) time.Time {
+	year, month, day := t.Date()
+	first := time.Date(year, month+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+
+	lastDay := first.AddDate(0, 1, -1).Day()
+	if day > lastDay {
+		day = lastDay
+	}
+
+	return first.AddDate(0, 0, day-1)
+}
@@ -0,0 +1,254 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	mockdb "simplebank/db/mock"
+	db "simplebank/db/sqlc"
+	"simplebank/util"
+
+	"github.com/golang/mock/gomock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecuteScheduledTransfer(t *testing.T) {
+	startAt := time.Date(2023, time.January, 31, 9, 0, 0, 0, time.UTC)
+	run := db.ScheduledTransferRun{ID: util.RandomInt(1, 1000)}
+	transfer := db.Transfer{ID: util.RandomInt(1, 1000)}
+
+	testCases := []struct {
+		name       string
+		order      db.ScheduledTransfer
+		buildStubs func(store *mockdb.MockStore, order db.ScheduledTransfer)
+	}{
+		{
+			name:  "OK",
+			order: randomScheduledTransfer(db.FrequencyMonthly, startAt, 0),
+			buildStubs: func(store *mockdb.MockStore, order db.ScheduledTransfer) {
+				store.EXPECT().
+					CreateScheduledTransferRun(gomock.Any(), gomock.Eq(db.CreateScheduledTransferRunParams{
+						ScheduledTransferID: order.ID,
+						ScheduledFor:        order.NextRunAt,
+						Status:              db.RunPending,
+					})).
+					Times(1).
+					Return(run, nil)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Eq(db.TransferTxParams{
+						FromAccountID: order.FromAccountID,
+						ToAccountID:   order.ToAccountID,
+						Amount:        order.Amount,
+						User:          order.Owner,
+					})).
+					Times(1).
+					Return(db.TransferTxResult{Transfer: transfer}, nil)
+				store.EXPECT().
+					UpdateScheduledTransferRun(gomock.Any(), gomock.Eq(db.UpdateScheduledTransferRunParams{
+						ID:         run.ID,
+						Status:     db.RunSucceeded,
+						TransferID: sql.NullInt64{Int64: transfer.ID, Valid: true},
+					})).
+					Times(1)
+				store.EXPECT().
+					FinishScheduledTransferRun(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.FinishScheduledTransferRunParams) (db.ScheduledTransfer, error) {
+						require.Equal(t, order.ID, arg.ID)
+						require.Equal(t, db.ScheduledTransferActive, arg.Status)
+						require.Zero(t, arg.FailureCount)
+						require.True(t, arg.NextRunAt.After(time.Now()))
+						require.Equal(t, startAt.Hour(), arg.NextRunAt.Hour())
+						return db.ScheduledTransfer{}, nil
+					})
+			},
+		},
+		{
+			name:  "OnceCompleted",
+			order: randomScheduledTransfer(db.FrequencyOnce, startAt, 0),
+			buildStubs: func(store *mockdb.MockStore, order db.ScheduledTransfer) {
+				store.EXPECT().CreateScheduledTransferRun(gomock.Any(), gomock.Any()).Times(1).Return(run, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{Transfer: transfer}, nil)
+				store.EXPECT().UpdateScheduledTransferRun(gomock.Any(), gomock.Any()).Times(1)
+				store.EXPECT().
+					FinishScheduledTransferRun(gomock.Any(), gomock.Eq(db.FinishScheduledTransferRunParams{
+						ID:           order.ID,
+						NextRunAt:    order.NextRunAt,
+						Status:       db.ScheduledTransferCompleted,
+						FailureCount: 0,
+					})).
+					Times(1)
+			},
+		},
+		{
+			name:  "TransferFailed",
+			order: randomScheduledTransfer(db.FrequencyMonthly, startAt, 0),
+			buildStubs: func(store *mockdb.MockStore, order db.ScheduledTransfer) {
+				store.EXPECT().CreateScheduledTransferRun(gomock.Any(), gomock.Any()).Times(1).Return(run, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, db.ErrInsufficientFunds)
+				store.EXPECT().
+					UpdateScheduledTransferRun(gomock.Any(), gomock.Eq(db.UpdateScheduledTransferRunParams{
+						ID:     run.ID,
+						Status: db.RunFailed,
+						Error:  db.ErrInsufficientFunds.Error(),
+					})).
+					Times(1)
+				store.EXPECT().
+					FinishScheduledTransferRun(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.FinishScheduledTransferRunParams) (db.ScheduledTransfer, error) {
+						// The order is retried later
+						require.Equal(t, db.ScheduledTransferActive, arg.Status)
+						require.Equal(t, int32(1), arg.FailureCount)
+						require.WithinDuration(t, time.Now().Add(scheduledTransferRetryDelay), arg.NextRunAt, time.Second)
+						return db.ScheduledTransfer{}, nil
+					})
+			},
+		},
+		{
+			name:  "TooManyFailures",
+			order: randomScheduledTransfer(db.FrequencyMonthly, startAt, maxScheduledTransferFailures-1),
+			buildStubs: func(store *mockdb.MockStore, order db.ScheduledTransfer) {
+				store.EXPECT().CreateScheduledTransferRun(gomock.Any(), gomock.Any()).Times(1).Return(run, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, db.ErrInsufficientFunds)
+				store.EXPECT().UpdateScheduledTransferRun(gomock.Any(), gomock.Any()).Times(1)
+				store.EXPECT().
+					FinishScheduledTransferRun(gomock.Any(), gomock.Eq(db.FinishScheduledTransferRunParams{
+						ID:           order.ID,
+						NextRunAt:    order.NextRunAt,
+						Status:       db.ScheduledTransferPaused,
+						FailureCount: maxScheduledTransferFailures,
+					})).
+					Times(1)
+			},
+		},
+		{
+			name:  "PreviousRunPending",
+			order: randomScheduledTransfer(db.FrequencyMonthly, startAt, 0),
+			buildStubs: func(store *mockdb.MockStore, order db.ScheduledTransfer) {
+				store.EXPECT().
+					CreateScheduledTransferRun(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ScheduledTransferRun{}, &pq.Error{Code: "23505"})
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().
+					FinishScheduledTransferRun(gomock.Any(), gomock.Eq(db.FinishScheduledTransferRunParams{
+						ID:           order.ID,
+						NextRunAt:    order.NextRunAt,
+						Status:       db.ScheduledTransferPaused,
+						FailureCount: 0,
+					})).
+					Times(1)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store, tc.order)
+
+			worker := NewWorker(util.Config{}, store)
+			err := worker.executeScheduledTransfer(context.Background(), tc.order)
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestExecuteScheduledTransfers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	order := randomScheduledTransfer(db.FrequencyOnce, time.Now(), 0)
+
+	store.EXPECT().
+		ClaimDueScheduledTransfers(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ interface{}, arg db.ClaimDueScheduledTransfersParams) ([]db.ScheduledTransfer, error) {
+			require.Equal(t, int32(scheduledTransferBatchSize), arg.Limit)
+			require.WithinDuration(t, time.Now().Add(scheduledTransferLease), arg.LockedUntil, time.Second)
+			return []db.ScheduledTransfer{order}, nil
+		})
+	store.EXPECT().CreateScheduledTransferRun(gomock.Any(), gomock.Any()).Times(1)
+	store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1)
+	store.EXPECT().UpdateScheduledTransferRun(gomock.Any(), gomock.Any()).Times(1)
+	store.EXPECT().FinishScheduledTransferRun(gomock.Any(), gomock.Any()).Times(1)
+
+	worker := NewWorker(util.Config{}, store)
+	err := worker.executeScheduledTransfers(context.Background())
+	require.NoError(t, err)
+}
+
+func TestNextRunAt(t *testing.T) {
+	startAt := time.Date(2023, time.January, 31, 9, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name      string
+		frequency string
+		after     time.Time
+		expected  time.Time
+	}{
+		{
+			name:      "Daily",
+			frequency: db.FrequencyDaily,
+			after:     startAt,
+			expected:  time.Date(2023, time.February, 1, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "Weekly",
+			frequency: db.FrequencyWeekly,
+			after:     startAt.Add(time.Hour),
+			expected:  time.Date(2023, time.February, 7, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "MonthlyShorterMonth",
+			frequency: db.FrequencyMonthly,
+			after:     startAt,
+			expected:  time.Date(2023, time.February, 28, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "MonthlyKeepsDay",
+			frequency: db.FrequencyMonthly,
+			after:     time.Date(2023, time.February, 28, 9, 0, 0, 0, time.UTC),
+			expected:  time.Date(2023, time.March, 31, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "MissedRunsAreSkipped",
+			frequency: db.FrequencyMonthly,
+			after:     time.Date(2023, time.June, 15, 0, 0, 0, 0, time.UTC),
+			expected:  time.Date(2023, time.June, 30, 9, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, nextRunAt(tc.frequency, startAt, tc.after))
+		})
+	}
+}
+
+func randomScheduledTransfer(frequency string, startAt time.Time, failureCount int32) db.ScheduledTransfer {
+	return db.ScheduledTransfer{
+		ID:            util.RandomInt(1, 1000),
+		Owner:         util.RandomOwner(),
+		FromAccountID: util.RandomInt(1, 1000),
+		ToAccountID:   util.RandomInt(1, 1000),
+		Amount:        util.RandomMoney(),
+		Frequency:     frequency,
+		StartAt:       startAt,
+		NextRunAt:     startAt,
+		Status:        db.ScheduledTransferActive,
+		FailureCount:  failureCount,
+	}
+}
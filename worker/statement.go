@@ -0,0 +1,77 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	db "simplebank/db/sqlc"
+	"simplebank/statement"
+)
+
+const statementBatchSize = 100
+
+// generateStatements stores the statements of last month, so they are ready to be downloaded.
+// Accounts which have their statement already are skipped
+func (worker *Worker) generateStatements(ctx context.Context) error {
+	now := time.Now().UTC()
+	periodEnd := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	period := periodEnd.AddDate(0, -1, 0)
+
+	for {
+		accounts, err := worker.store.ListAccountsWithoutStatement(ctx, db.ListAccountsWithoutStatementParams{
+			PeriodEnd: periodEnd,
+			Period:    period,
+			Limit:     statementBatchSize,
+		})
+		if err != nil {
+			return fmt.Errorf("cannot list accounts without statement: %w", err)
+		}
+
+		generated := 0
+		for _, account := range accounts {
+			if err := worker.generateStatement(ctx, account, period); err != nil {
+				log.Printf("cannot generate statement of account [%d]: %v", account.ID, err)
+				continue
+			}
+			generated++
+		}
+
+		// Statements which keep failing are left for the next run, instead of being listed over and over
+		if len(accounts) < statementBatchSize || generated == 0 {
+			return nil
+		}
+	}
+}
+
+// generateStatement stores the statement of an account in every downloadable format
+func (worker *Worker) generateStatement(ctx context.Context, account db.Account, period time.Time) error {
+	accountStatement, err := statement.Generate(ctx, worker.store, account, period)
+	if err != nil {
+		return err
+	}
+
+	pdf, err := accountStatement.PDF()
+	if err != nil {
+		return fmt.Errorf("cannot render pdf: %w", err)
+	}
+
+	csv, err := accountStatement.CSV()
+	if err != nil {
+		return fmt.Errorf("cannot render csv: %w", err)
+	}
+
+	_, err = worker.store.CreateStatement(ctx, db.CreateStatementParams{
+		AccountID: account.ID,
+		Period:    period,
+		Pdf:       pdf,
+		Csv:       csv,
+	})
+	// Another worker stored the statement in the meantime
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,57 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	mockdb "simplebank/db/mock"
+	db "simplebank/db/sqlc"
+	"simplebank/util"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateStatements(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	account := db.Account{
+		ID:       util.RandomInt(1, 1000),
+		Owner:    util.RandomOwner(),
+		Balance:  util.RandomMoney(),
+		Currency: util.USD,
+	}
+
+	now := time.Now().UTC()
+	periodEnd := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	period := periodEnd.AddDate(0, -1, 0)
+
+	store.EXPECT().
+		ListAccountsWithoutStatement(gomock.Any(), gomock.Eq(db.ListAccountsWithoutStatementParams{
+			PeriodEnd: periodEnd,
+			Period:    period,
+			Limit:     statementBatchSize,
+		})).
+		Times(1).
+		Return([]db.Account{account}, nil)
+	store.EXPECT().GetAccountBalanceAt(gomock.Any(), gomock.Any()).Times(1).Return(account.Balance, nil)
+	store.EXPECT().ListStatementEntries(gomock.Any(), gomock.Any()).Times(1)
+	store.EXPECT().
+		CreateStatement(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ interface{}, arg db.CreateStatementParams) (db.Statement, error) {
+			require.Equal(t, account.ID, arg.AccountID)
+			require.Equal(t, period, arg.Period)
+			require.True(t, bytes.HasPrefix(arg.Pdf, []byte("%PDF-")))
+			require.Contains(t, string(arg.Csv), "Closing balance")
+			return db.Statement{ID: 1}, nil
+		})
+
+	worker := NewWorker(util.Config{}, store)
+	err := worker.generateStatements(context.Background())
+	require.NoError(t, err)
+}
@@ -0,0 +1,58 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	db "simplebank/db/sqlc"
+	"simplebank/util"
+)
+
+// Worker runs the background jobs of our simple banking service.
+// Several instances can run at the same time, since every job claims its work on the database
+type Worker struct {
+	config util.Config
+	store  db.Store
+}
+
+// NewWorker creates a new background worker
+func NewWorker(config util.Config, store db.Store) *Worker {
+	return &Worker{
+		config: config,
+		store:  store,
+	}
+}
+
+// Start runs every job periodically, until the context is cancelled
+func (worker *Worker) Start(ctx context.Context) {
+	go worker.runPeriodically(ctx, "scheduled transfers", worker.config.ScheduledTransferInterval, worker.executeScheduledTransfers)
+	go worker.runPeriodically(ctx, "hold expiry", worker.config.HoldExpiryInterval, worker.expireHolds)
+	go worker.runPeriodically(ctx, "interest accrual", worker.config.InterestInterval, worker.accrueInterest)
+	go worker.runPeriodically(ctx, "interest posting", worker.config.InterestInterval, worker.postInterest)
+	go worker.runPeriodically(ctx, "balance snapshots", worker.config.BalanceSnapshotInterval, worker.snapshotBalances)
+	go worker.runPeriodically(ctx, "statements", worker.config.StatementInterval, worker.generateStatements)
+	go worker.runPeriodically(ctx, "idempotency key cleanup", worker.config.IdempotencyCleanupInterval, worker.deleteExpiredIdempotencyKeys)
+
+	// Reconciliation is optional, since it goes through every entry of the ledger
+	if worker.config.ReconcileInterval > 0 {
+		go worker.runPeriodically(ctx, "reconciliation", worker.config.ReconcileInterval, worker.reconcileLedger)
+	}
+}
+
+func (worker *Worker) runPeriodically(ctx context.Context, name string, interval time.Duration, job func(ctx context.Context) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := job(ctx); err != nil {
+			log.Printf("%s job failed: %v", name, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}